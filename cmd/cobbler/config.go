@@ -0,0 +1,153 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/config"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// Global flag names.
+const (
+	flagConfig    = "config"
+	flagDataDir   = "data-dir"
+	flagLogLevel  = "log-level"
+	flagLogFormat = "log-format"
+	flagQuiet     = "quiet"
+	// flagAgent, the agent retry flags, and the inspect flags below are
+	// defined by the subcommands that use them; loadConfig honors them when
+	// set.
+	flagAgent            = "agent"
+	flagAgentMaxAttempts = "agent-max-attempts"
+	flagAgentRetryDelay  = "agent-retry-delay"
+	flagConcurrency      = "concurrency"
+	flagTechniqueTimeout = "technique-timeout"
+	flagSecurity         = "security"
+	flagExpect           = "expect"
+	flagStrict           = "strict"
+)
+
+// cfg is the configuration resolved before any subcommand runs.
+var cfg = config.Default()
+
+// logger receives the structured events of the running command. It is
+// replaced before any subcommand runs.
+var logger = logging.OrDiscard(nil)
+
+// addGlobalFlags registers the flags every subcommand inherits.
+func addGlobalFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.String(flagConfig, "", "Configuration file (YAML)")
+	flags.String(flagDataDir, crumbs.DefaultDataDir, "Crumbs data directory")
+	flags.String(flagLogLevel, logging.DefaultLevel, "Log level: debug, info, warn, or error")
+	flags.String(flagLogFormat, logging.FormatText, "Log format: text or json")
+	flags.BoolP(flagQuiet, "q", false, "Log errors only")
+}
+
+// newLogger returns the logger the log flags of cmd configure, writing to w.
+func newLogger(cmd *cobra.Command, w io.Writer) (*slog.Logger, error) {
+	flags := cmd.Flags()
+	var opts logging.Options
+	opts.Level, _ = flags.GetString(flagLogLevel)
+	opts.Format, _ = flags.GetString(flagLogFormat)
+	opts.Quiet, _ = flags.GetBool(flagQuiet)
+	return logging.New(w, opts)
+}
+
+// loadConfig resolves the configuration for cmd with flag > configuration
+// file > built-in default: it loads --config over the defaults, then applies
+// the flags the user set.
+func loadConfig(cmd *cobra.Command) (config.Config, error) {
+	flags := cmd.Flags()
+	resolved := config.Default()
+	if path, _ := flags.GetString(flagConfig); path != "" {
+		var err error
+		if resolved, err = config.Load(path); err != nil {
+			return config.Config{}, err
+		}
+	}
+	if flags.Changed(flagDataDir) {
+		resolved.DataDir, _ = flags.GetString(flagDataDir)
+	}
+	if flags.Changed(flagAgent) {
+		resolved.Agent.Command, _ = flags.GetString(flagAgent)
+	}
+	if flags.Changed(flagAgentMaxAttempts) {
+		resolved.Agent.MaxAttempts, _ = flags.GetInt(flagAgentMaxAttempts)
+	}
+	if flags.Changed(flagAgentRetryDelay) {
+		delay, _ := flags.GetDuration(flagAgentRetryDelay)
+		resolved.Agent.RetryBaseDelay = delay.String()
+	}
+	if flags.Changed(flagConcurrency) {
+		resolved.Inspect.Concurrency, _ = flags.GetInt(flagConcurrency)
+	}
+	if flags.Changed(flagTechniqueTimeout) {
+		timeout, _ := flags.GetDuration(flagTechniqueTimeout)
+		resolved.Inspect.TechniqueTimeout = timeout.String()
+	}
+	if flags.Changed(flagSecurity) {
+		resolved.Inspect.Security, _ = flags.GetBool(flagSecurity)
+	}
+	if flags.Changed(flagExpect) {
+		resolved.Inspect.Expected, _ = flags.GetStringSlice(flagExpect)
+	}
+	if flags.Changed(flagStrict) {
+		resolved.Inspect.Strict, _ = flags.GetBool(flagStrict)
+	}
+	return resolved, nil
+}
+
+// newAgent creates the command agent c configures, retrying transient
+// failures and logging retries to l.
+func newAgent(c config.Config, l *slog.Logger) (agent.Agent, error) {
+	a, err := agent.NewCommandAgent(c.Agent.Command)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := c.RetryConfig()
+	if err != nil {
+		return nil, err
+	}
+	retry.Logger = l
+	return agent.NewRetryAgent(a, retry), nil
+}
+
+// addAgentFlags registers the agent command and retry flags on cmd.
+func addAgentFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.String(flagAgent, agent.DefaultCommand, "Agent command; the prompt is written to its stdin")
+	flags.Int(flagAgentMaxAttempts, agent.DefaultMaxAttempts, "Agent runs per request when it fails transiently (rate limits, overload)")
+	flags.Duration(flagAgentRetryDelay, agent.DefaultRetryBaseDelay, "Wait before the first agent retry; doubles per retry")
+}
+
+// newPortfolio creates the stitch inspect portfolio scored and run as c
+// configures, with the portfolio techniques c enables, logging to l.
+func newPortfolio(c config.Config, l *slog.Logger) (*inspect.Portfolio, error) {
+	scorer, err := inspect.NewScorer(c.ScorerConfig())
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := c.TechniqueTimeout()
+	if err != nil {
+		return nil, err
+	}
+	p := inspect.NewPortfolio(scorer, inspect.PortfolioConfig{
+		Concurrency:      c.Inspect.Concurrency,
+		Logger:           l,
+		TestCommand:      c.Inspect.TestCommand,
+		TechniqueTimeout: timeout,
+	})
+	for _, tech := range inspect.EnableTechniques(inspect.PortfolioTechniques(), c.Inspect.EnabledTechniques) {
+		p.Register(tech)
+	}
+	if c.Inspect.Security {
+		p.Register(inspect.NewSecurityRunner())
+	}
+	return p, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/config"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cobbler.yaml")
+	content := "data_dir: from-file\nagent:\n  command: file-agent\ninspect:\n  concurrency: 3\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		args            []string
+		wantDataDir     string
+		wantAgent       string
+		wantConcurrency int
+	}{
+		{
+			name:            "built-in defaults",
+			wantDataDir:     crumbs.DefaultDataDir,
+			wantAgent:       agent.DefaultCommand,
+			wantConcurrency: inspect.DefaultPortfolioConcurrency,
+		},
+		{
+			name:            "file over defaults",
+			args:            []string{"--config", file},
+			wantDataDir:     "from-file",
+			wantAgent:       "file-agent",
+			wantConcurrency: 3,
+		},
+		{
+			name:            "flags over file",
+			args:            []string{"--config", file, "--data-dir", "from-flag", "--agent", "flag-agent"},
+			wantDataDir:     "from-flag",
+			wantAgent:       "flag-agent",
+			wantConcurrency: 3,
+		},
+		{
+			name:            "flags over defaults",
+			args:            []string{"--concurrency", "8"},
+			wantDataDir:     crumbs.DefaultDataDir,
+			wantAgent:       agent.DefaultCommand,
+			wantConcurrency: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addGlobalFlags(cmd)
+			cmd.Flags().String(flagAgent, agent.DefaultCommand, "")
+			cmd.Flags().Int(flagConcurrency, inspect.DefaultPortfolioConcurrency, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := loadConfig(cmd)
+			if err != nil {
+				t.Fatalf("loadConfig: %v", err)
+			}
+			if got.DataDir != tt.wantDataDir {
+				t.Errorf("DataDir = %q, want %q", got.DataDir, tt.wantDataDir)
+			}
+			if got.Agent.Command != tt.wantAgent {
+				t.Errorf("Agent.Command = %q, want %q", got.Agent.Command, tt.wantAgent)
+			}
+			if got.Inspect.Concurrency != tt.wantConcurrency {
+				t.Errorf("Inspect.Concurrency = %d, want %d", got.Inspect.Concurrency, tt.wantConcurrency)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	cmd := &cobra.Command{}
+	addGlobalFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(cmd); err == nil {
+		t.Fatal("loadConfig succeeded with a missing config file")
+	}
+}
+
+func TestLoadConfig_Expected(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cobbler.yaml")
+	content := "inspect:\n  expect: [translation_validation, mutation_testing]\n  strict: true\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantExpected []string
+		wantStrict   bool
+	}{
+		{"file", []string{"--config", file}, []string{inspect.TranslationValidatorName, inspect.MutationRunnerName}, true},
+		{"flags over file", []string{"--config", file, "--expect", inspect.CoverageRunnerName, "--strict=false"}, []string{inspect.CoverageRunnerName}, false},
+		{"flags over defaults", []string{"--expect", inspect.CoverageRunnerName, "--strict"}, []string{inspect.CoverageRunnerName}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addGlobalFlags(cmd)
+			cmd.Flags().StringSlice(flagExpect, nil, "")
+			cmd.Flags().Bool(flagStrict, false, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := loadConfig(cmd)
+			if err != nil {
+				t.Fatalf("loadConfig: %v", err)
+			}
+			if !slices.Equal(got.Inspect.Expected, tt.wantExpected) || got.Inspect.Strict != tt.wantStrict {
+				t.Errorf("Expected = %v Strict = %v, want %v %v", got.Inspect.Expected, got.Inspect.Strict, tt.wantExpected, tt.wantStrict)
+			}
+		})
+	}
+}
+
+func TestNewPortfolio_EnabledTechniques(t *testing.T) {
+	c := config.Default()
+	c.Inspect.EnabledTechniques = []string{inspect.TranslationValidatorName}
+	p, err := newPortfolio(c, nil)
+	if err != nil {
+		t.Fatalf("newPortfolio failed: %v", err)
+	}
+	if techniques := p.Techniques(); len(techniques) != 1 || techniques[0].Name() != inspect.TranslationValidatorName {
+		t.Errorf("portfolio techniques = %v, want only the translation validator", techniques)
+	}
+
+	techniques, err := inspectTechniques(inspectOptions{EnabledTechniques: []string{inspect.CoverageRunnerName}, Security: true})
+	if err != nil {
+		t.Fatalf("inspectTechniques failed: %v", err)
+	}
+	var names []string
+	for _, tech := range techniques {
+		names = append(names, tech.Name())
+	}
+	if want := []string{inspect.CoverageRunnerName, inspect.SecurityRunnerName}; !slices.Equal(names, want) {
+		t.Errorf("inspect techniques = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// crumbRecord is the --json form of a crumb.
+type crumbRecord struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	State      types.State    `json:"state"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+func newCrumbRecord(c *types.Crumb) crumbRecord {
+	return crumbRecord{
+		ID:         c.CrumbID,
+		Name:       c.Name,
+		State:      c.State,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+		Properties: c.Properties,
+	}
+}
+
+// crumbsListOptions configures crumbs list.
+type crumbsListOptions struct {
+	States []string
+	// WorkTypes selects crumbs by their work_type property.
+	WorkTypes []string
+	// Tags selects crumbs by tag: those with any of them, or with all of
+	// them when AllTags is set.
+	Tags    []string
+	AllTags bool
+	JSON    bool
+}
+
+// crumbsCreateOptions configures crumbs create.
+type crumbsCreateOptions struct {
+	Name  string
+	State string
+	// Props are key=value pairs. A value that parses as JSON is stored
+	// decoded; anything else is stored as a string.
+	Props []string
+	JSON  bool
+}
+
+var (
+	crumbsListOpts   crumbsListOptions
+	crumbsShowJSON   bool
+	crumbsCreateOpts crumbsCreateOptions
+	crumbsNoVacuum   bool
+	crumbsExportPath string
+)
+
+var crumbsCmd = &cobra.Command{
+	Use:   "crumbs",
+	Short: "List, show, create, check, export, and import crumbs",
+	Long: `Crumbs manages the work items in the cupboard without writing Go.
+
+Output is human-readable by default; --json prints machine-readable JSON.`,
+}
+
+var crumbsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List crumbs, oldest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runCrumbsList(os.Stdout, cupboard, crumbsListOpts)
+		})
+	},
+}
+
+var crumbsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a crumb and its properties",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runCrumbsShow(os.Stdout, cupboard, args[0], crumbsShowJSON)
+		})
+	},
+}
+
+var crumbsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a crumb",
+	Long: `Create adds a crumb to the cupboard and prints its ID.
+
+Set properties with repeated --prop key=value flags. A value that parses as
+JSON (a number, boolean, list, or quoted string) is stored decoded; any other
+value is stored as a string.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runCrumbsCreate(os.Stdout, cupboard, crumbsCreateOpts)
+		})
+	},
+}
+
+var crumbsDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the cupboard database and reclaim space",
+	Long: `Doctor runs SQLite's integrity check on the cupboard database, for example
+after a crash. When the check fails, the database cannot be trusted: restore
+it from an export.
+
+When the check passes, doctor vacuums the database to reclaim the space of
+deleted data; --no-vacuum skips this.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runCrumbsDoctor(os.Stdout, cupboard, !crumbsNoVacuum)
+		})
+	},
+}
+
+var crumbsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write every crumb as JSON",
+	Long: `Export writes every crumb and its properties as a JSON array, oldest first,
+to stdout or to the --output file. Import reads the same format back.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			if crumbsExportPath == "" {
+				return cupboard.ExportCrumbs(os.Stdout)
+			}
+			return runCrumbsExport(crumbsExportPath, cupboard)
+		})
+	},
+}
+
+var crumbsImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Read crumbs from an export",
+	Long: `Import reads crumbs in the export format from file, or from stdin when
+file is omitted or -, and upserts each one under its exported ID. On error,
+the crumbs read before the failing one remain imported.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := io.Reader(os.Stdin)
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runCrumbsImport(os.Stdout, cupboard, r)
+		})
+	},
+}
+
+// withCupboard opens the configured cupboard for fn and closes it after.
+func withCupboard(fn func(*crumbs.Cupboard) error) error {
+	cupboard, err := crumbs.NewCupboard(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	defer cupboard.Close()
+	return fn(cupboard)
+}
+
+// runCrumbsList writes the crumbs in the states, of the work types, and
+// with the tags opts selects (all when none) to w.
+func runCrumbsList(w io.Writer, cupboard *crumbs.Cupboard, opts crumbsListOptions) error {
+	filter := map[string]any{}
+	if len(opts.States) > 0 {
+		states := make([]types.State, 0, len(opts.States))
+		for _, s := range opts.States {
+			state, err := parseState(s)
+			if err != nil {
+				return err
+			}
+			states = append(states, state)
+		}
+		filter["State"] = states
+	}
+	if len(opts.WorkTypes) > 0 {
+		filter[crumbs.PropertyFilterPrefix+crumbs.PropWorkType] = opts.WorkTypes
+	}
+	if len(opts.Tags) > 0 {
+		key := crumbs.TagsAnyFilter
+		if opts.AllTags {
+			key = crumbs.TagsAllFilter
+		}
+		filter[key] = opts.Tags
+	}
+	list, err := cupboard.FetchCrumbs(filter)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		records := make([]crumbRecord, 0, len(list))
+		for _, c := range list {
+			records = append(records, newCrumbRecord(c))
+		}
+		return writeJSON(w, records)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tNAME")
+	for _, c := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.CrumbID, c.State, c.Name)
+	}
+	return tw.Flush()
+}
+
+// runCrumbsShow writes the crumb with the given ID and its properties to w.
+func runCrumbsShow(w io.Writer, cupboard *crumbs.Cupboard, id string, asJSON bool) error {
+	c, err := cupboard.GetCrumb(id)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeJSON(w, newCrumbRecord(c))
+	}
+
+	fmt.Fprintf(w, "ID:      %s\n", c.CrumbID)
+	fmt.Fprintf(w, "Name:    %s\n", c.Name)
+	fmt.Fprintf(w, "State:   %s\n", c.State)
+	fmt.Fprintf(w, "Created: %s\n", c.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Updated: %s\n", c.UpdatedAt.Format(time.RFC3339))
+	if len(c.Properties) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(c.Properties))
+	for name := range c.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "Properties:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s: %s\n", name, formatProperty(c.Properties[name]))
+	}
+	return nil
+}
+
+// runCrumbsCreate creates the crumb opts describes and writes its ID to w.
+func runCrumbsCreate(w io.Writer, cupboard *crumbs.Cupboard, opts crumbsCreateOptions) error {
+	if strings.TrimSpace(opts.Name) == "" {
+		return fmt.Errorf("crumbs: --name is required")
+	}
+	state, err := parseState(opts.State)
+	if err != nil {
+		return err
+	}
+	props, err := parseProps(opts.Props)
+	if err != nil {
+		return err
+	}
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: opts.Name, State: state, Properties: props})
+	if err != nil {
+		return err
+	}
+	if !opts.JSON {
+		_, err = fmt.Fprintln(w, id)
+		return err
+	}
+	c, err := cupboard.GetCrumb(id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, newCrumbRecord(c))
+}
+
+// runCrumbsDoctor verifies the cupboard and, when it is sound and vacuum is
+// set, vacuums it, reporting each step to w.
+func runCrumbsDoctor(w io.Writer, cupboard *crumbs.Cupboard, vacuum bool) error {
+	if err := cupboard.Verify(); err != nil {
+		fmt.Fprintln(w, "integrity: failed")
+		return err
+	}
+	fmt.Fprintln(w, "integrity: ok")
+	if !vacuum {
+		return nil
+	}
+	reclaimed, err := cupboard.Vacuum()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "vacuum: reclaimed %d bytes\n", max(0, reclaimed))
+	return err
+}
+
+// runCrumbsExport writes every crumb to the file at path. A failed export
+// removes the partial file.
+func runCrumbsExport(path string, cupboard *crumbs.Cupboard) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = cupboard.ExportCrumbs(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return err
+}
+
+// runCrumbsImport imports the crumbs r holds and writes how many to w.
+func runCrumbsImport(w io.Writer, cupboard *crumbs.Cupboard, r io.Reader) error {
+	n, err := cupboard.ImportCrumbs(r)
+	if err != nil {
+		return fmt.Errorf("crumbs: imported %d crumbs before failing: %w", n, err)
+	}
+	_, err = fmt.Fprintf(w, "imported %d crumbs\n", n)
+	return err
+}
+
+// parseState returns s as a workflow state.
+func parseState(s string) (types.State, error) {
+	state := types.State(s)
+	if !slices.Contains(crumbs.States, state) {
+		return "", fmt.Errorf("crumbs: unknown state %q (valid: %v)", s, crumbs.States)
+	}
+	return state, nil
+}
+
+// parseProps parses key=value pairs into crumb properties.
+func parseProps(pairs []string) (map[string]any, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	props := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("crumbs: property %q is not key=value", pair)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		props[key] = value
+	}
+	return props, nil
+}
+
+// formatProperty renders a property value for human output: strings as is,
+// anything else as JSON.
+func formatProperty(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func init() {
+	crumbsListCmd.Flags().StringSliceVar(&crumbsListOpts.States, "state", nil, "Only list crumbs in these states (repeatable or comma-separated)")
+	crumbsListCmd.Flags().StringSliceVar(&crumbsListOpts.WorkTypes, "work-type", nil, "Only list crumbs of these work types (repeatable or comma-separated)")
+	crumbsListCmd.Flags().StringSliceVar(&crumbsListOpts.Tags, "tag", nil, "Only list crumbs with any of these tags (repeatable or comma-separated)")
+	crumbsListCmd.Flags().BoolVar(&crumbsListOpts.AllTags, "all-tags", false, "With --tag, only list crumbs with every tag")
+	crumbsListCmd.Flags().BoolVar(&crumbsListOpts.JSON, "json", false, "Print JSON")
+	crumbsShowCmd.Flags().BoolVar(&crumbsShowJSON, "json", false, "Print JSON")
+	crumbsCreateCmd.Flags().StringVar(&crumbsCreateOpts.Name, "name", "", "Crumb name (required)")
+	crumbsCreateCmd.Flags().StringVar(&crumbsCreateOpts.State, "state", string(types.StateDraft), "Initial state")
+	crumbsCreateCmd.Flags().StringArrayVar(&crumbsCreateOpts.Props, "prop", nil, "Property as key=value (repeatable)")
+	crumbsCreateCmd.Flags().BoolVar(&crumbsCreateOpts.JSON, "json", false, "Print the created crumb as JSON")
+	crumbsDoctorCmd.Flags().BoolVar(&crumbsNoVacuum, "no-vacuum", false, "Only check integrity")
+	crumbsExportCmd.Flags().StringVarP(&crumbsExportPath, "output", "o", "", "Write the export to this file instead of stdout")
+	crumbsCmd.AddCommand(crumbsListCmd, crumbsShowCmd, crumbsCreateCmd, crumbsDoctorCmd, crumbsExportCmd, crumbsImportCmd)
+	rootCmd.AddCommand(crumbsCmd)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestRunCrumbs(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cupboard.Close()
+
+	var out bytes.Buffer
+	create := crumbsCreateOptions{
+		Name:  "Write the parser",
+		State: string(types.StateReady),
+		Props: []string{"description=Parse it", "priority=3", "tags=[\"a\",\"b\"]", "work_type=code"},
+	}
+	if err := runCrumbsCreate(&out, cupboard, create); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := strings.TrimSpace(out.String())
+	if err := runCrumbsCreate(&out, cupboard, crumbsCreateOptions{Name: "Draft the PRD", State: string(types.StateDraft)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	out.Reset()
+	if err := runCrumbsList(&out, cupboard, crumbsListOptions{}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[1], id) {
+		t.Errorf("list output = %q, want a header and both crumbs, oldest first", out.String())
+	}
+
+	out.Reset()
+	if err := runCrumbsList(&out, cupboard, crumbsListOptions{States: []string{"ready"}, JSON: true}); err != nil {
+		t.Fatalf("list --json: %v", err)
+	}
+	var records []crumbRecord
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("list --json output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(records) != 1 || records[0].ID != id || records[0].Properties["priority"] != 3.0 {
+		t.Errorf("list --state ready --json = %+v", records)
+	}
+
+	out.Reset()
+	if err := runCrumbsList(&out, cupboard, crumbsListOptions{WorkTypes: []string{"docs", "code"}}); err != nil {
+		t.Fatalf("list --work-type: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], id) {
+		t.Errorf("list --work-type docs,code output = %q, want only the code crumb", out.String())
+	}
+
+	for _, tt := range []struct {
+		opts  crumbsListOptions
+		wantN int
+	}{
+		{crumbsListOptions{Tags: []string{"a", "c"}}, 1},
+		{crumbsListOptions{Tags: []string{"a", "c"}, AllTags: true}, 0},
+		{crumbsListOptions{Tags: []string{"a", "b"}, AllTags: true}, 1},
+	} {
+		out.Reset()
+		if err := runCrumbsList(&out, cupboard, tt.opts); err != nil {
+			t.Fatalf("list --tag: %v", err)
+		}
+		if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 1+tt.wantN {
+			t.Errorf("list %+v output = %q, want %d crumbs", tt.opts, out.String(), tt.wantN)
+		}
+	}
+
+	out.Reset()
+	if err := runCrumbsShow(&out, cupboard, id, false); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	for _, want := range []string{"Write the parser", "State:   ready", "description: Parse it", "priority: 3", `tags: ["a","b"]`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("show output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunCrumbs_Errors(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cupboard.Close()
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"create without name", func() error {
+			return runCrumbsCreate(&bytes.Buffer{}, cupboard, crumbsCreateOptions{State: "draft"})
+		}},
+		{"create with unknown state", func() error {
+			return runCrumbsCreate(&bytes.Buffer{}, cupboard, crumbsCreateOptions{Name: "x", State: "open"})
+		}},
+		{"create with malformed property", func() error {
+			return runCrumbsCreate(&bytes.Buffer{}, cupboard, crumbsCreateOptions{Name: "x", State: "draft", Props: []string{"novalue"}})
+		}},
+		{"list with unknown state", func() error {
+			return runCrumbsList(&bytes.Buffer{}, cupboard, crumbsListOptions{States: []string{"open"}})
+		}},
+		{"show missing crumb", func() error {
+			return runCrumbsShow(&bytes.Buffer{}, cupboard, "missing", false)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRunCrumbsDoctor(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cupboard.Close()
+
+	var out bytes.Buffer
+	if err := runCrumbsDoctor(&out, cupboard, true); err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "integrity: ok\nvacuum: reclaimed ") {
+		t.Errorf("doctor output = %q, want integrity ok then the vacuum result", got)
+	}
+
+	out.Reset()
+	if err := runCrumbsDoctor(&out, cupboard, false); err != nil || out.String() != "integrity: ok\n" {
+		t.Errorf("doctor --no-vacuum = %q, %v; want only the integrity line", out.String(), err)
+	}
+}
+
+func TestRunCrumbsExportImport(t *testing.T) {
+	src, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	var out bytes.Buffer
+	create := crumbsCreateOptions{Name: "Write the parser", State: string(types.StateReady), Props: []string{"priority=3"}}
+	if err := runCrumbsCreate(&out, src, create); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := strings.TrimSpace(out.String())
+
+	path := filepath.Join(t.TempDir(), "crumbs.json")
+	if err := runCrumbsExport(path, src); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dst, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	out.Reset()
+	if err := runCrumbsImport(&out, dst, f); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if got := out.String(); got != "imported 1 crumbs\n" {
+		t.Errorf("import output = %q", got)
+	}
+	c, err := dst.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("imported crumb %s: %v", id, err)
+	}
+	if c.Name != create.Name || c.State != types.StateReady || formatProperty(c.Properties["priority"]) != "3" {
+		t.Errorf("imported crumb = %+v", c)
+	}
+
+	if err := runCrumbsImport(&out, dst, strings.NewReader("{}")); err == nil {
+		t.Error("import of a non-array: expected an error")
+	}
+}
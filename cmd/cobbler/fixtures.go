@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixturesBinary string
+	fixturesOut    string
+)
+
+var fixturesCmd = &cobra.Command{
+	Use:   "fixtures",
+	Short: "Manage differential testing fixtures",
+}
+
+var fixturesCaptureCmd = &cobra.Command{
+	Use:   "capture --binary PATH INPUT...",
+	Short: "Record a binary's output on each input as a fixture",
+	Long: `Capture runs --binary once per input file, with the file on stdin, and
+records the input and the binary's stdout as a fixture pair in --out:
+<name>.in and <name>.out, where name is the input's base name without its
+extension. inspect --fixture-dir then replays the pairs to catch changes in
+behavior.
+
+Capture records current behavior as the oracle: whatever the binary prints
+today is what differential testing will expect. Run it with a build you
+trust. Existing fixtures of the same name are overwritten, and nothing is
+written if any input fails.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFixturesCapture(cmd.Context(), os.Stdout, fixturesBinary, args, fixturesOut)
+	},
+}
+
+// runFixturesCapture records the binary's output on inputs as fixtures in
+// outDir and lists the fixtures written to w.
+func runFixturesCapture(ctx context.Context, w io.Writer, binary string, inputs []string, outDir string) error {
+	names, err := inspect.GenerateFixtures(ctx, binary, inputs, outDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "captured %s\n", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	flags := fixturesCaptureCmd.Flags()
+	flags.StringVar(&fixturesBinary, "binary", "", "Trusted build of the program to record (required)")
+	flags.StringVar(&fixturesOut, "out", inspect.DefaultFixtureDir, "Fixture directory to write")
+	_ = fixturesCaptureCmd.MarkFlagRequired("binary")
+	fixturesCmd.AddCommand(fixturesCaptureCmd)
+	rootCmd.AddCommand(fixturesCmd)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunFixturesCapture(t *testing.T) {
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat not installed")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "echo.txt")
+	if err := os.WriteFile(input, []byte("same\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "fixtures")
+
+	var w bytes.Buffer
+	if err := runFixturesCapture(context.Background(), &w, cat, []string{input}, out); err != nil {
+		t.Fatalf("runFixturesCapture failed: %v", err)
+	}
+	if w.String() != "captured echo\n" {
+		t.Errorf("output = %q, want the captured fixture listed", w.String())
+	}
+	if got, err := os.ReadFile(filepath.Join(out, "echo.out")); err != nil || string(got) != "same\n" {
+		t.Errorf("echo.out = %q, %v; want cat's output", got, err)
+	}
+}
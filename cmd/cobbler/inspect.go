@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// inspectOptions carries the parsed inspect command flags.
+type inspectOptions struct {
+	Input    inspect.InspectInput
+	Expected []string
+	Strict   bool
+	// RedactionPatterns mask secrets in evidence before it is printed.
+	RedactionPatterns []string
+	// DataDir is the crumbs data directory that holds the mutation and
+	// result caches.
+	DataDir string
+	// NoMutationCache forces every mutant to be re-tested.
+	NoMutationCache bool
+	// NoCache forces every technique to run, ignoring cached results.
+	NoCache bool
+	// DiffFile is a unified diff loaded into the input's Diff.
+	DiffFile string
+	// BaseRef and HeadRef select the git changes to inspect; BaseRef empty
+	// means the input comes from the flags alone.
+	BaseRef, HeadRef string
+	// ChangedLinesOnly restricts mutation to lines changed by the diff.
+	ChangedLinesOnly bool
+	// CoveredTestsOnly runs only the tests covering each mutated line.
+	CoveredTestsOnly bool
+	// MutationOperators limits mutation to the named types; empty means all.
+	MutationOperators []string
+	// MutationDryRun prints the mutation plan instead of inspecting.
+	MutationDryRun bool
+	// Format selects the report format: text, json, junit, or sarif.
+	Format string
+	// Output is the report file; empty writes to stdout.
+	Output string
+	// Concurrency bounds how many techniques run at once.
+	Concurrency int
+	// TechniqueTimeout skips a technique that runs longer; zero means no
+	// limit.
+	TechniqueTimeout time.Duration
+	// Logger receives technique events; nil discards them.
+	Logger *slog.Logger
+	// EnabledTechniques limits the default techniques to those named; empty
+	// keeps them all.
+	EnabledTechniques []string
+	// Security adds the gosec security runner.
+	Security bool
+	// Bench adds the benchmark regression runner.
+	Bench bool
+	// APICompat adds the API compatibility runner.
+	APICompat bool
+	// UpdateBaseline records benchmark results, and with APICompat the
+	// exported API, as the new baseline.
+	UpdateBaseline bool
+	// Scorer is the base scorer configuration that Weights apply over; nil
+	// uses the defaults.
+	Scorer *inspect.ScorerConfig
+	// Weights overrides technique weights, as name=weight pairs.
+	Weights []string
+	// TestCommand replaces go test in the techniques that run the tests;
+	// empty runs go test.
+	TestCommand []string
+	// Sensitivity appends the weight sensitivity report to the text report.
+	Sensitivity bool
+}
+
+// Report formats.
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJUnit = "junit"
+	formatSARIF = "sarif"
+)
+
+var inspectOpts inspectOptions
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Evaluate output quality",
+	Long: `Inspect runs the applicable verification techniques against stitch output
+and reports each technique's score, verdict, and evidence, then the
+composite score and the action it leads to.
+
+The changes come from --diff-file or, with --base, from git. Technique and
+mutation results are cached under --data-dir. Evidence is redacted before
+it is printed.
+
+The inspect section of the config file sets the scorer, the enabled
+techniques, the test command, and the expected techniques; flags override
+it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scorer := cfg.ScorerConfig()
+		inspectOpts.Scorer = &scorer
+		inspectOpts.DataDir = cfg.DataDir
+		inspectOpts.Concurrency = cfg.Inspect.Concurrency
+		timeout, err := cfg.TechniqueTimeout()
+		if err != nil {
+			return err
+		}
+		inspectOpts.TechniqueTimeout = timeout
+		inspectOpts.Logger = logger
+		inspectOpts.Security = cfg.Inspect.Security
+		inspectOpts.Expected = cfg.Inspect.Expected
+		inspectOpts.Strict = cfg.Inspect.Strict
+		inspectOpts.EnabledTechniques = cfg.Inspect.EnabledTechniques
+		inspectOpts.TestCommand = cfg.Inspect.TestCommand
+		if inspectOpts.DiffFile != "" && inspectOpts.BaseRef != "" {
+			return fmt.Errorf("--diff-file and --base are mutually exclusive")
+		}
+		if inspectOpts.DiffFile != "" {
+			diff, err := os.ReadFile(inspectOpts.DiffFile)
+			if err != nil {
+				return fmt.Errorf("reading diff: %w", err)
+			}
+			inspectOpts.Input.Diff = string(diff)
+		}
+		if inspectOpts.BaseRef != "" {
+			if err := gitInput(cmd.Context(), &inspectOpts.Input, inspectOpts.BaseRef, inspectOpts.HeadRef); err != nil {
+				return err
+			}
+		}
+		if err := deriveModified(cmd.Context(), &inspectOpts.Input); err != nil {
+			return err
+		}
+		if inspectOpts.MutationDryRun {
+			return runMutationPlan(os.Stdout, inspectOpts)
+		}
+		techniques, err := inspectTechniques(inspectOpts)
+		if err != nil {
+			return err
+		}
+		if inspectOpts.Output == "" {
+			return runInspect(cmd.Context(), os.Stdout, techniques, inspectOpts)
+		}
+		f, err := os.Create(inspectOpts.Output)
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		if err := runInspect(cmd.Context(), f, techniques, inspectOpts); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	},
+}
+
+// gitInput fills the input's diff, and its modified files and packages when
+// they were not given, from the changes between base and head in the
+// input's directory.
+func gitInput(ctx context.Context, input *inspect.InspectInput, base, head string) error {
+	fromGit, err := inspect.NewInspectInputFromGit(ctx, input.Dir, base, head)
+	if err != nil {
+		return err
+	}
+	input.Diff = fromGit.Diff
+	if len(input.ModifiedFiles) == 0 {
+		input.ModifiedFiles = fromGit.ModifiedFiles
+	}
+	if len(input.ModifiedPackages) == 0 {
+		input.ModifiedPackages = fromGit.ModifiedPackages
+	}
+	return nil
+}
+
+// deriveModified fills the input's modified files from its diff and its
+// modified packages from those files, when they were not given.
+func deriveModified(ctx context.Context, input *inspect.InspectInput) error {
+	if len(input.ModifiedFiles) == 0 {
+		for _, file := range inspect.ParseDiff(input.Diff) {
+			if !file.IsDeleted() {
+				input.ModifiedFiles = append(input.ModifiedFiles, file.NewPath)
+			}
+		}
+	}
+	if len(input.ModifiedPackages) > 0 || input.WorkType != inspect.WorkTypeCode {
+		return nil
+	}
+	pkgs, err := inspect.PackagesForFiles(ctx, input.Dir, input.ModifiedFiles)
+	if err != nil {
+		return err
+	}
+	input.ModifiedPackages = pkgs
+	return nil
+}
+
+// inspectTechniques returns the default techniques enabled in opts, with
+// the mutation runner configured from opts, plus the security and benchmark
+// runners when opts enables them.
+func inspectTechniques(opts inspectOptions) ([]inspect.Technique, error) {
+	config, err := mutationConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	techniques := inspect.EnableTechniques(inspect.DefaultTechniques(), opts.EnabledTechniques)
+	for i, tech := range techniques {
+		if tech.Name() == inspect.MutationRunnerName {
+			techniques[i] = inspect.NewMutationRunner(config)
+		}
+	}
+	if opts.Security {
+		techniques = append(techniques, inspect.NewSecurityRunner())
+	}
+	if opts.Bench || opts.UpdateBaseline {
+		bench := inspect.DefaultBenchConfig()
+		bench.BaselineDir = filepath.Join(opts.DataDir, inspect.BenchBaselineDirName)
+		bench.UpdateBaseline = opts.UpdateBaseline
+		techniques = append(techniques, inspect.NewBenchRunner(bench))
+	}
+	if opts.APICompat {
+		techniques = append(techniques, inspect.NewAPICompatRunner(inspect.APICompatConfig{
+			BaselineDir:    filepath.Join(opts.DataDir, inspect.APIBaselineDirName),
+			UpdateBaseline: opts.UpdateBaseline,
+		}))
+	}
+	return techniques, nil
+}
+
+// mutationConfig returns the mutation runner settings from opts.
+func mutationConfig(opts inspectOptions) (inspect.MutationConfig, error) {
+	operators, err := parseMutationOperators(opts.MutationOperators)
+	if err != nil {
+		return inspect.MutationConfig{}, err
+	}
+	config := inspect.DefaultMutationConfig()
+	config.EnabledOperators = operators
+	config.CacheDir = filepath.Join(opts.DataDir, inspect.MutationCacheDirName)
+	config.NoCache = opts.NoMutationCache
+	config.ChangedLinesOnly = opts.ChangedLinesOnly
+	config.CoveredTestsOnly = opts.CoveredTestsOnly
+	return config, nil
+}
+
+// runMutationPlan writes the mutants the mutation runner configured from
+// opts would test on the input, without testing them.
+func runMutationPlan(w io.Writer, opts inspectOptions) error {
+	config, err := mutationConfig(opts)
+	if err != nil {
+		return err
+	}
+	input := opts.Input
+	plan, err := inspect.NewMutationRunner(config).Plan(&input)
+	if err != nil {
+		return err
+	}
+	return inspect.WriteMutationPlan(w, plan)
+}
+
+// parseMutationOperators converts operator names to mutation types,
+// rejecting unknown names.
+func parseMutationOperators(names []string) ([]inspect.MutationType, error) {
+	operators := make([]inspect.MutationType, 0, len(names))
+	for _, name := range names {
+		op := inspect.MutationType(name)
+		if !slices.Contains(inspect.AllMutationTypes, op) {
+			return nil, fmt.Errorf("unknown mutation operator %q (valid: %v)", name, inspect.AllMutationTypes)
+		}
+		operators = append(operators, op)
+	}
+	return operators, nil
+}
+
+// scorerConfig returns base (the defaults when nil) with weights merged over
+// its weights. Each weight is a name=value pair naming one of techniques.
+func scorerConfig(base *inspect.ScorerConfig, weights []string, techniques []inspect.Technique) (inspect.ScorerConfig, error) {
+	config := inspect.DefaultScorerConfig()
+	if base != nil {
+		config = *base
+		config.Weights = maps.Clone(base.Weights)
+	}
+	if len(weights) == 0 {
+		return config, nil
+	}
+	known := make([]string, 0, len(techniques))
+	for _, tech := range techniques {
+		known = append(known, tech.Name())
+	}
+	slices.Sort(known)
+	for _, spec := range weights {
+		name, value, ok := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return inspect.ScorerConfig{}, fmt.Errorf("invalid weight %q: want technique=weight", spec)
+		}
+		if !slices.Contains(known, name) {
+			return inspect.ScorerConfig{}, fmt.Errorf("unknown technique %q in --weights (valid: %s)", name, strings.Join(known, ", "))
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return inspect.ScorerConfig{}, fmt.Errorf("invalid weight for %s: %w", name, err)
+		}
+		config.Weights[name] = w
+	}
+	return config, nil
+}
+
+// runInspect runs techniques on the input, prints the report, and enforces
+// strict mode.
+func runInspect(ctx context.Context, w io.Writer, techniques []inspect.Technique, opts inspectOptions) error {
+	switch opts.Format {
+	case "", formatText, formatJSON, formatJUnit, formatSARIF:
+	default:
+		return fmt.Errorf("unknown report format %q (valid: %s, %s, %s, %s)", opts.Format, formatText, formatJSON, formatJUnit, formatSARIF)
+	}
+	redactor, err := inspect.NewRedactor(opts.RedactionPatterns)
+	if err != nil {
+		return err
+	}
+	config, err := scorerConfig(opts.Scorer, opts.Weights, techniques)
+	if err != nil {
+		return err
+	}
+	scorer, err := inspect.NewScorer(config)
+	if err != nil {
+		return err
+	}
+	portfolioConfig := inspect.PortfolioConfig{
+		Concurrency:      opts.Concurrency,
+		Logger:           opts.Logger,
+		NoCache:          opts.NoCache,
+		TestCommand:      opts.TestCommand,
+		TechniqueTimeout: opts.TechniqueTimeout,
+	}
+	if opts.DataDir != "" {
+		portfolioConfig.CacheDir = filepath.Join(opts.DataDir, inspect.ResultCacheDirName)
+	}
+	portfolio := inspect.NewPortfolio(scorer, portfolioConfig)
+	for _, tech := range techniques {
+		portfolio.Register(tech)
+	}
+	input := opts.Input
+	cr, err := portfolio.Run(ctx, &input)
+	if err != nil {
+		return err
+	}
+	if err := writeInspectReport(w, opts.Format, redactor.RedactComposite(cr)); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	if cr.LowConfidenceAccept() {
+		logging.OrDiscard(opts.Logger).Warn("low-confidence accept", logging.KeyScore, cr.Score, "confidence", cr.Confidence)
+	}
+	uncovered := portfolio.UncoveredFaultClasses()
+	for _, class := range uncovered {
+		logging.OrDiscard(opts.Logger).Warn("fault class not covered", logging.KeyFaultClass, class)
+	}
+	if opts.Format == "" || opts.Format == formatText {
+		if cr.LowConfidenceAccept() {
+			if _, err := fmt.Fprintf(w, "warning: accepted with low confidence %.2f\n", cr.Confidence); err != nil {
+				return fmt.Errorf("writing report: %w", err)
+			}
+		}
+		if err := inspect.WriteFaultCoverage(w, uncovered); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+		if opts.Sensitivity {
+			if err := inspect.WriteSensitivity(w, scorer.SensitivityReport(cr.Results)); err != nil {
+				return fmt.Errorf("writing report: %w", err)
+			}
+		}
+	}
+	if !opts.Strict {
+		return nil
+	}
+	return inspect.CheckExpected(opts.Expected, cr.Results)
+}
+
+// writeInspectReport writes the redacted composite result in the given format.
+func writeInspectReport(w io.Writer, format string, cr inspect.CompositeResult) error {
+	switch format {
+	case formatJSON:
+		return inspect.WriteJSON(w, cr)
+	case formatJUnit:
+		return inspect.WriteJUnit(w, cr)
+	case formatSARIF:
+		return inspect.WriteSARIF(w, cr)
+	default:
+		if err := inspect.WriteReport(w, cr.Results); err != nil {
+			return err
+		}
+		return inspect.WriteSummary(w, cr)
+	}
+}
+
+func init() {
+	flags := inspectCmd.Flags()
+	flags.StringVar(&inspectOpts.Input.WorkType, "type", inspect.WorkTypeCode, "Work type: code or docs")
+	flags.StringVar(&inspectOpts.Input.Dir, "dir", "", "Module root (default: current directory)")
+	flags.StringSliceVar(&inspectOpts.Input.ModifiedFiles, "files", nil, "Modified files, relative to --dir (default: the files in the diff)")
+	flags.StringSliceVar(&inspectOpts.Input.ModifiedPackages, "packages", nil, "Modified Go packages (default: the packages of the modified Go files)")
+	flags.StringVar(&inspectOpts.Input.FixtureDir, "fixture-dir", "", "Benchmark fixture directory")
+	flags.StringArrayVar(&inspectOpts.Input.PRDCriteria, "criterion", nil, "PRD acceptance criterion (repeatable)")
+	flags.StringSlice(flagExpect, nil, "Techniques expected to run")
+	flags.Bool(flagStrict, false, "Fail when a technique listed in --expect is skipped")
+	flags.StringArrayVar(&inspectOpts.RedactionPatterns, "redact-pattern", inspect.DefaultRedactionPatterns, "Regular expression masked in evidence, besides high-entropy strings (repeatable)")
+	flags.BoolVar(&inspectOpts.NoMutationCache, "no-mutation-cache", false, "Re-test every mutant, ignoring cached results")
+	flags.BoolVar(&inspectOpts.NoCache, "no-cache", false, "Run every technique, ignoring results cached for the same input and settings")
+	flags.StringVar(&inspectOpts.DiffFile, "diff-file", "", "Unified diff of the stitch changes")
+	flags.StringVar(&inspectOpts.BaseRef, "base", "", "Inspect the git changes since this ref, instead of --diff-file")
+	flags.StringVar(&inspectOpts.HeadRef, "head", "HEAD", "Git ref whose changes since --base are inspected")
+	flags.BoolVar(&inspectOpts.ChangedLinesOnly, "changed-lines-only", false, "Mutate only lines added or changed by --diff-file")
+	flags.BoolVar(&inspectOpts.CoveredTestsOnly, "covered-tests-only", false, "Test each mutant with only the tests covering its line")
+	flags.StringSliceVar(&inspectOpts.MutationOperators, "mutation-operators", nil, "Mutation types to apply (default: all)")
+	flags.BoolVar(&inspectOpts.MutationDryRun, "mutation-dry-run", false, "Print the mutants per file and type without running any technique")
+	flags.StringVar(&inspectOpts.Format, "format", formatText, "Report format: text, json, junit, or sarif")
+	flags.StringVarP(&inspectOpts.Output, "output", "o", "", "Write the report to a file instead of stdout")
+	flags.Bool(flagSecurity, false, "Run the gosec security analysis (skipped when gosec is not installed)")
+	flags.BoolVar(&inspectOpts.Bench, "bench", false, "Compare benchmarks with the stored baseline")
+	flags.BoolVar(&inspectOpts.APICompat, "api-compat", false, "Compare the exported API with the stored baseline")
+	flags.BoolVar(&inspectOpts.UpdateBaseline, "update-baseline", false, "Record benchmark results, and the API with --api-compat, as the new baseline")
+	flags.Int(flagConcurrency, inspect.DefaultPortfolioConcurrency, "Maximum techniques run at once")
+	flags.Duration(flagTechniqueTimeout, 0, "Skip a technique that runs longer than this (0: no limit)")
+	flags.StringSliceVar(&inspectOpts.Weights, "weights", nil, "Technique weight overrides as name=weight, e.g. mutation_testing=0.3 (comma-separated)")
+	flags.BoolVar(&inspectOpts.Sensitivity, "sensitivity", false, "Report how ±10% changes to each weight move the decision")
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+func TestRunInspect_Strict(t *testing.T) {
+	// A code task with no modified test files: the assertion checker skips.
+	base := inspectOptions{
+		Input:    inspect.InspectInput{WorkType: inspect.WorkTypeCode, ModifiedFiles: []string{"calc.go"}},
+		Expected: []string{inspect.AssertionCheckerName},
+	}
+	techniques := []inspect.Technique{inspect.NewAssertionChecker()}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		wantErr bool
+	}{
+		{"strict fails on expected skip", true, true},
+		{"lenient passes", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			opts.Strict = tt.strict
+			var out bytes.Buffer
+			err := runInspect(context.Background(), &out, techniques, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runInspect error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, inspect.ErrExpectedSkipped) {
+				t.Errorf("error = %v, want ErrExpectedSkipped", err)
+			}
+			if !strings.Contains(err.Error(), inspect.AssertionCheckerName) || !strings.Contains(err.Error(), "no modified test files") {
+				t.Errorf("error %q should name the technique and reason", err)
+			}
+		})
+	}
+}
+
+func TestParseMutationOperators(t *testing.T) {
+	got, err := parseMutationOperators([]string{"literal", "statement_delete"})
+	if err != nil {
+		t.Fatalf("parseMutationOperators failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != inspect.MutationLiteral || got[1] != inspect.MutationStatementDelete {
+		t.Errorf("parseMutationOperators = %v", got)
+	}
+	if _, err := parseMutationOperators([]string{"swap"}); err == nil || !strings.Contains(err.Error(), `"swap"`) {
+		t.Errorf("parseMutationOperators(swap) error = %v, want unknown operator", err)
+	}
+}
+
+func TestRunMutationPlan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "calc.go"), []byte("package calc\n\nfunc Add(a, b int) int { return a + b }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := inspectOptions{
+		Input:             inspect.InspectInput{WorkType: inspect.WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc.go"}},
+		MutationOperators: []string{"statement_delete"},
+	}
+	var out bytes.Buffer
+	if err := runMutationPlan(&out, opts); err != nil {
+		t.Fatalf("runMutationPlan failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "0 mutants") || strings.Contains(out.String(), "arithmetic") {
+		t.Errorf("plan with only statement_delete enabled:\n%s", out.String())
+	}
+	opts.MutationOperators = nil
+	out.Reset()
+	if err := runMutationPlan(&out, opts); err != nil {
+		t.Fatalf("runMutationPlan failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "calc.go  arithmetic  1") || !strings.Contains(out.String(), "1 mutants") {
+		t.Errorf("plan with every operator:\n%s", out.String())
+	}
+}
+
+func TestRunInspect_JSON(t *testing.T) {
+	opts := inspectOptions{
+		Input:  inspect.InspectInput{WorkType: inspect.WorkTypeCode, ModifiedFiles: []string{"calc.go"}},
+		Format: formatJSON,
+	}
+	var out bytes.Buffer
+	if err := runInspect(context.Background(), &out, []inspect.Technique{inspect.NewAssertionChecker()}, opts); err != nil {
+		t.Fatalf("runInspect failed: %v", err)
+	}
+	var report inspect.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not a JSON report: %v\n%s", err, out.String())
+	}
+	if report.SchemaVersion != inspect.ReportSchemaVersion || len(report.Techniques) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	opts.Format = "yaml"
+	if err := runInspect(context.Background(), &out, nil, opts); err == nil {
+		t.Error("runInspect accepted an unknown format")
+	}
+}
+
+func TestRunInspect_Sensitivity(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "calc_test.go"), []byte("package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Error(\"Add\")\n\t}\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := inspectOptions{
+		Input:       inspect.InspectInput{WorkType: inspect.WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc_test.go"}},
+		Weights:     []string{inspect.AssertionCheckerName + "=0.5"},
+		Sensitivity: true,
+	}
+	var out bytes.Buffer
+	if err := runInspect(context.Background(), &out, []inspect.Technique{inspect.NewAssertionChecker()}, opts); err != nil {
+		t.Fatalf("runInspect failed: %v", err)
+	}
+	for _, want := range []string{inspect.AssertionCheckerName + ": -10% weight", "most influential: " + inspect.AssertionCheckerName} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestScorerConfig_Weights(t *testing.T) {
+	techniques := inspect.DefaultTechniques()
+	config, err := scorerConfig(nil, []string{"translation_validation=0.4", "mutation_testing = 0.3"}, techniques)
+	if err != nil {
+		t.Fatalf("scorerConfig failed: %v", err)
+	}
+	if config.Weights[inspect.TranslationValidatorName] != 0.4 || config.Weights[inspect.MutationRunnerName] != 0.3 {
+		t.Errorf("overridden weights = %v", config.Weights)
+	}
+	if got, want := config.Weights[inspect.DifferentialTestingName], inspect.DefaultWeights[inspect.DifferentialTestingName]; got != want {
+		t.Errorf("unspecified weight = %v, want default %v", got, want)
+	}
+	if inspect.DefaultWeights[inspect.TranslationValidatorName] == 0.4 {
+		t.Error("scorerConfig modified DefaultWeights")
+	}
+
+	for _, spec := range []string{"mutation_tesing=0.3", "mutation_testing", "mutation_testing=high"} {
+		if _, err := scorerConfig(nil, []string{spec}, techniques); err == nil {
+			t.Errorf("scorerConfig(%q) succeeded, want error", spec)
+		}
+	}
+	_, err = scorerConfig(nil, []string{"mutation_tesing=0.3"}, techniques)
+	if err == nil || !strings.Contains(err.Error(), `"mutation_tesing"`) || !strings.Contains(err.Error(), inspect.MutationRunnerName) {
+		t.Errorf("typo error = %v, want it to name the typo and valid techniques", err)
+	}
+}
+
+func TestRunInspect_InvalidWeight(t *testing.T) {
+	opts := inspectOptions{Weights: []string{inspect.AssertionCheckerName + "=1.5"}}
+	err := runInspect(context.Background(), io.Discard, []inspect.Technique{inspect.NewAssertionChecker()}, opts)
+	if !errors.Is(err, inspect.ErrInvalidWeight) {
+		t.Errorf("runInspect error = %v, want ErrInvalidWeight", err)
+	}
+}
+
+func TestDeriveModified(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":       "module example.com/m\n\ngo 1.21\n",
+		"calc/calc.go": "package calc\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	diff := "diff --git a/calc/calc.go b/calc/calc.go\n--- a/calc/calc.go\n+++ b/calc/calc.go\n@@ -1 +1 @@\n-package calc \n+package calc\n" +
+		"diff --git a/old.go b/old.go\ndeleted file mode 100644\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package m\n"
+	input := inspect.InspectInput{WorkType: inspect.WorkTypeCode, Dir: dir, Diff: diff}
+	if err := deriveModified(context.Background(), &input); err != nil {
+		t.Fatalf("deriveModified failed: %v", err)
+	}
+	if !slices.Equal(input.ModifiedFiles, []string{"calc/calc.go"}) {
+		t.Errorf("ModifiedFiles = %v, want [calc/calc.go]", input.ModifiedFiles)
+	}
+	if !slices.Equal(input.ModifiedPackages, []string{"example.com/m/calc"}) {
+		t.Errorf("ModifiedPackages = %v, want [example.com/m/calc]", input.ModifiedPackages)
+	}
+}
@@ -22,7 +22,22 @@ Commands follow shoemaking terminology:
   stitch    Execute work via AI agents
   inspect   Evaluate output quality
   mend      Fix issues found by inspect
-  pattern   Propose design changes`,
+  pattern   Propose design changes
+
+Settings resolve from flags, then the --config file, then built-in defaults.
+Structured events are logged to stderr; see --log-level, --log-format, and
+--quiet.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		resolved, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		cfg = resolved
+		if logger, err = newLogger(cmd, os.Stderr); err != nil {
+			return err
+		}
+		return nil
+	},
 }
 
 var versionCmd = &cobra.Command{
@@ -34,6 +49,7 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	addGlobalFlags(rootCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -1,23 +1,133 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/measure"
+	"github.com/petar-djukic/cobbler/internal/prompt"
 	"github.com/spf13/cobra"
 )
 
+// defaultProposalsFile is where measure writes proposals for review.
+const defaultProposalsFile = "measure-proposals.json"
+
+// measureOptions configures one measure run.
+type measureOptions struct {
+	Root    string
+	DataDir string
+	Output  string
+	Limit   int
+	DryRun  bool
+	Import  string
+}
+
+var measureOpts measureOptions
+
 var measureCmd = &cobra.Command{
 	Use:   "measure",
 	Short: "Assess project state and propose tasks",
 	Long: `Measure reads project state (VISION, ARCHITECTURE, ROADMAP, cupboard)
 and invokes an AI agent to analyze the state and propose new work items.
 
-Output is a set of proposed crumbs that the user reviews before import.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("measure: not implemented")
+Output is a set of proposed crumbs written to --output for review. With
+--dry-run, measure prints the planning prompt instead of calling the agent.
+After review, import the proposals as pending crumbs with --import.
+
+The planning prompt can be overridden with <data-dir>/prompts/measure.tmpl.
+
+Agent runs that fail transiently, on a rate limit or an overloaded API, are
+retried with exponential backoff: see --agent-max-attempts and
+--agent-retry-delay.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := newAgent(cfg, logger)
+		if err != nil {
+			return err
+		}
+		measureOpts.DataDir = cfg.DataDir
+		return runMeasure(cmd.Context(), os.Stdout, a, measureOpts)
 	},
 }
 
+// runMeasure runs measure with opts, writing progress and dry-run prompts to w.
+func runMeasure(ctx context.Context, w io.Writer, a agent.Agent, opts measureOptions) error {
+	cupboard, err := crumbs.NewCupboard(opts.DataDir)
+	if err != nil {
+		return err
+	}
+	defer cupboard.Close()
+
+	if opts.Import != "" {
+		return importProposals(w, cupboard, opts.Import)
+	}
+
+	templates, err := prompt.Load(opts.DataDir)
+	if err != nil {
+		return err
+	}
+	state, err := measure.ReadProjectState(opts.Root, cupboard)
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		text, err := measure.BuildPrompt(templates, state, opts.Limit)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, text)
+		return err
+	}
+
+	proposals, err := measure.Measure(ctx, a, state, measure.Config{Limit: opts.Limit, Templates: templates})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", opts.Output, err)
+	}
+	if err := measure.WriteProposals(f, proposals); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", opts.Output, err)
+	}
+	fmt.Fprintf(w, "wrote %d proposals to %s\n", len(proposals), opts.Output)
+	return nil
+}
+
+// importProposals creates pending crumbs from a reviewed proposals file.
+func importProposals(w io.Writer, cupboard *crumbs.Cupboard, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	proposals, err := measure.ReadProposals(f)
+	if err != nil {
+		return err
+	}
+	ids, err := measure.ImportProposals(cupboard, proposals)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		fmt.Fprintf(w, "%s %s\n", id, proposals[i].Title)
+	}
+	return nil
+}
+
 func init() {
+	measureCmd.Flags().StringVarP(&measureOpts.Output, "output", "o", defaultProposalsFile, "File to write proposals to for review")
+	measureCmd.Flags().IntVar(&measureOpts.Limit, "limit", measure.DefaultLimit, "Maximum number of proposals")
+	measureCmd.Flags().BoolVar(&measureOpts.DryRun, "dry-run", false, "Print the planning prompt instead of calling the agent")
+	measureCmd.Flags().StringVar(&measureOpts.Import, "import", "", "Import a reviewed proposals file into the cupboard as pending crumbs")
+	addAgentFlags(measureCmd)
 	rootCmd.AddCommand(measureCmd)
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+)
+
+const measureResponse = "```json\n" + `[
+  {"index": 0, "title": "Write the parser PRD", "work_type": "docs", "dependency": -1, "description": "Draft it.", "target_file": "docs/parser.md"},
+  {"index": 1, "title": "Implement the parser", "work_type": "code", "dependency": 0, "description": "Build it."}
+]` + "\n```"
+
+func TestRunMeasure(t *testing.T) {
+	dir := t.TempDir()
+	opts := measureOptions{
+		Root:    dir,
+		DataDir: filepath.Join(dir, "crumbs"),
+		Output:  filepath.Join(dir, "proposals.json"),
+		Limit:   10,
+	}
+
+	dryRun := opts
+	dryRun.DryRun = true
+	a := agent.NewMockAgent(agent.Response{Content: measureResponse})
+	var out bytes.Buffer
+	if err := runMeasure(context.Background(), &out, a, dryRun); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Existing crumbs") || len(a.Requests()) != 0 {
+		t.Errorf("dry run should print the prompt without calling the agent; output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runMeasure(context.Background(), &out, a, opts); err != nil {
+		t.Fatalf("runMeasure failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "wrote 2 proposals") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	imported := opts
+	imported.Import = opts.Output
+	out.Reset()
+	if err := runMeasure(context.Background(), &out, a, imported); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.HasSuffix(lines[1], "Implement the parser") {
+		t.Errorf("import output = %q, want one line per created crumb", out.String())
+	}
+}
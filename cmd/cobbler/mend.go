@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/petar-djukic/cobbler/internal/config"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/mend"
+	"github.com/petar-djukic/cobbler/internal/stitch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mendAll         bool
+	mendMaxAttempts int
+	mendStitch      = stitch.DefaultConfig()
+)
+
+var mendCmd = &cobra.Command{
+	Use:   "mend",
+	Short: "Fix issues found by inspect",
+	Long: `Mend runs targeted fixes for crumbs that inspect sent to mend, re-inspecting
+after each attempt.
+
+With --all, mend claims every crumb whose latest inspect action is mend,
+makes up to --max-attempts attempts each, and prints how many were
+accepted, remain in mend, or were escalated to human review. Accepted
+crumbs are closed; the rest are released back to ready.
+
+A docs crumb's target file is rewritten from the agent's response, as
+stitch writes it. A code crumb is mended in the worktree stitch kept under
+--worktree-root, where each attempt is committed and re-inspected against
+--base-branch; on accept its branch is merged into --base-branch.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !mendAll {
+			return fmt.Errorf("mend: specify --all")
+		}
+		fixer, err := newMendFixer(cfg, logger, mendStitch)
+		if err != nil {
+			return err
+		}
+		cupboard, err := crumbs.NewCupboard(cfg.DataDir)
+		if err != nil {
+			return err
+		}
+		defer cupboard.Close()
+
+		summary, err := mend.MendAll(cmd.Context(), cupboard, fixer, mend.Config{MaxAttempts: mendMaxAttempts, Logger: logger})
+		if err != nil {
+			return err
+		}
+		fmt.Println(summary)
+		for _, msg := range summary.Errors {
+			fmt.Printf("  failed: %s\n", msg)
+		}
+		return nil
+	},
+}
+
+// newMendFixer returns a fixer that runs the agent c configures on crumbs
+// stitched with sc and re-inspects them with the stitch portfolio.
+func newMendFixer(c config.Config, l *slog.Logger, sc stitch.Config) (mend.Fixer, error) {
+	a, err := newAgent(c, l)
+	if err != nil {
+		return nil, err
+	}
+	portfolio, err := newPortfolio(c, l)
+	if err != nil {
+		return nil, err
+	}
+	return mend.NewAgentFixer(a, portfolio, stitch.NewMendWorkspace(sc)), nil
+}
+
+func init() {
+	mendCmd.Flags().BoolVar(&mendAll, "all", false, "Mend every crumb whose latest inspect action is mend")
+	mendCmd.Flags().IntVar(&mendMaxAttempts, "max-attempts", mend.DefaultMaxAttempts, "Maximum mend attempts per crumb")
+	mendCmd.Flags().StringVar(&mendStitch.BaseBranch, "base-branch", stitch.DefaultBaseBranch, "Branch code crumbs are re-inspected against")
+	mendCmd.Flags().StringVar(&mendStitch.WorktreeRoot, "worktree-root", stitch.DefaultWorktreeRoot, "Directory of the code crumb worktrees stitch kept")
+	addAgentFlags(mendCmd)
+	rootCmd.AddCommand(mendCmd)
+}
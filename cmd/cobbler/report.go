@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/mend"
+	"github.com/spf13/cobra"
+)
+
+// defaultReportTop is the number of failure modes printed by default.
+const defaultReportTop = 10
+
+var reportTop int
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize why crumbs were not accepted",
+	Long: `Report aggregates the latest inspect result of every crumb in the cupboard.
+For crumbs that were not accepted, it tallies which techniques failed and
+prints the top failure modes with their fault class and the number of
+affected crumbs.
+
+This command is read-only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cupboard, err := crumbs.NewCupboard(cfg.DataDir)
+		if err != nil {
+			return err
+		}
+		defer cupboard.Close()
+
+		results, err := mend.LatestResults(cupboard)
+		if err != nil {
+			return fmt.Errorf("loading inspect results: %w", err)
+		}
+		return inspect.WriteFailureModes(os.Stdout, inspect.AggregateFailureModes(results), reportTop)
+	},
+}
+
+func init() {
+	reportCmd.Flags().IntVar(&reportTop, "top", defaultReportTop, "Number of failure modes to show (0 for all)")
+	rootCmd.AddCommand(reportCmd)
+}
@@ -1,12 +1,48 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 
+	"github.com/petar-djukic/cobbler/internal/agent"
+	cobble "github.com/petar-djukic/cobbler/internal/context"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/prompt"
+	"github.com/petar-djukic/cobbler/internal/stitch"
 	"github.com/spf13/cobra"
 )
 
-var stitchType string
+// Stitch task types.
+const (
+	stitchTypeDocs = "docs"
+	stitchTypeCode = "code"
+)
+
+// stitchOptions configures one stitch run.
+type stitchOptions struct {
+	Type    string
+	DataDir string
+	Config  stitch.Config
+	// CostModel prices the crumb's token usage in the summary.
+	CostModel agent.CostModel
+	// Stream prints the agent's output to stderr while it runs.
+	Stream bool
+}
+
+var stitchOpts = stitchOptions{Config: stitch.DefaultConfig(), CostModel: agent.DefaultCostModel()}
+
+// stitchGCOptions configures stitch gc.
+type stitchGCOptions struct {
+	WorktreeRoot string
+	DryRun       bool
+}
+
+var stitchGCOpts stitchGCOptions
 
 var stitchCmd = &cobra.Command{
 	Use:   "stitch",
@@ -16,13 +52,158 @@ runs an AI agent, validates the output, and closes the crumb.
 
 Task types:
   --type docs   Execute documentation tasks (write or update markdown)
-  --type code   Execute code tasks (git worktree, implement, merge)`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("stitch: not implemented (type=%s)\n", stitchType)
+  --type code   Execute code tasks (git worktree, implement, merge)
+
+A docs crumb names the file to write in its target_file property. When
+inspect accepts the file the crumb is closed; otherwise it is released back
+to ready with a note.
+
+A code crumb is implemented in a git worktree under --worktree-root on branch
+stitch/<crumb>, created from --base-branch. Stitch builds and tests the
+worktree, inspects the diff, and merges into the base branch (which must be
+checked out) only when inspect accepts. Otherwise the worktree is kept for
+inspection and the crumb is released to ready.
+
+Without --crumb, stitch claims the ready crumb with the highest priority
+property (1 to 5; crumbs without one count as 3), the oldest first among
+equals. Use --crumb to stitch a specific crumb instead.
+Prompts can be overridden with <data-dir>/prompts/stitch-docs.tmpl and
+stitch-code.tmpl.
+
+Agent runs that fail transiently, on a rate limit or an overloaded API, are
+retried with exponential backoff: see --agent-max-attempts and
+--agent-retry-delay. --stream prints the agent's output to stderr as it
+arrives, so long runs show progress.
+
+Worktrees kept for inspection accumulate; cobbler stitch gc removes those
+nothing will work in again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := newAgent(cfg, logger)
+		if err != nil {
+			return err
+		}
+		portfolio, err := newPortfolio(cfg, logger)
+		if err != nil {
+			return err
+		}
+		stitchOpts.DataDir = cfg.DataDir
+		stitchOpts.Config.Logger = logger
+		if stitchOpts.Stream {
+			stitchOpts.Config.AgentOutput = os.Stderr
+		}
+		return runStitch(cmd.Context(), os.Stdout, a, portfolio, stitchOpts)
+	},
+}
+
+var stitchGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove code task worktrees of finished crumbs",
+	Long: `Gc lists the code task worktrees under --worktree-root and removes those
+whose crumb is done or no longer in the cupboard. Worktrees of crumbs in any
+other state are kept; in particular, a taken crumb's worktree is never
+removed. The stitch/<crumb> branch of a removed worktree is deleted when it
+is merged; an unmerged branch is kept so its commits stay reachable.
+
+--dry-run lists what would be removed without removing anything.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCupboard(func(cupboard *crumbs.Cupboard) error {
+			return runStitchGC(cmd.Context(), os.Stdout, cupboard, stitchGCOpts)
+		})
 	},
 }
 
+// runStitchGC removes the stale code task worktrees, or with opts.DryRun
+// only finds them, and writes each worktree and what happened to it to w.
+func runStitchGC(ctx context.Context, w io.Writer, cupboard *crumbs.Cupboard, opts stitchGCOptions) error {
+	config := stitch.DefaultConfig()
+	config.WorktreeRoot = opts.WorktreeRoot
+	worktrees, err := stitch.CollectWorktrees(ctx, cupboard, config, opts.DryRun)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CRUMB\tSTATE\tACTION\tWORKTREE")
+	for _, wt := range worktrees {
+		state, action := string(wt.State), "kept"
+		if state == "" {
+			state = "missing"
+		}
+		switch {
+		case wt.Removed:
+			action = "removed"
+		case wt.Stale && opts.DryRun:
+			action = "would remove"
+		case wt.Stale:
+			action = "remove failed"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", wt.CrumbID, state, action, wt.Path)
+	}
+	if flushErr := tw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// runStitch stitches one crumb of opts.Type with a, inspecting the result
+// with portfolio, and writes the outcome to w.
+func runStitch(ctx context.Context, w io.Writer, a agent.Agent, portfolio *inspect.Portfolio, opts stitchOptions) error {
+	var run func(context.Context, *crumbs.Cupboard, agent.Agent, *inspect.Portfolio, stitch.Config) (stitch.Result, error)
+	switch opts.Type {
+	case stitchTypeDocs:
+		run = stitch.StitchDocs
+	case stitchTypeCode:
+		run = stitch.StitchCode
+	default:
+		return fmt.Errorf("stitch: unknown type %q (use %s or %s)", opts.Type, stitchTypeDocs, stitchTypeCode)
+	}
+	templates, err := prompt.Load(opts.DataDir)
+	if err != nil {
+		return err
+	}
+	cupboard, err := crumbs.NewCupboard(opts.DataDir)
+	if err != nil {
+		return err
+	}
+	defer cupboard.Close()
+
+	config := opts.Config
+	config.Templates = templates
+	result, err := run(ctx, cupboard, a, portfolio, config)
+	var notFound *crumbs.CrumbNotFoundError
+	if errors.As(err, &notFound) {
+		// A mistyped --crumb is the likely cause, not a cupboard failure.
+		return fmt.Errorf("stitch: no crumb %s in %s; cobbler crumbs list shows the crumbs", notFound.ID, opts.DataDir)
+	}
+	if err != nil {
+		return err
+	}
+	outcome := "released"
+	if result.Done {
+		outcome = "done"
+	}
+	fmt.Fprintf(w, "%s: %s (score %.2f), %s\n", result.CrumbID, result.Composite.Action, result.Composite.Score, outcome)
+	if result.Worktree != "" {
+		fmt.Fprintf(w, "  worktree kept at %s\n", result.Worktree)
+	}
+	u := result.Usage
+	if u == (agent.Usage{}) {
+		// A command agent reports no usage; a $0 estimate would mislead.
+		fmt.Fprintln(w, "  tokens: not reported by the agent")
+		return nil
+	}
+	fmt.Fprintf(w, "  tokens: %d in, %d out, %d cache write, %d cache read; estimated cost $%.4f\n",
+		u.InputTokens, u.OutputTokens, u.CacheCreationTokens, u.CacheReadTokens, opts.CostModel.Cost(u))
+	return nil
+}
+
 func init() {
-	stitchCmd.Flags().StringVar(&stitchType, "type", "docs", "Task type: docs or code")
+	stitchCmd.Flags().StringVar(&stitchOpts.Type, "type", stitchTypeDocs, "Task type: docs or code")
+	stitchCmd.Flags().StringVar(&stitchOpts.Config.CrumbID, "crumb", "", "Stitch this crumb instead of claiming the next ready one")
+	stitchCmd.Flags().StringVar(&stitchOpts.Config.BaseBranch, "base-branch", stitch.DefaultBaseBranch, "Branch code tasks start from and merge into")
+	stitchCmd.Flags().StringVar(&stitchOpts.Config.WorktreeRoot, "worktree-root", stitch.DefaultWorktreeRoot, "Directory for code task worktrees")
+	stitchCmd.Flags().IntVar(&stitchOpts.Config.ContextBudget, "context-budget", cobble.DefaultBudget, "Maximum bytes of repository context in each prompt")
+	stitchCmd.Flags().BoolVar(&stitchOpts.Stream, "stream", false, "Print the agent's output to stderr while it runs")
+	addAgentFlags(stitchCmd)
+	stitchGCCmd.Flags().StringVar(&stitchGCOpts.WorktreeRoot, "worktree-root", stitch.DefaultWorktreeRoot, "Directory for code task worktrees")
+	stitchGCCmd.Flags().BoolVar(&stitchGCOpts.DryRun, "dry-run", false, "List the worktrees that would be removed without removing them")
+	stitchCmd.AddCommand(stitchGCCmd)
 	rootCmd.AddCommand(stitchCmd)
 }
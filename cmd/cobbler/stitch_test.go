@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/stitch"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestRunStitch_UnknownType(t *testing.T) {
+	a := agent.NewMockAgent()
+	opts := stitchOptions{Type: "ops", DataDir: t.TempDir()}
+	if err := runStitch(context.Background(), io.Discard, a, defaultPortfolio(t), opts); err == nil {
+		t.Error("runStitch accepted an unknown type")
+	}
+	if len(a.Requests()) != 0 {
+		t.Error("agent was called for an unknown type")
+	}
+}
+
+func TestRunStitch_MissingCrumb(t *testing.T) {
+	a := agent.NewMockAgent()
+	opts := stitchOptions{Type: stitchTypeDocs, DataDir: t.TempDir()}
+	opts.Config.CrumbID = "missing"
+	err := runStitch(context.Background(), io.Discard, a, defaultPortfolio(t), opts)
+	if err == nil || !strings.Contains(err.Error(), "no crumb missing") {
+		t.Errorf("runStitch error = %v, want it to name the missing crumb", err)
+	}
+	if len(a.Requests()) != 0 {
+		t.Error("agent was called for a missing crumb")
+	}
+}
+
+func TestRunStitch_Usage(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage agent.Usage
+		want  string
+	}{
+		{"reported usage is priced", agent.Usage{InputTokens: 1000, OutputTokens: 100}, "tokens: 1000 in, 100 out, 0 cache write, 0 cache read; estimated cost $0.0045"},
+		{"no usage is not priced", agent.Usage{}, "tokens: not reported by the agent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			cupboard, err := crumbs.NewCupboard(dataDir)
+			if err != nil {
+				t.Fatalf("NewCupboard failed: %v", err)
+			}
+			_, err = cupboard.SetCrumb("", &types.Crumb{Name: "Write the guide", State: types.StateReady, Properties: map[string]any{
+				crumbs.PropWorkType:   inspect.WorkTypeDocs,
+				crumbs.PropTargetFile: "guide.md",
+			}})
+			cupboard.Close()
+			if err != nil {
+				t.Fatalf("SetCrumb failed: %v", err)
+			}
+
+			opts := stitchOptions{Type: stitchTypeDocs, DataDir: dataDir, CostModel: agent.DefaultCostModel()}
+			opts.Config.Dir = t.TempDir()
+			a := agent.NewMockAgent(agent.Response{Content: "# Guide\n", Usage: tt.usage})
+			var out bytes.Buffer
+			if err := runStitch(context.Background(), &out, a, defaultPortfolio(t), opts); err != nil {
+				t.Fatalf("runStitch failed: %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunStitchGC(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.email=gc@example.com", "-c", "user.name=GC Test", "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"worktree", "add", "-q", "-b", "stitch/gone", filepath.Join(stitch.DefaultWorktreeRoot, "gone"), "main"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	t.Chdir(repo)
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cupboard.Close()
+
+	for _, tt := range []struct {
+		dryRun bool
+		want   string
+	}{
+		{true, `gone\s+missing\s+would remove`},
+		{false, `gone\s+missing\s+removed`},
+	} {
+		var out bytes.Buffer
+		if err := runStitchGC(context.Background(), &out, cupboard, stitchGCOptions{WorktreeRoot: stitch.DefaultWorktreeRoot, DryRun: tt.dryRun}); err != nil {
+			t.Fatalf("runStitchGC(dry run %v) failed: %v", tt.dryRun, err)
+		}
+		if !regexp.MustCompile(tt.want).MatchString(out.String()) {
+			t.Errorf("runStitchGC(dry run %v) output = %q, want a line matching %q", tt.dryRun, out.String(), tt.want)
+		}
+	}
+}
+
+// defaultPortfolio returns inspect's default portfolio.
+func defaultPortfolio(t *testing.T) *inspect.Portfolio {
+	t.Helper()
+	p, err := inspect.NewDefaultPortfolio()
+	if err != nil {
+		t.Fatalf("NewDefaultPortfolio: %v", err)
+	}
+	return p
+}
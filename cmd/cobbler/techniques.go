@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/spf13/cobra"
+)
+
+var matrixDir string
+
+var techniquesCmd = &cobra.Command{
+	Use:   "techniques",
+	Short: "Introspect the inspect verification techniques",
+}
+
+var techniquesMatrixCmd = &cobra.Command{
+	Use:   "matrix",
+	Short: "Show which techniques apply to which work types in this project",
+	Long: `Matrix detects project characteristics (code, tests, fixtures, PRDs) and
+evaluates each registered technique against a representative input for every
+work type. It prints applicable or not-applicable with the reason.
+
+This command is read-only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := inspect.DetectProfile(matrixDir)
+		if err != nil {
+			return fmt.Errorf("detecting project profile: %w", err)
+		}
+		rows := inspect.ApplicabilityMatrix(inspect.DefaultTechniques(), profile)
+		return inspect.WriteMatrix(os.Stdout, rows)
+	},
+}
+
+func init() {
+	techniquesMatrixCmd.Flags().StringVar(&matrixDir, "dir", ".", "Project root to evaluate")
+	techniquesCmd.AddCommand(techniquesMatrixCmd)
+	rootCmd.AddCommand(techniquesCmd)
+}
@@ -3,13 +3,15 @@ module github.com/petar-djukic/cobbler
 go 1.25.7
 
 require (
+	github.com/google/uuid v1.6.0
 	github.com/petar-djukic/crumbs v0.0.0-00010101000000-000000000000
 	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.44.3
 )
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v1.0.0 // indirect
@@ -20,7 +22,6 @@ require (
 	modernc.org/libc v1.67.6 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
-	modernc.org/sqlite v1.44.3 // indirect
 )
 
 replace github.com/petar-djukic/crumbs => /Users/petardjukic/WORKSPACE/crumbs
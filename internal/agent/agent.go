@@ -0,0 +1,139 @@
+// Package agent abstracts the AI agents cobbler dispatches work to.
+// Implements: prd001-agent-interface R1 (Agent interface), R3 (response),
+// R6 (cancellation), R7 (token tracking).
+//
+// An Agent takes a prompt and returns the generated text and its token
+// usage. CommandAgent runs an agent CLI (for example "claude -p") with the
+// prompt on stdin; MockAgent returns canned responses for tests. RetryAgent
+// wraps another agent to retry rate limits and other transient failures. A
+// StreamingAgent also delivers output while it runs; Streaming adapts any
+// agent to it.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultCommand is the agent CLI used when none is configured.
+const DefaultCommand = "claude -p"
+
+// ErrAgent reports that the agent failed to produce a response.
+var ErrAgent = fmt.Errorf("agent: run failed")
+
+// Request is one prompt sent to an agent.
+type Request struct {
+	// Prompt is the full text the agent receives.
+	Prompt string
+	// Dir is the directory the agent works in; empty means the current
+	// directory.
+	Dir string
+}
+
+// Response is the agent's answer to a Request.
+type Response struct {
+	// Content is the generated text.
+	Content string
+	// Usage is the token usage of the request. Agents that cannot report
+	// usage leave it zero.
+	Usage Usage
+}
+
+// Usage counts the tokens one agent invocation consumed (prd001 R7.1).
+type Usage struct {
+	InputTokens         int `json:"input_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	CacheCreationTokens int `json:"cache_creation_tokens"`
+	CacheReadTokens     int `json:"cache_read_tokens"`
+}
+
+// Agent runs a single request. Implementations must honor ctx cancellation.
+type Agent interface {
+	Run(ctx context.Context, req Request) (Response, error)
+}
+
+// CommandAgent runs an external command, writing the prompt to its stdin and
+// reading the response from its stdout. It does not report usage: stdout is
+// the response content, with no token counts to parse, so the usage totals
+// stitch records for crumbs done through it stay zero.
+type CommandAgent struct {
+	// Args is the command and its arguments.
+	Args []string
+}
+
+// NewCommandAgent creates a CommandAgent from a command line split on
+// whitespace, such as "claude -p".
+func NewCommandAgent(command string) (*CommandAgent, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("agent: empty command")
+	}
+	return &CommandAgent{Args: args}, nil
+}
+
+// streamBufferSize bounds the content of one chunk streamed from a command.
+const streamBufferSize = 4096
+
+// Run executes the command in req.Dir with req.Prompt on stdin. A non-zero exit is
+// reported as ErrAgent with the command's stderr.
+func (a *CommandAgent) Run(ctx context.Context, req Request) (Response, error) {
+	chunks, err := a.RunStream(ctx, req)
+	if err != nil {
+		return Response{}, err
+	}
+	resp, err := Collect(chunks, nil)
+	if err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// RunStream starts the command as Run does and streams its stdout as it is
+// written. The final chunk carries Run's error.
+func (a *CommandAgent) RunStream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	cmd := exec.CommandContext(ctx, a.Args[0], a.Args[1:]...)
+	cmd.Dir = req.Dir
+	cmd.Stdin = strings.NewReader(req.Prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, a.failure(ctx, err, &stderr)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, a.failure(ctx, err, &stderr)
+	}
+
+	chunks := make(chan Chunk)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, streamBufferSize)
+		for {
+			n, readErr := stdout.Read(buf)
+			if n > 0 {
+				chunks <- Chunk{Content: string(buf[:n])}
+			}
+			if readErr != nil {
+				break
+			}
+		}
+		final := Chunk{Final: true}
+		if err := cmd.Wait(); err != nil {
+			final.Err = a.failure(ctx, err, &stderr)
+		}
+		chunks <- final
+	}()
+	return chunks, nil
+}
+
+// failure reports a failed run: ctx's error when it was cancelled, and
+// otherwise ErrAgent with the command's stderr.
+func (a *CommandAgent) failure(ctx context.Context, err error, stderr *bytes.Buffer) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w: %s: %v: %s", ErrAgent, strings.Join(a.Args, " "), err, strings.TrimSpace(stderr.String()))
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCommandAgent(t *testing.T) {
+	a, err := NewCommandAgent("cat")
+	if err != nil {
+		t.Fatalf("NewCommandAgent failed: %v", err)
+	}
+	resp, err := a.Run(context.Background(), Request{Prompt: "propose tasks"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if resp.Content != "propose tasks" {
+		t.Errorf("Content = %q, want the prompt echoed back", resp.Content)
+	}
+
+	failing := &CommandAgent{Args: []string{"sh", "-c", "echo quota exceeded >&2; exit 1"}}
+	_, err = failing.Run(context.Background(), Request{})
+	if !errors.Is(err, ErrAgent) {
+		t.Fatalf("Run error = %v, want ErrAgent", err)
+	}
+	if got := err.Error(); !strings.Contains(got, "quota exceeded") {
+		t.Errorf("error %q should include the command's stderr", got)
+	}
+
+	if _, err := NewCommandAgent("  "); err == nil {
+		t.Error("NewCommandAgent accepted an empty command")
+	}
+}
+
+func TestMockAgent(t *testing.T) {
+	m := NewMockAgent(
+		Response{Content: "first", Usage: Usage{InputTokens: 10, OutputTokens: 2}},
+		Response{Content: "second"},
+	)
+	var got []string
+	for _, prompt := range []string{"a", "b", "c"} {
+		resp, err := m.Run(context.Background(), Request{Prompt: prompt})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		got = append(got, resp.Content)
+	}
+	if strings.Join(got, ",") != "first,second,second" {
+		t.Errorf("responses = %v, want canned responses with the last repeated", got)
+	}
+	if reqs := m.Requests(); len(reqs) != 3 || reqs[2].Prompt != "c" {
+		t.Errorf("Requests = %+v, want all three recorded", reqs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.Run(ctx, Request{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCostModel(t *testing.T) {
+	u := Usage{InputTokens: 1000, OutputTokens: 200}.Add(Usage{InputTokens: 1000, CacheCreationTokens: 400, CacheReadTokens: 10000})
+	if want := (Usage{InputTokens: 2000, OutputTokens: 200, CacheCreationTokens: 400, CacheReadTokens: 10000}); u != want {
+		t.Fatalf("Add = %+v, want %+v", u, want)
+	}
+	m := CostModel{InputPerMTok: 3, OutputPerMTok: 15, CacheCreationPerMTok: 3.75, CacheReadPerMTok: 0.3}
+	// 2000*3 + 200*15 + 400*3.75 + 10000*0.3 = 13500 dollars per million.
+	if got, want := m.Cost(u), 0.0135; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package agent
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:         u.InputTokens + other.InputTokens,
+		OutputTokens:        u.OutputTokens + other.OutputTokens,
+		CacheCreationTokens: u.CacheCreationTokens + other.CacheCreationTokens,
+		CacheReadTokens:     u.CacheReadTokens + other.CacheReadTokens,
+	}
+}
+
+// CostModel prices token usage in US dollars per million tokens.
+type CostModel struct {
+	InputPerMTok         float64
+	OutputPerMTok        float64
+	CacheCreationPerMTok float64
+	CacheReadPerMTok     float64
+}
+
+// DefaultCostModel returns list prices for a Claude Sonnet class model.
+func DefaultCostModel() CostModel {
+	return CostModel{
+		InputPerMTok:         3.00,
+		OutputPerMTok:        15.00,
+		CacheCreationPerMTok: 3.75,
+		CacheReadPerMTok:     0.30,
+	}
+}
+
+// Cost estimates the dollar cost of u.
+func (m CostModel) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*m.InputPerMTok +
+		float64(u.OutputTokens)*m.OutputPerMTok +
+		float64(u.CacheCreationTokens)*m.CacheCreationPerMTok +
+		float64(u.CacheReadTokens)*m.CacheReadPerMTok) / 1e6
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+	"sync"
+)
+
+// MockAgent returns canned responses and records the requests it receives.
+// It is safe for concurrent use.
+type MockAgent struct {
+	// Responses are returned in order; the last one repeats once the rest
+	// are used up. With no responses, Run returns an empty Response.
+	Responses []Response
+	// Err, when set, is returned by every Run instead of a response.
+	Err error
+	// OnRun, when set, is called with each request before responding. It
+	// simulates agent side effects such as edited files; an error from
+	// OnRun is returned by Run.
+	OnRun func(Request) error
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewMockAgent creates a MockAgent that returns responses in order.
+func NewMockAgent(responses ...Response) *MockAgent {
+	return &MockAgent{Responses: responses}
+}
+
+// Run records req and returns the next canned response.
+func (m *MockAgent) Run(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	m.mu.Lock()
+	n := len(m.requests)
+	m.requests = append(m.requests, req)
+	m.mu.Unlock()
+
+	if m.OnRun != nil {
+		if err := m.OnRun(req); err != nil {
+			return Response{}, err
+		}
+	}
+	if m.Err != nil {
+		return Response{}, m.Err
+	}
+	if len(m.Responses) == 0 {
+		return Response{}, nil
+	}
+	return m.Responses[min(n, len(m.Responses)-1)], nil
+}
+
+// Requests returns the requests received so far, in order.
+func (m *MockAgent) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Request(nil), m.requests...)
+}
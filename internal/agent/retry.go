@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/logging"
+)
+
+// Default retry settings.
+const (
+	DefaultMaxAttempts    = 4
+	DefaultRetryBaseDelay = 2 * time.Second
+	DefaultRetryMaxDelay  = time.Minute
+)
+
+// ErrTransient marks an agent failure that may succeed when retried. Agents
+// that can tell transient failures apart wrap it; otherwise IsTransient
+// recognizes them from the error text.
+var ErrTransient = fmt.Errorf("agent: transient failure")
+
+// transientMarkers are lowercase substrings of error output that signal a
+// rate limit or a temporarily unavailable API.
+var transientMarkers = []string{
+	"429",
+	"503",
+	"529",
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"overloaded",
+	"service unavailable",
+	"temporarily unavailable",
+}
+
+// IsTransient reports whether err is worth retrying: it wraps ErrTransient
+// or its text names a rate limit or an unavailable service, as 429 and 503
+// responses do. Cancellation and deadline errors are never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrTransient) {
+		return true
+	}
+	text := strings.ToLower(err.Error())
+	for _, marker := range transientMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryConfig controls a RetryAgent.
+type RetryConfig struct {
+	// MaxAttempts bounds the runs per request, including the first.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; each retry doubles it.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts.
+	MaxDelay time.Duration
+	// Logger receives a warning per retry; nil discards them.
+	Logger *slog.Logger
+}
+
+// DefaultRetryConfig returns the default retry settings.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// RetryAgent retries the requests of another agent that fail transiently,
+// as IsTransient classifies them, with exponential backoff and jitter.
+// Other errors are returned at once.
+type RetryAgent struct {
+	agent  Agent
+	config RetryConfig
+	// sleep waits for d or until ctx is done.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryAgent wraps a with the retry policy of config. A MaxAttempts
+// below one means a single attempt.
+func NewRetryAgent(a Agent, config RetryConfig) *RetryAgent {
+	return &RetryAgent{agent: a, config: config, sleep: sleepContext}
+}
+
+// Run runs req on the wrapped agent until it succeeds, fails with an error
+// that is not transient, or uses up MaxAttempts. The last error is returned,
+// annotated with the attempt count when there were retries.
+func (r *RetryAgent) Run(ctx context.Context, req Request) (Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := r.agent.Run(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if err := r.wait(ctx, attempt, err); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// RunStream streams req from the wrapped agent, retrying as Run does. Once
+// an attempt has streamed content, its failure is final: the content cannot
+// be taken back from the receiver.
+func (r *RetryAgent) RunStream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	stream := Streaming(r.agent)
+	out := make(chan Chunk)
+	go func() {
+		defer close(out)
+		for attempt := 1; ; attempt++ {
+			final, streamed := forward(ctx, stream, req, out)
+			if final.Err == nil || streamed {
+				out <- final
+				return
+			}
+			if err := r.wait(ctx, attempt, final.Err); err != nil {
+				final.Err = err
+				out <- final
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// forward runs one streamed attempt, sending its content chunks to out, and
+// returns its final chunk and whether any content was sent.
+func forward(ctx context.Context, stream StreamingAgent, req Request, out chan<- Chunk) (Chunk, bool) {
+	chunks, err := stream.RunStream(ctx, req)
+	if err != nil {
+		return Chunk{Final: true, Err: err}, false
+	}
+	final := Chunk{Final: true, Err: ErrIncompleteStream}
+	streamed := false
+	for c := range chunks {
+		if c.Final {
+			final = c
+			continue
+		}
+		streamed = streamed || c.Content != ""
+		out <- c
+	}
+	return final, streamed
+}
+
+// wait decides what follows failed attempt number attempt: it returns nil
+// after the backoff when err is transient and attempts remain, and
+// otherwise the error to report.
+func (r *RetryAgent) wait(ctx context.Context, attempt int, err error) error {
+	if !IsTransient(err) {
+		return err
+	}
+	if attempt >= max(1, r.config.MaxAttempts) {
+		if attempt > 1 {
+			err = fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+		return err
+	}
+	delay := r.backoff(attempt)
+	logging.OrDiscard(r.config.Logger).Warn("agent retry", logging.KeyAttempt, attempt, logging.KeyDuration, delay, "error", err)
+	return r.sleep(ctx, delay)
+}
+
+// backoff returns the wait after the given failed attempt: BaseDelay doubled
+// per earlier attempt and capped at MaxDelay, of which a random half is
+// dropped so concurrent callers do not retry in lockstep.
+func (r *RetryAgent) backoff(attempt int) time.Duration {
+	d := r.config.BaseDelay
+	for i := 1; i < attempt && (r.config.MaxDelay <= 0 || d < r.config.MaxDelay); i++ {
+		d *= 2
+	}
+	if r.config.MaxDelay > 0 {
+		d = min(d, r.config.MaxDelay)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// sleepContext waits for d, returning ctx.Err() if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// flakyAgent returns a MockAgent whose first failures runs fail with err.
+func flakyAgent(failures int, err error) *MockAgent {
+	m := NewMockAgent(Response{Content: "done"})
+	m.OnRun = func(Request) error {
+		if len(m.Requests()) <= failures {
+			return err
+		}
+		return nil
+	}
+	return m
+}
+
+// recordSleeps makes r record its waits instead of sleeping.
+func recordSleeps(r *RetryAgent) *[]time.Duration {
+	var sleeps []time.Duration
+	r.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return &sleeps
+}
+
+func TestRetryAgent(t *testing.T) {
+	rateLimited := fmt.Errorf("%w: claude -p: exit status 1: API error 429: rate limit exceeded", ErrAgent)
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"fails twice then succeeds", 2, rateLimited, 3, false},
+		{"wrapped transient error", 2, fmt.Errorf("upstream: %w", ErrTransient), 3, false},
+		{"gives up after max attempts", 10, rateLimited, 4, true},
+		{"non-retryable error", 10, fmt.Errorf("%w: invalid API key", ErrAgent), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := flakyAgent(tt.failures, tt.err)
+			r := NewRetryAgent(mock, RetryConfig{MaxAttempts: 4, BaseDelay: time.Second, MaxDelay: 3 * time.Second})
+			sleeps := recordSleeps(r)
+
+			resp, err := r.Run(context.Background(), Request{Prompt: "stitch"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Run error = %v, want it to wrap %v", err, tt.err)
+			}
+			if err == nil && resp.Content != "done" {
+				t.Errorf("Content = %q, want done", resp.Content)
+			}
+			if calls := len(mock.Requests()); calls != tt.wantCalls {
+				t.Errorf("agent ran %d times, want %d", calls, tt.wantCalls)
+			}
+			if len(*sleeps) != tt.wantCalls-1 {
+				t.Fatalf("slept %d times, want %d", len(*sleeps), tt.wantCalls-1)
+			}
+			// Waits double from BaseDelay up to MaxDelay, jittered into
+			// [d/2, d].
+			for i, d := range *sleeps {
+				full := min(time.Second<<i, 3*time.Second)
+				if d < full/2 || d > full {
+					t.Errorf("wait %d = %v, want within [%v, %v]", i, d, full/2, full)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAgent_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mock := flakyAgent(10, ErrTransient)
+	r := NewRetryAgent(mock, RetryConfig{MaxAttempts: 4, BaseDelay: time.Hour})
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+	if _, err := r.Run(ctx, Request{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run error = %v, want context.Canceled", err)
+	}
+	if calls := len(mock.Requests()); calls != 1 {
+		t.Errorf("agent ran %d times after cancellation, want 1", calls)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("HTTP 503 Service Unavailable"), true},
+		{errors.New("Overloaded"), true},
+		{errors.New("Too Many Requests"), true},
+		{fmt.Errorf("retry later: %w", ErrTransient), true},
+		{errors.New("invalid prompt"), false},
+		{fmt.Errorf("429: %w", context.DeadlineExceeded), false},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
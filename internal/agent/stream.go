@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrIncompleteStream reports a stream that closed without its final chunk.
+var ErrIncompleteStream = errors.New("agent: stream ended without a final chunk")
+
+// Chunk is one piece of a streamed response. Content chunks arrive as the
+// agent produces output; the stream ends with one final chunk, with Final
+// set, that carries no content but the request's usage and error.
+type Chunk struct {
+	// Content is the text produced since the previous chunk.
+	Content string
+	// Final marks the last chunk of the stream.
+	Final bool
+	// Usage is the token usage of the whole request, on the final chunk.
+	Usage Usage
+	// Err is the request's error, on the final chunk.
+	Err error
+}
+
+// StreamingAgent is an Agent that can also deliver its output while it runs.
+type StreamingAgent interface {
+	Agent
+	// RunStream starts req and returns its chunks. The channel is closed
+	// after the final chunk; callers must receive until it is closed. An
+	// error means the request did not start.
+	RunStream(ctx context.Context, req Request) (<-chan Chunk, error)
+}
+
+// Streaming returns a as a StreamingAgent. An agent that cannot stream is
+// buffered: its whole response arrives as one content chunk when it
+// finishes.
+func Streaming(a Agent) StreamingAgent {
+	if s, ok := a.(StreamingAgent); ok {
+		return s
+	}
+	return bufferedAgent{a}
+}
+
+// Collect receives chunks until the stream closes and assembles them into
+// the response, with the usage and error of the final chunk. progress, when
+// not nil, is called with each content chunk as it arrives.
+func Collect(chunks <-chan Chunk, progress func(Chunk)) (Response, error) {
+	var content strings.Builder
+	var final *Chunk
+	for c := range chunks {
+		if c.Final {
+			final = &c
+			continue
+		}
+		content.WriteString(c.Content)
+		if progress != nil {
+			progress(c)
+		}
+	}
+	if final == nil {
+		return Response{Content: content.String()}, ErrIncompleteStream
+	}
+	return Response{Content: content.String(), Usage: final.Usage}, final.Err
+}
+
+// bufferedAgent adapts an Agent that cannot stream to StreamingAgent.
+type bufferedAgent struct {
+	Agent
+}
+
+// RunStream runs the request to completion, then sends its content and the
+// final chunk.
+func (b bufferedAgent) RunStream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	chunks := make(chan Chunk, 2)
+	go func() {
+		defer close(chunks)
+		resp, err := b.Run(ctx, req)
+		if resp.Content != "" {
+			chunks <- Chunk{Content: resp.Content}
+		}
+		chunks <- Chunk{Final: true, Usage: resp.Usage, Err: err}
+	}()
+	return chunks, nil
+}
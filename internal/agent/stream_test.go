@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCommandAgent_RunStream(t *testing.T) {
+	a := &CommandAgent{Args: []string{"sh", "-c", "printf first; sleep 0.1; printf second"}}
+	chunks, err := a.RunStream(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	var parts []string
+	resp, err := Collect(chunks, func(c Chunk) { parts = append(parts, c.Content) })
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if resp.Content != "firstsecond" {
+		t.Errorf("Content = %q, want firstsecond", resp.Content)
+	}
+	if len(parts) < 2 || parts[0] != "first" {
+		t.Errorf("chunks = %q, want the output before the pause delivered first", parts)
+	}
+
+	failing := &CommandAgent{Args: []string{"sh", "-c", "printf partial; echo overloaded >&2; exit 1"}}
+	chunks, err = failing.RunStream(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	if _, err := Collect(chunks, nil); !errors.Is(err, ErrAgent) {
+		t.Errorf("Collect error = %v, want ErrAgent from the final chunk", err)
+	}
+}
+
+func TestStreaming_BuffersPlainAgents(t *testing.T) {
+	command := &CommandAgent{Args: []string{"cat"}}
+	if Streaming(command) != StreamingAgent(command) {
+		t.Error("Streaming wrapped an agent that already streams")
+	}
+
+	usage := Usage{InputTokens: 100, OutputTokens: 20}
+	mock := NewMockAgent(Response{Content: "whole answer", Usage: usage})
+	chunks, err := Streaming(mock).RunStream(context.Background(), Request{Prompt: "p"})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	var got []Chunk
+	for c := range chunks {
+		got = append(got, c)
+	}
+	want := []Chunk{{Content: "whole answer"}, {Final: true, Usage: usage}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("chunks = %+v, want %+v", got, want)
+	}
+
+	mock.Err = ErrAgent
+	chunks, _ = Streaming(mock).RunStream(context.Background(), Request{})
+	if _, err := Collect(chunks, nil); !errors.Is(err, ErrAgent) {
+		t.Errorf("Collect error = %v, want the agent's error", err)
+	}
+}
+
+func TestCollect_IncompleteStream(t *testing.T) {
+	chunks := make(chan Chunk, 1)
+	chunks <- Chunk{Content: "cut off"}
+	close(chunks)
+	resp, err := Collect(chunks, nil)
+	if !errors.Is(err, ErrIncompleteStream) || resp.Content != "cut off" {
+		t.Errorf("Collect = %q, %v; want the content and ErrIncompleteStream", resp.Content, err)
+	}
+}
+
+// scriptedStream streams each attempt's chunks in turn.
+type scriptedStream struct {
+	attempts [][]Chunk
+	runs     int
+}
+
+func (s *scriptedStream) Run(ctx context.Context, req Request) (Response, error) {
+	chunks, _ := s.RunStream(ctx, req)
+	return Collect(chunks, nil)
+}
+
+func (s *scriptedStream) RunStream(context.Context, Request) (<-chan Chunk, error) {
+	attempt := s.attempts[min(s.runs, len(s.attempts)-1)]
+	s.runs++
+	chunks := make(chan Chunk, len(attempt))
+	for _, c := range attempt {
+		chunks <- c
+	}
+	close(chunks)
+	return chunks, nil
+}
+
+func TestRetryAgent_RunStream(t *testing.T) {
+	overloaded := Chunk{Final: true, Err: ErrTransient}
+	tests := []struct {
+		name     string
+		attempts [][]Chunk
+		wantRuns int
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "retries before any output",
+			attempts: [][]Chunk{{overloaded}, {overloaded}, {{Content: "ok"}, {Final: true}}},
+			wantRuns: 3,
+			want:     "ok",
+		},
+		{
+			name:     "output already streamed",
+			attempts: [][]Chunk{{{Content: "half"}, overloaded}, {{Content: "ok"}, {Final: true}}},
+			wantRuns: 1,
+			want:     "half",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &scriptedStream{attempts: tt.attempts}
+			r := NewRetryAgent(stream, RetryConfig{MaxAttempts: 4})
+			recordSleeps(r)
+			chunks, err := r.RunStream(context.Background(), Request{})
+			if err != nil {
+				t.Fatalf("RunStream failed: %v", err)
+			}
+			resp, err := Collect(chunks, nil)
+			if (err != nil) != tt.wantErr || resp.Content != tt.want {
+				t.Errorf("Collect = %q, %v; want %q, error %v", resp.Content, err, tt.want, tt.wantErr)
+			}
+			if stream.runs != tt.wantRuns {
+				t.Errorf("agent ran %d times, want %d", stream.runs, tt.wantRuns)
+			}
+		})
+	}
+
+	// A plain agent is buffered, so each failed attempt streams nothing and
+	// is retried.
+	mock := flakyAgent(2, ErrTransient)
+	r := NewRetryAgent(mock, RetryConfig{MaxAttempts: 4})
+	recordSleeps(r)
+	chunks, _ := r.RunStream(context.Background(), Request{})
+	if resp, err := Collect(chunks, nil); err != nil || resp.Content != "done" || len(mock.Requests()) != 3 {
+		t.Errorf("flaky plain agent: %q, %v after %d runs; want done after 3", resp.Content, err, len(mock.Requests()))
+	}
+}
@@ -0,0 +1,198 @@
+// Package config holds the settings shared by cobbler commands and loads
+// them from a configuration file.
+// Implements: docs/ARCHITECTURE § project structure (internal/config);
+// prd001-agent-interface R9 (configuration).
+//
+// Settings resolve with flag > configuration file > built-in default. Load
+// applies a file over Default; commands then apply the flags the user set.
+// Configuration files are YAML.
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the resolved cobbler configuration.
+type Config struct {
+	// DataDir is the crumbs data directory.
+	DataDir string  `yaml:"data_dir"`
+	Agent   Agent   `yaml:"agent"`
+	Inspect Inspect `yaml:"inspect"`
+}
+
+// Agent configures the agent commands dispatch work to.
+type Agent struct {
+	// Command is the agent CLI; the prompt is written to its stdin.
+	Command string `yaml:"command"`
+	// MaxAttempts bounds the runs of one request when the agent fails
+	// transiently, for example on a rate limit.
+	MaxAttempts int `yaml:"max_attempts"`
+	// RetryBaseDelay is the wait before the first retry, as a Go duration
+	// such as "2s"; each retry doubles it.
+	RetryBaseDelay string `yaml:"retry_base_delay"`
+}
+
+// Inspect configures scoring and technique execution.
+type Inspect struct {
+	// Weights maps technique names to scorer weights. Techniques a file
+	// does not name keep their default weight.
+	Weights         map[string]float64 `yaml:"weights"`
+	AcceptThreshold float64            `yaml:"accept_threshold"`
+	MendThreshold   float64            `yaml:"mend_threshold"`
+	// Tiers maps action labels to the minimum score that takes them, for
+	// example to add an accept_with_warning tier between mend and accept.
+	// When set it replaces accept_threshold and mend_threshold; scores
+	// below every tier go to human review.
+	Tiers map[string]float64 `yaml:"tiers"`
+	// Aggregation combines technique scores: weighted_mean or
+	// weighted_median.
+	Aggregation inspect.Aggregation `yaml:"aggregation"`
+	// Concurrency bounds how many techniques run at once.
+	Concurrency int `yaml:"concurrency"`
+	// TechniqueTimeout bounds each technique's run, as a Go duration such
+	// as "10m"; a technique that runs longer is skipped with a timeout
+	// note. Empty or zero leaves techniques unbounded.
+	TechniqueTimeout string `yaml:"technique_timeout"`
+	// Security registers the gosec security runner, which needs gosec
+	// installed.
+	Security bool `yaml:"security"`
+	// EnabledTechniques limits the built-in techniques that run to those
+	// named, for example to skip mutation testing on a large repository.
+	// Empty runs them all. Opt-in runners such as security still need
+	// their own setting.
+	EnabledTechniques []string `yaml:"enabled_techniques"`
+	// TestCommand replaces go test in the translation validator and the
+	// mutation runner, for example [go, test, -tags, integration] or a
+	// wrapper script such as [./scripts/test.sh] that passes its arguments
+	// on to go test. The techniques append their go test flags and
+	// packages. Empty runs go test.
+	TestCommand []string `yaml:"test_command"`
+	// Expected lists the techniques inspect expects to run; with Strict,
+	// inspect fails when one of them is skipped.
+	Expected []string `yaml:"expect"`
+	Strict   bool     `yaml:"strict"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	scorer := inspect.DefaultScorerConfig()
+	return Config{
+		DataDir: crumbs.DefaultDataDir,
+		Agent: Agent{
+			Command:        agent.DefaultCommand,
+			MaxAttempts:    agent.DefaultMaxAttempts,
+			RetryBaseDelay: agent.DefaultRetryBaseDelay.String(),
+		},
+		Inspect: Inspect{
+			Weights:         scorer.Weights,
+			AcceptThreshold: scorer.AcceptThreshold,
+			MendThreshold:   scorer.MendThreshold,
+			Aggregation:     scorer.Aggregation,
+			Concurrency:     inspect.DefaultPortfolioConcurrency,
+		},
+	}
+}
+
+// Load reads the configuration file at path and applies it over Default.
+// Settings the file does not mention keep their defaults; unknown keys are
+// an error so typos do not go unnoticed.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config: %w", err)
+	}
+	cfg, err := parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parse decodes a configuration document over Default: keys the document
+// omits keep their default values, and weights merge into the default
+// weights.
+func parse(data []byte) (Config, error) {
+	cfg := Default()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, err
+	}
+	if _, err := cfg.RetryConfig(); err != nil {
+		return Config{}, err
+	}
+	if _, err := cfg.TechniqueTimeout(); err != nil {
+		return Config{}, err
+	}
+	if err := inspect.CheckTechniqueNames(cfg.Inspect.EnabledTechniques); err != nil {
+		return Config{}, fmt.Errorf("enabled_techniques: %w", err)
+	}
+	return cfg, nil
+}
+
+// ScorerConfig returns the default scorer configuration with the weights,
+// thresholds, and aggregation of c.
+func (c Config) ScorerConfig() inspect.ScorerConfig {
+	scorer := inspect.DefaultScorerConfig()
+	scorer.Weights = maps.Clone(c.Inspect.Weights)
+	scorer.AcceptThreshold = c.Inspect.AcceptThreshold
+	scorer.MendThreshold = c.Inspect.MendThreshold
+	scorer.Tiers = c.tiers()
+	scorer.Aggregation = c.Inspect.Aggregation
+	return scorer
+}
+
+// tiers returns the action tiers of c, highest threshold first; labels
+// with equal thresholds are ordered by name.
+func (c Config) tiers() []inspect.Tier {
+	var tiers []inspect.Tier
+	for action, threshold := range c.Inspect.Tiers {
+		tiers = append(tiers, inspect.Tier{Threshold: threshold, Action: inspect.Action(action)})
+	}
+	slices.SortFunc(tiers, func(a, b inspect.Tier) int {
+		if n := cmp.Compare(b.Threshold, a.Threshold); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Action, b.Action)
+	})
+	return tiers
+}
+
+// RetryConfig returns the default agent retry configuration with the
+// attempts and base delay of c.
+func (c Config) RetryConfig() (agent.RetryConfig, error) {
+	retry := agent.DefaultRetryConfig()
+	retry.MaxAttempts = c.Agent.MaxAttempts
+	delay, err := time.ParseDuration(c.Agent.RetryBaseDelay)
+	if err != nil || delay < 0 {
+		return agent.RetryConfig{}, fmt.Errorf("agent: invalid retry_base_delay %q: want a duration such as 2s", c.Agent.RetryBaseDelay)
+	}
+	retry.BaseDelay = delay
+	return retry, nil
+}
+
+// TechniqueTimeout returns the technique_timeout of c; zero when it is
+// unset.
+func (c Config) TechniqueTimeout() (time.Duration, error) {
+	if c.Inspect.TechniqueTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.Inspect.TechniqueTimeout)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("inspect: invalid technique_timeout %q: want a duration such as 10m", c.Inspect.TechniqueTimeout)
+	}
+	return timeout, nil
+}
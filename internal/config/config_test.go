@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	doc := "agent:\n  command: my-agent\ninspect:\n  weights:\n    mutation_testing: 0.3\n  accept_threshold: 0.9\n  aggregation: weighted_median\n"
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	def := Default()
+	if cfg.Agent.Command != "my-agent" || cfg.DataDir != def.DataDir {
+		t.Errorf("cfg = %+v, want file agent and default data dir", cfg)
+	}
+	scorer := cfg.ScorerConfig()
+	if scorer.AcceptThreshold != 0.9 || scorer.MendThreshold != def.Inspect.MendThreshold {
+		t.Errorf("thresholds = %v/%v", scorer.AcceptThreshold, scorer.MendThreshold)
+	}
+	if scorer.Aggregation != inspect.AggregationWeightedMedian || def.Inspect.Aggregation != inspect.AggregationWeightedMean {
+		t.Errorf("aggregation = %q (default %q), want the file's weighted median over the mean default", scorer.Aggregation, def.Inspect.Aggregation)
+	}
+	if scorer.Weights[inspect.MutationRunnerName] != 0.3 ||
+		scorer.Weights[inspect.TranslationValidatorName] != inspect.DefaultWeights[inspect.TranslationValidatorName] {
+		t.Errorf("weights = %v, want the file weight merged over the defaults", scorer.Weights)
+	}
+	if inspect.DefaultWeights[inspect.MutationRunnerName] == 0.3 {
+		t.Error("Load modified DefaultWeights")
+	}
+
+	if err := os.WriteFile(path, []byte("agent:\n  comand: typo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "comand") {
+		t.Errorf("Load with an unknown key error = %v, want it named", err)
+	}
+}
+
+func TestLoad_AgentRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	if err := os.WriteFile(path, []byte("agent:\n  max_attempts: 6\n  retry_base_delay: 500ms\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	retry, err := cfg.RetryConfig()
+	if err != nil {
+		t.Fatalf("RetryConfig failed: %v", err)
+	}
+	if retry.MaxAttempts != 6 || retry.BaseDelay != 500*time.Millisecond || retry.MaxDelay != agent.DefaultRetryMaxDelay {
+		t.Errorf("RetryConfig = %+v, want the file's attempts and delay over the defaults", retry)
+	}
+	if def, err := Default().RetryConfig(); err != nil || def.MaxAttempts != agent.DefaultMaxAttempts || def.BaseDelay != agent.DefaultRetryBaseDelay {
+		t.Errorf("default RetryConfig = %+v, %v; want the agent defaults", def, err)
+	}
+
+	if err := os.WriteFile(path, []byte("agent:\n  retry_base_delay: soon\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "retry_base_delay") {
+		t.Errorf("Load with an invalid delay error = %v, want it named", err)
+	}
+}
+
+func TestLoad_TechniqueTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	if err := os.WriteFile(path, []byte("inspect:\n  technique_timeout: 10m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if timeout, err := cfg.TechniqueTimeout(); err != nil || timeout != 10*time.Minute {
+		t.Errorf("TechniqueTimeout = %v, %v; want 10m", timeout, err)
+	}
+	if timeout, err := Default().TechniqueTimeout(); err != nil || timeout != 0 {
+		t.Errorf("default TechniqueTimeout = %v, %v; want no limit", timeout, err)
+	}
+
+	if err := os.WriteFile(path, []byte("inspect:\n  technique_timeout: -1s\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "technique_timeout") {
+		t.Errorf("Load with a negative timeout error = %v, want it named", err)
+	}
+}
+
+func TestLoad_EnabledTechniques(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	if err := os.WriteFile(path, []byte("inspect:\n  enabled_techniques: [translation_validation, statement_coverage]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	enabled := inspect.EnableTechniques(inspect.DefaultTechniques(), cfg.Inspect.EnabledTechniques)
+	var names []string
+	for _, tech := range enabled {
+		names = append(names, tech.Name())
+	}
+	if want := []string{inspect.TranslationValidatorName, inspect.CoverageRunnerName}; !slices.Equal(names, want) {
+		t.Errorf("enabled techniques = %v, want %v without mutation testing", names, want)
+	}
+	if len(Default().Inspect.EnabledTechniques) != 0 {
+		t.Errorf("default EnabledTechniques = %v, want every technique enabled", Default().Inspect.EnabledTechniques)
+	}
+
+	if err := os.WriteFile(path, []byte("inspect:\n  enabled_techniques:\n    - mutation\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); !errors.Is(err, inspect.ErrUnknownTechnique) || !strings.Contains(err.Error(), `"mutation"`) {
+		t.Errorf("Load with an unknown technique error = %v, want ErrUnknownTechnique naming it", err)
+	}
+}
+
+func TestLoad_Tiers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	doc := "inspect:\n  tiers:\n    mend: 0.5\n    accept: 0.9\n    accept_with_warning: 0.8\n"
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	scorer := cfg.ScorerConfig()
+	want := []inspect.Tier{{Threshold: 0.9, Action: inspect.ActionAccept}, {Threshold: 0.8, Action: "accept_with_warning"}, {Threshold: 0.5, Action: inspect.ActionMend}}
+	if !slices.Equal(scorer.Tiers, want) {
+		t.Errorf("Tiers = %v, want %v", scorer.Tiers, want)
+	}
+	if _, err := inspect.NewScorer(scorer); err != nil {
+		t.Errorf("NewScorer rejected the file's tiers: %v", err)
+	}
+	if tiers := Default().ScorerConfig().Tiers; len(tiers) != 0 {
+		t.Errorf("default Tiers = %v, want the accept and mend thresholds", tiers)
+	}
+}
+
+func TestLoad_TestCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cobbler.yaml")
+	if err := os.WriteFile(path, []byte("inspect:\n  test_command: [go, test, -tags, integration]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := []string{"go", "test", "-tags", "integration"}; !slices.Equal(cfg.Inspect.TestCommand, want) {
+		t.Errorf("TestCommand = %q, want %q", cfg.Inspect.TestCommand, want)
+	}
+	if len(Default().Inspect.TestCommand) != 0 {
+		t.Errorf("default TestCommand = %q, want go test", Default().Inspect.TestCommand)
+	}
+}
@@ -0,0 +1,258 @@
+// Package context cobbles together the repository files an agent needs for
+// a crumb.
+// Implements: prd007-context-assembly; prd002-stitch R3 (context assembler).
+//
+// Assemble collects the files a crumb references, plus the other files in
+// their directories, and trims them to a byte budget by relevance: files the
+// crumb names come first, then recently modified files, then the rest. A
+// file that does not fit whole is clipped with a truncation marker; files
+// after it are omitted and listed, so the least relevant are dropped first. Importers alias the package
+// (for example cobble) to keep the standard library context package.
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// DefaultBudget is the default size of an assembled context in bytes,
+// roughly 16k tokens at BytesPerToken.
+const DefaultBudget = 64 << 10
+
+// BytesPerToken approximates how many bytes of source make one token.
+const BytesPerToken = 4
+
+// Relevance tiers, most relevant first.
+const (
+	// Referenced files are named by the crumb.
+	Referenced = iota
+	// Recent files share a directory with a referenced file and were
+	// modified recently.
+	Recent
+	// Neighbor files share a directory with a referenced file.
+	Neighbor
+)
+
+// pathPattern matches path-like words with an extension, such as
+// internal/crumbs/claim.go or README.md.
+var pathPattern = regexp.MustCompile(`[A-Za-z0-9_.\-/]+\.[A-Za-z0-9]+`)
+
+// Config controls context assembly.
+type Config struct {
+	// Budget is the maximum total size of file contents in bytes. Values
+	// below 1 use DefaultBudget.
+	Budget int
+	// Exclude lists root-relative paths to leave out, such as a file the
+	// prompt already shows in full.
+	Exclude []string
+	// Recent lists root-relative paths of recently modified files, most
+	// recent first, such as the files touched by the last few git commits.
+	// Candidates among them rank Recent in this order; the others keep
+	// their Neighbor rank.
+	Recent []string
+}
+
+// File is one file in an assembled context.
+type File struct {
+	// Path is relative to the assembly root, with forward slashes.
+	Path string
+	// Content is the included text; it is a prefix of the file when
+	// Truncated is set.
+	Content string
+	// Size is the full size of the file in bytes.
+	Size int
+	// Truncated reports that Content was clipped to fit the budget.
+	Truncated bool
+	// Relevance is the file's tier: Referenced, Recent, or Neighbor.
+	Relevance int
+}
+
+// Context is an assembled set of files.
+type Context struct {
+	Files []File
+	// Omitted lists candidate files left out because the budget ran out.
+	Omitted []string
+}
+
+// Assemble gathers the context for crumb from the files under root. The
+// crumb's target_file and every existing path mentioned in its name or
+// description are Referenced; the remaining regular files in their
+// directories are Neighbors, except those listed in config.Recent. Paths
+// that leave root are ignored.
+func Assemble(root string, crumb *types.Crumb, config Config) (Context, error) {
+	budget := config.Budget
+	if budget < 1 {
+		budget = DefaultBudget
+	}
+	candidates, err := candidates(root, crumb)
+	if err != nil {
+		return Context{}, err
+	}
+	candidates = slices.DeleteFunc(candidates, func(f File) bool {
+		return slices.ContainsFunc(config.Exclude, func(p string) bool {
+			return filepath.ToSlash(filepath.Clean(p)) == f.Path
+		})
+	})
+	rank(candidates, config.Recent)
+
+	var out Context
+	for i, c := range candidates {
+		if budget == 0 {
+			for _, rest := range candidates[i:] {
+				out.Omitted = append(out.Omitted, rest.Path)
+			}
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(c.Path)))
+		if err != nil {
+			return Context{}, fmt.Errorf("reading %s: %w", c.Path, err)
+		}
+		c.Size = len(data)
+		c.Content = string(data)
+		if len(data) > budget {
+			c.Content = clip(c.Content, budget)
+			c.Truncated = true
+		}
+		budget -= len(c.Content)
+		if c.Truncated {
+			budget = 0
+		}
+		out.Files = append(out.Files, c)
+	}
+	return out, nil
+}
+
+// candidates lists the files to consider, most relevant first.
+func candidates(root string, crumb *types.Crumb) ([]File, error) {
+	var referenced []string
+	add := func(p string) {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if !filepath.IsLocal(p) || slices.Contains(referenced, p) {
+			return
+		}
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(p)))
+		if err == nil && info.Mode().IsRegular() {
+			referenced = append(referenced, p)
+		}
+	}
+	if target, ok := crumb.Properties[crumbs.PropTargetFile].(string); ok {
+		add(target)
+	}
+	description, _ := crumb.Properties[crumbs.PropDescription].(string)
+	for _, text := range []string{crumb.Name, description} {
+		for _, match := range pathPattern.FindAllString(text, -1) {
+			add(strings.TrimRight(match, "."))
+		}
+	}
+
+	files := make([]File, 0, len(referenced))
+	for _, p := range referenced {
+		files = append(files, File{Path: p, Relevance: Referenced})
+	}
+	var dirs []string
+	for _, p := range referenced {
+		if dir := filepath.Dir(filepath.FromSlash(p)); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		neighbors, err := neighbors(root, dir, referenced)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range neighbors {
+			files = append(files, File{Path: p, Relevance: Neighbor})
+		}
+	}
+	return files, nil
+}
+
+// rank moves the neighbors listed in recent ahead of the other neighbors,
+// most recent first, and marks them Recent. The sort is stable, so
+// referenced files and the remaining neighbors keep their order.
+func rank(files []File, recent []string) {
+	order := make(map[string]int, len(recent))
+	for i, p := range recent {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if _, ok := order[p]; !ok {
+			order[p] = i
+		}
+	}
+	for i, f := range files {
+		if _, ok := order[f.Path]; ok && f.Relevance == Neighbor {
+			files[i].Relevance = Recent
+		}
+	}
+	slices.SortStableFunc(files, func(a, b File) int {
+		if a.Relevance != b.Relevance {
+			return a.Relevance - b.Relevance
+		}
+		if a.Relevance == Recent {
+			return order[a.Path] - order[b.Path]
+		}
+		return 0
+	})
+}
+
+// neighbors lists the regular files in dir, sorted, skipping those in exclude.
+func neighbors(root, dir string, exclude []string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", dir, err)
+	}
+	var out []string
+	for _, e := range entries {
+		p := filepath.ToSlash(filepath.Join(dir, e.Name()))
+		if !e.Type().IsRegular() || slices.Contains(exclude, p) {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// clip shortens s to at most n bytes, cutting at the last line break when
+// there is one so no line is split.
+func clip(s string, n int) string {
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+// String renders the context as a prompt section: each file under a heading
+// in a fenced block, with a marker after clipped files and a list of
+// omitted files at the end.
+func (c Context) String() string {
+	var b strings.Builder
+	for _, f := range c.Files {
+		fmt.Fprintf(&b, "### %s\n```\n%s", f.Path, f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+		if f.Truncated {
+			fmt.Fprintf(&b, "[truncated: showing %d of %d bytes]\n", len(f.Content), f.Size)
+		}
+		b.WriteString("\n")
+	}
+	if len(c.Omitted) > 0 {
+		fmt.Fprintf(&b, "Omitted to fit the context budget: %s\n", strings.Join(c.Omitted, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// writeTree creates files under a temp root and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func crumbMentioning(description string) *types.Crumb {
+	return &types.Crumb{
+		Name:       "Fix the parser",
+		Properties: map[string]any{crumbs.PropDescription: description},
+	}
+}
+
+func paths(files []File) []string {
+	var out []string
+	for _, f := range files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestAssemble(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"parser/parse.go":  "package parser\n",
+		"parser/lex.go":    "package parser\n\n// lexer\n",
+		"parser/README.md": "# Parser\n",
+		"other/other.go":   "package other\n",
+	})
+	crumb := crumbMentioning("Handle EOF in parser/parse.go. See ../secret.txt and missing.go.")
+
+	got, err := Assemble(root, crumb, Config{})
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	want := []string{"parser/parse.go", "parser/README.md", "parser/lex.go"}
+	if strings.Join(paths(got.Files), ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want referenced first, then sorted neighbors %v", paths(got.Files), want)
+	}
+	if got.Files[0].Relevance != Referenced || got.Files[1].Relevance != Neighbor {
+		t.Errorf("relevance = %d, %d", got.Files[0].Relevance, got.Files[1].Relevance)
+	}
+
+	excluded, err := Assemble(root, crumb, Config{Exclude: []string{"./parser/README.md"}})
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	if strings.Contains(strings.Join(paths(excluded.Files), ","), "README") {
+		t.Errorf("files = %v, want README.md excluded", paths(excluded.Files))
+	}
+}
+
+func TestAssemble_Budget(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a/first.go":  strings.Repeat("// line\n", 4), // 32 bytes
+		"a/second.go": strings.Repeat("// line\n", 4),
+		"a/third.go":  "package a\n",
+	})
+	crumb := crumbMentioning("Touch a/first.go and a/second.go.")
+
+	got, err := Assemble(root, crumb, Config{Budget: 50})
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	if len(got.Files) != 2 || got.Files[0].Truncated {
+		t.Fatalf("files = %+v, want first whole and second clipped", got.Files)
+	}
+	second := got.Files[1]
+	if !second.Truncated || second.Content != strings.Repeat("// line\n", 2) || second.Size != 32 {
+		t.Errorf("second = %+v, want clipped at a line break to fit the remaining 18 bytes", second)
+	}
+	if len(got.Omitted) != 1 || got.Omitted[0] != "a/third.go" {
+		t.Errorf("Omitted = %v, want [a/third.go]", got.Omitted)
+	}
+
+	blob := got.String()
+	for _, want := range []string{"### a/first.go\n```\n", "[truncated: showing 16 of 32 bytes]", "Omitted to fit the context budget: a/third.go"} {
+		if !strings.Contains(blob, want) {
+			t.Errorf("String() does not contain %q:\n%s", want, blob)
+		}
+	}
+}
+
+func TestAssemble_RecentRanking(t *testing.T) {
+	line := strings.Repeat("x", 19) + "\n" // 20 bytes
+	root := writeTree(t, map[string]string{
+		"a/target.go": line,
+		"a/b.go":      line,
+		"a/c.go":      line,
+		"a/d.go":      line,
+		"a/e.go":      line,
+	})
+	crumb := crumbMentioning("Change a/target.go.")
+	recent := []string{"a/e.go", "other/x.go", "./a/c.go", "a/target.go", "a/e.go"}
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantFiles   []string
+		wantOmitted []string
+	}{
+		{"everything fits", 100, []string{"a/target.go", "a/e.go", "a/c.go", "a/b.go", "a/d.go"}, nil},
+		{"drops plain neighbors first", 60, []string{"a/target.go", "a/e.go", "a/c.go"}, []string{"a/b.go", "a/d.go"}},
+		{"then older recent files", 40, []string{"a/target.go", "a/e.go"}, []string{"a/c.go", "a/b.go", "a/d.go"}},
+		{"referenced survives", 20, []string{"a/target.go"}, []string{"a/e.go", "a/c.go", "a/b.go", "a/d.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Assemble(root, crumb, Config{Budget: tt.budget, Recent: recent})
+			if err != nil {
+				t.Fatalf("Assemble failed: %v", err)
+			}
+			if strings.Join(paths(got.Files), ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("files = %v, want %v", paths(got.Files), tt.wantFiles)
+			}
+			if strings.Join(got.Omitted, ",") != strings.Join(tt.wantOmitted, ",") {
+				t.Errorf("Omitted = %v, want %v", got.Omitted, tt.wantOmitted)
+			}
+		})
+	}
+
+	got, err := Assemble(root, crumb, Config{Recent: recent})
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	want := []int{Referenced, Recent, Recent, Neighbor, Neighbor}
+	for i, f := range got.Files {
+		if f.Relevance != want[i] {
+			t.Errorf("%s relevance = %d, want %d", f.Path, f.Relevance, want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package crumbs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// insertCrumb creates a crumb row in the backend's crumbs table.
+const insertCrumb = `INSERT INTO crumbs (crumb_id, name, state, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+
+// NewCrumbID returns a new crumb ID in the backend's format, a UUIDv7, so
+// IDs generated ahead of a SetCrumbs batch sort by creation time as the
+// backend's own do.
+func NewCrumbID() (string, error) {
+	u, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("%w: generating crumb ID: %v", ErrCrumbSet, err)
+	}
+	return u.String(), nil
+}
+
+// SetCrumbs creates crumbs in order and returns their IDs in the same order.
+// A crumb with an empty CrumbID gets an ID from NewCrumbID. Every crumb
+// needs a name and a state. The batch is written in one transaction that
+// holds the database write lock from the start: either every crumb is
+// created or, on any error, none is. A batch naming an ID that already
+// exists, or naming one ID twice, is rejected with ErrCrumbSet; use
+// SetCrumb to update a crumb.
+//
+// The crumbs are written to the backend's crumbs table directly, as claims
+// are, because the crumbs Table API owns its own connection and cannot join
+// the transaction. Their properties are kept only in cobbler's property
+// table.
+func (c *Cupboard) SetCrumbs(crumbs []*types.Crumb) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db == nil {
+		return nil, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, backendError(ErrCrumbSet, fmt.Errorf("creating crumbs: %w", err))
+	}
+	// Best-effort rollback once committed or on an earlier error.
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	ids := make([]string, 0, len(crumbs))
+	seen := make(map[string]bool, len(crumbs))
+	for i, crumb := range crumbs {
+		id, err := insertBatchCrumb(tx, crumb, now, seen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: crumb %d (%s): %w", ErrCrumbSet, i, crumb.Name, err)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, backendError(ErrCrumbSet, fmt.Errorf("creating crumbs: %w", err))
+	}
+	return ids, nil
+}
+
+// insertBatchCrumb writes one crumb of a SetCrumbs batch in tx and returns
+// its ID. seen holds the IDs the batch has already written.
+func insertBatchCrumb(tx *sql.Tx, crumb *types.Crumb, now string, seen map[string]bool) (string, error) {
+	if crumb.Name == "" {
+		return "", errors.New("name required")
+	}
+	if crumb.State == "" {
+		return "", errors.New("state required")
+	}
+	id := crumb.CrumbID
+	if id == "" {
+		var err error
+		if id, err = NewCrumbID(); err != nil {
+			return "", err
+		}
+	}
+	if seen[id] {
+		return "", fmt.Errorf("%s is named twice in the batch", id)
+	}
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM crumbs WHERE crumb_id = ?`, id).Scan(&exists); err != nil {
+		return "", backendError(ErrCrumbSet, err)
+	}
+	if exists > 0 {
+		return "", fmt.Errorf("%s already exists; SetCrumbs only creates crumbs", id)
+	}
+	if _, err := tx.Exec(insertCrumb, id, crumb.Name, string(crumb.State), now, now); err != nil {
+		return "", backendError(ErrCrumbSet, err)
+	}
+	return id, storeProperties(tx, id, crumb.Properties)
+}
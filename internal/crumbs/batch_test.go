@@ -0,0 +1,176 @@
+package crumbs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestSetCrumbs(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	batch := []*types.Crumb{
+		{Name: "First", State: types.StateReady, Properties: map[string]any{"priority": 1}},
+		{Name: "Second", State: types.StateReady},
+		{Name: "Third", State: types.StateDraft},
+	}
+	ids, err := cupboard.SetCrumbs(batch)
+	if err != nil {
+		t.Fatalf("SetCrumbs failed: %v", err)
+	}
+	if len(ids) != len(batch) {
+		t.Fatalf("SetCrumbs returned %d IDs, want %d", len(ids), len(batch))
+	}
+	for i, id := range ids {
+		crumb, err := cupboard.GetCrumb(id)
+		if err != nil {
+			t.Fatalf("GetCrumb(%s) failed: %v", id, err)
+		}
+		if crumb.Name != batch[i].Name {
+			t.Errorf("ids[%d] names %q, want %q", i, crumb.Name, batch[i].Name)
+		}
+	}
+}
+
+func TestSetCrumbs_RollsBackOnFailure(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	batch := []*types.Crumb{
+		{Name: "First", State: types.StateReady},
+		{Name: "Second", State: types.StateReady},
+		{State: types.StateReady}, // no name
+	}
+	ids, err := cupboard.SetCrumbs(batch)
+	if !errors.Is(err, ErrCrumbSet) {
+		t.Fatalf("SetCrumbs error = %v, want ErrCrumbSet", err)
+	}
+	if ids != nil {
+		t.Errorf("SetCrumbs returned IDs %v on failure", ids)
+	}
+
+	n, err := cupboard.CountCrumbs(nil)
+	if err != nil {
+		t.Fatalf("CountCrumbs failed: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("%d crumbs persisted after a failed batch, want 0", n)
+	}
+}
+
+func TestSetCrumbs_RejectsExistingIDs(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	existing, err := cupboard.SetCrumb("", &types.Crumb{Name: "Existing", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		batch []*types.Crumb
+	}{
+		{"existing id", []*types.Crumb{
+			{Name: "New", State: types.StateReady},
+			{CrumbID: existing, Name: "Overwrite", State: types.StateDraft},
+		}},
+		{"repeated id", []*types.Crumb{
+			{CrumbID: "twice", Name: "First", State: types.StateReady},
+			{CrumbID: "twice", Name: "Second", State: types.StateReady},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cupboard.SetCrumbs(tt.batch); !errors.Is(err, ErrCrumbSet) {
+				t.Fatalf("SetCrumbs error = %v, want ErrCrumbSet", err)
+			}
+			n, err := cupboard.CountCrumbs(nil)
+			if err != nil {
+				t.Fatalf("CountCrumbs failed: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("%d crumbs after a rejected batch, want only the existing one", n)
+			}
+			crumb, err := cupboard.GetCrumb(existing)
+			if err != nil {
+				t.Fatalf("existing crumb lost: %v", err)
+			}
+			if crumb.Name != "Existing" || crumb.State != types.StateReady {
+				t.Errorf("existing crumb = %s %s, want it unchanged", crumb.Name, crumb.State)
+			}
+		})
+	}
+}
+
+func TestSetCrumbs_RollsBackProperties(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	batch := []*types.Crumb{
+		{CrumbID: "first", Name: "First", State: types.StateReady, Properties: map[string]any{PropPriority: 1}},
+		{Name: "Unencodable", State: types.StateReady, Properties: map[string]any{"bad": func() {}}},
+	}
+	if _, err := cupboard.SetCrumbs(batch); !errors.Is(err, ErrCrumbSet) {
+		t.Fatalf("SetCrumbs error = %v, want ErrCrumbSet", err)
+	}
+	if n, err := cupboard.CountCrumbs(nil); err != nil || n != 0 {
+		t.Errorf("CountCrumbs = %d, %v; want 0 after a failed batch", n, err)
+	}
+	if props, err := cupboard.loadProperties("first"); err != nil || len(props) != 0 {
+		t.Errorf("properties of the rolled back crumb = %v, %v; want none", props, err)
+	}
+}
+
+func TestSetCrumbs_ConcurrentSameID(t *testing.T) {
+	dataDir := tempDir(t)
+	// Two cupboards on one database stand in for two processes.
+	var cupboards []*Cupboard
+	for i := 0; i < 2; i++ {
+		cupboard, err := NewCupboard(dataDir)
+		if err != nil {
+			t.Fatalf("NewCupboard failed: %v", err)
+		}
+		defer cupboard.Close()
+		cupboards = append(cupboards, cupboard)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(cupboards))
+	for i, cupboard := range cupboards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = cupboard.SetCrumbs([]*types.Crumb{{CrumbID: "shared", Name: fmt.Sprintf("Writer %d", i), State: types.StateReady}})
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("SetCrumbs errors = %v, want exactly one batch to create the crumb", errs)
+	}
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrCrumbSet) {
+			t.Errorf("losing SetCrumbs error = %v, want ErrCrumbSet", err)
+		}
+	}
+}
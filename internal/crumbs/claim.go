@@ -0,0 +1,121 @@
+package crumbs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// ErrNoReadyCrumb reports that ClaimCrumb found no unblocked crumb in StateReady.
+var ErrNoReadyCrumb = fmt.Errorf("cobbler: no ready crumb")
+
+// claimNextReady moves the first ready, unblocked crumb in readyOrder to
+// taken and returns its ID. The %s restricts the crumbs further, as
+// workTypeFilter does.
+var claimNextReady = `UPDATE crumbs SET state = ?, updated_at = ?
+WHERE crumb_id = (
+	SELECT crumb_id FROM crumbs WHERE state = ? AND ` + unblocked + `%s
+	` + readyOrder + ` LIMIT 1
+) AND state = ?
+RETURNING crumb_id`
+
+// claimByID moves one ready, unblocked crumb to taken and returns its ID.
+// The %s restricts the crumb further, as workTypeFilter does.
+const claimByID = `UPDATE crumbs SET state = ?, updated_at = ?
+WHERE crumb_id = ? AND state = ? AND ` + unblocked + `%s
+RETURNING crumb_id`
+
+// workTypeFilter returns the condition, and its arguments, that restricts a
+// claim to crumbs whose PropWorkType is one of workTypes. No work types
+// restrict nothing.
+func workTypeFilter(workTypes []string) (string, []any) {
+	if len(workTypes) == 0 {
+		return "", nil
+	}
+	args := []any{PropWorkType}
+	for _, wt := range workTypes {
+		args = append(args, wt)
+	}
+	return " AND " + fmt.Sprintf(propertyMatch, "IN ("+placeholders(len(workTypes))+")"), args
+}
+
+// ClaimCrumb atomically takes the ready crumb whose blockers are all done
+// that ReadyCrumbs lists first, the oldest of those with the highest
+// PropPriority: it moves the crumb to StateTaken and returns it.
+// With workTypes, only crumbs whose PropWorkType is one of them are
+// considered. Returns ErrNoReadyCrumb when no such crumb exists.
+// Concurrent callers, including other processes, each claim a different
+// crumb.
+func (c *Cupboard) ClaimCrumb(workTypes ...string) (*types.Crumb, error) {
+	filter, filterArgs := workTypeFilter(workTypes)
+	args := append(append([]any{string(types.StateReady)}, filterArgs...), string(types.StateReady))
+	return c.claim(fmt.Sprintf(claimNextReady, filter), args...)
+}
+
+// ClaimCrumbByID atomically takes the crumb with the given ID, which must be
+// ready with all blockers done and, with workTypes, have a PropWorkType
+// that is one of them. Returns ErrNoReadyCrumb, naming the ID, when the
+// crumb does not exist, is not ready, is blocked, or has another work type;
+// when it does not exist the error also wraps a *CrumbNotFoundError.
+func (c *Cupboard) ClaimCrumbByID(id string, workTypes ...string) (*types.Crumb, error) {
+	filter, filterArgs := workTypeFilter(workTypes)
+	args := append([]any{id, string(types.StateReady)}, filterArgs...)
+	crumb, err := c.claim(fmt.Sprintf(claimByID, filter), args...)
+	if errors.Is(err, ErrNoReadyCrumb) {
+		if !c.crumbExists(id) {
+			return nil, fmt.Errorf("%w: %w", ErrNoReadyCrumb, &CrumbNotFoundError{ID: id})
+		}
+		if len(workTypes) > 0 {
+			return nil, fmt.Errorf("%w: %s is not ready, is blocked, or is not %s work", ErrNoReadyCrumb, id, strings.Join(workTypes, " or "))
+		}
+		return nil, fmt.Errorf("%w: %s is not ready or is blocked", ErrNoReadyCrumb, id)
+	}
+	return crumb, err
+}
+
+// claim runs a claim statement that takes the new state and update time
+// followed by args, and returns the claimed crumb.
+func (c *Cupboard) claim(query string, args ...any) (*types.Crumb, error) {
+	id, err := c.claimID(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetCrumb(id)
+}
+
+// claimID runs a claim statement, as claim does, and returns the ID of the
+// claimed crumb. It holds mu exclusively so claims in this process do not
+// contend for the database write lock.
+func (c *Cupboard) claimID(query string, args ...any) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db == nil {
+		return "", fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+
+	// The transaction holds the write lock from its first statement, so no
+	// other claim can select the same crumb before this one commits.
+	tx, err := c.db.Begin()
+	if err != nil {
+		return "", backendError(ErrCrumbSet, fmt.Errorf("claiming crumb: %w", err))
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	var id string
+	err = tx.QueryRow(query, append([]any{string(types.StateTaken), now}, args...)...).Scan(&id)
+	if err != nil {
+		// Best-effort rollback; nothing was changed.
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoReadyCrumb
+		}
+		return "", backendError(ErrCrumbSet, fmt.Errorf("claiming crumb: %w", err))
+	}
+	if err := tx.Commit(); err != nil {
+		return "", backendError(ErrCrumbSet, fmt.Errorf("claiming crumb: %w", err))
+	}
+	return id, nil
+}
@@ -0,0 +1,250 @@
+package crumbs
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestClaimCrumb_Oldest(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Taken crumb", State: types.StateTaken}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	first, err := cupboard.SetCrumb("", &types.Crumb{Name: "First ready", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Second ready", State: types.StateReady}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	claimed, err := cupboard.ClaimCrumb()
+	if err != nil {
+		t.Fatalf("ClaimCrumb failed: %v", err)
+	}
+	if claimed.CrumbID != first || claimed.State != types.StateTaken {
+		t.Errorf("claimed %s in state %s, want %s taken", claimed.CrumbID, claimed.State, first)
+	}
+	stored, err := cupboard.GetCrumb(first)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if stored.State != types.StateTaken {
+		t.Errorf("stored State = %s, want %s", stored.State, types.StateTaken)
+	}
+}
+
+func TestClaimCrumb_Priority(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	// Created oldest first; a priority that is not a number counts as the
+	// default.
+	priorities := []struct {
+		name     string
+		priority any
+	}{
+		{"low, oldest", MinPriority},
+		{"unprioritized", nil},
+		{"not a number", "urgent"},
+		{"high, newest", MaxPriority},
+	}
+	ids := map[string]string{}
+	for _, p := range priorities {
+		crumb := &types.Crumb{Name: p.name, State: types.StateReady, Properties: map[string]any{}}
+		if p.priority != nil {
+			crumb.Properties[PropPriority] = p.priority
+		}
+		id, err := cupboard.SetCrumb("", crumb)
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		ids[p.name] = id
+	}
+	want := []string{ids["high, newest"], ids["unprioritized"], ids["not a number"], ids["low, oldest"]}
+
+	ready, err := cupboard.ReadyCrumbs()
+	if err != nil {
+		t.Fatalf("ReadyCrumbs failed: %v", err)
+	}
+	if got := crumbIDs(ready); !slices.Equal(got, want) {
+		t.Errorf("ReadyCrumbs = %v, want %v", got, want)
+	}
+	for _, id := range want {
+		claimed, err := cupboard.ClaimCrumb()
+		if err != nil {
+			t.Fatalf("ClaimCrumb failed: %v", err)
+		}
+		if claimed.CrumbID != id {
+			t.Errorf("claimed %s (%s), want %s", claimed.CrumbID, claimed.Name, id)
+		}
+	}
+}
+
+func TestClaimCrumb_NoneReady(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Done crumb", State: types.StateDone}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if _, err := cupboard.ClaimCrumb(); !errors.Is(err, ErrNoReadyCrumb) {
+		t.Errorf("ClaimCrumb error = %v, want ErrNoReadyCrumb", err)
+	}
+}
+
+func TestClaimCrumb_Concurrent(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	const crumbCount, workers = 20, 8
+	for i := 0; i < crumbCount; i++ {
+		if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Ready crumb", State: types.StateReady}); err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+	}
+
+	var mu sync.Mutex
+	claims := map[string]int{}
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				crumb, err := cupboard.ClaimCrumb()
+				if errors.Is(err, ErrNoReadyCrumb) {
+					return
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+				mu.Lock()
+				claims[crumb.CrumbID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("ClaimCrumb failed: %v", err)
+	}
+
+	if len(claims) != crumbCount {
+		t.Errorf("claimed %d distinct crumbs, want %d", len(claims), crumbCount)
+	}
+	for id, n := range claims {
+		if n != 1 {
+			t.Errorf("crumb %s claimed %d times, want 1", id, n)
+		}
+	}
+}
+
+func TestClaimCrumbByID(t *testing.T) {
+	dataDir := tempDir(t)
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	older, err := cupboard.SetCrumb("", &types.Crumb{Name: "Older", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	target, err := cupboard.SetCrumb("", &types.Crumb{Name: "Target", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	claimed, err := cupboard.ClaimCrumbByID(target)
+	if err != nil {
+		t.Fatalf("ClaimCrumbByID failed: %v", err)
+	}
+	if claimed.CrumbID != target || claimed.State != types.StateTaken {
+		t.Errorf("claimed = %s %s, want %s taken", claimed.CrumbID, claimed.State, target)
+	}
+	if crumb, _ := cupboard.GetCrumb(older); crumb.State != types.StateReady {
+		t.Errorf("older crumb State = %s, want it left ready", crumb.State)
+	}
+
+	for _, id := range []string{target, "missing"} {
+		_, err := cupboard.ClaimCrumbByID(id)
+		if !errors.Is(err, ErrNoReadyCrumb) {
+			t.Errorf("ClaimCrumbByID(%s) error = %v, want ErrNoReadyCrumb", id, err)
+		}
+		var notFound *CrumbNotFoundError
+		if found := errors.As(err, &notFound); found != (id == "missing") {
+			t.Errorf("ClaimCrumbByID(%s) error = %v, want a CrumbNotFoundError only for the missing crumb", id, err)
+		}
+	}
+}
+
+func TestClaimCrumb_WorkType(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	ids := map[string]string{}
+	for _, workType := range []string{"", "code", "docs"} {
+		crumb := &types.Crumb{Name: "Crumb " + workType, State: types.StateReady}
+		if workType != "" {
+			crumb.Properties = map[string]any{PropWorkType: workType}
+		}
+		id, err := cupboard.SetCrumb("", crumb)
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		ids[workType] = id
+	}
+
+	if _, err := cupboard.ClaimCrumbByID(ids["code"], "docs"); !errors.Is(err, ErrNoReadyCrumb) {
+		t.Errorf("ClaimCrumbByID of a code crumb as docs error = %v, want ErrNoReadyCrumb", err)
+	}
+	claimed, err := cupboard.ClaimCrumb("docs")
+	if err != nil {
+		t.Fatalf("ClaimCrumb(docs) failed: %v", err)
+	}
+	if claimed.CrumbID != ids["docs"] {
+		t.Errorf("ClaimCrumb(docs) = %s, want the docs crumb %s", claimed.CrumbID, ids["docs"])
+	}
+	if _, err := cupboard.ClaimCrumb("docs"); !errors.Is(err, ErrNoReadyCrumb) {
+		t.Errorf("second ClaimCrumb(docs) error = %v, want ErrNoReadyCrumb", err)
+	}
+	if claimed, err = cupboard.ClaimCrumbByID(ids["code"], "code"); err != nil {
+		t.Fatalf("ClaimCrumbByID(code) failed: %v", err)
+	}
+	// Without work types, any ready crumb is claimed, typed or not.
+	if claimed, err = cupboard.ClaimCrumb(); err != nil || claimed.CrumbID != ids[""] {
+		t.Errorf("ClaimCrumb() = %v, %v; want the untyped crumb", claimed, err)
+	}
+}
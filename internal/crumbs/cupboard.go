@@ -9,16 +9,42 @@
 package crumbs
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/petar-djukic/crumbs/pkg/sqlite"
 	"github.com/petar-djukic/crumbs/pkg/types"
+	_ "modernc.org/sqlite" // registers the sqlite driver for direct queries
 )
 
 // Default data directory for crumbs storage.
 const DefaultDataDir = ".crumbs"
 
-// Error wrapping for cobbler context.
+// SQLite database file and driver used for queries the Table API does not expose.
+// dbOptions makes writers wait for a locked database instead of failing, so
+// concurrent claims from several connections queue up, and starts
+// transactions with BEGIN IMMEDIATE so they take the write lock up front.
+// The journal mode is left to the backend.
+const (
+	dbFileName = "cupboard.db"
+	sqlDriver  = "sqlite"
+	dbOptions  = "?_pragma=busy_timeout(5000)&_txlock=immediate"
+)
+
+// crumbsColumns lists the columns of the backend's crumbs table that cobbler
+// queries directly. NewCupboard checks for them, so a backend whose schema
+// changed fails to open instead of failing queries later.
+var crumbsColumns = []string{"crumb_id", "name", "state", "created_at", "updated_at"}
+
+// Error wrapping for cobbler context. Errors returned by Cupboard methods
+// match one of these with errors.Is; errors.As extracts a
+// *CrumbNotFoundError or *BackendError with the details.
 var (
 	ErrCupboardInit   = fmt.Errorf("cobbler: cupboard initialization failed")
 	ErrCupboardAttach = fmt.Errorf("cobbler: cupboard attach failed")
@@ -28,11 +54,62 @@ var (
 	ErrCrumbFetch     = fmt.Errorf("cobbler: crumb fetch failed")
 )
 
+// CrumbNotFoundError reports that the cupboard has no crumb with ID. It
+// matches ErrCrumbGet.
+type CrumbNotFoundError struct {
+	ID string
+	// Err is the backend error that reported the miss, if any.
+	Err error
+}
+
+func (e *CrumbNotFoundError) Error() string {
+	return fmt.Sprintf("cobbler: crumb %s not found", e.ID)
+}
+
+// Unwrap returns ErrCrumbGet and the backend error.
+func (e *CrumbNotFoundError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrCrumbGet}
+	}
+	return []error{ErrCrumbGet, e.Err}
+}
+
+// BackendError reports a failed backend or database operation: the
+// cupboard could not be reached or did not answer, as opposed to a crumb
+// that does not exist.
+type BackendError struct {
+	// Kind is the sentinel error of the operation, such as ErrCrumbSet.
+	Kind error
+	// Err is the backend error.
+	Err error
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns Kind and the backend error.
+func (e *BackendError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// backendError wraps err, a backend error, as a BackendError of kind.
+func backendError(kind, err error) error {
+	return &BackendError{Kind: kind, Err: err}
+}
+
 // Cupboard wraps the crumbs Cupboard interface with typed convenience methods.
 // It initializes an SQLite backend, attaches the cupboard, and provides
 // direct access to crumb operations without re-abstracting the interface.
+// Its methods are safe for concurrent use.
 type Cupboard struct {
+	// mu guards backend and db. The backend is not documented as safe for
+	// concurrent use, so backend calls hold mu exclusively, as do claims,
+	// which must not interleave, and Close. Queries through db hold it
+	// shared. Locked sections never call other locking methods.
+	mu      sync.RWMutex
 	backend types.Cupboard
+	db      *sql.DB
 	dataDir string
 }
 
@@ -53,35 +130,133 @@ func NewCupboard(dataDir string) (*Cupboard, error) {
 	}
 
 	if err := backend.Attach(config); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrCupboardAttach, err)
+		return nil, backendError(ErrCupboardAttach, err)
+	}
+
+	db, err := sql.Open(sqlDriver, filepath.Join(dataDir, dbFileName)+dbOptions)
+	if err == nil {
+		err = initDB(db)
+		if err != nil {
+			_ = db.Close()
+		}
+	}
+	if err != nil {
+		// Best-effort cleanup; the open error is what the caller needs.
+		_ = backend.Detach()
+		return nil, backendError(ErrCupboardInit, err)
 	}
 
 	return &Cupboard{
 		backend: backend,
+		db:      db,
 		dataDir: dataDir,
 	}, nil
 }
 
+// initDB checks the backend's crumbs table and creates cobbler's property
+// table. A new property table is filled from the backend's property tables,
+// where cupboards written before cobbler kept its own hold their properties.
+func initDB(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('crumbs')`)
+	if err != nil {
+		return fmt.Errorf("reading crumbs schema: %w", err)
+	}
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("reading crumbs schema: %w", err)
+		}
+		columns = append(columns, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading crumbs schema: %w", err)
+	}
+	for _, column := range crumbsColumns {
+		if !slices.Contains(columns, column) {
+			return fmt.Errorf("backend crumbs table has no %s column (has %v)", column, columns)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	// Best-effort rollback once committed or on an earlier error.
+	defer tx.Rollback()
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'cobbler_properties'`).Scan(&exists); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(createPropertiesTable); err != nil {
+		return fmt.Errorf("creating property table: %w", err)
+	}
+	if exists == 0 {
+		if err := importBackendProperties(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// importBackendProperties copies the properties in the backend's
+// crumb_properties and properties tables, when both exist, into cobbler's
+// property table.
+func importBackendProperties(tx *sql.Tx) error {
+	var tables int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master
+WHERE type = 'table' AND name IN ('crumb_properties', 'properties')`).Scan(&tables)
+	if err != nil || tables < 2 {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO cobbler_properties (crumb_id, name, value)
+SELECT cp.crumb_id, p.name, cp.value
+FROM crumb_properties cp
+JOIN properties p ON p.property_id = cp.property_id`)
+	if err != nil {
+		return fmt.Errorf("importing backend properties: %w", err)
+	}
+	return nil
+}
+
 // Close detaches the cupboard and releases all resources.
 // After Close, all operations will fail. Close is idempotent.
 func (c *Cupboard) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.backend == nil {
 		return nil
 	}
-	return c.backend.Detach()
+	// The backend is detached even when the database fails to close, so
+	// the cupboard is never left half open.
+	var dbErr error
+	if c.db != nil {
+		dbErr = c.db.Close()
+		c.db = nil
+	}
+	return errors.Join(dbErr, c.backend.Detach())
 }
 
-// GetCrumb retrieves a crumb by ID from the crumbs table.
-// Returns the typed Crumb or an error if not found or access fails.
+// GetCrumb retrieves a crumb by ID from the crumbs table, with its
+// Properties loaded from cobbler's property table.
+// Returns the typed Crumb, a *CrumbNotFoundError when there is no such
+// crumb, or a *BackendError when access fails.
 func (c *Cupboard) GetCrumb(id string) (*types.Crumb, error) {
-	table, err := c.backend.GetTable(types.CrumbsTable)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrTableAccess, err)
+	var entity any
+	var getErr error
+	if err := c.withCrumbsTable(func(table types.Table) error {
+		entity, getErr = table.Get(id)
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-
-	entity, err := table.Get(id)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrCrumbGet, err)
+	if getErr != nil {
+		if !c.crumbExists(id) {
+			return nil, &CrumbNotFoundError{ID: id, Err: getErr}
+		}
+		return nil, backendError(ErrCrumbGet, getErr)
 	}
 
 	crumb, ok := entity.(*types.Crumb)
@@ -89,56 +264,201 @@ func (c *Cupboard) GetCrumb(id string) (*types.Crumb, error) {
 		return nil, fmt.Errorf("%w: unexpected type %T", ErrCrumbGet, entity)
 	}
 
+	props, err := c.loadProperties(crumb.CrumbID)
+	if err != nil {
+		return nil, err
+	}
+	crumb.Properties = props
 	return crumb, nil
 }
 
-// SetCrumb creates or updates a crumb in the crumbs table.
-// If id is empty, a new UUID v7 is generated.
-// Returns the actual ID (generated or provided) or an error.
-func (c *Cupboard) SetCrumb(id string, crumb *types.Crumb) (string, error) {
+// withCrumbsTable calls fn with the crumbs table while holding mu
+// exclusively. Failing to get the table is returned as ErrTableAccess.
+func (c *Cupboard) withCrumbsTable(fn func(types.Table) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	table, err := c.backend.GetTable(types.CrumbsTable)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTableAccess, err)
+		return backendError(ErrTableAccess, err)
 	}
+	return fn(table)
+}
 
-	actualID, err := table.Set(id, crumb)
-	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrCrumbSet, err)
+// crumbExists reports whether the crumbs table has a row for id. It reports
+// true when the database cannot answer, so a failing backend is not
+// mistaken for a missing crumb.
+func (c *Cupboard) crumbExists(id string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return true
 	}
+	var one int
+	err := c.db.QueryRow(`SELECT 1 FROM crumbs WHERE crumb_id = ?`, id).Scan(&one)
+	return !errors.Is(err, sql.ErrNoRows)
+}
 
+// SetCrumb creates or updates a crumb in the crumbs table and replaces its
+// stored properties with crumb.Properties.
+// If id is empty, a new UUID v7 is generated.
+// Returns the actual ID (generated or provided) or an error.
+func (c *Cupboard) SetCrumb(id string, crumb *types.Crumb) (string, error) {
+	var actualID string
+	err := c.withCrumbsTable(func(table types.Table) error {
+		var err error
+		if actualID, err = table.Set(id, crumb); err != nil {
+			return backendError(ErrCrumbSet, err)
+		}
+		return storeProperties(c.db, actualID, crumb.Properties)
+	})
+	if err != nil {
+		return "", err
+	}
 	return actualID, nil
 }
 
-// FetchCrumbs queries crumbs matching the filter.
-// Filter keys are field names; values are required field values.
+// FetchCrumbs queries crumbs matching the filter, oldest first.
+// Filter keys are field names; values are required field values. A slice
+// value matches any of its elements, so {"State": []types.State{StateReady,
+// StateTaken}} returns ready and taken crumbs. A key of PropertyFilterPrefix
+// and a property name matches the property's value instead: strings match
+// as text and numbers by value, so {"Properties.work_type": "docs"} and
+// {"Properties.priority": 2} select by property. TagsAnyFilter and
+// TagsAllFilter select by tag: {"TagsAll": []string{"backend", "epic-7"}}
+// returns the crumbs tagged with both. Time fields match times stored in
+// UTC to the nanosecond. Any other key is passed to the backend's
+// Table.Fetch, which decides what it matches.
 // An empty filter returns all crumbs.
-// Returns typed Crumb slices or an error.
+// Returns typed Crumb slices, with Properties loaded, or an error.
 func (c *Cupboard) FetchCrumbs(filter map[string]any) ([]*types.Crumb, error) {
-	table, err := c.backend.GetTable(types.CrumbsTable)
+	return c.fetchCrumbs(filter, FetchOptions{})
+}
+
+// selectCrumbsByID reads the crumbs whose IDs are in a JSON array.
+const selectCrumbsByID = `SELECT crumb_id, name, state, created_at, updated_at
+	FROM crumbs WHERE crumb_id IN (SELECT value FROM json_each(?))`
+
+// selectPropertiesByID reads the properties of the crumbs whose IDs are in a
+// JSON array.
+const selectPropertiesByID = `SELECT crumb_id, name, value
+	FROM cobbler_properties WHERE crumb_id IN (SELECT value FROM json_each(?))`
+
+// loadCrumbs runs query, which selects crumb IDs, and loads those crumbs
+// with their properties in the order query returns them. The IDs, crumbs,
+// and properties are read in one transaction, so a crumb deleted meanwhile
+// is either returned whole or not at all.
+func (c *Cupboard) loadCrumbs(query string, args ...any) ([]*types.Crumb, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return nil, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
+	}
+	// Best-effort rollback; the transaction only reads.
+	defer tx.Rollback()
+
+	ids, err := queryIDs(tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return queryCrumbs(tx, ids)
+}
+
+// queryIDs runs query, which selects crumb IDs, on db.
+func queryIDs(db querier, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrTableAccess, err)
+		return nil, backendError(ErrCrumbFetch, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, backendError(ErrCrumbFetch, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
 	}
+	return ids, nil
+}
 
-	entities, err := table.Fetch(filter)
+// queryCrumbs reads the crumbs with the given IDs, in order, and their
+// properties from db with one query each.
+func queryCrumbs(db querier, ids []string) ([]*types.Crumb, error) {
+	list, err := json.Marshal(ids)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrCrumbFetch, err)
 	}
+	byID := make(map[string]*types.Crumb, len(ids))
+	rows, err := db.Query(selectCrumbsByID, string(list))
+	if err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var crumb types.Crumb
+		var state, created, updated string
+		if err := rows.Scan(&crumb.CrumbID, &crumb.Name, &state, &created, &updated); err != nil {
+			return nil, backendError(ErrCrumbFetch, err)
+		}
+		crumb.State = types.State(state)
+		if crumb.CreatedAt, err = time.Parse(time.RFC3339Nano, created); err != nil {
+			return nil, fmt.Errorf("%w: %s created_at: %v", ErrCrumbFetch, crumb.CrumbID, err)
+		}
+		if crumb.UpdatedAt, err = time.Parse(time.RFC3339Nano, updated); err != nil {
+			return nil, fmt.Errorf("%w: %s updated_at: %v", ErrCrumbFetch, crumb.CrumbID, err)
+		}
+		crumb.Properties = map[string]any{}
+		byID[crumb.CrumbID] = &crumb
+	}
+	if err := rows.Err(); err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
+	}
 
-	crumbs := make([]*types.Crumb, 0, len(entities))
-	for _, entity := range entities {
-		crumb, ok := entity.(*types.Crumb)
+	props, err := db.Query(selectPropertiesByID, string(list))
+	if err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
+	}
+	defer props.Close()
+	for props.Next() {
+		var id, name, raw string
+		if err := props.Scan(&id, &name, &raw); err != nil {
+			return nil, backendError(ErrCrumbFetch, err)
+		}
+		crumb, ok := byID[id]
 		if !ok {
-			return nil, fmt.Errorf("%w: unexpected type %T in results", ErrCrumbFetch, entity)
+			continue
+		}
+		if crumb.Properties[name], err = decodePropertyValue(raw); err != nil {
+			return nil, fmt.Errorf("%w: %s property %s: %v", ErrCrumbFetch, id, name, err)
 		}
-		crumbs = append(crumbs, crumb)
+	}
+	if err := props.Err(); err != nil {
+		return nil, backendError(ErrCrumbFetch, err)
 	}
 
+	crumbs := make([]*types.Crumb, 0, len(ids))
+	for _, id := range ids {
+		if crumb, ok := byID[id]; ok {
+			crumbs = append(crumbs, crumb)
+		}
+	}
 	return crumbs, nil
 }
 
 // GetTable provides direct access to a table by name.
-// Use this for operations beyond crumb convenience methods.
+// Use this for operations beyond crumb convenience methods. Calls on the
+// returned table are not serialized with the cupboard's own operations.
 func (c *Cupboard) GetTable(name string) (types.Table, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.backend.GetTable(name)
 }
 
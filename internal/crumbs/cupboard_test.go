@@ -1,8 +1,11 @@
 package crumbs
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/petar-djukic/crumbs/pkg/types"
@@ -152,7 +155,37 @@ func TestGetCrumb_NotFound(t *testing.T) {
 
 	_, err = cupboard.GetCrumb("nonexistent-id")
 	if err == nil {
-		t.Error("GetCrumb with nonexistent ID should return error")
+		t.Fatal("GetCrumb with nonexistent ID should return error")
+	}
+	var notFound *CrumbNotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != "nonexistent-id" || !errors.Is(err, ErrCrumbGet) {
+		t.Errorf("GetCrumb error = %v, want a CrumbNotFoundError for nonexistent-id matching ErrCrumbGet", err)
+	}
+	var backend *BackendError
+	if errors.As(err, &backend) {
+		t.Errorf("GetCrumb error = %v, want no BackendError for a missing crumb", err)
+	}
+}
+
+func TestGetCrumb_BackendError(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Task", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	cupboard.Close()
+
+	_, err = cupboard.GetCrumb(id)
+	var backend *BackendError
+	if !errors.As(err, &backend) || backend.Kind != ErrTableAccess || !errors.Is(err, ErrTableAccess) {
+		t.Errorf("GetCrumb on a closed cupboard error = %v, want a BackendError of ErrTableAccess", err)
+	}
+	var notFound *CrumbNotFoundError
+	if errors.As(err, &notFound) {
+		t.Errorf("GetCrumb on a closed cupboard error = %v, want no CrumbNotFoundError", err)
 	}
 }
 
@@ -275,6 +308,102 @@ func TestClose_OperationsFailAfterClose(t *testing.T) {
 	}
 }
 
+// TestCupboard_ConcurrentAccess hammers one cupboard from several
+// goroutines mixing writes, reads, and claims; run it with -race.
+func TestCupboard_ConcurrentAccess(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	const workers, perWorker = 8, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	var mu sync.Mutex
+	claims := map[string]int{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Task", State: types.StateReady, Properties: map[string]any{PropWorkType: "code"}})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err := cupboard.GetCrumb(id); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := cupboard.FetchCrumbs(map[string]any{"State": types.StateReady}); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := cupboard.CountCrumbs(nil); err != nil {
+					errs <- err
+					return
+				}
+				crumb, err := cupboard.ClaimCrumb()
+				if err != nil {
+					errs <- err
+					return
+				}
+				mu.Lock()
+				claims[crumb.CrumbID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent operation failed: %v", err)
+	}
+
+	if len(claims) != workers*perWorker {
+		t.Errorf("claimed %d distinct crumbs, want %d", len(claims), workers*perWorker)
+	}
+	for id, n := range claims {
+		if n != 1 {
+			t.Errorf("crumb %s claimed %d times, want 1", id, n)
+		}
+	}
+}
+
+// TestCupboard_CloseDuringAccess closes a cupboard while other goroutines
+// use it: operations either complete or fail, without racing Close.
+func TestCupboard_CloseDuringAccess(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Task", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				_, _ = cupboard.GetCrumb(id)
+				_, _ = cupboard.FetchCrumbs(nil)
+				_, _ = cupboard.ClaimCrumb()
+			}
+		}()
+	}
+	if err := cupboard.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	wg.Wait()
+	if _, err := cupboard.FetchCrumbs(nil); err == nil {
+		t.Error("FetchCrumbs after Close succeeded")
+	}
+}
+
 func TestGetTable(t *testing.T) {
 	dataDir := tempDir(t)
 
@@ -311,13 +440,6 @@ func TestGetTable_NotFound(t *testing.T) {
 }
 
 func TestCrumbWithProperties(t *testing.T) {
-	// Note: The crumbs backend currently stores properties in memory during Set
-	// but does not load them back during Get. Properties are stored separately
-	// in the crumb_properties table and require the Properties system to be
-	// used properly (defining Property entities first).
-	//
-	// This test verifies that crumbs with Properties set can be stored and
-	// retrieved, even though the Properties map won't be populated on Get.
 	dataDir := tempDir(t)
 
 	cupboard, err := NewCupboard(dataDir)
@@ -330,7 +452,7 @@ func TestCrumbWithProperties(t *testing.T) {
 		Name:  "Crumb with Properties",
 		State: types.StateReady,
 		Properties: map[string]any{
-			"work_type":   "documentation",
+			"work_type":   "docs",
 			"priority":    1,
 			"description": "Test description",
 		},
@@ -341,7 +463,6 @@ func TestCrumbWithProperties(t *testing.T) {
 		t.Fatalf("SetCrumb failed: %v", err)
 	}
 
-	// Verify crumb can be retrieved (properties not loaded by Get)
 	retrieved, err := cupboard.GetCrumb(id)
 	if err != nil {
 		t.Fatalf("GetCrumb failed: %v", err)
@@ -353,4 +474,70 @@ func TestCrumbWithProperties(t *testing.T) {
 	if retrieved.CrumbID != id {
 		t.Errorf("CrumbID = %q, want %q", retrieved.CrumbID, id)
 	}
+	if !reflect.DeepEqual(retrieved.Properties, crumb.Properties) {
+		t.Errorf("Properties = %v, want %v", retrieved.Properties, crumb.Properties)
+	}
+
+	fetched, err := cupboard.FetchCrumbs(map[string]any{})
+	if err != nil {
+		t.Fatalf("FetchCrumbs failed: %v", err)
+	}
+	if len(fetched) != 1 || !reflect.DeepEqual(fetched[0].Properties, crumb.Properties) {
+		t.Errorf("FetchCrumbs Properties = %+v, want %v", fetched, crumb.Properties)
+	}
+}
+
+func TestFetchCrumbs_SliceFilter(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	for _, c := range []*types.Crumb{
+		{Name: "Alpha", State: types.StateReady},
+		{Name: "Alpha", State: types.StateTaken},
+		{Name: "Alpha", State: types.StateDone},
+		{Name: "Beta", State: types.StateReady},
+	} {
+		if _, err := cupboard.SetCrumb("", c); err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   int
+	}{
+		{"slice only", map[string]any{"State": []types.State{types.StateReady, types.StateTaken}}, 3},
+		{"scalar and slice", map[string]any{"Name": "Alpha", "State": []types.State{types.StateReady, types.StateTaken}}, 2},
+		{"string slice", map[string]any{"Name": []string{"Beta"}}, 1},
+		{"empty slice", map[string]any{"State": []types.State{}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := cupboard.FetchCrumbs(tt.filter)
+			if err != nil {
+				t.Fatalf("FetchCrumbs failed: %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("FetchCrumbs returned %d crumbs, want %d", len(results), tt.want)
+			}
+			for _, c := range results {
+				if name, ok := tt.filter["Name"].(string); ok && c.Name != name {
+					t.Errorf("FetchCrumbs returned crumb with Name = %q, want %q", c.Name, name)
+				}
+				if c.State == types.StateDone {
+					t.Errorf("FetchCrumbs returned a done crumb for %v", tt.filter)
+				}
+			}
+		})
+	}
+
+	if _, err := cupboard.FetchCrumbs(map[string]any{"Colour": "red"}); !errors.Is(err, ErrCrumbFetch) {
+		t.Errorf("FetchCrumbs(unknown field) error = %v, want ErrCrumbFetch", err)
+	}
 }
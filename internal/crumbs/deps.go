@@ -0,0 +1,28 @@
+package crumbs
+
+import "github.com/petar-djukic/crumbs/pkg/types"
+
+// PropBlockedBy holds the IDs of the crumbs that must be done before a crumb
+// can be worked, as a list of strings.
+const PropBlockedBy = "blocked_by"
+
+// unblocked holds for a crumb whose every blocker is done. A blocker that
+// does not exist counts as not done, so a dangling edge keeps the crumb
+// blocked rather than silently releasing it.
+const unblocked = `NOT EXISTS (
+	SELECT 1 FROM cobbler_properties cp
+	JOIN json_each(cp.value) b
+	LEFT JOIN crumbs blocker ON blocker.crumb_id = b.value
+	WHERE cp.crumb_id = crumbs.crumb_id AND cp.name = '` + PropBlockedBy + `'
+	AND (blocker.state IS NULL OR blocker.state <> '` + string(types.StateDone) + `'))`
+
+// selectReadyCrumbs selects ready, unblocked crumbs in claim order.
+var selectReadyCrumbs = `SELECT crumb_id FROM crumbs WHERE state = ? AND ` + unblocked + `
+` + readyOrder
+
+// ReadyCrumbs returns the crumbs in StateReady whose blockers are all in
+// StateDone, in the order ClaimCrumb takes them: highest PropPriority
+// first, then oldest first. Crumbs without blockers are always included.
+func (c *Cupboard) ReadyCrumbs() ([]*types.Crumb, error) {
+	return c.loadCrumbs(selectReadyCrumbs, string(types.StateReady))
+}
@@ -0,0 +1,92 @@
+package crumbs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestClaimCrumb_BlockedChain(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	// C is created first so creation order alone would claim it first.
+	c, err := cupboard.SetCrumb("", &types.Crumb{Name: "C", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	b, err := cupboard.SetCrumb("", &types.Crumb{Name: "B", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	a, err := cupboard.SetCrumb("", &types.Crumb{Name: "A", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	// A -> B -> C: C waits on B, which waits on A.
+	for id, blockers := range map[string][]string{c: {b}, b: {a}} {
+		crumb, err := cupboard.GetCrumb(id)
+		if err != nil {
+			t.Fatalf("GetCrumb failed: %v", err)
+		}
+		crumb.Properties[PropBlockedBy] = blockers
+		if _, err := cupboard.SetCrumb(id, crumb); err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{a, b, c} {
+		ready, err := cupboard.ReadyCrumbs()
+		if err != nil {
+			t.Fatalf("ReadyCrumbs failed: %v", err)
+		}
+		if len(ready) != 1 || ready[0].CrumbID != want {
+			t.Fatalf("ReadyCrumbs = %v, want only %s", crumbIDs(ready), want)
+		}
+
+		claimed, err := cupboard.ClaimCrumb()
+		if err != nil {
+			t.Fatalf("ClaimCrumb failed: %v", err)
+		}
+		if claimed.CrumbID != want {
+			t.Fatalf("ClaimCrumb = %s (%s), want %s", claimed.CrumbID, claimed.Name, want)
+		}
+		if _, err := cupboard.ClaimCrumb(); !errors.Is(err, ErrNoReadyCrumb) {
+			t.Fatalf("ClaimCrumb while %s is taken: error = %v, want ErrNoReadyCrumb", claimed.Name, err)
+		}
+		if err := cupboard.SetCrumbState(claimed.CrumbID, types.StateDone); err != nil {
+			t.Fatalf("SetCrumbState failed: %v", err)
+		}
+	}
+}
+
+func TestReadyCrumbs_MissingBlocker(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	if _, err := cupboard.SetCrumb("", &types.Crumb{
+		Name:       "Dangling",
+		State:      types.StateReady,
+		Properties: map[string]any{PropBlockedBy: []string{"no-such-crumb"}},
+	}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	ready, err := cupboard.ReadyCrumbs()
+	if err != nil {
+		t.Fatalf("ReadyCrumbs failed: %v", err)
+	}
+	if len(ready) != 0 {
+		t.Errorf("ReadyCrumbs = %v, want none while the blocker is missing", crumbIDs(ready))
+	}
+}
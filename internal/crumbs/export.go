@@ -0,0 +1,120 @@
+package crumbs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// exportedCrumb is the JSON form of a crumb written by ExportCrumbs.
+type exportedCrumb struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	State      types.State     `json:"state"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+}
+
+// ExportCrumbs writes every crumb, with its properties, to w as a JSON
+// array, oldest first. Crumbs are encoded one at a time.
+func (c *Cupboard) ExportCrumbs(w io.Writer) error {
+	ids, err := c.selectCrumbIDs(nil, FetchOptions{})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		crumb, err := c.GetCrumb(id)
+		if err != nil {
+			return err
+		}
+		record := exportedCrumb{
+			ID:        crumb.CrumbID,
+			Name:      crumb.Name,
+			State:     crumb.State,
+			CreatedAt: crumb.CreatedAt,
+			UpdatedAt: crumb.UpdatedAt,
+		}
+		if len(crumb.Properties) > 0 {
+			if record.Properties, err = json.Marshal(crumb.Properties); err != nil {
+				return fmt.Errorf("encoding properties of %s: %w", id, err)
+			}
+		}
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("encoding crumb %s: %w", id, err)
+		}
+		sep := ",\n"
+		if i == 0 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, sep+string(encoded)); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
+
+// ImportCrumbs reads crumbs in the ExportCrumbs format from r and upserts
+// each one under its exported ID. Returns the number of crumbs imported;
+// on error, crumbs imported before the failing one remain.
+func (c *Cupboard) ImportCrumbs(r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return 0, fmt.Errorf("%w: import must be a JSON array", ErrCrumbSet)
+	}
+	var n int
+	for dec.More() {
+		var record exportedCrumb
+		if err := dec.Decode(&record); err != nil {
+			return n, fmt.Errorf("%w: decoding crumb %d: %v", ErrCrumbSet, n, err)
+		}
+		if record.ID == "" {
+			return n, fmt.Errorf("%w: crumb %d has no id", ErrCrumbSet, n)
+		}
+		crumb := &types.Crumb{
+			Name:      record.Name,
+			State:     record.State,
+			CreatedAt: record.CreatedAt,
+			UpdatedAt: record.UpdatedAt,
+		}
+		if len(record.Properties) > 0 {
+			props, err := decodeProperties(record.Properties)
+			if err != nil {
+				return n, fmt.Errorf("%w: crumb %s properties: %v", ErrCrumbSet, record.ID, err)
+			}
+			crumb.Properties = props
+		}
+		if _, err := c.SetCrumb(record.ID, crumb); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if _, err := dec.Token(); err != nil {
+		return n, fmt.Errorf("%w: reading end of import: %v", ErrCrumbSet, err)
+	}
+	return n, nil
+}
+
+// decodeProperties decodes a JSON object of property values the way
+// loadProperties does, so integers stay ints.
+func decodeProperties(raw json.RawMessage) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var props map[string]any
+	if err := dec.Decode(&props); err != nil {
+		return nil, err
+	}
+	for k, v := range props {
+		props[k] = normalizeNumbers(v)
+	}
+	return props, nil
+}
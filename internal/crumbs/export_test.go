@@ -0,0 +1,88 @@
+package crumbs
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestExportImportCrumbs_RoundTrip(t *testing.T) {
+	source, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer source.Close()
+
+	originals := []*types.Crumb{
+		{Name: "Ready crumb", State: types.StateReady, Properties: map[string]any{
+			"work_type":   "docs",
+			"priority":    2,
+			"description": "Write the guide",
+			"blocked_by":  []any{"other"},
+			"tags":        []any{"backend", "epic-7"},
+		}},
+		{Name: "Done crumb", State: types.StateDone},
+	}
+	ids, err := source.SetCrumbs(originals)
+	if err != nil {
+		t.Fatalf("SetCrumbs failed: %v", err)
+	}
+
+	var backup bytes.Buffer
+	if err := source.ExportCrumbs(&backup); err != nil {
+		t.Fatalf("ExportCrumbs failed: %v", err)
+	}
+
+	// Import into an empty cupboard, as when restoring on another machine.
+	target, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer target.Close()
+	n, err := target.ImportCrumbs(&backup)
+	if err != nil {
+		t.Fatalf("ImportCrumbs failed: %v", err)
+	}
+	if n != len(originals) {
+		t.Fatalf("ImportCrumbs imported %d crumbs, want %d", n, len(originals))
+	}
+
+	for i, id := range ids {
+		got, err := target.GetCrumb(id)
+		if err != nil {
+			t.Fatalf("GetCrumb(%s) after import failed: %v", id, err)
+		}
+		want := originals[i]
+		if got.Name != want.Name || got.State != want.State {
+			t.Errorf("imported %s = %q/%s, want %q/%s", id, got.Name, got.State, want.Name, want.State)
+		}
+		wantProps := want.Properties
+		if wantProps == nil {
+			wantProps = map[string]any{}
+		}
+		if !reflect.DeepEqual(got.Properties, wantProps) {
+			t.Errorf("imported %s Properties = %v, want %v", id, got.Properties, wantProps)
+		}
+	}
+	if tagged, err := target.FetchCrumbs(map[string]any{TagsAllFilter: []string{"backend", "epic-7"}}); err != nil || len(tagged) != 1 || tagged[0].CrumbID != ids[0] {
+		t.Errorf("FetchCrumbs by tag after import = %v, %v; want %s", crumbIDs(tagged), err, ids[0])
+	}
+}
+
+func TestImportCrumbs_Invalid(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	for _, input := range []string{`{"id": "x"}`, `[{"name": "No ID"}]`, `[{"id": "x", "name": "Bad", "properties": 3}]`} {
+		if _, err := cupboard.ImportCrumbs(strings.NewReader(input)); !errors.Is(err, ErrCrumbSet) {
+			t.Errorf("ImportCrumbs(%s) error = %v, want ErrCrumbSet", input, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package crumbs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cupboard health errors. ErrCupboardCorrupt means the database cannot be
+// trusted and should be restored from an export; ErrCupboardVacuum means
+// space was not reclaimed but the data is unaffected.
+var (
+	ErrCupboardCorrupt = fmt.Errorf("cobbler: cupboard integrity check failed")
+	ErrCupboardVacuum  = fmt.Errorf("cobbler: cupboard vacuum failed")
+)
+
+// integrityOK is the single row PRAGMA integrity_check returns for a sound
+// database.
+const integrityOK = "ok"
+
+// walSuffix names SQLite's write-ahead log next to the database file.
+const walSuffix = "-wal"
+
+// Verify runs SQLite's integrity check on the cupboard database. Every
+// problem it reports is included in the returned error, which wraps
+// ErrCupboardCorrupt.
+func (c *Cupboard) Verify() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	rows, err := c.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%w: %v; restore the cupboard from an export", ErrCupboardCorrupt, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return fmt.Errorf("%w: %v; restore the cupboard from an export", ErrCupboardCorrupt, err)
+		}
+		if problem != integrityOK {
+			problems = append(problems, problem)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v; restore the cupboard from an export", ErrCupboardCorrupt, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s; restore the cupboard from an export", ErrCupboardCorrupt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Vacuum rebuilds the cupboard database to reclaim the space of deleted
+// rows, then checkpoints the write-ahead log into it, when the backend uses
+// one, so the reclaimed space leaves the disk. Returns the number of bytes reclaimed, which is negative
+// if the files grew.
+func (c *Cupboard) Vacuum() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db == nil {
+		return 0, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	before, err := c.diskSize()
+	if err != nil {
+		return 0, backendError(ErrCupboardVacuum, err)
+	}
+	if _, err := c.db.Exec("VACUUM"); err != nil {
+		return 0, backendError(ErrCupboardVacuum, err)
+	}
+	if _, err := c.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return 0, fmt.Errorf("%w: checkpointing: %v", ErrCupboardVacuum, err)
+	}
+	after, err := c.diskSize()
+	if err != nil {
+		return 0, backendError(ErrCupboardVacuum, err)
+	}
+	return before - after, nil
+}
+
+// diskSize returns the combined size of the database file and its
+// write-ahead log.
+func (c *Cupboard) diskSize() (int64, error) {
+	path := filepath.Join(c.dataDir, dbFileName)
+	var size int64
+	for _, file := range []string{path, path + walSuffix} {
+		info, err := os.Stat(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		size += info.Size()
+	}
+	return size, nil
+}
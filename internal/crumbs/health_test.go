@@ -0,0 +1,90 @@
+package crumbs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestCupboard_VerifyAndVacuum(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Healthy crumb", State: types.StateReady}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if err := cupboard.Verify(); err != nil {
+		t.Fatalf("Verify on a healthy cupboard: %v", err)
+	}
+
+	// Fill and drop a scratch table so there is free space to reclaim.
+	for _, stmt := range []string{
+		"CREATE TABLE scratch (data BLOB)",
+		"INSERT INTO scratch SELECT randomblob(4096) FROM (WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 256) SELECT i FROM n)",
+		"DROP TABLE scratch",
+	} {
+		if _, err := cupboard.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	reclaimed, err := cupboard.Vacuum()
+	if err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+	if reclaimed < 256*4096 {
+		t.Errorf("Vacuum reclaimed %d bytes, want at least the dropped %d", reclaimed, 256*4096)
+	}
+	if err := cupboard.Verify(); err != nil {
+		t.Errorf("Verify after Vacuum: %v", err)
+	}
+	if list, err := cupboard.FetchCrumbs(nil); err != nil || len(list) != 1 {
+		t.Errorf("FetchCrumbs after Vacuum = %d crumbs, %v; want the one crumb", len(list), err)
+	}
+}
+
+func TestCupboard_VerifyCorrupt(t *testing.T) {
+	dataDir := tempDir(t)
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := cupboard.SetCrumb("", &types.Crumb{Name: strings.Repeat("crumb ", 20), State: types.StateReady}); err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+	}
+	// Closing the last connection checkpoints the WAL into the file.
+	if err := cupboard.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Overwrite the second page, the root of the first table, with garbage.
+	path := filepath.Join(dataDir, dbFileName)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte(strings.Repeat("\xff", 512)), 4096); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cupboard, err = NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+	err = cupboard.Verify()
+	if !errors.Is(err, ErrCupboardCorrupt) || !strings.Contains(err.Error(), "restore the cupboard from an export") {
+		t.Errorf("Verify on a corrupt cupboard = %v, want ErrCupboardCorrupt advising a restore", err)
+	}
+}
@@ -0,0 +1,190 @@
+package crumbs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// Crumb properties that hold inspect results.
+const (
+	PropInspectResult  = "inspect_result"
+	PropInspectHistory = "inspect_history"
+)
+
+// MaxInspectHistory bounds the number of inspect results kept per crumb.
+const MaxInspectHistory = 10
+
+// InspectRecord is one inspect result as the cupboard stores it. The
+// cupboard reads only the fields it tracks scores with; Result holds the
+// full result, encoded as JSON by the caller.
+type InspectRecord struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Score     float64         `json:"score"`
+	Action    string          `json:"action"`
+	Regressed bool            `json:"regressed,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+// RecordInspectResult stores rec as the crumb's latest inspect result and
+// appends it to the crumb's inspect history. The history keeps the most
+// recent MaxInspectHistory entries, oldest first. A zero Timestamp is set to
+// the current time, and Regressed is set when Score dropped below the
+// previous entry's score. Only the two inspect properties are written, in
+// one transaction, so concurrent records each append their entry. Returns
+// the record as stored.
+func (c *Cupboard) RecordInspectResult(id string, rec InspectRecord) (InspectRecord, error) {
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now().UTC()
+	}
+	err := c.UpdateProperties(id, func(props map[string]any) (map[string]any, error) {
+		history, err := inspectHistory(props)
+		if err != nil {
+			return nil, err
+		}
+		rec.Regressed = len(history) > 0 && rec.Score < history[len(history)-1].Score
+		history = append(history, rec)
+		if len(history) > MaxInspectHistory {
+			history = history[len(history)-MaxInspectHistory:]
+		}
+
+		latest, err := jsonValue(rec)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encoding inspect result: %v", ErrCrumbSet, err)
+		}
+		encoded, err := jsonValue(history)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encoding inspect history: %v", ErrCrumbSet, err)
+		}
+		return map[string]any{PropInspectResult: latest, PropInspectHistory: encoded}, nil
+	})
+	return rec, err
+}
+
+// CrumbInspectHistory returns the crumb's recorded inspect results, oldest
+// first. Returns an empty slice when the crumb has never been inspected.
+func (c *Cupboard) CrumbInspectHistory(id string) ([]InspectRecord, error) {
+	props, err := c.loadProperties(id)
+	if err != nil {
+		return nil, err
+	}
+	return inspectHistory(props)
+}
+
+// inspectHistory decodes the inspect history in props. Returns an empty
+// slice when props has none.
+func inspectHistory(props map[string]any) ([]InspectRecord, error) {
+	history := []InspectRecord{}
+	if value, ok := props[PropInspectHistory]; ok {
+		if err := fromJSONValue(value, &history); err != nil {
+			return nil, fmt.Errorf("%w: decoding inspect history: %v", ErrCrumbGet, err)
+		}
+	}
+	return history, nil
+}
+
+// ScorePoint is one entry of a crumb's score history.
+type ScorePoint struct {
+	Timestamp time.Time
+	Score     float64
+	Action    string
+	Regressed bool
+}
+
+// ScoreHistory returns the composite score of each recorded inspect result
+// of the crumb, oldest first. Entries recorded before timestamps were kept
+// have a zero Timestamp.
+func (c *Cupboard) ScoreHistory(crumbID string) ([]ScorePoint, error) {
+	history, err := c.CrumbInspectHistory(crumbID)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ScorePoint, len(history))
+	for i, rec := range history {
+		points[i] = ScorePoint{Timestamp: rec.Timestamp, Score: rec.Score, Action: rec.Action, Regressed: rec.Regressed}
+	}
+	return points, nil
+}
+
+// LatestInspectResult returns the crumb's most recent inspect result.
+// The boolean is false when the crumb has never been inspected.
+func (c *Cupboard) LatestInspectResult(id string) (InspectRecord, bool, error) {
+	props, err := c.loadProperties(id)
+	if err != nil {
+		return InspectRecord{}, false, err
+	}
+	value, ok := props[PropInspectResult]
+	if !ok {
+		return InspectRecord{}, false, nil
+	}
+	var rec InspectRecord
+	if err := fromJSONValue(value, &rec); err != nil {
+		return InspectRecord{}, false, fmt.Errorf("%w: decoding inspect result: %v", ErrCrumbGet, err)
+	}
+	return rec, true, nil
+}
+
+// CrumbsByAction returns the crumbs whose latest inspect result carries action.
+func (c *Cupboard) CrumbsByAction(action string) ([]*types.Crumb, error) {
+	all, err := c.FetchCrumbs(nil)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*types.Crumb
+	for _, crumb := range all {
+		rec, ok, err := c.LatestInspectResult(crumb.CrumbID)
+		if err != nil {
+			return nil, err
+		}
+		if ok && rec.Action == action {
+			matched = append(matched, crumb)
+		}
+	}
+	return matched, nil
+}
+
+// LatestInspectResults returns the latest inspect result of every crumb that
+// has been inspected, in crumb fetch order.
+func (c *Cupboard) LatestInspectResults() ([]InspectRecord, error) {
+	all, err := c.FetchCrumbs(nil)
+	if err != nil {
+		return nil, err
+	}
+	var records []InspectRecord
+	for _, crumb := range all {
+		rec, ok, err := c.LatestInspectResult(crumb.CrumbID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// jsonValue converts v to the plain JSON value (map, slice, string, number,
+// or bool) a property holds, so it is stored as JSON rather than as a string
+// of JSON.
+func jsonValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// fromJSONValue decodes a property value stored by jsonValue into v.
+func fromJSONValue(value, v any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,239 @@
+package crumbs
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestRecordInspectResult_History(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Mended crumb", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	attempts := []InspectRecord{
+		{Score: 0.42, Action: "human_review"},
+		{Score: 0.65, Action: "mend"},
+		{Score: 0.85, Action: "accept"},
+	}
+	for _, cr := range attempts {
+		if _, err := cupboard.RecordInspectResult(id, cr); err != nil {
+			t.Fatalf("RecordInspectResult failed: %v", err)
+		}
+	}
+
+	history, err := cupboard.CrumbInspectHistory(id)
+	if err != nil {
+		t.Fatalf("CrumbInspectHistory failed: %v", err)
+	}
+	if len(history) != len(attempts) {
+		t.Fatalf("history has %d entries, want %d", len(history), len(attempts))
+	}
+	for i, cr := range history {
+		if cr.Score != attempts[i].Score || cr.Action != attempts[i].Action {
+			t.Errorf("history[%d] = %.2f %s, want %.2f %s", i, cr.Score, cr.Action, attempts[i].Score, attempts[i].Action)
+		}
+	}
+
+	// The history is stored as JSON, not as a string holding JSON.
+	props, err := cupboard.loadProperties(id)
+	if err != nil {
+		t.Fatalf("loadProperties failed: %v", err)
+	}
+	if stored, ok := props[PropInspectHistory].([]any); !ok || len(stored) != len(attempts) {
+		t.Errorf("stored history = %#v, want a JSON list of %d records", props[PropInspectHistory], len(attempts))
+	}
+}
+
+func TestRecordInspectResult_Bounded(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Busy crumb", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	for i := 0; i < MaxInspectHistory+2; i++ {
+		if _, err := cupboard.RecordInspectResult(id, InspectRecord{Score: float64(i) / 100}); err != nil {
+			t.Fatalf("RecordInspectResult failed: %v", err)
+		}
+	}
+
+	history, err := cupboard.CrumbInspectHistory(id)
+	if err != nil {
+		t.Fatalf("CrumbInspectHistory failed: %v", err)
+	}
+	if len(history) != MaxInspectHistory {
+		t.Fatalf("history has %d entries, want %d", len(history), MaxInspectHistory)
+	}
+	if history[0].Score != 0.02 {
+		t.Errorf("oldest kept Score = %v, want 0.02", history[0].Score)
+	}
+}
+
+func TestRecordInspectResult_Regression(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Worsened crumb", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	first, err := cupboard.RecordInspectResult(id, InspectRecord{Score: 0.7, Action: "mend"})
+	if err != nil {
+		t.Fatalf("RecordInspectResult failed: %v", err)
+	}
+	if first.Regressed || first.Timestamp.IsZero() {
+		t.Errorf("first run: Regressed = %v Timestamp = %v, want not regressed with a timestamp", first.Regressed, first.Timestamp)
+	}
+	second, err := cupboard.RecordInspectResult(id, InspectRecord{Score: 0.55, Action: "mend"})
+	if err != nil {
+		t.Fatalf("RecordInspectResult failed: %v", err)
+	}
+	if !second.Regressed {
+		t.Error("score drop 0.70 -> 0.55: Regressed = false, want true")
+	}
+
+	points, err := cupboard.ScoreHistory(id)
+	if err != nil {
+		t.Fatalf("ScoreHistory failed: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("ScoreHistory has %d entries, want 2", len(points))
+	}
+	if points[0].Score != 0.7 || points[0].Regressed || points[1].Score != 0.55 || !points[1].Regressed {
+		t.Errorf("ScoreHistory = %+v, want 0.70 then regressed 0.55", points)
+	}
+	if points[1].Timestamp.Before(points[0].Timestamp) {
+		t.Errorf("timestamps out of order: %v then %v", points[0].Timestamp, points[1].Timestamp)
+	}
+	latest, _, err := cupboard.LatestInspectResult(id)
+	if err != nil {
+		t.Fatalf("LatestInspectResult failed: %v", err)
+	}
+	if !latest.Regressed || !latest.Timestamp.Equal(second.Timestamp) {
+		t.Errorf("LatestInspectResult = %+v, want the regressed second run", latest)
+	}
+}
+
+func TestRecordInspectResult_Concurrent(t *testing.T) {
+	dataDir := tempDir(t)
+	// Two cupboards on one database stand in for stitch and mend running
+	// in separate processes.
+	var cupboards []*Cupboard
+	for i := 0; i < 2; i++ {
+		cupboard, err := NewCupboard(dataDir)
+		if err != nil {
+			t.Fatalf("NewCupboard failed: %v", err)
+		}
+		defer cupboard.Close()
+		cupboards = append(cupboards, cupboard)
+	}
+	id, err := cupboards[0].SetCrumb("", &types.Crumb{Name: "Inspected", State: types.StateTaken, Properties: map[string]any{PropPriority: 2}})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	const records = MaxInspectHistory
+	var wg sync.WaitGroup
+	errs := make(chan error, records)
+	for i := 0; i < records; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cupboards[i%2].RecordInspectResult(id, InspectRecord{Score: float64(i) / records, Action: "mend"}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("RecordInspectResult failed: %v", err)
+	}
+
+	history, err := cupboards[0].CrumbInspectHistory(id)
+	if err != nil {
+		t.Fatalf("CrumbInspectHistory failed: %v", err)
+	}
+	if len(history) != records {
+		t.Errorf("history has %d entries, want all %d concurrent records", len(history), records)
+	}
+	if crumb, err := cupboards[1].GetCrumb(id); err != nil || crumb.Properties[PropPriority] != 2 {
+		t.Errorf("crumb = %+v, %v; want its other properties untouched", crumb, err)
+	}
+}
+
+func TestCrumbInspectHistory_Empty(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Fresh crumb", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	history, err := cupboard.CrumbInspectHistory(id)
+	if err != nil {
+		t.Fatalf("CrumbInspectHistory failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("history has %d entries, want 0", len(history))
+	}
+}
+
+func TestLatestInspectResults(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	inspected, err := cupboard.SetCrumb("", &types.Crumb{Name: "Inspected crumb", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Fresh crumb", State: types.StateReady}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	for _, cr := range []InspectRecord{{Score: 0.3, Action: "human_review"}, {Score: 0.6, Action: "mend"}} {
+		if _, err := cupboard.RecordInspectResult(inspected, cr); err != nil {
+			t.Fatalf("RecordInspectResult failed: %v", err)
+		}
+	}
+
+	results, err := cupboard.LatestInspectResults()
+	if err != nil {
+		t.Fatalf("LatestInspectResults failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != "mend" {
+		t.Errorf("LatestInspectResults = %+v, want only the latest mend result", results)
+	}
+}
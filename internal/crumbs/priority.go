@@ -0,0 +1,23 @@
+package crumbs
+
+import "fmt"
+
+// PropPriority holds a crumb's priority, an integer from MinPriority to
+// MaxPriority; higher priorities are claimed first.
+const PropPriority = "priority"
+
+// Crumb priorities. A crumb without a numeric priority is claimed as if it
+// had DefaultPriority, so unprioritized work sits between urgent and
+// deferred work.
+const (
+	MinPriority     = 1
+	DefaultPriority = 3
+	MaxPriority     = 5
+)
+
+// readyOrder orders ready crumbs for claiming: highest priority first, then
+// oldest first within a priority.
+var readyOrder = fmt.Sprintf(`ORDER BY COALESCE((SELECT json_extract(cp.value, '$')
+	FROM cobbler_properties cp
+	WHERE cp.crumb_id = crumbs.crumb_id AND cp.name = '%s'
+	AND json_type(cp.value) IN ('integer', 'real')), %d) DESC, created_at, crumb_id`, PropPriority, DefaultPriority)
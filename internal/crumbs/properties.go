@@ -0,0 +1,205 @@
+package crumbs
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Crumb properties shared by the workflow commands.
+const (
+	// PropDescription holds the task description given to the agent.
+	PropDescription = "description"
+	// PropWorkType holds the task work type assigned by measure.
+	PropWorkType = "work_type"
+	// PropTargetFile holds the file a documentation task writes.
+	PropTargetFile = "target_file"
+	// PropReleaseNote records why a taken crumb was released back to ready.
+	PropReleaseNote = "release_note"
+	// The token properties total the agent tokens spent on a crumb across
+	// stitch attempts.
+	PropInputTokens         = "input_tokens"
+	PropOutputTokens        = "output_tokens"
+	PropCacheCreationTokens = "cache_creation_tokens"
+	PropCacheReadTokens     = "cache_read_tokens"
+)
+
+// Cobbler keeps crumb properties in its own table in the cupboard database,
+// one row per crumb and property with the value as JSON, rather than
+// querying the backend's property tables, whose schema the crumbs Table API
+// does not expose. The backend is still given the properties on every
+// SetCrumb, but not those changed by UpdateProperties.
+const createPropertiesTable = `CREATE TABLE IF NOT EXISTS cobbler_properties (
+	crumb_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (crumb_id, name)
+)`
+
+// selectCrumbProperties reads a crumb's property values keyed by property name.
+const selectCrumbProperties = `SELECT name, value FROM cobbler_properties WHERE crumb_id = ?`
+
+// execer runs statements on a database or in a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// storeProperties replaces the stored properties of the crumb with the given
+// ID by props. Callers hold mu exclusively.
+func storeProperties(db execer, id string, props map[string]any) error {
+	if _, err := db.Exec(`DELETE FROM cobbler_properties WHERE crumb_id = ?`, id); err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("storing properties of %s: %w", id, err))
+	}
+	for name, value := range props {
+		if err := storeProperty(db, id, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeProperty sets one property of the crumb with the given ID. Callers
+// hold mu exclusively.
+func storeProperty(db execer, id, name string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: property %s: %v", ErrCrumbSet, name, err)
+	}
+	_, err = db.Exec(`INSERT INTO cobbler_properties (crumb_id, name, value) VALUES (?, ?, ?)
+ON CONFLICT (crumb_id, name) DO UPDATE SET value = excluded.value`, id, name, string(raw))
+	if err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("storing property %s of %s: %w", name, id, err))
+	}
+	return nil
+}
+
+// touchCrumb sets a crumb's update time. As the first statement of a
+// transaction it takes the database write lock.
+const touchCrumb = `UPDATE crumbs SET updated_at = ? WHERE crumb_id = ?`
+
+// UpdateProperties passes the crumb's properties to update and stores the
+// properties update returns, in one transaction that holds the database
+// write lock from the start, so updates made concurrently, in this process
+// or another, are applied one after the other and none is lost. Properties
+// update does not return are left as they are. An error from update aborts
+// the transaction and is returned as is.
+// Returns a *CrumbNotFoundError when the crumb does not exist.
+func (c *Cupboard) UpdateProperties(id string, update func(props map[string]any) (map[string]any, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db == nil {
+		return fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("updating properties of %s: %w", id, err))
+	}
+	// Best-effort rollback once committed or on an earlier error.
+	defer tx.Rollback()
+
+	res, err := tx.Exec(touchCrumb, time.Now().UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("updating properties of %s: %w", id, err))
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("updating properties of %s: %w", id, err))
+	} else if n == 0 {
+		return &CrumbNotFoundError{ID: id}
+	}
+	props, err := queryProperties(tx, id)
+	if err != nil {
+		return err
+	}
+	changed, err := update(props)
+	if err != nil {
+		return err
+	}
+	for name, value := range changed {
+		if err := storeProperty(tx, id, name, value); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return backendError(ErrCrumbSet, fmt.Errorf("updating properties of %s: %w", id, err))
+	}
+	return nil
+}
+
+// querier runs queries on a database or in a transaction.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadProperties reads every property stored for the crumb with the given ID.
+// Returns an empty map when the crumb has no properties.
+func (c *Cupboard) loadProperties(id string) (map[string]any, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return nil, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	return queryProperties(c.db, id)
+}
+
+// queryProperties reads every property stored for the crumb with the given
+// ID from db, as loadProperties does.
+func queryProperties(db querier, id string) (map[string]any, error) {
+	rows, err := db.Query(selectCrumbProperties, id)
+	if err != nil {
+		return nil, backendError(ErrTableAccess, err)
+	}
+	defer rows.Close()
+
+	props := map[string]any{}
+	for rows.Next() {
+		var name, raw string
+		if err := rows.Scan(&name, &raw); err != nil {
+			return nil, backendError(ErrCrumbGet, err)
+		}
+		value, err := decodePropertyValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: property %s: %v", ErrCrumbGet, name, err)
+		}
+		props[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, backendError(ErrCrumbGet, err)
+	}
+	return props, nil
+}
+
+// decodePropertyValue decodes a JSON property value. Integral numbers decode
+// to int so values round-trip as they were set; other numbers are float64.
+func decodePropertyValue(raw string) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(value), nil
+}
+
+// normalizeNumbers converts json.Number values, including those nested in
+// slices and maps, to int or float64.
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64() // json.Number is always a valid float literal
+		return f
+	case []any:
+		for i := range v {
+			v[i] = normalizeNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalizeNumbers(v[k])
+		}
+	}
+	return value
+}
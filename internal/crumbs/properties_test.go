@@ -0,0 +1,189 @@
+package crumbs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// seedDB runs statements on the cupboard database in dataDir before any
+// cupboard opens it.
+func seedDB(t *testing.T, dataDir string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open(sqlDriver, filepath.Join(dataDir, dbFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seeding %q: %v", stmt, err)
+		}
+	}
+}
+
+func TestSetCrumb_ReplacesProperties(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{
+		Name:       "Replaced",
+		State:      types.StateReady,
+		Properties: map[string]any{PropPriority: 2, PropDescription: "old"},
+	})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	want := map[string]any{PropPriority: 4}
+	if _, err := cupboard.SetCrumb(id, &types.Crumb{Name: "Replaced", State: types.StateReady, Properties: want}); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	crumb, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if !reflect.DeepEqual(crumb.Properties, want) {
+		t.Errorf("Properties = %v, want %v", crumb.Properties, want)
+	}
+}
+
+func TestNewCupboard_ImportsBackendProperties(t *testing.T) {
+	dataDir := tempDir(t)
+	// A cupboard written before cobbler kept its own property table.
+	seedDB(t, dataDir,
+		`CREATE TABLE crumbs (crumb_id TEXT PRIMARY KEY, name TEXT NOT NULL, state TEXT NOT NULL, created_at TEXT NOT NULL, updated_at TEXT NOT NULL)`,
+		`CREATE TABLE properties (property_id TEXT PRIMARY KEY, name TEXT NOT NULL UNIQUE, value_type TEXT NOT NULL, created_at TEXT NOT NULL)`,
+		`CREATE TABLE crumb_properties (crumb_id TEXT NOT NULL, property_id TEXT NOT NULL, value_type TEXT NOT NULL, value TEXT NOT NULL, PRIMARY KEY (crumb_id, property_id))`,
+		`INSERT INTO crumbs VALUES ('c1', 'Legacy', 'ready', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z')`,
+		`INSERT INTO properties VALUES ('p1', 'priority', 'json', '2026-01-01T00:00:00Z'), ('p2', 'tags', 'json', '2026-01-01T00:00:00Z')`,
+		`INSERT INTO crumb_properties VALUES ('c1', 'p1', 'json', '5'), ('c1', 'p2', 'json', '["backend"]')`,
+	)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	crumb, err := cupboard.GetCrumb("c1")
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	want := map[string]any{PropPriority: 5, PropTags: []any{"backend"}}
+	if !reflect.DeepEqual(crumb.Properties, want) {
+		t.Errorf("Properties = %v, want %v", crumb.Properties, want)
+	}
+
+	// The import runs once: later changes are not overwritten by the
+	// backend's stale copy.
+	crumb.Properties = map[string]any{PropPriority: 1}
+	if _, err := cupboard.SetCrumb("c1", crumb); err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	cupboard.Close()
+	seedDB(t, dataDir, `UPDATE crumb_properties SET value = '3' WHERE property_id = 'p1'`)
+	cupboard, err = NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	defer cupboard.Close()
+	if crumb, err = cupboard.GetCrumb("c1"); err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if got := crumb.Properties[PropPriority]; got != 1 {
+		t.Errorf("priority after reopening = %v, want 1", got)
+	}
+}
+
+func TestNewCupboard_ChecksCrumbsSchema(t *testing.T) {
+	dataDir := tempDir(t)
+	seedDB(t, dataDir, `CREATE TABLE crumbs (id TEXT PRIMARY KEY, title TEXT)`)
+
+	if _, err := NewCupboard(dataDir); !errors.Is(err, ErrCupboardInit) {
+		t.Errorf("NewCupboard over a foreign crumbs table error = %v, want ErrCupboardInit", err)
+	}
+}
+
+func TestUpdateProperties_Concurrent(t *testing.T) {
+	dataDir := tempDir(t)
+	// Two cupboards on one database stand in for two processes.
+	var cupboards []*Cupboard
+	for i := 0; i < 2; i++ {
+		cupboard, err := NewCupboard(dataDir)
+		if err != nil {
+			t.Fatalf("NewCupboard failed: %v", err)
+		}
+		defer cupboard.Close()
+		cupboards = append(cupboards, cupboard)
+	}
+	id, err := cupboards[0].SetCrumb("", &types.Crumb{Name: "Tagged", State: types.StateReady, Properties: map[string]any{PropPriority: 3}})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	const tagsPerCupboard = 10
+	var want []string
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*tagsPerCupboard)
+	for i, cupboard := range cupboards {
+		for j := 0; j < tagsPerCupboard; j++ {
+			tag := fmt.Sprintf("tag-%d-%02d", i, j)
+			want = append(want, tag)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := cupboard.AddTag(id, tag); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("AddTag failed: %v", err)
+	}
+
+	crumb, err := cupboards[0].GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if got := crumbTags(crumb.Properties); !slices.Equal(got, want) {
+		t.Errorf("tags = %v, want every concurrently added tag %v", got, want)
+	}
+	if got := crumb.Properties[PropPriority]; got != 3 {
+		t.Errorf("priority = %v, want it untouched", got)
+	}
+}
+
+func TestUpdateProperties_UpdateError(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Kept", State: types.StateReady, Properties: map[string]any{PropPriority: 3}})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	boom := errors.New("boom")
+	err = cupboard.UpdateProperties(id, func(map[string]any) (map[string]any, error) { return nil, boom })
+	if !errors.Is(err, boom) {
+		t.Errorf("UpdateProperties error = %v, want the update's error", err)
+	}
+	var notFound *CrumbNotFoundError
+	err = cupboard.UpdateProperties("missing", func(map[string]any) (map[string]any, error) { return nil, nil })
+	if !errors.As(err, &notFound) {
+		t.Errorf("UpdateProperties(missing) error = %v, want CrumbNotFoundError", err)
+	}
+}
@@ -0,0 +1,319 @@
+package crumbs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// crumbColumns maps the Crumb field names accepted as filter keys to their
+// crumbs table columns.
+var crumbColumns = map[string]string{
+	"CrumbID":   "crumb_id",
+	"Name":      "name",
+	"State":     "state",
+	"CreatedAt": "created_at",
+	"UpdatedAt": "updated_at",
+}
+
+// PropertyFilterPrefix starts a filter key that matches a property instead
+// of a Crumb field: "Properties.work_type" matches the work_type property.
+const PropertyFilterPrefix = "Properties."
+
+// propertyMatch selects the crumbs whose property, named by the first
+// argument, has one of the decoded JSON values that follow. The %s is the
+// comparison: "= ?" or an IN list.
+const propertyMatch = `crumb_id IN (SELECT cp.crumb_id
+	FROM cobbler_properties cp
+	WHERE cp.name = ? AND json_extract(cp.value, '$') %s)`
+
+// crumbWhere builds the WHERE clause and arguments for filter. Scalar values
+// match by equality; slice values match any of their elements, as an IN
+// clause. An empty slice matches nothing. Keys are Crumb field names,
+// PropertyFilterPrefix and a property name, TagsAnyFilter, or TagsAllFilter;
+// splitFilter separates out any other key.
+func crumbWhere(filter map[string]any) (string, []any, error) {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conds := make([]string, 0, len(keys))
+	var args []any
+	for _, key := range keys {
+		if key == TagsAnyFilter || key == TagsAllFilter {
+			cond, values := tagWhere(key, filter[key])
+			conds = append(conds, cond)
+			args = append(args, values...)
+			continue
+		}
+		if name, ok := strings.CutPrefix(key, PropertyFilterPrefix); ok && name != "" {
+			values, isSlice := propertyValues(filter[key])
+			switch {
+			case !isSlice:
+				conds = append(conds, fmt.Sprintf(propertyMatch, "= ?"))
+				args = append(args, name, propertyValue(filter[key]))
+			case len(values) == 0:
+				conds = append(conds, "1 = 0")
+			default:
+				conds = append(conds, fmt.Sprintf(propertyMatch, "IN ("+placeholders(len(values))+")"))
+				args = append(append(args, name), values...)
+			}
+			continue
+		}
+		column, ok := crumbColumns[key]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown filter field %q", ErrCrumbFetch, key)
+		}
+		values, isSlice := filterValues(filter[key])
+		switch {
+		case !isSlice:
+			conds = append(conds, column+" = ?")
+			args = append(args, columnValue(filter[key]))
+		case len(values) == 0:
+			conds = append(conds, "1 = 0")
+		default:
+			conds = append(conds, fmt.Sprintf("%s IN (%s)", column, placeholders(len(values))))
+			args = append(args, values...)
+		}
+	}
+	if len(conds) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+// splitFilter separates the keys of filter that crumbWhere translates to
+// SQL from the rest, which the backend's Table.Fetch matches instead.
+func splitFilter(filter map[string]any) (sqlFilter, backendFilter map[string]any) {
+	sqlFilter = make(map[string]any, len(filter))
+	for key, value := range filter {
+		name, isProperty := strings.CutPrefix(key, PropertyFilterPrefix)
+		_, isColumn := crumbColumns[key]
+		if isColumn || isProperty && name != "" || key == TagsAnyFilter || key == TagsAllFilter {
+			sqlFilter[key] = value
+			continue
+		}
+		if backendFilter == nil {
+			backendFilter = map[string]any{}
+		}
+		backendFilter[key] = value
+	}
+	return sqlFilter, backendFilter
+}
+
+// where builds the WHERE clause for filter, as crumbWhere does, after
+// resolving the keys crumbWhere does not know through the backend: the
+// crumbs the backend returns for them restrict the clause by ID. It takes
+// the crumbs table lock, so callers must not hold mu.
+func (c *Cupboard) where(filter map[string]any) (string, []any, error) {
+	sqlFilter, backendFilter := splitFilter(filter)
+	where, args, err := crumbWhere(sqlFilter)
+	if err != nil || backendFilter == nil {
+		return where, args, err
+	}
+	var matched []any
+	err = c.withCrumbsTable(func(table types.Table) error {
+		results, err := table.Fetch(backendFilter)
+		if err != nil {
+			return backendError(ErrCrumbFetch, err)
+		}
+		for _, r := range results {
+			crumb, ok := r.(*types.Crumb)
+			if !ok {
+				return fmt.Errorf("%w: unexpected type %T", ErrCrumbFetch, r)
+			}
+			matched = append(matched, crumb.CrumbID)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	cond := "1 = 0"
+	if len(matched) > 0 {
+		cond = "crumb_id IN (" + placeholders(len(matched)) + ")"
+	}
+	if where == "" {
+		return " WHERE " + cond, matched, nil
+	}
+	return where + " AND " + cond, append(args, matched...), nil
+}
+
+// columnValue converts v to the text a crumbs column stores for it: times
+// in RFC 3339 with nanoseconds in UTC, as the backend writes them, and
+// anything else formatted with fmt.Sprint.
+func columnValue(v any) string {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// filterValues returns the elements of v, as column values, when v is a
+// slice or array other than []byte.
+func filterValues(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = columnValue(rv.Index(i).Interface())
+	}
+	return values, true
+}
+
+// placeholders returns n comma-separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// propertyValues returns the elements of v as property values when v is a
+// slice or array other than []byte.
+func propertyValues(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = propertyValue(rv.Index(i).Interface())
+	}
+	return values, true
+}
+
+// propertyValue converts v to what json_extract returns for it when stored:
+// numbers and booleans compare as numbers, strings (including named string
+// types such as types.State) as text.
+func propertyValue(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// FetchOptions orders and pages the results of FetchCrumbsPaged.
+type FetchOptions struct {
+	// Limit is the maximum number of crumbs returned; 0 means no limit.
+	Limit int
+	// Offset skips that many crumbs before the first one returned.
+	Offset int
+	// OrderBy names a Crumb field (such as "CreatedAt" or "Name") or, when
+	// no field matches, a property (such as "priority"). Crumbs without the
+	// property sort last. Empty orders by creation time.
+	OrderBy string
+	// Descending reverses the order.
+	Descending bool
+}
+
+// propertyOrder sorts by a property's decoded JSON value. Values are stored
+// as JSON, so json_extract compares numbers as numbers.
+const propertyOrder = `(SELECT json_extract(cp.value, '$')
+	FROM cobbler_properties cp
+	WHERE cp.crumb_id = crumbs.crumb_id AND cp.name = ?)`
+
+// FetchCrumbsPaged queries crumbs matching the filter, with the same filter
+// semantics as FetchCrumbs, ordered and paged by opts. Ties are broken by
+// creation time and ID, so pages are deterministic.
+func (c *Cupboard) FetchCrumbsPaged(filter map[string]any, opts FetchOptions) ([]*types.Crumb, error) {
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return nil, fmt.Errorf("%w: negative limit or offset", ErrCrumbFetch)
+	}
+	return c.fetchCrumbs(filter, opts)
+}
+
+// CountCrumbs returns the number of crumbs matching the filter, with the
+// same filter semantics as FetchCrumbs, without loading them.
+func (c *Cupboard) CountCrumbs(filter map[string]any) (int, error) {
+	where, args, err := c.where(filter)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return 0, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	var n int
+	if err := c.db.QueryRow("SELECT COUNT(*) FROM crumbs"+where, args...).Scan(&n); err != nil {
+		return 0, backendError(ErrCrumbFetch, err)
+	}
+	return n, nil
+}
+
+// fetchCrumbs loads the crumbs matching filter, ordered and paged by opts.
+func (c *Cupboard) fetchCrumbs(filter map[string]any, opts FetchOptions) ([]*types.Crumb, error) {
+	where, args, err := c.where(filter)
+	if err != nil {
+		return nil, err
+	}
+	query, args := crumbIDQuery(where, args, opts)
+	return c.loadCrumbs(query, args...)
+}
+
+// selectCrumbIDs returns the IDs of the crumbs matching filter, ordered and
+// paged by opts.
+func (c *Cupboard) selectCrumbIDs(filter map[string]any, opts FetchOptions) ([]string, error) {
+	where, args, err := c.where(filter)
+	if err != nil {
+		return nil, err
+	}
+	query, args := crumbIDQuery(where, args, opts)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return nil, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	return queryIDs(c.db, query, args...)
+}
+
+// crumbIDQuery returns the query selecting the IDs of the crumbs matched by
+// where and args, ordered and paged by opts, and its arguments.
+func crumbIDQuery(where string, args []any, opts FetchOptions) (string, []any) {
+	query := "SELECT crumb_id FROM crumbs" + where + " ORDER BY "
+	dir := ""
+	if opts.Descending {
+		dir = " DESC"
+	}
+	switch column, ok := crumbColumns[opts.OrderBy]; {
+	case opts.OrderBy == "":
+		query += "created_at" + dir + ", crumb_id" + dir
+	case ok:
+		query += column + dir + ", created_at, crumb_id"
+	default:
+		query += propertyOrder + dir + " NULLS LAST, created_at, crumb_id"
+		args = append(args, opts.OrderBy)
+	}
+	if opts.Limit > 0 || opts.Offset > 0 {
+		limit := opts.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, opts.Offset)
+	}
+	return query, args
+}
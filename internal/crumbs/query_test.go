@@ -0,0 +1,374 @@
+package crumbs
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// seedPagedCrumbs stores crumbs created a minute apart, oldest first, with
+// the given priorities; a negative priority leaves the property unset.
+func seedPagedCrumbs(t *testing.T, cupboard *Cupboard, priorities ...int) []string {
+	t.Helper()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := make([]string, len(priorities))
+	for i, priority := range priorities {
+		crumb := &types.Crumb{
+			Name:      "Paged crumb",
+			State:     types.StateReady,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if priority >= 0 {
+			crumb.Properties = map[string]any{"priority": priority}
+		}
+		id, err := cupboard.SetCrumb("", crumb)
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func crumbIDs(crumbs []*types.Crumb) []string {
+	ids := make([]string, len(crumbs))
+	for i, c := range crumbs {
+		ids[i] = c.CrumbID
+	}
+	return ids
+}
+
+func TestFetchCrumbsPaged(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	// Priorities 10 and 9 would sort wrongly as strings.
+	ids := seedPagedCrumbs(t, cupboard, 10, 2, -1, 9)
+
+	tests := []struct {
+		name string
+		opts FetchOptions
+		want []string
+	}{
+		{"created at", FetchOptions{}, ids},
+		{"created at descending", FetchOptions{OrderBy: "CreatedAt", Descending: true}, []string{ids[3], ids[2], ids[1], ids[0]}},
+		{"priority", FetchOptions{OrderBy: "priority"}, []string{ids[1], ids[3], ids[0], ids[2]}},
+		{"priority descending", FetchOptions{OrderBy: "priority", Descending: true}, []string{ids[0], ids[3], ids[1], ids[2]}},
+		{"first page", FetchOptions{OrderBy: "priority", Limit: 2}, []string{ids[1], ids[3]}},
+		{"second page", FetchOptions{OrderBy: "priority", Limit: 2, Offset: 2}, []string{ids[0], ids[2]}},
+		{"offset only", FetchOptions{Offset: 3}, []string{ids[3]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := cupboard.FetchCrumbsPaged(nil, tt.opts)
+			if err != nil {
+				t.Fatalf("FetchCrumbsPaged failed: %v", err)
+			}
+			got := crumbIDs(results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FetchCrumbsPaged returned %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("FetchCrumbsPaged returned %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := cupboard.FetchCrumbsPaged(nil, FetchOptions{Limit: -1}); !errors.Is(err, ErrCrumbFetch) {
+		t.Errorf("FetchCrumbsPaged(negative limit) error = %v, want ErrCrumbFetch", err)
+	}
+}
+
+func TestFetchCrumbs_MatchesGetCrumb(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	ids := seedPagedCrumbs(t, cupboard, 3, -1, 1)
+	if err := cupboard.SetCrumbState(ids[1], types.StateTaken); err != nil {
+		t.Fatalf("SetCrumbState failed: %v", err)
+	}
+	if err := cupboard.AddTag(ids[2], "parser"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	fetched, err := cupboard.FetchCrumbs(nil)
+	if err != nil {
+		t.Fatalf("FetchCrumbs failed: %v", err)
+	}
+	if got := crumbIDs(fetched); !slices.Equal(got, ids) {
+		t.Fatalf("FetchCrumbs returned %v, want %v", got, ids)
+	}
+	for _, crumb := range fetched {
+		want, err := cupboard.GetCrumb(crumb.CrumbID)
+		if err != nil {
+			t.Fatalf("GetCrumb failed: %v", err)
+		}
+		if crumb.Name != want.Name || crumb.State != want.State ||
+			!crumb.CreatedAt.Equal(want.CreatedAt) || !crumb.UpdatedAt.Equal(want.UpdatedAt) ||
+			!reflect.DeepEqual(crumb.Properties, want.Properties) {
+			t.Errorf("FetchCrumbs loaded %+v, GetCrumb %+v", crumb, want)
+		}
+	}
+}
+
+func TestCountCrumbs(t *testing.T) {
+	dataDir := tempDir(t)
+
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+
+	for _, state := range []types.State{types.StateReady, types.StateReady, types.StateTaken, types.StateDone} {
+		if _, err := cupboard.SetCrumb("", &types.Crumb{Name: "Counted crumb", State: state}); err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   int
+	}{
+		{"unfiltered", nil, 4},
+		{"scalar", map[string]any{"State": types.StateReady}, 2},
+		{"slice", map[string]any{"State": []types.State{types.StateTaken, types.StateDone}}, 2},
+		{"no match", map[string]any{"State": types.StatePending}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cupboard.CountCrumbs(tt.filter)
+			if err != nil {
+				t.Fatalf("CountCrumbs failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountCrumbs = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	cupboard.Close()
+	if _, err := cupboard.CountCrumbs(nil); !errors.Is(err, ErrTableAccess) {
+		t.Errorf("CountCrumbs after Close error = %v, want ErrTableAccess", err)
+	}
+}
+
+func TestFetchCrumbs_PropertyFilter(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	var ids []string
+	for _, props := range []map[string]any{
+		{PropWorkType: "docs", "priority": 2},
+		{PropWorkType: "code", "priority": 10},
+		{PropWorkType: "docs", "priority": 10},
+		{"priority": 2.5},
+	} {
+		id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Filtered", State: types.StateReady, Properties: props})
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   []string
+	}{
+		{"string", map[string]any{"Properties.work_type": "docs"}, []string{ids[0], ids[2]}},
+		{"number", map[string]any{"Properties.priority": 10}, []string{ids[1], ids[2]}},
+		{"float", map[string]any{"Properties.priority": 2.5}, []string{ids[3]}},
+		{"number is not text", map[string]any{"Properties.priority": "10"}, nil},
+		{"any of", map[string]any{"Properties.priority": []int{2, 3}}, []string{ids[0]}},
+		{"empty slice", map[string]any{"Properties.work_type": []string{}}, nil},
+		{"with field", map[string]any{"Properties.work_type": "docs", "Properties.priority": 10, "State": types.StateReady}, []string{ids[2]}},
+		{"missing property", map[string]any{"Properties.owner": "ana"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cupboard.FetchCrumbs(tt.filter)
+			if err != nil {
+				t.Fatalf("FetchCrumbs failed: %v", err)
+			}
+			if !slices.Equal(crumbIDs(got), tt.want) {
+				t.Errorf("FetchCrumbs = %v, want %v", crumbIDs(got), tt.want)
+			}
+			if n, err := cupboard.CountCrumbs(tt.filter); err != nil || n != len(tt.want) {
+				t.Errorf("CountCrumbs = %d, %v; want %d", n, err, len(tt.want))
+			}
+		})
+	}
+
+	if _, err := cupboard.FetchCrumbs(map[string]any{"Properties.": "x"}); !errors.Is(err, ErrCrumbFetch) {
+		t.Errorf("FetchCrumbs with an empty property name error = %v, want ErrCrumbFetch", err)
+	}
+}
+
+func TestFetchCrumbs_Tags(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	ids := seedPagedCrumbs(t, cupboard, -1, -1, -1, -1)
+	for i, tags := range [][]string{{"backend"}, {"backend", "epic-7"}, {"epic-7", "frontend"}} {
+		for _, tag := range tags {
+			if err := cupboard.AddTag(ids[i], tag); err != nil {
+				t.Fatalf("AddTag failed: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   []string
+	}{
+		{"any of one", map[string]any{TagsAnyFilter: "backend"}, []string{ids[0], ids[1]}},
+		{"any of two", map[string]any{TagsAnyFilter: []string{"backend", "frontend"}}, []string{ids[0], ids[1], ids[2]}},
+		{"all of one", map[string]any{TagsAllFilter: []string{"epic-7"}}, []string{ids[1], ids[2]}},
+		{"all of two", map[string]any{TagsAllFilter: []string{"backend", "epic-7"}}, []string{ids[1]}},
+		{"all with a duplicate", map[string]any{TagsAllFilter: []string{"backend", "backend"}}, []string{ids[0], ids[1]}},
+		{"all with an unused tag", map[string]any{TagsAllFilter: []string{"backend", "mobile"}}, nil},
+		{"empty any", map[string]any{TagsAnyFilter: []string{}}, nil},
+		{"with state", map[string]any{TagsAnyFilter: "epic-7", "State": types.StateReady}, []string{ids[1], ids[2]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := cupboard.FetchCrumbs(tt.filter)
+			if err != nil {
+				t.Fatalf("FetchCrumbs failed: %v", err)
+			}
+			if got := crumbIDs(results); !slices.Equal(got, tt.want) {
+				t.Errorf("FetchCrumbs(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRemoveTag(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Tagged crumb", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	for _, tag := range []string{"epic-7", "backend", "epic-7"} {
+		if err := cupboard.AddTag(id, tag); err != nil {
+			t.Fatalf("AddTag(%q) failed: %v", tag, err)
+		}
+	}
+	if tags, err := cupboard.CrumbTags(id); err != nil || !slices.Equal(tags, []string{"backend", "epic-7"}) {
+		t.Fatalf("CrumbTags = %v, %v; want [backend epic-7]", tags, err)
+	}
+	for _, tag := range []string{"epic-7", "mobile"} {
+		if err := cupboard.RemoveTag(id, tag); err != nil {
+			t.Fatalf("RemoveTag(%q) failed: %v", tag, err)
+		}
+	}
+	if tags, err := cupboard.CrumbTags(id); err != nil || !slices.Equal(tags, []string{"backend"}) {
+		t.Fatalf("CrumbTags = %v, %v; want [backend]", tags, err)
+	}
+	if err := cupboard.RemoveTag(id, "backend"); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	if tags, err := cupboard.CrumbTags(id); err != nil || len(tags) != 0 {
+		t.Errorf("CrumbTags = %v, %v; want none after removing the last tag", tags, err)
+	}
+	if results, err := cupboard.FetchCrumbs(map[string]any{TagsAnyFilter: "backend"}); err != nil || len(results) != 0 {
+		t.Errorf("FetchCrumbs(TagsAny backend) = %v, %v; want none", crumbIDs(results), err)
+	}
+
+	if err := cupboard.AddTag(id, " "); !errors.Is(err, ErrCrumbSet) {
+		t.Errorf("AddTag(blank) error = %v, want ErrCrumbSet", err)
+	}
+	var notFound *CrumbNotFoundError
+	if err := cupboard.AddTag("no-such-crumb", "backend"); !errors.As(err, &notFound) {
+		t.Errorf("AddTag(missing crumb) error = %v, want CrumbNotFoundError", err)
+	}
+}
+
+func TestFetchCrumbs_TimeFilter(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+	ids := seedPagedCrumbs(t, cupboard, -1, -1, -1)
+
+	// The same instants in another zone match the stored UTC times.
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).In(zone)
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   []string
+	}{
+		{"scalar", map[string]any{"CreatedAt": base.Add(time.Minute)}, ids[1:2]},
+		{"slice", map[string]any{"CreatedAt": []time.Time{base, base.Add(2 * time.Minute)}}, []string{ids[0], ids[2]}},
+		{"no match", map[string]any{"CreatedAt": base.Add(time.Second)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cupboard.FetchCrumbs(tt.filter)
+			if err != nil {
+				t.Fatalf("FetchCrumbs failed: %v", err)
+			}
+			if ids := crumbIDs(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("FetchCrumbs = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFilter(t *testing.T) {
+	filter := map[string]any{
+		"State":                types.StateReady,
+		"Properties.work_type": "docs",
+		TagsAnyFilter:          []string{"backend"},
+		"Properties.":          "empty property name",
+		"Colour":               "red",
+	}
+	sqlFilter, backendFilter := splitFilter(filter)
+	for _, key := range []string{"State", "Properties.work_type", TagsAnyFilter} {
+		if _, ok := sqlFilter[key]; !ok {
+			t.Errorf("%s not translated to SQL", key)
+		}
+	}
+	for _, key := range []string{"Properties.", "Colour"} {
+		if _, ok := backendFilter[key]; !ok {
+			t.Errorf("%s not passed to the backend", key)
+		}
+	}
+	if len(sqlFilter)+len(backendFilter) != len(filter) {
+		t.Errorf("split %v into %v and %v", filter, sqlFilter, backendFilter)
+	}
+
+	if _, backendFilter := splitFilter(map[string]any{"Name": "x"}); backendFilter != nil {
+		t.Errorf("backend filter = %v, want nil when SQL covers every key", backendFilter)
+	}
+}
@@ -0,0 +1,115 @@
+package crumbs
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// ErrIllegalTransition reports a state change the workflow does not allow.
+var ErrIllegalTransition = fmt.Errorf("cobbler: illegal crumb state transition")
+
+// States lists the workflow states in order.
+var States = []types.State{
+	types.StateDraft,
+	types.StatePending,
+	types.StateReady,
+	types.StateTaken,
+	types.StateDone,
+}
+
+// stateTransitions lists the states each state may move to. A taken crumb
+// may be released back to ready; done is terminal.
+var stateTransitions = map[types.State][]types.State{
+	types.StateDraft:   {types.StatePending, types.StateReady},
+	types.StatePending: {types.StateReady},
+	types.StateReady:   {types.StateTaken, types.StatePending},
+	types.StateTaken:   {types.StateDone, types.StateReady},
+}
+
+// CanTransition reports whether a crumb may move from one state to another.
+func CanTransition(from, to types.State) bool {
+	return slices.Contains(stateTransitions[from], to)
+}
+
+// SetCrumbState moves the crumb with the given ID to state to, enforcing the
+// workflow transition table. Returns ErrIllegalTransition, naming both
+// states, when the move is not allowed, including when another writer
+// changed the crumb's state since it was read. Use SetCrumb to overwrite a
+// crumb without checks.
+func (c *Cupboard) SetCrumbState(id string, to types.State) error {
+	crumb, err := c.GetCrumb(id)
+	if err != nil {
+		return err
+	}
+	return c.transition(id, crumb.State, to, nil)
+}
+
+// ReleaseCrumb moves a taken crumb back to ready and records note as its
+// release_note property, so the next worker can see why it was released.
+func (c *Cupboard) ReleaseCrumb(id, note string) error {
+	return c.transition(id, types.StateTaken, types.StateReady, map[string]any{PropReleaseNote: note})
+}
+
+// transitionState moves a crumb to a new state only while it is still in
+// the expected one.
+const transitionState = `UPDATE crumbs SET state = ?, updated_at = ?
+WHERE crumb_id = ? AND state = ?`
+
+// transition moves the crumb from state from to state to in one
+// compare-and-set update, setting props with the move. When no row
+// changes, the crumb's current state decides the error: missing, or an
+// illegal move from where it now is.
+func (c *Cupboard) transition(id string, from, to types.State, props map[string]any) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	changed, err := c.updateState(id, from, to, props)
+	if err != nil || changed {
+		return err
+	}
+	crumb, err := c.GetCrumb(id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s -> %s (was %s)", ErrIllegalTransition, crumb.State, to, from)
+}
+
+// updateState runs transitionState and, when it changed the crumb, stores
+// props in the same transaction. Reports whether the crumb changed.
+func (c *Cupboard) updateState(id string, from, to types.State, props map[string]any) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db == nil {
+		return false, fmt.Errorf("%w: cupboard closed", ErrTableAccess)
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return false, backendError(ErrCrumbSet, fmt.Errorf("setting state of %s: %w", id, err))
+	}
+	// Best-effort rollback once committed or on an earlier error.
+	defer tx.Rollback()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	res, err := tx.Exec(transitionState, string(to), now, id, string(from))
+	if err != nil {
+		return false, backendError(ErrCrumbSet, fmt.Errorf("setting state of %s: %w", id, err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, backendError(ErrCrumbSet, fmt.Errorf("setting state of %s: %w", id, err))
+	}
+	if n != 1 {
+		return false, nil
+	}
+	for name, value := range props {
+		if err := storeProperty(tx, id, name, value); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, backendError(ErrCrumbSet, fmt.Errorf("setting state of %s: %w", id, err))
+	}
+	return true, nil
+}
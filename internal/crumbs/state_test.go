@@ -0,0 +1,156 @@
+package crumbs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestSetCrumbState(t *testing.T) {
+	tests := []struct {
+		from, to types.State
+		legal    bool
+	}{
+		{types.StateDraft, types.StatePending, true},
+		{types.StateDraft, types.StateReady, true},
+		{types.StatePending, types.StateReady, true},
+		{types.StateReady, types.StateTaken, true},
+		{types.StateReady, types.StatePending, true},
+		{types.StateTaken, types.StateDone, true},
+		{types.StateTaken, types.StateReady, true},
+		{types.StateDraft, types.StateTaken, false},
+		{types.StateDraft, types.StateDone, false},
+		{types.StatePending, types.StateTaken, false},
+		{types.StatePending, types.StateDone, false},
+		{types.StateReady, types.StateDone, false},
+		{types.StateReady, types.StateReady, false},
+		{types.StateTaken, types.StatePending, false},
+		{types.StateDone, types.StateReady, false},
+		{types.StateDone, types.StateTaken, false},
+		{types.StateDone, types.StateDraft, false},
+	}
+
+	dataDir := tempDir(t)
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Workflow crumb", State: tt.from})
+			if err != nil {
+				t.Fatalf("SetCrumb failed: %v", err)
+			}
+
+			err = cupboard.SetCrumbState(id, tt.to)
+			if tt.legal && err != nil {
+				t.Fatalf("SetCrumbState failed: %v", err)
+			}
+			if !tt.legal && !errors.Is(err, ErrIllegalTransition) {
+				t.Fatalf("SetCrumbState error = %v, want ErrIllegalTransition", err)
+			}
+
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			want := tt.from
+			if tt.legal {
+				want = tt.to
+			}
+			if crumb.State != want {
+				t.Errorf("State = %s, want %s", crumb.State, want)
+			}
+		})
+	}
+}
+
+func TestSetCrumbState_NotFound(t *testing.T) {
+	dataDir := tempDir(t)
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	if err := cupboard.SetCrumbState("missing", types.StateTaken); !errors.Is(err, ErrCrumbGet) {
+		t.Errorf("SetCrumbState error = %v, want ErrCrumbGet", err)
+	}
+}
+
+func TestReleaseCrumb(t *testing.T) {
+	dataDir := tempDir(t)
+	cupboard, err := NewCupboard(dataDir)
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Released", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if err := cupboard.ReleaseCrumb(id, "inspect: mend"); err != nil {
+		t.Fatalf("ReleaseCrumb failed: %v", err)
+	}
+	crumb, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if crumb.State != types.StateReady || crumb.Properties[PropReleaseNote] != "inspect: mend" {
+		t.Errorf("crumb = %s %v, want ready with release note", crumb.State, crumb.Properties)
+	}
+
+	if err := cupboard.ReleaseCrumb(id, "again"); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("ReleaseCrumb of a ready crumb error = %v, want ErrIllegalTransition", err)
+	}
+}
+
+func TestSetCrumbState_Concurrent(t *testing.T) {
+	cupboard, err := NewCupboard(tempDir(t))
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Contended", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	// Workers race to move the taken crumb to done or back to ready; only
+	// the first move may win.
+	const workers = 8
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				errs <- cupboard.SetCrumbState(id, types.StateDone)
+				return
+			}
+			errs <- cupboard.ReleaseCrumb(id, "released")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var won int
+	for err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, ErrIllegalTransition):
+			t.Errorf("losing move error = %v, want ErrIllegalTransition", err)
+		}
+	}
+	if won != 1 {
+		t.Errorf("%d moves out of taken succeeded, want 1", won)
+	}
+}
@@ -0,0 +1,108 @@
+package crumbs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PropTags holds a crumb's tags, such as the epic it belongs to, as a
+// sorted list of distinct strings.
+const PropTags = "tags"
+
+// Filter keys that select crumbs by tag. The value is a tag or a slice of
+// tags: TagsAnyFilter matches crumbs with at least one of them,
+// TagsAllFilter crumbs with every one of them. An empty slice matches
+// nothing.
+const (
+	TagsAnyFilter = "TagsAny"
+	TagsAllFilter = "TagsAll"
+)
+
+// tagMatches counts how many of the tags that follow the crumb has; the %s
+// is their placeholder list. json_each also reads a tags property stored as
+// a single string.
+const tagMatches = `(SELECT COUNT(DISTINCT t.value)
+	FROM cobbler_properties cp
+	JOIN json_each(cp.value) t
+	WHERE cp.crumb_id = crumbs.crumb_id AND cp.name = '` + PropTags + `' AND t.value IN (%s))`
+
+// tagWhere builds the condition and arguments for a TagsAnyFilter or
+// TagsAllFilter value.
+func tagWhere(key string, v any) (string, []any) {
+	values, isSlice := filterValues(v)
+	if !isSlice {
+		values = []any{fmt.Sprint(v)}
+	}
+	values = slices.Compact(slices.SortedFunc(slices.Values(values), func(a, b any) int {
+		return strings.Compare(a.(string), b.(string))
+	}))
+	if len(values) == 0 {
+		return "1 = 0", nil
+	}
+	want := 1
+	if key == TagsAllFilter {
+		want = len(values)
+	}
+	return fmt.Sprintf(tagMatches+" >= %d", placeholders(len(values)), want), values
+}
+
+// CrumbTags returns the crumb's tags, sorted. Returns an empty slice when
+// the crumb has none.
+func (c *Cupboard) CrumbTags(id string) ([]string, error) {
+	crumb, err := c.GetCrumb(id)
+	if err != nil {
+		return nil, err
+	}
+	return crumbTags(crumb.Properties), nil
+}
+
+// AddTag adds tag to the crumb's tags. Adding a tag the crumb already has
+// does nothing.
+func (c *Cupboard) AddTag(id, tag string) error {
+	return c.updateTags(id, tag, func(tags []string) []string {
+		return append(tags, tag)
+	})
+}
+
+// RemoveTag removes tag from the crumb's tags. Removing a tag the crumb
+// does not have does nothing.
+func (c *Cupboard) RemoveTag(id, tag string) error {
+	return c.updateTags(id, tag, func(tags []string) []string {
+		return slices.DeleteFunc(tags, func(t string) bool { return t == tag })
+	})
+}
+
+// updateTags stores the crumb's tags as update returns them, sorted and
+// without duplicates. Only the tags property is written, so concurrent
+// changes to other properties are kept.
+func (c *Cupboard) updateTags(id, tag string, update func([]string) []string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("%w: empty tag", ErrCrumbSet)
+	}
+	return c.UpdateProperties(id, func(props map[string]any) (map[string]any, error) {
+		tags := update(crumbTags(props))
+		slices.Sort(tags)
+		return map[string]any{PropTags: slices.Compact(tags)}, nil
+	})
+}
+
+// crumbTags returns the tags in props, sorted. A tags property holding a
+// single string is one tag.
+func crumbTags(props map[string]any) []string {
+	tags := []string{}
+	switch v := props[PropTags].(type) {
+	case string:
+		tags = append(tags, v)
+	case []any:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	case []string:
+		tags = append(tags, v...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
@@ -0,0 +1,398 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// APICompatRunnerName identifies the API compatibility runner in weights
+// and reports.
+const APICompatRunnerName = "api_compatibility"
+
+// FaultAPICompatibility is the fault class for changes that break callers
+// of a package's exported API.
+const FaultAPICompatibility = "breaking API changes"
+
+// APIBaselineDirName is the directory under the crumbs data directory that
+// holds API baselines.
+const APIBaselineDirName = "api-baseline"
+
+// apiBaselineFile is the baseline file inside APICompatConfig.BaselineDir.
+const apiBaselineFile = "api.json"
+
+// APICompatConfig controls the APICompatRunner.
+type APICompatConfig struct {
+	// BaselineDir holds the stored API snapshot. Empty means no baseline.
+	BaselineDir string
+	// UpdateBaseline records the current API as the new baseline instead of
+	// comparing against the old one.
+	UpdateBaseline bool
+}
+
+// apiSurface maps a package import path to its exported symbols, each
+// described by the text that must stay the same for callers to keep
+// compiling.
+type apiSurface map[string]map[string]string
+
+// APICompatRunner compares the exported API of the modified packages with a
+// stored snapshot and fails when a symbol was removed or its declaration
+// changed. Added symbols are compatible. The snapshot is syntactic: function
+// and method signatures without parameter names, exported struct fields,
+// interface method sets, and the declared types of other types, constants,
+// and variables. Adding a method to an interface counts as a change, since
+// it breaks implementations outside the package. The score is the fraction
+// of baseline symbols that are unchanged.
+type APICompatRunner struct {
+	config APICompatConfig
+}
+
+// NewAPICompatRunner creates an APICompatRunner.
+func NewAPICompatRunner(config APICompatConfig) *APICompatRunner {
+	return &APICompatRunner{config: config}
+}
+
+// cacheConfig returns the runner's settings for the result cache. A run
+// that updates the baseline is never cached.
+func (a *APICompatRunner) cacheConfig() (any, bool) {
+	return a.config, !a.config.UpdateBaseline
+}
+
+// Name returns the technique identifier.
+func (a *APICompatRunner) Name() string { return APICompatRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (a *APICompatRunner) FaultClass() string { return FaultAPICompatibility }
+
+// Applicable reports whether the input is code work with modified packages.
+func (a *APICompatRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run snapshots the modified packages' API and compares it with the
+// baseline. It skips when there is no baseline, when no modified package
+// has one, and after updating the baseline. Packages absent from the
+// baseline are noted in evidence but not scored.
+func (a *APICompatRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	baseline, err := a.loadBaseline()
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if baseline == nil && !a.config.UpdateBaseline {
+		return skipResult(a.Name(), true, "no API baseline; record one with --update-baseline"), nil
+	}
+	dir := input.Dir
+	if dir == "" {
+		dir = "."
+	}
+	pkgs, err := listPackages(ctx, dir, input.ModifiedPackages)
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("listing packages: %w", err)
+	}
+	current := apiSurface{}
+	var unparseable []Evidence
+	for _, pkg := range pkgs {
+		api, broken, err := packageAPI(dir, pkg)
+		if err != nil {
+			return TechniqueResult{}, err
+		}
+		current[pkg.ImportPath] = api
+		unparseable = append(unparseable, broken...)
+	}
+	// A package with a file that does not parse has no complete API to
+	// compare or record.
+	if len(unparseable) > 0 {
+		return unparseableResult(a.Name(), true, unparseable, nil), nil
+	}
+	if a.config.UpdateBaseline {
+		if err := a.saveBaseline(baseline, current); err != nil {
+			return TechniqueResult{}, err
+		}
+		return skipResult(a.Name(), true, fmt.Sprintf("recorded API baseline for %d packages", len(current))), nil
+	}
+
+	var total, kept int
+	var evidence []Evidence
+	for _, path := range slices.Sorted(maps.Keys(current)) {
+		old, ok := baseline[path]
+		if !ok {
+			evidence = append(evidence, Evidence{CriterionID: path, Detail: "no API baseline for this package; not compared"})
+			continue
+		}
+		for _, symbol := range slices.Sorted(maps.Keys(old)) {
+			total++
+			now, ok := current[path][symbol]
+			switch {
+			case !ok:
+				evidence = append(evidence, Evidence{CriterionID: path, Detail: fmt.Sprintf("removed %s", symbol)})
+			case now != old[symbol]:
+				evidence = append(evidence, Evidence{CriterionID: path, Detail: fmt.Sprintf("changed %s: %s -> %s", symbol, old[symbol], now)})
+			default:
+				kept++
+			}
+		}
+	}
+
+	if total == 0 {
+		result := skipResult(a.Name(), true, "no modified package has an API baseline")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	verdict := VerdictPass
+	if kept < total {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     a.Name(),
+		Score:         float64(kept) / float64(total),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// loadBaseline reads the stored API snapshot. Returns nil when there is
+// none.
+func (a *APICompatRunner) loadBaseline() (apiSurface, error) {
+	if a.config.BaselineDir == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(filepath.Join(a.config.BaselineDir, apiBaselineFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading API baseline: %w", err)
+	}
+	var baseline apiSurface
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing API baseline: %w", err)
+	}
+	return baseline, nil
+}
+
+// saveBaseline stores current over baseline, keeping the baseline of
+// packages that were not modified.
+func (a *APICompatRunner) saveBaseline(baseline, current apiSurface) error {
+	if a.config.BaselineDir == "" {
+		return errors.New("updating API baseline: no baseline directory configured")
+	}
+	merged := apiSurface{}
+	maps.Copy(merged, baseline)
+	maps.Copy(merged, current)
+	raw, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding API baseline: %w", err)
+	}
+	if err := os.MkdirAll(a.config.BaselineDir, 0o755); err != nil {
+		return fmt.Errorf("creating API baseline: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(a.config.BaselineDir, apiBaselineFile), raw, 0o644); err != nil {
+		return fmt.Errorf("writing API baseline: %w", err)
+	}
+	return nil
+}
+
+// packageAPI returns the exported symbols declared in the package's source
+// files, and evidence for each file that does not parse, named relative to
+// dir.
+func packageAPI(dir string, pkg goPackage) (map[string]string, []Evidence, error) {
+	api := map[string]string{}
+	var unparseable []Evidence
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		path := filepath.Join(pkg.Dir, name)
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			if root, absErr := filepath.Abs(dir); absErr == nil {
+				if rel, relErr := filepath.Rel(root, path); relErr == nil {
+					path = rel
+				}
+			}
+			if ev, ok := syntaxError(filepath.ToSlash(path), err); ok {
+				unparseable = append(unparseable, ev)
+				continue
+			}
+			return nil, nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				funcAPI(fset, api, decl)
+			case *ast.GenDecl:
+				genDeclAPI(fset, api, decl)
+			}
+		}
+	}
+	return api, unparseable, nil
+}
+
+// funcAPI records an exported function, or an exported method of an
+// exported type.
+func funcAPI(fset *token.FileSet, api map[string]string, fn *ast.FuncDecl) {
+	if !fn.Name.IsExported() {
+		return
+	}
+	if fn.Recv == nil {
+		api["func "+fn.Name.Name] = exprText(fset, unnamedParams(fn.Type))
+		return
+	}
+	recv := fn.Recv.List[0].Type
+	pointer := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv, pointer = star.X, "*"
+	}
+	// Drop type parameters: T[K, V] becomes T.
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok || !ident.IsExported() {
+		return
+	}
+	api["method "+ident.Name+"."+fn.Name.Name] = "(" + pointer + ident.Name + ") " + exprText(fset, unnamedParams(fn.Type))
+}
+
+// genDeclAPI records the exported types, constants, and variables of decl.
+func genDeclAPI(fset *token.FileSet, api map[string]string, decl *ast.GenDecl) {
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			if spec.Name.IsExported() {
+				typeAPI(fset, api, spec)
+			}
+		case *ast.ValueSpec:
+			typ := decl.Tok.String()
+			if spec.Type != nil {
+				typ += " " + exprText(fset, spec.Type)
+			}
+			for _, name := range spec.Names {
+				if name.IsExported() {
+					api[decl.Tok.String()+" "+name.Name] = typ
+				}
+			}
+		}
+	}
+}
+
+// typeAPI records an exported type. Structs are recorded field by field so
+// that adding a field stays compatible; other types by their declaration.
+func typeAPI(fset *token.FileSet, api map[string]string, spec *ast.TypeSpec) {
+	key := "type " + spec.Name.Name
+	prefix := ""
+	if spec.TypeParams != nil {
+		prefix = exprText(fset, &ast.IndexListExpr{X: spec.Name, Indices: fieldTypes(spec.TypeParams)}) + " "
+	}
+	if spec.Assign.IsValid() {
+		api[key] = prefix + "= " + exprText(fset, spec.Type)
+		return
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		api[key] = prefix + exprText(fset, exportedOnly(spec.Type))
+		return
+	}
+	api[key] = prefix + "struct"
+	for _, field := range st.Fields.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(field.Type)}
+		}
+		for _, name := range names {
+			if name != nil && name.IsExported() {
+				api["field "+spec.Name.Name+"."+name.Name] = exprText(fset, field.Type)
+			}
+		}
+	}
+}
+
+// exportedOnly drops the unexported methods of an interface type, which
+// callers outside the package cannot see. Other types are returned as is.
+func exportedOnly(typ ast.Expr) ast.Expr {
+	iface, ok := typ.(*ast.InterfaceType)
+	if !ok {
+		return typ
+	}
+	methods := &ast.FieldList{}
+	for _, m := range iface.Methods.List {
+		if len(m.Names) == 0 || m.Names[0].IsExported() {
+			methods.List = append(methods.List, m)
+		}
+	}
+	return &ast.InterfaceType{Methods: methods}
+}
+
+// embeddedName returns the name an embedded field is accessed by.
+func embeddedName(typ ast.Expr) *ast.Ident {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return nil
+}
+
+// unnamedParams returns a copy of fn with parameter and result names
+// removed, since renaming them does not affect callers.
+func unnamedParams(fn *ast.FuncType) *ast.FuncType {
+	return &ast.FuncType{TypeParams: fn.TypeParams, Params: unnamed(fn.Params), Results: unnamed(fn.Results)}
+}
+
+// unnamed returns the types of list as unnamed fields.
+func unnamed(list *ast.FieldList) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	out := &ast.FieldList{}
+	for _, typ := range fieldTypes(list) {
+		out.List = append(out.List, &ast.Field{Type: typ})
+	}
+	return out
+}
+
+// fieldTypes returns one type per name in list, or one per unnamed field.
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	var types []ast.Expr
+	for _, field := range list.List {
+		for range max(1, len(field.Names)) {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
+
+// exprText prints node on one line with runs of whitespace collapsed.
+func exprText(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
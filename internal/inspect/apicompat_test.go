@@ -0,0 +1,127 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const apiBefore = `package calc
+
+// Adder adds.
+type Adder interface {
+	Add(a, b int) int
+}
+
+type Point struct {
+	X, Y int
+	hidden int
+}
+
+const Max = 10
+
+func Add(a, b int) int { return a + b }
+
+func Sub(a, b int) int { return a - b }
+
+func (p *Point) Norm() int { return p.X*p.X + p.Y*p.Y }
+`
+
+func TestAPICompatRunner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	tests := []struct {
+		name        string
+		after       string
+		wantVerdict Verdict
+		wantChanges []string
+	}{
+		{"unchanged passes", apiBefore, VerdictPass, nil},
+		{"compatible additions pass", `package calc
+
+type Adder interface {
+	Add(a, b int) int
+}
+
+type Point struct {
+	X, Y, Z int
+}
+
+const Max = 10
+
+func Add(x, y int) int { return x + y }
+
+func Sub(a, b int) int { return a - b }
+
+func Mul(a, b int) int { return a * b }
+
+func (p *Point) Norm() int { return p.X*p.X + p.Y*p.Y + p.Z*p.Z }
+
+func (p *Point) Scale(k int) { p.X *= k }
+`, VerdictPass, nil},
+		{"breaking changes fail", `package calc
+
+type Adder interface {
+	Add(a, b int) int
+	Reset()
+}
+
+type Point struct {
+	X int
+}
+
+const Max = 10
+
+func Add(a, b int64) int64 { return a + b }
+
+func (p Point) Norm() int { return p.X * p.X }
+`, VerdictFail, []string{
+			"changed func Add: func(int, int) int -> func(int64, int64) int64",
+			"changed method Point.Norm: (*Point) func() int -> (Point) func() int",
+			"changed type Adder: interface { Add(a, b int) int } -> interface { Add(a, b int) int Reset() }",
+			"removed field Point.Y",
+			"removed func Sub",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"go.mod": goModule, "calc/calc.go": apiBefore})
+			baselineDir := t.TempDir()
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}}
+
+			result, err := NewAPICompatRunner(APICompatConfig{BaselineDir: baselineDir}).Run(context.Background(), input)
+			if err != nil || result.Verdict != VerdictSkip {
+				t.Fatalf("without baseline: %+v, %v; want skip", result, err)
+			}
+			if _, err := NewAPICompatRunner(APICompatConfig{BaselineDir: baselineDir, UpdateBaseline: true}).Run(context.Background(), input); err != nil {
+				t.Fatalf("recording baseline: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "calc", "calc.go"), []byte(tt.after), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err = NewAPICompatRunner(APICompatConfig{BaselineDir: baselineDir}).Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || !result.Deterministic {
+				t.Fatalf("result = %+v, want deterministic %s", result, tt.wantVerdict)
+			}
+			var changes []string
+			for _, ev := range result.Evidence {
+				changes = append(changes, ev.Detail)
+			}
+			slices.Sort(changes)
+			if !slices.Equal(changes, tt.wantChanges) {
+				t.Errorf("evidence = %q, want %q", changes, tt.wantChanges)
+			}
+			// Eight baseline symbols: Adder, Point, Point.X, Point.Y, Max, Add, Sub, Point.Norm.
+			if want := float64(8-len(tt.wantChanges)) / 8; result.Score != want {
+				t.Errorf("Score = %v, want %v", result.Score, want)
+			}
+		})
+	}
+}
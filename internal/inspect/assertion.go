@@ -0,0 +1,199 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// AssertionCheckerName identifies the assertion checker in weights and reports.
+const AssertionCheckerName = "assertion_checking"
+
+// testFileSuffix marks Go test files.
+const testFileSuffix = "_test.go"
+
+// failureMethods are the testing.T methods that report a failure.
+var failureMethods = map[string]bool{
+	"Error": true, "Errorf": true, "Fatal": true, "Fatalf": true,
+	"Fail": true, "FailNow": true,
+}
+
+// nonAssertingMethods are testing.T methods that receive t-scoped callbacks
+// or arguments without checking anything themselves.
+var nonAssertingMethods = map[string]bool{
+	"Run": true, "Helper": true, "Parallel": true, "Cleanup": true,
+	"Log": true, "Logf": true, "Skip": true, "Skipf": true, "SkipNow": true,
+	"Setenv": true, "TempDir": true, "Name": true, "Deadline": true,
+}
+
+// AssertionChecker flags test functions that never report a failure.
+// A test with no assertions inflates coverage while catching nothing.
+// Assertions are recognized directly (t.Error, t.Fatal, ...) and, heuristically,
+// through helpers: any call that receives the *testing.T value counts.
+type AssertionChecker struct{}
+
+// NewAssertionChecker creates an AssertionChecker.
+func NewAssertionChecker() *AssertionChecker {
+	return &AssertionChecker{}
+}
+
+// Name returns the technique identifier.
+func (a *AssertionChecker) Name() string { return AssertionCheckerName }
+
+// FaultClass returns the fault class this technique targets.
+func (a *AssertionChecker) FaultClass() string { return FaultTestInadequacy }
+
+// Applicable reports whether the input is code work that modifies test files.
+func (a *AssertionChecker) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(testFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified test files"
+	}
+	return true, ""
+}
+
+// Run parses each modified test file and scores the fraction of test
+// functions that contain at least one assertion.
+func (a *AssertionChecker) Run(_ context.Context, input *InspectInput) (TechniqueResult, error) {
+	var total, asserting int
+	var evidence, unparseable []Evidence
+	fset := token.NewFileSet()
+	for _, file := range testFiles(input.ModifiedFiles) {
+		f, err := parser.ParseFile(fset, input.path(file), nil, 0)
+		if ev, ok := syntaxError(file, err); ok {
+			unparseable = append(unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return TechniqueResult{}, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		for _, fn := range testFuncs(f) {
+			total++
+			if hasAssertion(fn) {
+				asserting++
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				File:   file,
+				Line:   fset.Position(fn.Pos()).Line,
+				Detail: fmt.Sprintf("%s never reports a failure", fn.Name.Name),
+			})
+		}
+	}
+
+	if len(unparseable) > 0 {
+		return unparseableResult(a.Name(), true, unparseable, evidence), nil
+	}
+	if total == 0 {
+		return skipResult(a.Name(), true, "no test functions in modified test files"), nil
+	}
+
+	verdict := VerdictPass
+	if len(evidence) > 0 {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     a.Name(),
+		Score:         float64(asserting) / float64(total),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// testFiles filters paths down to Go test files.
+func testFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if strings.HasSuffix(f, testFileSuffix) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// testFuncs returns the top-level TestXxx(t *testing.T) functions in f.
+func testFuncs(f *ast.File) []*ast.FuncDecl {
+	var out []*ast.FuncDecl
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+		if strings.HasPrefix(fn.Name.Name, "Test") && len(testingParams(fn.Type)) > 0 {
+			out = append(out, fn)
+		}
+	}
+	return out
+}
+
+// testingParams returns the names of *testing.T parameters in a function type.
+func testingParams(ft *ast.FuncType) []string {
+	var names []string
+	for _, field := range ft.Params.List {
+		star, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := star.X.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "T" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "testing" {
+			continue
+		}
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// hasAssertion reports whether fn, including subtests declared inside it,
+// calls a failure method on its *testing.T or passes it to a helper.
+func hasAssertion(fn *ast.FuncDecl) bool {
+	tNames := map[string]bool{}
+	for _, n := range testingParams(fn.Type) {
+		tNames[n] = true
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			for _, name := range testingParams(lit.Type) {
+				tNames[name] = true
+			}
+		}
+		return true
+	})
+
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if recv, ok := sel.X.(*ast.Ident); ok && tNames[recv.Name] {
+				found = failureMethods[sel.Sel.Name]
+				if nonAssertingMethods[sel.Sel.Name] || found {
+					return !found
+				}
+			}
+		}
+		for _, arg := range call.Args {
+			if id, ok := arg.(*ast.Ident); ok && tNames[id.Name] {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,127 @@
+package inspect
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const assertingTest = `package calc
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Errorf("Add(1, 2) != 3")
+	}
+}
+
+func TestAddHelper(t *testing.T) {
+	checkSum(t, Add(2, 2), 4)
+}
+
+func TestAddSubtests(t *testing.T) {
+	t.Run("zero", func(st *testing.T) {
+		if Add(0, 0) != 0 {
+			st.Fatal("Add(0, 0) != 0")
+		}
+	})
+}
+`
+
+const assertionFreeTest = `package calc
+
+import "testing"
+
+func TestAddRuns(t *testing.T) {
+	t.Parallel()
+	_ = Add(1, 2)
+	t.Log("done")
+}
+`
+
+func TestAssertionChecker_Applicable(t *testing.T) {
+	checker := NewAssertionChecker()
+	tests := []struct {
+		name  string
+		input *InspectInput
+		want  bool
+	}{
+		{"code with tests", &InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"calc_test.go"}}, true},
+		{"code without tests", &InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"calc.go"}}, false},
+		{"docs", &InspectInput{WorkType: WorkTypeDocs, ModifiedFiles: []string{"calc_test.go"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := checker.Applicable(tt.input)
+			if got != tt.want {
+				t.Errorf("Applicable = %v, want %v", got, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("Applicable returned false without a reason")
+			}
+		})
+	}
+}
+
+func TestAssertionChecker_Asserting(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc_test.go": assertingTest})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc_test.go"}}
+
+	result, err := NewAssertionChecker().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictPass {
+		t.Errorf("Verdict = %q, want %q (evidence: %+v)", result.Verdict, VerdictPass, result.Evidence)
+	}
+	if result.Score != 1.0 {
+		t.Errorf("Score = %v, want 1.0", result.Score)
+	}
+	if !result.Deterministic {
+		t.Error("Deterministic = false, want true")
+	}
+}
+
+func TestAssertionChecker_AssertionFree(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"calc_test.go": assertingTest,
+		"runs_test.go": assertionFreeTest,
+	})
+	input := &InspectInput{
+		WorkType:      WorkTypeCode,
+		Dir:           dir,
+		ModifiedFiles: []string{"calc_test.go", "runs_test.go"},
+	}
+
+	result, err := NewAssertionChecker().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictFail {
+		t.Errorf("Verdict = %q, want %q", result.Verdict, VerdictFail)
+	}
+	if result.Score != 0.75 {
+		t.Errorf("Score = %v, want 0.75", result.Score)
+	}
+	if len(result.Evidence) != 1 {
+		t.Fatalf("Evidence count = %d, want 1", len(result.Evidence))
+	}
+	ev := result.Evidence[0]
+	if ev.File != "runs_test.go" || ev.Line != 5 || !strings.Contains(ev.Detail, "TestAddRuns") {
+		t.Errorf("Evidence = %+v, want TestAddRuns at runs_test.go:5", ev)
+	}
+}
+
+func TestAssertionChecker_NoTestFuncs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"helpers_test.go": "package calc\n\nfunc helper() {}\n"})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"helpers_test.go"}}
+
+	result, err := NewAssertionChecker().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictSkip {
+		t.Errorf("Verdict = %q, want %q", result.Verdict, VerdictSkip)
+	}
+}
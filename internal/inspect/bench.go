@@ -0,0 +1,320 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BenchRunnerName identifies the benchmark runner in weights and reports.
+const BenchRunnerName = "benchmark_regression"
+
+// FaultPerformance is the fault class for changes that slow the code down.
+const FaultPerformance = "performance regressions"
+
+// BenchBaselineDirName is the directory under the crumbs data directory that
+// holds benchmark baselines.
+const BenchBaselineDirName = "bench-baseline"
+
+// benchBaselineFile is the baseline file inside BenchConfig.BaselineDir.
+const benchBaselineFile = "baseline.json"
+
+// Default benchmark settings.
+const (
+	DefaultBenchThreshold = 10.0
+	DefaultBenchCount     = 6
+	// benchAlpha is the significance level below which a difference between
+	// baseline and current samples is treated as real, as in benchstat.
+	benchAlpha = 0.05
+)
+
+// benchLine matches a benchmark result line, capturing the name without its
+// GOMAXPROCS suffix and the ns/op value.
+var benchLine = regexp.MustCompile(`^(Benchmark\S*?)(?:-\d+)?\s+\d+\s+([0-9.]+) ns/op`)
+
+// BenchConfig controls the BenchRunner.
+type BenchConfig struct {
+	// BaselineDir holds the stored baseline. Empty means no baseline.
+	BaselineDir string
+	// Threshold is the slowdown, in percent of the baseline median, that a
+	// benchmark may show before it counts as a regression.
+	Threshold float64
+	// Count is the number of times each benchmark runs; the significance
+	// test needs several samples on each side to detect anything.
+	Count int
+	// UpdateBaseline records the current results as the new baseline
+	// instead of comparing against the old one.
+	UpdateBaseline bool
+}
+
+// DefaultBenchConfig returns the default benchmark settings.
+func DefaultBenchConfig() BenchConfig {
+	return BenchConfig{Threshold: DefaultBenchThreshold, Count: DefaultBenchCount}
+}
+
+// benchSamples maps a benchmark, qualified by its package import path, to
+// its ns/op samples.
+type benchSamples map[string][]float64
+
+// BenchRunner runs the modified packages' benchmarks and compares ns/op with
+// a stored baseline. A benchmark regresses when its median slows by more
+// than the threshold and a Mann-Whitney U test, as benchstat uses, finds the
+// difference significant. Each regression lowers the score by how far it
+// exceeds the threshold: 1 / (1 + sum of (slowdown - threshold) / threshold).
+// Timings vary between machines and runs, so results are non-deterministic
+// and a baseline is only meaningful on the machine that recorded it.
+type BenchRunner struct {
+	config BenchConfig
+	// runBench runs the benchmarks of pkgs in dir and returns the output.
+	runBench func(ctx context.Context, dir string, pkgs []string) (string, error)
+}
+
+// NewBenchRunner creates a BenchRunner that runs go test -bench.
+func NewBenchRunner(config BenchConfig) *BenchRunner {
+	count := strconv.Itoa(max(1, config.Count))
+	return &BenchRunner{config: config, runBench: func(ctx context.Context, dir string, pkgs []string) (string, error) {
+		return runGo(ctx, dir, append([]string{"test", "-run", noTestsPattern, "-bench", ".", "-count", count}, pkgs...)...)
+	}}
+}
+
+// cacheConfig returns the runner's settings for the result cache. A run
+// that updates the baseline is never cached.
+func (b *BenchRunner) cacheConfig() (any, bool) {
+	return b.config, !b.config.UpdateBaseline
+}
+
+// Name returns the technique identifier.
+func (b *BenchRunner) Name() string { return BenchRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (b *BenchRunner) FaultClass() string { return FaultPerformance }
+
+// Applicable reports whether the input is code work with modified packages.
+func (b *BenchRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run benchmarks the modified packages. It skips when there are no
+// benchmarks or no baseline, and after updating the baseline. Benchmarks
+// absent from the baseline are noted in evidence but not scored.
+func (b *BenchRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	baseline, err := b.loadBaseline()
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if baseline == nil && !b.config.UpdateBaseline {
+		return skipResult(b.Name(), false, "no benchmark baseline; record one with --update-baseline"), nil
+	}
+	dir := input.Dir
+	if dir == "" {
+		dir = "."
+	}
+	out, err := b.runBench(ctx, dir, input.ModifiedPackages)
+	if ctx.Err() != nil {
+		return TechniqueResult{}, ctx.Err()
+	}
+	if err != nil {
+		result := skipResult(b.Name(), false, "benchmarks failed")
+		result.Evidence = append(result.Evidence, Evidence{Detail: checkDetail(err)})
+		return result, nil
+	}
+	current := parseBenchmarks(out)
+	if len(current) == 0 {
+		return skipResult(b.Name(), false, "no benchmarks in modified packages"), nil
+	}
+	if b.config.UpdateBaseline {
+		if err := b.saveBaseline(baseline, current); err != nil {
+			return TechniqueResult{}, err
+		}
+		return skipResult(b.Name(), false, fmt.Sprintf("recorded baseline for %d benchmarks", len(current))), nil
+	}
+
+	var excess float64
+	var compared int
+	var evidence []Evidence
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		old, ok := baseline[name]
+		if !ok {
+			evidence = append(evidence, Evidence{CriterionID: name, Detail: "no baseline for this benchmark; not compared"})
+			continue
+		}
+		compared++
+		slowdown := (median(current[name])/median(old) - 1) * 100
+		if slowdown <= b.config.Threshold {
+			continue
+		}
+		p := mannWhitneyP(old, current[name])
+		if p >= benchAlpha {
+			continue
+		}
+		excess += (slowdown - b.config.Threshold) / b.config.Threshold
+		evidence = append(evidence, Evidence{
+			CriterionID: name,
+			Detail:      fmt.Sprintf("%.1f%% slower than baseline (%.0f -> %.0f ns/op, p=%.3f, threshold %.0f%%)", slowdown, median(old), median(current[name]), p, b.config.Threshold),
+		})
+	}
+
+	if compared == 0 {
+		result := skipResult(b.Name(), false, "no benchmarks have a baseline")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	verdict := VerdictPass
+	if excess > 0 {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique: b.Name(),
+		Score:     1 / (1 + excess),
+		Verdict:   verdict,
+		Evidence:  evidence,
+	}, nil
+}
+
+// loadBaseline reads the stored baseline. Returns nil when there is none.
+func (b *BenchRunner) loadBaseline() (benchSamples, error) {
+	if b.config.BaselineDir == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(filepath.Join(b.config.BaselineDir, benchBaselineFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading benchmark baseline: %w", err)
+	}
+	var baseline benchSamples
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing benchmark baseline: %w", err)
+	}
+	return baseline, nil
+}
+
+// saveBaseline stores current over baseline, keeping the baseline of
+// benchmarks that did not run.
+func (b *BenchRunner) saveBaseline(baseline, current benchSamples) error {
+	if b.config.BaselineDir == "" {
+		return errors.New("updating benchmark baseline: no baseline directory configured")
+	}
+	merged := benchSamples{}
+	maps.Copy(merged, baseline)
+	maps.Copy(merged, current)
+	raw, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding benchmark baseline: %w", err)
+	}
+	if err := os.MkdirAll(b.config.BaselineDir, 0o755); err != nil {
+		return fmt.Errorf("creating benchmark baseline: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(b.config.BaselineDir, benchBaselineFile), raw, 0o644); err != nil {
+		return fmt.Errorf("writing benchmark baseline: %w", err)
+	}
+	return nil
+}
+
+// parseBenchmarks collects the ns/op samples in go test -bench output, keyed
+// by package import path and benchmark name.
+func parseBenchmarks(out string) benchSamples {
+	samples := benchSamples{}
+	var pkg string
+	for _, line := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimSpace(rest)
+			continue
+		}
+		m := benchLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		ns, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		name := m[1]
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		samples[name] = append(samples[name], ns)
+	}
+	return samples
+}
+
+// median returns the median of xs, which must not be empty.
+func median(xs []float64) float64 {
+	sorted := slices.Sorted(slices.Values(xs))
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// mannWhitneyP returns the two-sided p-value of a Mann-Whitney U test that
+// a and b come from the same distribution, using the normal approximation
+// with tie and continuity corrections. Fewer than two samples on either
+// side give 1: nothing can be concluded.
+func mannWhitneyP(a, b []float64) float64 {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+	type sample struct {
+		v     float64
+		fromA bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Assign average ranks to ties and accumulate the tie correction.
+	var rankA, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	u := rankA - n1*(n1+1)/2
+	n := n1 + n2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,110 @@
+package inspect
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchOutput renders go test -bench output for example.com/m/calc with one
+// line per ns/op sample of each benchmark.
+func benchOutput(samples map[string][]string) string {
+	var b strings.Builder
+	b.WriteString("goos: linux\ngoarch: amd64\npkg: example.com/m/calc\n")
+	for name, values := range samples {
+		for _, ns := range values {
+			b.WriteString(name + "-8   \t 1000000\t      " + ns + " ns/op\t      16 B/op\n")
+		}
+	}
+	b.WriteString("PASS\nok  \texample.com/m/calc\t3.1s\n")
+	return b.String()
+}
+
+func TestParseBenchmarks(t *testing.T) {
+	got := parseBenchmarks(benchOutput(map[string][]string{"BenchmarkAdd": {"10.5", "11"}}) + "BenchmarkBroken\tFAIL\n")
+	want := []float64{10.5, 11}
+	if len(got) != 1 || len(got["example.com/m/calc.BenchmarkAdd"]) != 2 {
+		t.Fatalf("parseBenchmarks = %v, want example.com/m/calc.BenchmarkAdd %v", got, want)
+	}
+	for i, v := range want {
+		if got["example.com/m/calc.BenchmarkAdd"][i] != v {
+			t.Errorf("sample %d = %v, want %v", i, got["example.com/m/calc.BenchmarkAdd"][i], v)
+		}
+	}
+}
+
+func TestMannWhitneyP(t *testing.T) {
+	a := []float64{100, 101, 102, 103, 104, 105}
+	if p := mannWhitneyP(a, []float64{130, 131, 132, 133, 134, 135}); p >= benchAlpha {
+		t.Errorf("separated samples p = %v, want < %v", p, benchAlpha)
+	}
+	if p := mannWhitneyP(a, []float64{100.5, 101.5, 102.5, 103.5, 104.5, 105.5}); p < benchAlpha {
+		t.Errorf("interleaved samples p = %v, want >= %v", p, benchAlpha)
+	}
+	if p := mannWhitneyP([]float64{100}, []float64{200}); p != 1 {
+		t.Errorf("single samples p = %v, want 1", p)
+	}
+}
+
+func TestBenchRunner(t *testing.T) {
+	baseline := `{"example.com/m/calc.BenchmarkAdd": [100, 101, 102, 103, 104, 105], "example.com/m/calc.BenchmarkSub": [50, 50, 51, 51, 52, 52]}`
+	input := &InspectInput{WorkType: WorkTypeCode, ModifiedPackages: []string{"./calc"}}
+
+	tests := []struct {
+		name         string
+		baseline     string
+		update       bool
+		samples      map[string][]string
+		wantVerdict  Verdict
+		wantScore    float64
+		wantEvidence []string
+	}{
+		{"no baseline skips", "", false, nil, VerdictSkip, 0, nil},
+		{"within threshold passes", baseline, false, map[string][]string{"BenchmarkAdd": {"105", "106", "107", "108", "109", "110"}}, VerdictPass, 1, nil},
+		{"significant regression fails", baseline, false, map[string][]string{"BenchmarkAdd": {"130", "131", "132", "133", "134", "135"}}, VerdictFail, 1 / (1 + ((132.5/102.5-1)*100-10)/10), []string{"example.com/m/calc.BenchmarkAdd"}},
+		{"single sample is not significant", baseline, false, map[string][]string{"BenchmarkSub": {"90"}}, VerdictPass, 1, nil},
+		{"new benchmark is noted", baseline, false, map[string][]string{"BenchmarkAdd": {"102"}, "BenchmarkMul": {"10"}}, VerdictPass, 1, []string{"example.com/m/calc.BenchmarkMul"}},
+		{"update records the baseline", "", true, map[string][]string{"BenchmarkAdd": {"100"}}, VerdictSkip, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.baseline != "" {
+				if err := os.WriteFile(filepath.Join(dir, benchBaselineFile), []byte(tt.baseline), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			config := DefaultBenchConfig()
+			config.BaselineDir = dir
+			config.UpdateBaseline = tt.update
+			runner := NewBenchRunner(config)
+			runner.runBench = func(context.Context, string, []string) (string, error) {
+				return benchOutput(tt.samples), nil
+			}
+			result, err := runner.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || math.Abs(result.Score-tt.wantScore) > 1e-9 || result.Deterministic {
+				t.Fatalf("result = %+v, want verdict %s, score %v, non-deterministic", result, tt.wantVerdict, tt.wantScore)
+			}
+			if tt.wantVerdict != VerdictSkip && len(result.Evidence) != len(tt.wantEvidence) {
+				t.Fatalf("Evidence = %+v, want %v", result.Evidence, tt.wantEvidence)
+			}
+			for i, name := range tt.wantEvidence {
+				if result.Evidence[i].CriterionID != name {
+					t.Errorf("Evidence[%d] = %+v, want %s", i, result.Evidence[i], name)
+				}
+			}
+			if tt.update {
+				stored, err := runner.loadBaseline()
+				if err != nil || len(stored["example.com/m/calc.BenchmarkAdd"]) != 1 {
+					t.Errorf("stored baseline = %v, %v, want BenchmarkAdd recorded", stored, err)
+				}
+			}
+		})
+	}
+}
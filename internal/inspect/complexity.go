@@ -0,0 +1,143 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// ComplexityRunnerName identifies the complexity runner in weights and reports.
+const ComplexityRunnerName = "cyclomatic_complexity"
+
+// FaultMaintainability is the fault class for code too complex to maintain.
+const FaultMaintainability = "maintainability faults"
+
+// DefaultComplexityThreshold is the highest cyclomatic complexity a function
+// may have and pass.
+const DefaultComplexityThreshold = 10
+
+// ComplexityConfig controls the ComplexityRunner.
+type ComplexityConfig struct {
+	// Threshold is the highest complexity that passes; functions above it
+	// are reported.
+	Threshold int
+}
+
+// DefaultComplexityConfig returns the default complexity settings.
+func DefaultComplexityConfig() ComplexityConfig {
+	return ComplexityConfig{Threshold: DefaultComplexityThreshold}
+}
+
+// ComplexityRunner computes the cyclomatic complexity of every function in
+// the modified Go source files: one plus the number of branch points (if,
+// for, range, non-default case and select clauses, && and ||). Function
+// literals count toward their enclosing function. The score is the fraction
+// of functions at or below the threshold.
+type ComplexityRunner struct {
+	config ComplexityConfig
+}
+
+// NewComplexityRunner creates a ComplexityRunner.
+func NewComplexityRunner(config ComplexityConfig) *ComplexityRunner {
+	return &ComplexityRunner{config: config}
+}
+
+// cacheConfig returns the runner's settings for the result cache.
+func (c *ComplexityRunner) cacheConfig() (any, bool) { return c.config, true }
+
+// Name returns the technique identifier.
+func (c *ComplexityRunner) Name() string { return ComplexityRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (c *ComplexityRunner) FaultClass() string { return FaultMaintainability }
+
+// Applicable reports whether the input is code work with modified Go sources.
+func (c *ComplexityRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(sourceFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified Go source files"
+	}
+	return true, ""
+}
+
+// Run measures each function in the modified files. Evidence lists the
+// functions above the threshold with their complexity.
+func (c *ComplexityRunner) Run(_ context.Context, input *InspectInput) (TechniqueResult, error) {
+	var total, simple int
+	var evidence, unparseable []Evidence
+	fset := token.NewFileSet()
+	for _, file := range sourceFiles(input.ModifiedFiles) {
+		f, err := parser.ParseFile(fset, input.path(file), nil, 0)
+		if ev, ok := syntaxError(file, err); ok {
+			unparseable = append(unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return TechniqueResult{}, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			total++
+			complexity := cyclomaticComplexity(fn)
+			if complexity <= c.config.Threshold {
+				simple++
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				File:   file,
+				Line:   fset.Position(fn.Pos()).Line,
+				Detail: fmt.Sprintf("%s has cyclomatic complexity %d (threshold %d)", funcName(fn), complexity, c.config.Threshold),
+			})
+		}
+	}
+
+	if len(unparseable) > 0 {
+		return unparseableResult(c.Name(), true, unparseable, evidence), nil
+	}
+	if total == 0 {
+		return skipResult(c.Name(), true, "no functions in modified files"), nil
+	}
+	verdict := VerdictPass
+	if simple < total {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     c.Name(),
+		Score:         float64(simple) / float64(total),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// cyclomaticComplexity returns the cyclomatic complexity of fn.
+func cyclomaticComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
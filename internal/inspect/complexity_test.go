@@ -0,0 +1,96 @@
+package inspect
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const complexitySource = `package calc
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Classify(xs []int, done <-chan struct{}) string {
+	out := ""
+	for _, x := range xs {
+		if x > 0 && x < 10 {
+			switch {
+			case x%2 == 0:
+				out += "even"
+			case x%3 == 0 || x%5 == 0:
+				out += "odd"
+			default:
+				out += "?"
+			}
+		} else if x == 0 {
+			select {
+			case <-done:
+				return out
+			default:
+			}
+		}
+	}
+	return out
+}
+`
+
+func TestComplexity(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "calc.go", complexitySource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Classify: 1 + range + if + && + case + case + || + else-if + select case.
+	want := map[string]int{"Add": 1, "Classify": 9}
+	for _, decl := range f.Decls {
+		fn := decl.(*ast.FuncDecl)
+		if got := cyclomaticComplexity(fn); got != want[fn.Name.Name] {
+			t.Errorf("cyclomaticComplexity(%s) = %d, want %d", fn.Name.Name, got, want[fn.Name.Name])
+		}
+	}
+}
+
+func TestComplexityRunner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc.go": complexitySource})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc.go"}}
+
+	tests := []struct {
+		name        string
+		threshold   int
+		wantVerdict Verdict
+		wantScore   float64
+	}{
+		{"both under threshold", DefaultComplexityThreshold, VerdictPass, 1},
+		{"nested function over threshold", 5, VerdictFail, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewComplexityRunner(ComplexityConfig{Threshold: tt.threshold}).Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore {
+				t.Fatalf("result = %+v, want verdict %s, score %v", result, tt.wantVerdict, tt.wantScore)
+			}
+			if tt.wantVerdict == VerdictFail {
+				if len(result.Evidence) != 1 || result.Evidence[0].Line != 7 || !strings.Contains(result.Evidence[0].Detail, "Classify has cyclomatic complexity 9") {
+					t.Errorf("Evidence = %+v, want Classify at line 7 with its complexity", result.Evidence)
+				}
+			}
+		})
+	}
+}
+
+func TestComplexityRunner_Applicable(t *testing.T) {
+	c := NewComplexityRunner(DefaultComplexityConfig())
+	if ok, _ := c.Applicable(&InspectInput{WorkType: WorkTypeDocs, ModifiedFiles: []string{"calc.go"}}); ok {
+		t.Error("complexity should not apply to docs tasks")
+	}
+	if ok, _ := c.Applicable(&InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"calc_test.go"}}); ok {
+		t.Error("complexity should not apply to test files only")
+	}
+}
@@ -0,0 +1,201 @@
+package inspect
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CoverageRunnerName identifies the coverage runner in weights and reports.
+const CoverageRunnerName = "statement_coverage"
+
+// DefaultCoverageThreshold is the statement coverage required to pass.
+const DefaultCoverageThreshold = 0.80
+
+// Coverage profile and cover tool output constants.
+const (
+	coverProfileFile  = "cover.out"
+	coverModePrefix   = "mode:"
+	coverTotalPrefix  = "total:"
+	coverPercentScale = 100
+)
+
+// CoverageConfig controls the CoverageRunner.
+type CoverageConfig struct {
+	// Threshold is the statement coverage fraction required to pass. It
+	// also marks functions reported in evidence.
+	Threshold float64
+}
+
+// DefaultCoverageConfig returns the default coverage settings.
+func DefaultCoverageConfig() CoverageConfig {
+	return CoverageConfig{Threshold: DefaultCoverageThreshold}
+}
+
+// CoverageRunner measures statement coverage of the modified packages with
+// go test -coverprofile. The score is the covered statement fraction.
+type CoverageRunner struct {
+	config CoverageConfig
+	goCmd  func(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// NewCoverageRunner creates a CoverageRunner that runs the go tool.
+func NewCoverageRunner(config CoverageConfig) *CoverageRunner {
+	return &CoverageRunner{config: config, goCmd: runGo}
+}
+
+// cacheConfig returns the runner's settings for the result cache.
+func (c *CoverageRunner) cacheConfig() (any, bool) { return c.config, true }
+
+// Name returns the technique identifier.
+func (c *CoverageRunner) Name() string { return CoverageRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (c *CoverageRunner) FaultClass() string { return FaultTestInadequacy }
+
+// Applicable reports whether the input is code work with modified packages.
+func (c *CoverageRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run tests the modified packages with coverage. The verdict passes when
+// coverage meets the threshold and the tests pass; evidence lists functions
+// below the threshold.
+func (c *CoverageRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if input.WorkType != WorkTypeCode {
+		return skipResult(c.Name(), true, "not a code task"), nil
+	}
+	tmp, err := os.MkdirTemp("", "cobbler-coverage-*")
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("creating coverage directory: %w", err)
+	}
+	// Best-effort removal; the profile lives in the temp directory.
+	defer func() { _ = os.RemoveAll(tmp) }()
+	profile := filepath.Join(tmp, coverProfileFile)
+
+	var evidence []Evidence
+	args := append([]string{"test", "-count=1", "-coverprofile=" + profile}, input.ModifiedPackages...)
+	_, testErr := c.goCmd(ctx, input.Dir, args...)
+	if ctx.Err() != nil {
+		return TechniqueResult{}, ctx.Err()
+	}
+	if testErr != nil {
+		evidence = append(evidence, Evidence{Detail: fmt.Sprintf("tests failed: %v", testErr)})
+	}
+	covered, total, err := parseCoverProfile(profile)
+	if errors.Is(err, os.ErrNotExist) {
+		result := skipResult(c.Name(), true, "tests did not produce a coverage profile")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if total == 0 {
+		return skipResult(c.Name(), true, "no statements to cover"), nil
+	}
+
+	funcs, err := c.goCmd(ctx, input.Dir, "tool", "cover", "-func="+profile)
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("reading per-function coverage: %w", err)
+	}
+	evidence = append(evidence, uncoveredFuncs(funcs, c.config.Threshold)...)
+
+	score := min(1, max(0, float64(covered)/float64(total)))
+	verdict := VerdictPass
+	if score < c.config.Threshold || testErr != nil {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     c.Name(),
+		Score:         score,
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// parseCoverProfile returns the covered and total statement counts in a
+// coverage profile. Blocks reported more than once count once, covered if
+// any report covered them.
+func parseCoverProfile(path string) (covered, total int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	blocks := map[string]int{}
+	hits := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, coverModePrefix) {
+			continue
+		}
+		// file:startLine.startCol,endLine.endCol numStmts count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, 0, fmt.Errorf("malformed coverage line %q", line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, 0, fmt.Errorf("malformed coverage line %q: %w", line, err)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, 0, fmt.Errorf("malformed coverage line %q: %w", line, err)
+		}
+		blocks[fields[0]] = stmts
+		hits[fields[0]] = hits[fields[0]] || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for block, stmts := range blocks {
+		total += stmts
+		if hits[block] {
+			covered += stmts
+		}
+	}
+	return covered, total, nil
+}
+
+// uncoveredFuncs parses go tool cover -func output and returns evidence for
+// each function covered below threshold.
+func uncoveredFuncs(out string, threshold float64) []Evidence {
+	var evidence []Evidence
+	for _, line := range strings.Split(out, "\n") {
+		// path/file.go:12:	Name		75.0%
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] == coverTotalPrefix {
+			continue
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "%"), 64)
+		if err != nil || pct/coverPercentScale >= threshold {
+			continue
+		}
+		file, lineNo := fields[0], 0
+		if parts := strings.Split(strings.TrimSuffix(fields[0], ":"), ":"); len(parts) == 2 {
+			file = parts[0]
+			lineNo, _ = strconv.Atoi(parts[1])
+		}
+		evidence = append(evidence, Evidence{
+			File:   file,
+			Line:   lineNo,
+			Detail: fmt.Sprintf("%s covered %.1f%%, below threshold %.0f%%", fields[1], pct, threshold*coverPercentScale),
+		})
+	}
+	return evidence
+}
@@ -0,0 +1,86 @@
+package inspect
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCoverageRunner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"calc/calc.go": `package calc
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`,
+		"calc/calc_test.go": `package calc
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Fatal("Add(1, 2) != 3")
+	}
+}
+`,
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}}
+
+	tests := []struct {
+		name        string
+		threshold   float64
+		wantVerdict Verdict
+	}{
+		{"below threshold", 0.8, VerdictFail},
+		{"meets threshold", 0.5, VerdictPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCoverageRunner(CoverageConfig{Threshold: tt.threshold}).Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != 0.5 || !result.Deterministic {
+				t.Fatalf("result = %s %.2f, want %s 0.50 (evidence: %+v)", result.Verdict, result.Score, tt.wantVerdict, result.Evidence)
+			}
+			if len(result.Evidence) != 1 || !strings.Contains(result.Evidence[0].Detail, "Sub covered 0.0%") || result.Evidence[0].Line != 7 {
+				t.Errorf("Evidence = %+v, want Sub below threshold", result.Evidence)
+			}
+		})
+	}
+}
+
+func TestCoverageRunner_SkipsDocs(t *testing.T) {
+	result, err := NewCoverageRunner(DefaultCoverageConfig()).Run(context.Background(), &InspectInput{WorkType: WorkTypeDocs})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictSkip {
+		t.Errorf("Verdict = %q, want %q", result.Verdict, VerdictSkip)
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"cover.out": `mode: set
+example.com/m/calc/calc.go:3.24,5.2 1 1
+example.com/m/calc/calc.go:7.24,9.2 3 0
+example.com/m/calc/calc.go:7.24,9.2 3 1
+example.com/m/calc/calc.go:11.24,13.2 2 0
+`})
+	covered, total, err := parseCoverProfile(dir + "/cover.out")
+	if err != nil {
+		t.Fatalf("parseCoverProfile failed: %v", err)
+	}
+	if covered != 4 || total != 6 {
+		t.Errorf("parseCoverProfile = %d/%d, want 4/6", covered, total)
+	}
+}
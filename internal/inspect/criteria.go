@@ -0,0 +1,172 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Criterion patterns recognized as mechanical checks.
+var (
+	exportCriterion  = regexp.MustCompile(`(?i)\bmust export ([A-Za-z_][\w.]*)`)
+	compileCriterion = regexp.MustCompile(`(?i)\bpackage (\S+) must compile\b`)
+	returnCriterion  = regexp.MustCompile(`(?i)\bfunction ([A-Za-z_][\w.]*) must return (.+?)\.?$`)
+)
+
+// typedPackages type-checks the modified packages once, on first use, so
+// several criteria can share the result.
+type typedPackages struct {
+	ctx   context.Context
+	input *InspectInput
+	once  sync.Once
+	pkgs  []*types.Package
+	err   error
+}
+
+// load returns the type-checked modified packages.
+func (t *typedPackages) load() ([]*types.Package, error) {
+	t.once.Do(func() {
+		t.pkgs, t.err = typeCheckPackages(t.ctx, t.input.Dir, t.input.ModifiedPackages)
+	})
+	return t.pkgs, t.err
+}
+
+// criterionChecks converts PRD criteria into mechanical checks. Criteria
+// matching no known pattern are returned as unmatched.
+func (v *TranslationValidator) criterionChecks(ctx context.Context, input *InspectInput) (checks []MechanicalCheck, unmatched []string) {
+	typed := &typedPackages{ctx: ctx, input: input}
+	for _, criterion := range input.PRDCriteria {
+		var run func() error
+		if m := exportCriterion.FindStringSubmatch(criterion); m != nil {
+			symbol := strings.TrimRight(m[1], ".")
+			run = func() error { return checkExported(typed, symbol) }
+		} else if m := compileCriterion.FindStringSubmatch(criterion); m != nil {
+			pkg := m[1]
+			build := v.buildCheck
+			if build == nil {
+				build = goCheck(input.Dir, "build")
+			}
+			run = func() error { return build(ctx, []string{pkg}) }
+		} else if m := returnCriterion.FindStringSubmatch(criterion); m != nil {
+			fn, want := m[1], m[2]
+			run = func() error { return checkReturns(typed, fn, want) }
+		}
+		if run == nil {
+			unmatched = append(unmatched, criterion)
+			continue
+		}
+		checks = append(checks, MechanicalCheck{Name: criterion, Run: run})
+	}
+	return checks, unmatched
+}
+
+// checkExported verifies that symbol, optionally qualified by package name,
+// is exported by one of the modified packages.
+func checkExported(typed *typedPackages, symbol string) error {
+	obj, _, err := lookupSymbol(typed, symbol)
+	if err != nil {
+		return err
+	}
+	if !obj.Exported() {
+		return fmt.Errorf("%s is not exported", symbol)
+	}
+	return nil
+}
+
+// checkReturns verifies that function fn returns want, compared without
+// whitespace or enclosing parentheses.
+func checkReturns(typed *typedPackages, fn, want string) error {
+	obj, pkg, err := lookupSymbol(typed, fn)
+	if err != nil {
+		return err
+	}
+	f, ok := obj.(*types.Func)
+	if !ok {
+		return fmt.Errorf("%s is not a function", fn)
+	}
+	results := f.Type().(*types.Signature).Results()
+	got := make([]string, results.Len())
+	for i := range got {
+		got[i] = types.TypeString(results.At(i).Type(), types.RelativeTo(pkg))
+	}
+	if normalizeType(strings.Join(got, ",")) != normalizeType(want) {
+		return fmt.Errorf("%s returns (%s), want %s", fn, strings.Join(got, ", "), want)
+	}
+	return nil
+}
+
+// lookupSymbol finds a package-level symbol, "Name" or "pkg.Name", or a
+// method "Type.Method" in the modified packages.
+func lookupSymbol(typed *typedPackages, symbol string) (types.Object, *types.Package, error) {
+	pkgs, err := typed.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	qualifier, name, qualified := strings.Cut(symbol, ".")
+	for _, pkg := range pkgs {
+		if !qualified {
+			if obj := pkg.Scope().Lookup(symbol); obj != nil {
+				return obj, pkg, nil
+			}
+			continue
+		}
+		if pkg.Name() == qualifier {
+			if obj := pkg.Scope().Lookup(name); obj != nil {
+				return obj, pkg, nil
+			}
+		}
+		if tn, ok := pkg.Scope().Lookup(qualifier).(*types.TypeName); ok {
+			if obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(tn.Type()), true, pkg, name); obj != nil {
+				return obj, pkg, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%s not found in modified packages", symbol)
+}
+
+// normalizeType strips whitespace and enclosing parentheses from a result
+// type list.
+func normalizeType(s string) string {
+	s = strings.Join(strings.Fields(s), "")
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// typeCheckPackages parses and type-checks the non-test files of each
+// package matching patterns, importing dependencies from source.
+func typeCheckPackages(ctx context.Context, dir string, patterns []string) ([]*types.Package, error) {
+	listed, err := listPackages(ctx, dir, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+	fset := token.NewFileSet()
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkgs := make([]*types.Package, 0, len(listed))
+	for _, lp := range listed {
+		files := make([]*ast.File, 0, len(lp.GoFiles))
+		for _, name := range lp.GoFiles {
+			path := filepath.Join(lp.Dir, name)
+			f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", path, err)
+			}
+			files = append(files, f)
+		}
+		pkg, err := conf.Check(lp.ImportPath, fset, files, nil)
+		if err != nil {
+			return nil, fmt.Errorf("type-checking %s: %w", lp.ImportPath, err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
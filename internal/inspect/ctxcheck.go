@@ -0,0 +1,218 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ContextPropagationCheckerName identifies the checker in weights and reports.
+const ContextPropagationCheckerName = "context_propagation"
+
+// FaultCancellation is the fault class for blocking work that ignores cancellation.
+const FaultCancellation = "missing cancellation propagation"
+
+// noContextDirective in a function's doc comment suppresses the check.
+const noContextDirective = "cobbler:nocontext"
+
+// contextPkg is the import path of the context package.
+const contextPkg = "context"
+
+// contextFactories create root contexts; calling them inside a function
+// severs the caller's cancellation.
+var contextFactories = map[string]bool{"Background": true, "TODO": true}
+
+// blockingCalls are I/O functions, by import path, that have a
+// context-aware variant which should be used instead.
+var blockingCalls = map[string]map[string]string{
+	"os/exec":  {"Command": "exec.CommandContext"},
+	"net/http": {"Get": "http.NewRequestWithContext", "Post": "http.NewRequestWithContext", "Head": "http.NewRequestWithContext", "PostForm": "http.NewRequestWithContext", "NewRequest": "http.NewRequestWithContext"},
+	"net":      {"Dial": "net.Dialer.DialContext", "DialTimeout": "net.Dialer.DialContext", "Listen": "net.ListenConfig.Listen"},
+}
+
+// ContextPropagationChecker flags exported functions in modified files that
+// perform blocking I/O or create root contexts instead of accepting and
+// propagating a context.Context. It is heuristic: it relies on syntax, not
+// types. A function whose doc comment contains "cobbler:nocontext" is exempt.
+type ContextPropagationChecker struct{}
+
+// NewContextPropagationChecker creates a ContextPropagationChecker.
+func NewContextPropagationChecker() *ContextPropagationChecker {
+	return &ContextPropagationChecker{}
+}
+
+// Name returns the technique identifier.
+func (c *ContextPropagationChecker) Name() string { return ContextPropagationCheckerName }
+
+// FaultClass returns the fault class this technique targets.
+func (c *ContextPropagationChecker) FaultClass() string { return FaultCancellation }
+
+// Applicable reports whether the input is code work with modified Go sources.
+func (c *ContextPropagationChecker) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(sourceFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified Go source files"
+	}
+	return true, ""
+}
+
+// Run scores the fraction of context-relevant exported functions that
+// accept a context and propagate it.
+func (c *ContextPropagationChecker) Run(_ context.Context, input *InspectInput) (TechniqueResult, error) {
+	var relevant, compliant int
+	var evidence, unparseable []Evidence
+	fset := token.NewFileSet()
+	for _, file := range sourceFiles(input.ModifiedFiles) {
+		f, err := parser.ParseFile(fset, input.path(file), nil, parser.ParseComments)
+		if ev, ok := syntaxError(file, err); ok {
+			unparseable = append(unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return TechniqueResult{}, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		imports := importNames(f)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !fn.Name.IsExported() || suppressed(fn) {
+				continue
+			}
+			problems, isRelevant := contextProblems(fn, imports)
+			if !isRelevant {
+				continue
+			}
+			relevant++
+			if len(problems) == 0 {
+				compliant++
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				File:   file,
+				Line:   fset.Position(fn.Pos()).Line,
+				Detail: fmt.Sprintf("%s %s", funcName(fn), strings.Join(problems, "; ")),
+			})
+		}
+	}
+
+	if len(unparseable) > 0 {
+		return unparseableResult(c.Name(), true, unparseable, evidence), nil
+	}
+	if relevant == 0 {
+		return skipResult(c.Name(), true, "no exported functions perform blocking work"), nil
+	}
+	verdict := VerdictPass
+	if compliant < relevant {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     c.Name(),
+		Score:         float64(compliant) / float64(relevant),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// sourceFiles filters paths down to non-test Go files.
+func sourceFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if strings.HasSuffix(f, ".go") && !strings.HasSuffix(f, testFileSuffix) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// importNames maps each local package name in f to its import path.
+func importNames(f *ast.File) map[string]string {
+	names := map[string]string{}
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		names[name] = p
+	}
+	return names
+}
+
+// suppressed reports whether fn's doc comment opts out of the check.
+func suppressed(fn *ast.FuncDecl) bool {
+	return fn.Doc != nil && strings.Contains(fn.Doc.Text(), noContextDirective)
+}
+
+// funcName renders a function or method name for evidence.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if id, ok := recv.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// contextProblems lists how fn fails to propagate a context. relevant is
+// false when fn neither blocks nor touches contexts.
+func contextProblems(fn *ast.FuncDecl, imports map[string]string) (problems []string, relevant bool) {
+	acceptsCtx := acceptsContext(fn.Type, imports)
+	relevant = acceptsCtx
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		importPath := imports[pkg.Name]
+		if importPath == contextPkg && contextFactories[sel.Sel.Name] {
+			relevant = true
+			problems = append(problems, fmt.Sprintf("creates context.%s() instead of propagating a caller context", sel.Sel.Name))
+		}
+		if alt, ok := blockingCalls[importPath][sel.Sel.Name]; ok {
+			relevant = true
+			problems = append(problems, fmt.Sprintf("calls %s.%s without a context (use %s)", pkg.Name, sel.Sel.Name, alt))
+		}
+		return true
+	})
+	if relevant && !acceptsCtx {
+		problems = append([]string{"does not accept a context.Context"}, problems...)
+	}
+	return problems, relevant
+}
+
+// acceptsContext reports whether ft has a context.Context parameter.
+func acceptsContext(ft *ast.FuncType, imports map[string]string) bool {
+	for _, field := range ft.Params.List {
+		sel, ok := field.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && imports[pkg.Name] == contextPkg {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package inspect
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const threadsContext = `package svc
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Build runs the build with the caller's context.
+func Build(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "./...")
+	cmd.Dir = dir
+	return cmd.Run()
+}
+
+// Pure does no I/O and is not counted.
+func Pure(a, b int) int { return a + b }
+`
+
+const backgroundContext = `package svc
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Fetch hides a blocking call behind a fresh root context.
+func Fetch(dir string) error {
+	cmd := exec.CommandContext(context.Background(), "git", "fetch")
+	cmd.Dir = dir
+	return cmd.Run()
+}
+
+// Legacy blocks without any context, but is explicitly exempt.
+//
+// cobbler:nocontext
+func Legacy() error {
+	return exec.Command("true").Run()
+}
+`
+
+func TestContextPropagationChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		wantVerdict Verdict
+		wantScore   float64
+		wantDetail  string
+	}{
+		{"threads context", threadsContext, VerdictPass, 1, ""},
+		{"background context", backgroundContext, VerdictFail, 0, "creates context.Background()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"svc.go": tt.src})
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"svc.go"}}
+
+			result, err := NewContextPropagationChecker().Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore {
+				t.Fatalf("result = %s %.2f, want %s %.2f (evidence: %+v)",
+					result.Verdict, result.Score, tt.wantVerdict, tt.wantScore, result.Evidence)
+			}
+			if tt.wantDetail == "" {
+				return
+			}
+			if len(result.Evidence) != 1 {
+				t.Fatalf("Evidence count = %d, want 1 (suppressed function must not be flagged)", len(result.Evidence))
+			}
+			if ev := result.Evidence[0]; !strings.Contains(ev.Detail, tt.wantDetail) || !strings.HasPrefix(ev.Detail, "Fetch") {
+				t.Errorf("Detail = %q, want Fetch flagged for %q", ev.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestContextPropagationChecker_BlockingWithoutContext(t *testing.T) {
+	src := `package svc
+
+import "net/http"
+
+func Ping(url string) error {
+	_, err := http.Get(url)
+	return err
+}
+`
+	dir := writeFiles(t, map[string]string{"svc.go": src})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"svc.go"}}
+	result, err := NewContextPropagationChecker().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Evidence) != 1 || !strings.Contains(result.Evidence[0].Detail, "does not accept a context.Context") {
+		t.Errorf("Evidence = %+v, want missing context parameter flagged", result.Evidence)
+	}
+}
+
+func TestContextPropagationChecker_NothingRelevant(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"svc.go": "package svc\n\nfunc Add(a, b int) int { return a + b }\n"})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"svc.go", "svc_test.go"}}
+	result, err := NewContextPropagationChecker().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictSkip {
+		t.Errorf("Verdict = %q, want %q", result.Verdict, VerdictSkip)
+	}
+}
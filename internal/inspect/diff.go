@@ -0,0 +1,217 @@
+package inspect
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Unified diff line prefixes.
+const (
+	diffGitPrefix     = "diff --git "
+	diffOldFilePrefix = "--- "
+	diffNewFilePrefix = "+++ "
+	diffHunkPrefix    = "@@ "
+	diffRenameFrom    = "rename from "
+	diffRenameTo      = "rename to "
+	diffNewFileMode   = "new file mode "
+	diffDeletedMode   = "deleted file mode "
+	diffNullFile      = "/dev/null"
+)
+
+// DiffHunk is the line range header of one hunk: the lines it covers in the
+// old and new versions of the file.
+type DiffHunk struct {
+	OldStart, OldCount int
+	NewStart, NewCount int
+}
+
+// FileDiff is the change to one file in a unified diff.
+type FileDiff struct {
+	// OldPath is the path before the change; "" for a new file.
+	OldPath string
+	// NewPath is the path after the change; "" for a deleted file.
+	NewPath string
+	// Added holds the line numbers, in the new version, of added lines.
+	Added []int
+	// Removed holds the line numbers, in the old version, of removed lines.
+	Removed []int
+	// Hunks holds the file's hunks in diff order.
+	Hunks []DiffHunk
+}
+
+// Path returns the file's path after the change, or before it for a deleted
+// file.
+func (f FileDiff) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// IsNew reports whether the diff creates the file.
+func (f FileDiff) IsNew() bool { return f.OldPath == "" }
+
+// IsDeleted reports whether the diff deletes the file.
+func (f FileDiff) IsDeleted() bool { return f.NewPath == "" }
+
+// IsRename reports whether the diff moves the file.
+func (f FileDiff) IsRename() bool {
+	return f.OldPath != "" && f.NewPath != "" && f.OldPath != f.NewPath
+}
+
+// ParseDiff parses unified diff text, as produced by git diff or diff -u,
+// into one FileDiff per file in diff order. File paths have their "a/" and
+// "b/" prefixes removed. Git's extended headers mark new, deleted, and
+// renamed files, including renames without content changes, which have no
+// hunks. Hunk line counts are tracked so that removed or added lines that
+// look like file headers are not mistaken for them.
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	var oldLine, newLine, oldLeft, newLeft int
+	for _, text := range strings.Split(diff, "\n") {
+		if cur != nil && (oldLeft > 0 || newLeft > 0) {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				cur.Added = append(cur.Added, newLine)
+				newLine++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				cur.Removed = append(cur.Removed, oldLine)
+				oldLine++
+				oldLeft--
+			case strings.HasPrefix(text, `\`):
+			default:
+				oldLine++
+				newLine++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, diffGitPrefix):
+			oldPath, newPath := gitDiffPaths(strings.TrimPrefix(text, diffGitPrefix))
+			files = append(files, FileDiff{OldPath: oldPath, NewPath: newPath})
+			cur = &files[len(files)-1]
+		case strings.HasPrefix(text, diffOldFilePrefix):
+			// Plain diff -u output has no git header: "---" starts the file.
+			if cur == nil || len(cur.Hunks) > 0 {
+				files = append(files, FileDiff{})
+				cur = &files[len(files)-1]
+			}
+			cur.OldPath = diffPath(strings.TrimPrefix(text, diffOldFilePrefix), "a/")
+		case cur == nil:
+		case strings.HasPrefix(text, diffNewFilePrefix):
+			cur.NewPath = diffPath(strings.TrimPrefix(text, diffNewFilePrefix), "b/")
+		case strings.HasPrefix(text, diffRenameFrom):
+			cur.OldPath = unquotePath(strings.TrimPrefix(text, diffRenameFrom))
+		case strings.HasPrefix(text, diffRenameTo):
+			cur.NewPath = unquotePath(strings.TrimPrefix(text, diffRenameTo))
+		case strings.HasPrefix(text, diffNewFileMode):
+			cur.OldPath = ""
+		case strings.HasPrefix(text, diffDeletedMode):
+			cur.NewPath = ""
+		case strings.HasPrefix(text, diffHunkPrefix):
+			if h, ok := parseHunk(text); ok {
+				cur.Hunks = append(cur.Hunks, h)
+				oldLine, newLine = h.OldStart, h.NewStart
+				oldLeft, newLeft = h.OldCount, h.NewCount
+			}
+		}
+	}
+	return files
+}
+
+// changedLines returns, per file, the line numbers in the new version that
+// the diff adds or changes. Deleted files are omitted.
+func changedLines(diff string) map[string]map[int]bool {
+	changed := map[string]map[int]bool{}
+	for _, file := range ParseDiff(diff) {
+		if file.IsDeleted() || len(file.Added) == 0 {
+			continue
+		}
+		if changed[file.NewPath] == nil {
+			changed[file.NewPath] = map[int]bool{}
+		}
+		for _, line := range file.Added {
+			changed[file.NewPath][line] = true
+		}
+	}
+	return changed
+}
+
+// gitDiffPaths extracts the old and new paths from the rest of a
+// "diff --git a/old b/new" line. Unquoted paths containing " b/" are
+// ambiguous; the rename, ---, and +++ headers that follow correct them.
+func gitDiffPaths(rest string) (string, string) {
+	if strings.HasPrefix(rest, `"`) {
+		if old, err := strconv.QuotedPrefix(rest); err == nil {
+			return diffPath(old, "a/"), diffPath(strings.TrimSpace(rest[len(old):]), "b/")
+		}
+	}
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return diffPath(rest[:i], "a/"), diffPath(rest[i+1:], "b/")
+	}
+	return "", ""
+}
+
+// diffPath extracts the file path from a header path, unquoting it and
+// dropping prefix and any trailing timestamp. Returns "" for /dev/null.
+func diffPath(header, prefix string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = unquotePath(path)
+	if path == diffNullFile {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// unquotePath undoes git's C-style quoting of paths with special characters.
+func unquotePath(path string) string {
+	if !strings.HasPrefix(path, `"`) {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// parseHunk parses a hunk header such as "@@ -10,4 +12,6 @@". An omitted
+// count means one line.
+func parseHunk(header string) (DiffHunk, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return DiffHunk{}, false
+	}
+	oldStart, oldCount, ok := parseRange(fields[1], "-")
+	if !ok {
+		return DiffHunk{}, false
+	}
+	newStart, newCount, ok := parseRange(fields[2], "+")
+	if !ok {
+		return DiffHunk{}, false
+	}
+	return DiffHunk{OldStart: oldStart, OldCount: oldCount, NewStart: newStart, NewCount: newCount}, true
+}
+
+// parseRange parses a hunk range such as "+12,6" with the given sign.
+func parseRange(field, sign string) (start, count int, ok bool) {
+	rest, ok := strings.CutPrefix(field, sign)
+	if !ok {
+		return 0, 0, false
+	}
+	startText, countText, hasCount := strings.Cut(rest, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
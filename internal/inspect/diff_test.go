@@ -0,0 +1,193 @@
+package inspect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedLines(t *testing.T) {
+	diff := `diff --git a/calc/calc.go b/calc/calc.go
+--- a/calc/calc.go
++++ b/calc/calc.go
+@@ -3,4 +3,4 @@ package calc
+ func Add(a, b int) int {
+-	return a - b
++	return a + b
++	// -- not a header
+ }
+--- removed line that looks like a header
+@@ -10 +11 @@
+-x
++y
+diff --git a/new.go b/new.go
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package calc
++
+`
+	got := changedLines(diff)
+	want := map[string]map[int]bool{
+		"calc/calc.go": {4: true, 5: true, 11: true},
+		"new.go":       {1: true, 2: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedLines = %v, want %v", got, want)
+	}
+}
+
+// gitDiffSample is git diff --cached -M output for a commit that edits,
+// renames, creates, and deletes files.
+const gitDiffSample = `diff --git a/calc/calc.go b/calc/calc.go
+index 2d46a13..4c55252 100644
+--- a/calc/calc.go
++++ b/calc/calc.go
+@@ -1,9 +1,13 @@
+ package calc
+ 
+ func Add(a, b int) int {
+-	return a - b
++	return a + b
+ }
+ 
+ func Sub(a, b int) int {
+ 	return a - b
+ }
++
++func Neg(a int) int {
++	return -a
++}
+diff --git a/calc/pi.go b/calc/const.go
+similarity index 100%
+rename from calc/pi.go
+rename to calc/const.go
+diff --git a/calc/div.go b/calc/div.go
+new file mode 100644
+index 0000000..d72e153
+--- /dev/null
++++ b/calc/div.go
+@@ -0,0 +1,5 @@
++package calc
++
++func Div(a, b int) int {
++	return a / b
++}
+diff --git a/calc/old.go b/calc/old.go
+deleted file mode 100644
+index c6eb7a4..0000000
+--- a/calc/old.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package calc
+-
+-const Old = 1
+diff --git a/calc/mul.go b/calc/product.go
+similarity index 66%
+rename from calc/mul.go
+rename to calc/product.go
+index 2832654..70db61f 100644
+--- a/calc/mul.go
++++ b/calc/product.go
+@@ -1,6 +1,6 @@
+ package calc
+ 
+-// Mul multiplies.
++// Mul multiplies two ints.
+ func Mul(a, b int) int {
+ 	return a * b
+ }
+`
+
+// gitDiffHunksSample is git diff -U1 output with two hunks in one file and
+// a missing newline at end of file.
+const gitDiffHunksSample = `diff --git a/calc/calc.go b/calc/calc.go
+index 4c55252..d4a35af 100644
+--- a/calc/calc.go
++++ b/calc/calc.go
+@@ -7,3 +7,3 @@ func Add(a, b int) int {
+ func Sub(a, b int) int {
+-	return a - b
++	return b - a
+ }
+@@ -11,3 +11,3 @@ func Sub(a, b int) int {
+ func Neg(a int) int {
+-	return -a
+-}
++	return 0 - a
++}
+\ No newline at end of file
+`
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []FileDiff
+	}{
+		{"empty", "", nil},
+		{"git changes", gitDiffSample, []FileDiff{
+			{
+				OldPath: "calc/calc.go", NewPath: "calc/calc.go",
+				Added: []int{4, 10, 11, 12, 13}, Removed: []int{4},
+				Hunks: []DiffHunk{{OldStart: 1, OldCount: 9, NewStart: 1, NewCount: 13}},
+			},
+			{OldPath: "calc/pi.go", NewPath: "calc/const.go"},
+			{
+				NewPath: "calc/div.go", Added: []int{1, 2, 3, 4, 5},
+				Hunks: []DiffHunk{{OldStart: 0, OldCount: 0, NewStart: 1, NewCount: 5}},
+			},
+			{
+				OldPath: "calc/old.go", Removed: []int{1, 2, 3},
+				Hunks: []DiffHunk{{OldStart: 1, OldCount: 3, NewStart: 0, NewCount: 0}},
+			},
+			{
+				OldPath: "calc/mul.go", NewPath: "calc/product.go",
+				Added: []int{3}, Removed: []int{3},
+				Hunks: []DiffHunk{{OldStart: 1, OldCount: 6, NewStart: 1, NewCount: 6}},
+			},
+		}},
+		{"several hunks", gitDiffHunksSample, []FileDiff{{
+			OldPath: "calc/calc.go", NewPath: "calc/calc.go",
+			Added: []int{8, 12, 13}, Removed: []int{8, 12, 13},
+			Hunks: []DiffHunk{
+				{OldStart: 7, OldCount: 3, NewStart: 7, NewCount: 3},
+				{OldStart: 11, OldCount: 3, NewStart: 11, NewCount: 3},
+			},
+		}}},
+		{"plain diff -u", "--- calc.go\t2026-01-02 10:00:00\n+++ calc.go\t2026-01-02 10:05:00\n@@ -2 +2 @@\n-x\n+y\n--- util.go\n+++ util.go\n@@ -1,0 +2 @@\n+z\n", []FileDiff{
+			{OldPath: "calc.go", NewPath: "calc.go", Added: []int{2}, Removed: []int{2}, Hunks: []DiffHunk{{2, 1, 2, 1}}},
+			{OldPath: "util.go", NewPath: "util.go", Added: []int{2}, Hunks: []DiffHunk{{1, 0, 2, 1}}},
+		}},
+		{"quoted paths", "diff --git \"a/my file.go\" \"b/my file.go\"\n--- \"a/my file.go\"\n+++ \"b/my file.go\"\n@@ -1 +1 @@\n-a\n+b\n", []FileDiff{
+			{OldPath: "my file.go", NewPath: "my file.go", Added: []int{1}, Removed: []int{1}, Hunks: []DiffHunk{{1, 1, 1, 1}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDiff(tt.diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDiff =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileDiff_Kind(t *testing.T) {
+	files := ParseDiff(gitDiffSample)
+	tests := []struct {
+		path                     string
+		isNew, isDeleted, rename bool
+	}{
+		{"calc/calc.go", false, false, false},
+		{"calc/const.go", false, false, true},
+		{"calc/div.go", true, false, false},
+		{"calc/old.go", false, true, false},
+		{"calc/product.go", false, false, true},
+	}
+	for i, tt := range tests {
+		f := files[i]
+		if f.Path() != tt.path || f.IsNew() != tt.isNew || f.IsDeleted() != tt.isDeleted || f.IsRename() != tt.rename {
+			t.Errorf("files[%d] = %s new=%v deleted=%v rename=%v, want %s new=%v deleted=%v rename=%v",
+				i, f.Path(), f.IsNew(), f.IsDeleted(), f.IsRename(), tt.path, tt.isNew, tt.isDeleted, tt.rename)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FaultRegression is the fault class for behavior that diverges from
+// reference outputs.
+const FaultRegression = "behavioral divergence from reference outputs"
+
+// Fixture file extensions: each <name>.in is paired with <name>.out.
+const (
+	fixtureInputExt    = ".in"
+	fixtureExpectedExt = ".out"
+)
+
+// differentialBinary is the name of the built target binary.
+const differentialBinary = "cobbler-differential-target"
+
+// fixture is one input/expected-output pair.
+type fixture struct {
+	name     string
+	input    []byte
+	expected []byte
+}
+
+// DifferentialTesting builds the modified main package and runs it against
+// fixture pairs in InspectInput.FixtureDir: each <name>.in is piped to the
+// binary's stdin and its stdout is compared with <name>.out.
+type DifferentialTesting struct {
+	runTarget func(ctx context.Context, binary string, stdin []byte) ([]byte, error)
+}
+
+// NewDifferentialTesting creates a DifferentialTesting technique that
+// executes the built binary.
+func NewDifferentialTesting() *DifferentialTesting {
+	return &DifferentialTesting{runTarget: runBinary}
+}
+
+// Name returns the technique identifier.
+func (d *DifferentialTesting) Name() string { return DifferentialTestingName }
+
+// FaultClass returns the fault class this technique targets.
+func (d *DifferentialTesting) FaultClass() string { return FaultRegression }
+
+// Applicable reports whether the input is code work with a fixture directory.
+func (d *DifferentialTesting) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if input.FixtureDir == "" {
+		return false, "no fixture directory"
+	}
+	return true, ""
+}
+
+// Run scores the fraction of fixtures whose output matches the expected
+// output exactly.
+func (d *DifferentialTesting) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if input.FixtureDir == "" {
+		return skipResult(d.Name(), true, "no fixture directory"), nil
+	}
+	fixtures, evidence, err := loadFixtures(input.path(input.FixtureDir))
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if len(fixtures) == 0 {
+		result := skipResult(d.Name(), true, "no fixtures found")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+
+	target, err := mainPackage(ctx, input)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if target == nil {
+		return skipResult(d.Name(), true, "no modified main package to run"), nil
+	}
+	binDir, err := os.MkdirTemp("", "cobbler-differential-*")
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("creating build directory: %w", err)
+	}
+	// Best-effort removal; the binary lives in the temp directory.
+	defer func() { _ = os.RemoveAll(binDir) }()
+	binary := filepath.Join(binDir, differentialBinary)
+	if _, err := runGo(ctx, input.Dir, "build", "-o", binary, target.ImportPath); err != nil {
+		return TechniqueResult{}, fmt.Errorf("building %s: %w", target.ImportPath, err)
+	}
+
+	var matched int
+	for _, fx := range fixtures {
+		actual, runErr := d.runTarget(ctx, binary, fx.input)
+		if ctx.Err() != nil {
+			return TechniqueResult{}, ctx.Err()
+		}
+		if runErr != nil {
+			evidence = append(evidence, Evidence{File: fx.name, Detail: fmt.Sprintf("target failed: %v", runErr)})
+			continue
+		}
+		if bytes.Equal(actual, fx.expected) {
+			matched++
+			continue
+		}
+		evidence = append(evidence, Evidence{File: fx.name, Detail: "output differs: " + shortDiff(string(fx.expected), string(actual))})
+	}
+
+	verdict := VerdictPass
+	if matched < len(fixtures) {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     d.Name(),
+		Score:         float64(matched) / float64(len(fixtures)),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// loadFixtures reads every <name>.in/<name>.out pair in dir, in name order.
+// Inputs without an expected output are reported in evidence and skipped.
+func loadFixtures(dir string) ([]fixture, []Evidence, error) {
+	inputs, err := filepath.Glob(filepath.Join(dir, "*"+fixtureInputExt))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(inputs)
+	var fixtures []fixture
+	var evidence []Evidence
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), fixtureInputExt)
+		input, err := os.ReadFile(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading fixture %s: %w", in, err)
+		}
+		expected, err := os.ReadFile(strings.TrimSuffix(in, fixtureInputExt) + fixtureExpectedExt)
+		if errors.Is(err, os.ErrNotExist) {
+			evidence = append(evidence, Evidence{File: name, Detail: "fixture has no expected output"})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading fixture %s: %w", name, err)
+		}
+		fixtures = append(fixtures, fixture{name: name, input: input, expected: expected})
+	}
+	return fixtures, evidence, nil
+}
+
+// mainPackage returns the first modified package named main, or nil.
+func mainPackage(ctx context.Context, input *InspectInput) (*goPackage, error) {
+	if len(input.ModifiedPackages) == 0 {
+		return nil, nil
+	}
+	pkgs, err := listPackages(ctx, input.Dir, input.ModifiedPackages)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+	for _, pkg := range pkgs {
+		if pkg.Name == "main" {
+			return &pkg, nil
+		}
+	}
+	return nil, nil
+}
+
+// runBinary runs binary with stdin and returns its stdout. A non-zero exit
+// wraps stderr into the error. Cancelling ctx kills the process.
+func runBinary(ctx context.Context, binary string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// shortDiff describes the first line where expected and actual differ.
+func shortDiff(expected, actual string) string {
+	want := strings.Split(expected, "\n")
+	got := strings.Split(actual, "\n")
+	for i := 0; i < max(len(want), len(got)); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g || i >= len(want) || i >= len(got) {
+			return fmt.Sprintf("line %d: expected %q, got %q", i+1, w, g)
+		}
+	}
+	return "outputs are equal"
+}
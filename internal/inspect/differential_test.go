@@ -0,0 +1,153 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDifferentialTesting(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"cmd/upper/main.go": `package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+func main() {
+	in, _ := io.ReadAll(os.Stdin)
+	os.Stdout.Write(bytes.ToUpper(in))
+}
+`,
+		"fixtures/hello.in":  "hello\nworld\n",
+		"fixtures/hello.out": "HELLO\nWORLD\n",
+		"fixtures/mixed.in":  "a\nb\n",
+		"fixtures/mixed.out": "A\nc\n",
+		"fixtures/orphan.in": "no expected output",
+	})
+	input := &InspectInput{
+		WorkType:         WorkTypeCode,
+		Dir:              dir,
+		ModifiedPackages: []string{"./cmd/upper"},
+		FixtureDir:       "fixtures",
+	}
+
+	result, err := NewDifferentialTesting().Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictFail || result.Score != 0.5 || !result.Deterministic {
+		t.Fatalf("result = %s %.2f, want fail 0.50 (evidence: %+v)", result.Verdict, result.Score, result.Evidence)
+	}
+	var mismatch *Evidence
+	for i, ev := range result.Evidence {
+		if ev.File == "mixed" {
+			mismatch = &result.Evidence[i]
+		}
+	}
+	if mismatch == nil || !strings.Contains(mismatch.Detail, `line 2: expected "c", got "B"`) {
+		t.Errorf("Evidence = %+v, want the mixed fixture with a line diff", result.Evidence)
+	}
+}
+
+func TestDifferentialTesting_NoFixtureDir(t *testing.T) {
+	result, err := NewDifferentialTesting().Run(context.Background(), &InspectInput{WorkType: WorkTypeCode})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictSkip || !result.Deterministic {
+		t.Errorf("result = %+v, want deterministic skip", result)
+	}
+}
+
+func TestShortDiff(t *testing.T) {
+	tests := []struct {
+		expected, actual, want string
+	}{
+		{"a\nb\n", "a\nc\n", `line 2: expected "b", got "c"`},
+		{"a\n", "a\nextra\n", `line 2: expected "", got "extra"`},
+		{"a\nb", "a", `line 2: expected "b", got ""`},
+	}
+	for _, tt := range tests {
+		if got := shortDiff(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("shortDiff(%q, %q) = %q, want %q", tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateFixtures(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"cmd/rev/main.go": `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+			fields[i], fields[j] = fields[j], fields[i]
+		}
+		fmt.Println(strings.Join(fields, " "))
+	}
+}
+`,
+		"inputs/words.txt": "one two three\n",
+		"inputs/lines":     "a b\nc d\n",
+	})
+	ctx := context.Background()
+	binary := filepath.Join(t.TempDir(), "rev")
+	if _, err := runGo(ctx, dir, "build", "-o", binary, "./cmd/rev"); err != nil {
+		t.Fatalf("building: %v", err)
+	}
+
+	inputs := []string{filepath.Join(dir, "inputs", "words.txt"), filepath.Join(dir, "inputs", "lines")}
+	names, err := GenerateFixtures(ctx, binary, inputs, filepath.Join(dir, "fixtures"))
+	if err != nil {
+		t.Fatalf("GenerateFixtures failed: %v", err)
+	}
+	if strings.Join(names, ",") != "words,lines" {
+		t.Errorf("names = %v, want [words lines]", names)
+	}
+	if out, err := os.ReadFile(filepath.Join(dir, "fixtures", "lines.out")); err != nil || string(out) != "b a\nd c\n" {
+		t.Errorf("lines.out = %q, %v; want the binary's output", out, err)
+	}
+
+	// Replaying against the unchanged program passes every fixture.
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./cmd/rev"}, FixtureDir: "fixtures"}
+	result, err := NewDifferentialTesting().Run(ctx, input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictPass || result.Score != 1 {
+		t.Errorf("replay = %s %.2f (evidence %+v), want pass 1.00", result.Verdict, result.Score, result.Evidence)
+	}
+
+	if _, err := GenerateFixtures(ctx, binary, []string{inputs[0], filepath.Join(dir, "words.md")}, t.TempDir()); err == nil {
+		t.Error("GenerateFixtures with two inputs named words: want an error")
+	}
+	empty := t.TempDir()
+	if _, err := GenerateFixtures(ctx, binary, []string{inputs[0], filepath.Join(dir, "missing")}, empty); err == nil {
+		t.Error("GenerateFixtures with a missing input: want an error")
+	}
+	if entries, _ := os.ReadDir(empty); len(entries) != 0 {
+		t.Errorf("failed capture wrote %d files, want none", len(entries))
+	}
+}
@@ -0,0 +1,137 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// DocRunnerName identifies the doc comment runner in weights and reports.
+const DocRunnerName = "doc_comments"
+
+// DocRunner checks that the exported identifiers declared in the modified
+// Go source files have doc comments: functions, methods of exported types,
+// types, constants, and variables. A doc comment on a parenthesized
+// declaration group documents every identifier in it. The score is the
+// documented fraction.
+type DocRunner struct{}
+
+// NewDocRunner creates a DocRunner.
+func NewDocRunner() *DocRunner {
+	return &DocRunner{}
+}
+
+// Name returns the technique identifier.
+func (d *DocRunner) Name() string { return DocRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (d *DocRunner) FaultClass() string { return FaultMaintainability }
+
+// Applicable reports whether the input is code work with modified Go sources.
+func (d *DocRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(sourceFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified Go source files"
+	}
+	return true, ""
+}
+
+// Run counts the exported identifiers in the modified files. Evidence lists
+// the undocumented ones.
+func (d *DocRunner) Run(_ context.Context, input *InspectInput) (TechniqueResult, error) {
+	var total, documented int
+	var evidence, unparseable []Evidence
+	fset := token.NewFileSet()
+	for _, file := range sourceFiles(input.ModifiedFiles) {
+		f, err := parser.ParseFile(fset, input.path(file), nil, parser.ParseComments)
+		if ev, ok := syntaxError(file, err); ok {
+			unparseable = append(unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return TechniqueResult{}, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		for _, sym := range exportedSymbols(f) {
+			total++
+			if sym.documented {
+				documented++
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				File:   file,
+				Line:   fset.Position(sym.pos).Line,
+				Detail: fmt.Sprintf("%s %s has no doc comment", sym.kind, sym.name),
+			})
+		}
+	}
+
+	if len(unparseable) > 0 {
+		return unparseableResult(d.Name(), true, unparseable, evidence), nil
+	}
+	if total == 0 {
+		return skipResult(d.Name(), true, "no exported identifiers in modified files"), nil
+	}
+	verdict := VerdictPass
+	if documented < total {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     d.Name(),
+		Score:         float64(documented) / float64(total),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// exportedSymbol is an exported identifier and whether it is documented.
+type exportedSymbol struct {
+	kind       string
+	name       string
+	pos        token.Pos
+	documented bool
+}
+
+// exportedSymbols returns the exported identifiers declared in f, in
+// source order.
+func exportedSymbols(f *ast.File) []exportedSymbol {
+	var syms []exportedSymbol
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			kind := "func"
+			if decl.Recv != nil {
+				if recv := embeddedName(decl.Recv.List[0].Type); recv == nil || !recv.IsExported() {
+					continue
+				}
+				kind = "method"
+			}
+			syms = append(syms, exportedSymbol{kind, funcName(decl), decl.Pos(), decl.Doc != nil})
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						documented := spec.Doc != nil || decl.Doc != nil
+						syms = append(syms, exportedSymbol{"type", spec.Name.Name, spec.Pos(), documented})
+					}
+				case *ast.ValueSpec:
+					documented := spec.Doc != nil || decl.Doc != nil
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							syms = append(syms, exportedSymbol{decl.Tok.String(), name.Name, name.Pos(), documented})
+						}
+					}
+				}
+			}
+		}
+	}
+	return syms
+}
@@ -0,0 +1,95 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+const docSource = `package calc
+
+// Add adds.
+func Add(a, b int) int { return a + b }
+
+func Sub(a, b int) int { return a - b }
+
+func helper() {}
+
+// Point is a point.
+type Point struct{ X, Y int }
+
+func (p Point) Norm() int { return p.X*p.X + p.Y*p.Y }
+
+type hidden struct{}
+
+func (hidden) Exported() {}
+
+// Limits.
+const (
+	Min = 0
+	Max = 10
+)
+
+var (
+	// Debug enables logging.
+	Debug bool
+	Verbose, Quiet bool
+)
+
+type Mode int
+`
+
+func TestDocRunner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"calc.go":      docSource,
+		"calc_test.go": "package calc\n\nfunc TestX() {}\n",
+		"doc.go":       "// Package calc calculates.\npackage calc\n\n// Zero is zero.\nvar Zero = 0\n",
+	})
+	tests := []struct {
+		name         string
+		files        []string
+		wantVerdict  Verdict
+		wantScore    float64
+		wantEvidence []string
+	}{
+		{"documented exports pass", []string{"doc.go", "calc_test.go"}, VerdictPass, 1, nil},
+		// Add, Point, Min, Max, Debug documented; Sub, Point.Norm, Verbose, Quiet, Mode not.
+		{"undocumented exports fail", []string{"calc.go"}, VerdictFail, 0.5, []string{
+			"calc.go:6: func Sub has no doc comment",
+			"calc.go:13: method Point.Norm has no doc comment",
+			"calc.go:28: var Verbose has no doc comment",
+			"calc.go:28: var Quiet has no doc comment",
+			"calc.go:31: type Mode has no doc comment",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: tt.files}
+			result, err := NewDocRunner().Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore || !result.Deterministic {
+				t.Fatalf("result = %+v, want deterministic verdict %s, score %v", result, tt.wantVerdict, tt.wantScore)
+			}
+			var evidence []string
+			for _, ev := range result.Evidence {
+				evidence = append(evidence, fmt.Sprintf("%s:%d: %s", ev.File, ev.Line, ev.Detail))
+			}
+			if !slices.Equal(evidence, tt.wantEvidence) {
+				t.Errorf("Evidence = %q, want %q", evidence, tt.wantEvidence)
+			}
+		})
+	}
+}
+
+func TestDocRunner_Applicable(t *testing.T) {
+	d := NewDocRunner()
+	if ok, _ := d.Applicable(&InspectInput{WorkType: WorkTypeDocs, ModifiedFiles: []string{"calc.go"}}); ok {
+		t.Error("doc comments should not apply to docs tasks")
+	}
+	if ok, _ := d.Applicable(&InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"calc_test.go"}}); ok {
+		t.Error("doc comments should not apply to test files only")
+	}
+}
@@ -0,0 +1,78 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// FailureMode is a technique that failed on one or more non-accepted crumbs.
+type FailureMode struct {
+	// Technique is the failing technique name.
+	Technique string
+	// FaultClass is the fault class the technique targets, when known.
+	FaultClass string
+	// Crumbs is the number of non-accepted crumbs the technique failed on.
+	Crumbs int
+}
+
+// AggregateFailureModes tallies failing techniques across the non-accepted
+// results. Modes are ranked by affected crumb count, most frequent first,
+// with ties broken by technique name.
+func AggregateFailureModes(results []CompositeResult) []FailureMode {
+	classes := map[string]string{}
+	for _, tech := range DefaultTechniques() {
+		classes[tech.Name()] = tech.FaultClass()
+	}
+
+	counts := map[string]int{}
+	for _, cr := range results {
+		if cr.Action.Accepting() {
+			continue
+		}
+		failed := map[string]bool{}
+		for _, r := range cr.Results {
+			if r.Verdict == VerdictFail {
+				failed[r.Technique] = true
+			}
+		}
+		for name := range failed {
+			counts[name]++
+		}
+	}
+
+	modes := make([]FailureMode, 0, len(counts))
+	for name, n := range counts {
+		modes = append(modes, FailureMode{Technique: name, FaultClass: classes[name], Crumbs: n})
+	}
+	sort.Slice(modes, func(i, j int) bool {
+		if modes[i].Crumbs != modes[j].Crumbs {
+			return modes[i].Crumbs > modes[j].Crumbs
+		}
+		return modes[i].Technique < modes[j].Technique
+	})
+	return modes
+}
+
+// WriteFailureModes prints up to top failure modes as a table. A top of zero
+// or less prints every mode.
+func WriteFailureModes(w io.Writer, modes []FailureMode, top int) error {
+	if len(modes) == 0 {
+		_, err := fmt.Fprintln(w, "no failure modes")
+		return err
+	}
+	if top > 0 && len(modes) > top {
+		modes = modes[:top]
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TECHNIQUE\tFAULT CLASS\tCRUMBS")
+	for _, m := range modes {
+		class := m.FaultClass
+		if class == "" {
+			class = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", m.Technique, class, m.Crumbs)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,66 @@
+package inspect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAggregateFailureModes(t *testing.T) {
+	fail := func(name string) TechniqueResult { return TechniqueResult{Technique: name, Verdict: VerdictFail} }
+	pass := func(name string) TechniqueResult {
+		return TechniqueResult{Technique: name, Verdict: VerdictPass, Score: 1}
+	}
+	results := []CompositeResult{
+		{Action: ActionMend, Results: []TechniqueResult{fail(AssertionCheckerName), fail(MutationRunnerName)}},
+		{Action: ActionHumanReview, Results: []TechniqueResult{fail(MutationRunnerName), pass(AssertionCheckerName)}},
+		{Action: ActionMend, Results: []TechniqueResult{fail(MutationRunnerName), fail(ContextPropagationCheckerName)}},
+		// Accepted crumbs never contribute, even with a failing technique.
+		{Action: ActionAccept, Results: []TechniqueResult{fail(ContextPropagationCheckerName), fail(ContextPropagationCheckerName)}},
+	}
+
+	got := AggregateFailureModes(results)
+	want := []FailureMode{
+		{Technique: MutationRunnerName, FaultClass: FaultTestInadequacy, Crumbs: 3},
+		{Technique: AssertionCheckerName, FaultClass: FaultTestInadequacy, Crumbs: 1},
+		{Technique: ContextPropagationCheckerName, FaultClass: FaultCancellation, Crumbs: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AggregateFailureModes = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mode %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAggregateFailureModes_DuplicateResultsCountOnce(t *testing.T) {
+	results := []CompositeResult{{Action: ActionMend, Results: []TechniqueResult{
+		{Technique: AssertionCheckerName, Verdict: VerdictFail},
+		{Technique: AssertionCheckerName, Verdict: VerdictFail},
+	}}}
+	got := AggregateFailureModes(results)
+	if len(got) != 1 || got[0].Crumbs != 1 {
+		t.Errorf("AggregateFailureModes = %+v, want one mode affecting 1 crumb", got)
+	}
+}
+
+func TestWriteFailureModes_Top(t *testing.T) {
+	modes := []FailureMode{
+		{Technique: "a", FaultClass: "class a", Crumbs: 3},
+		{Technique: "b", Crumbs: 2},
+		{Technique: "c", Crumbs: 1},
+	}
+	var buf bytes.Buffer
+	if err := WriteFailureModes(&buf, modes, 2); err != nil {
+		t.Fatalf("WriteFailureModes failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 modes:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "a ") || !strings.Contains(lines[1], "class a") || !strings.Contains(lines[2], "-") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
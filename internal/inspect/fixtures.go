@@ -0,0 +1,56 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateFixtures runs binary on each input file and records the pair as a
+// differential testing fixture in outDir: the input as <name>.in and the
+// binary's stdout as <name>.out, where name is the input's base name without
+// its extension. It returns the fixture names in input order.
+//
+// The recorded outputs are whatever binary does today, not a specification:
+// run it with a build that is known to behave correctly, because
+// DifferentialTesting later treats these outputs as the oracle. Existing
+// fixtures of the same name are overwritten. Nothing is written unless every
+// input runs successfully.
+func GenerateFixtures(ctx context.Context, binary string, inputs []string, outDir string) ([]string, error) {
+	fixtures := make([]fixture, 0, len(inputs))
+	seen := map[string]string{}
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), filepath.Ext(in))
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("capturing fixtures: %s and %s both name fixture %q", prev, in, name)
+		}
+		seen[name] = in
+		input, err := os.ReadFile(in)
+		if err != nil {
+			return nil, fmt.Errorf("reading fixture input: %w", err)
+		}
+		output, err := runBinary(ctx, binary, input)
+		if err != nil {
+			return nil, fmt.Errorf("running %s on %s: %w", binary, in, err)
+		}
+		fixtures = append(fixtures, fixture{name: name, input: input, expected: output})
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating fixture directory: %w", err)
+	}
+	names := make([]string, 0, len(fixtures))
+	for _, fx := range fixtures {
+		base := filepath.Join(outDir, fx.name)
+		if err := os.WriteFile(base+fixtureInputExt, fx.input, 0o644); err != nil {
+			return nil, fmt.Errorf("writing fixture %s: %w", fx.name, err)
+		}
+		if err := os.WriteFile(base+fixtureExpectedExt, fx.expected, 0o644); err != nil {
+			return nil, fmt.Errorf("writing fixture %s: %w", fx.name, err)
+		}
+		names = append(names, fx.name)
+	}
+	return names, nil
+}
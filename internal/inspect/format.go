@@ -0,0 +1,103 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+)
+
+// FormatRunnerName identifies the format runner in weights and reports.
+const FormatRunnerName = "formatting"
+
+// FaultStyleConformance is the fault class for code that does not follow
+// the project's formatting conventions.
+const FaultStyleConformance = "style conformance errors"
+
+// Formatter returns the canonical formatting of a Go source file.
+type Formatter func(src []byte) ([]byte, error)
+
+// FormatRunner checks that every modified Go file, tests included, is
+// already formatted: formatting it leaves it unchanged, as gofmt -l would
+// report. The score is the fraction of files already formatted.
+type FormatRunner struct {
+	format Formatter
+}
+
+// NewFormatRunner creates a FormatRunner. format may be nil to use
+// go/format, which matches gofmt.
+func NewFormatRunner(format Formatter) *FormatRunner {
+	return &FormatRunner{format: format}
+}
+
+// Name returns the technique identifier.
+func (f *FormatRunner) Name() string { return FormatRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (f *FormatRunner) FaultClass() string { return FaultStyleConformance }
+
+// Applicable reports whether the input is code work with modified Go files.
+func (f *FormatRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(goFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified Go files"
+	}
+	return true, ""
+}
+
+// Run formats each modified Go file in memory. Evidence names each file that
+// is not formatted or cannot be formatted.
+func (f *FormatRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	formatter := f.format
+	if formatter == nil {
+		formatter = format.Source
+	}
+	files := goFiles(input.ModifiedFiles)
+	var formatted int
+	var evidence []Evidence
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return TechniqueResult{}, err
+		}
+		src, err := os.ReadFile(input.path(file))
+		if err != nil {
+			return TechniqueResult{}, fmt.Errorf("reading %s: %w", file, err)
+		}
+		out, err := formatter(src)
+		switch {
+		case err != nil:
+			evidence = append(evidence, Evidence{File: file, Detail: fmt.Sprintf("cannot be formatted: %v", err)})
+		case !bytes.Equal(out, src):
+			evidence = append(evidence, Evidence{File: file, Detail: "not gofmt-formatted"})
+		default:
+			formatted++
+		}
+	}
+
+	verdict := VerdictPass
+	if formatted < len(files) {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     f.Name(),
+		Score:         float64(formatted) / float64(len(files)),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// goFiles filters paths down to Go files, tests included.
+func goFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if strings.HasSuffix(f, ".go") {
+			out = append(out, f)
+		}
+	}
+	return out
+}
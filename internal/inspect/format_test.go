@@ -0,0 +1,63 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFormatRunner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"good.go":      "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"bad.go":       "package calc\n\nfunc  Sub(a,b int) int {\nreturn a-b\n}\n",
+		"bad_test.go":  "package calc\nimport \"testing\"\nfunc TestSub(t *testing.T){}\n",
+		"README.md":    "not go\n",
+		"broken.go":    "package calc\n\nfunc {\n",
+		"formatted.go": "package calc\n",
+	})
+
+	tests := []struct {
+		name         string
+		files        []string
+		format       Formatter
+		wantVerdict  Verdict
+		wantScore    float64
+		wantEvidence []string
+	}{
+		{"formatted files pass", []string{"good.go", "formatted.go", "README.md"}, nil, VerdictPass, 1, nil},
+		{"unformatted files and tests fail", []string{"good.go", "bad.go", "bad_test.go"}, nil, VerdictFail, 1.0 / 3, []string{"bad.go", "bad_test.go"}},
+		{"unparseable file fails", []string{"good.go", "broken.go"}, nil, VerdictFail, 0.5, []string{"broken.go"}},
+		{"injected formatter", []string{"good.go", "bad.go"}, func(src []byte) ([]byte, error) { return src, nil }, VerdictPass, 1, nil},
+		{"injected formatter error", []string{"good.go"}, func([]byte) ([]byte, error) { return nil, errors.New("boom") }, VerdictFail, 0, []string{"good.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: tt.files}
+			result, err := NewFormatRunner(tt.format).Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore {
+				t.Fatalf("result = %+v, want verdict %s, score %v", result, tt.wantVerdict, tt.wantScore)
+			}
+			if len(result.Evidence) != len(tt.wantEvidence) {
+				t.Fatalf("Evidence = %+v, want files %v", result.Evidence, tt.wantEvidence)
+			}
+			for i, file := range tt.wantEvidence {
+				if result.Evidence[i].File != file {
+					t.Errorf("Evidence[%d].File = %q, want %q", i, result.Evidence[i].File, file)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatRunner_Applicable(t *testing.T) {
+	f := NewFormatRunner(nil)
+	if ok, _ := f.Applicable(&InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"README.md"}}); ok {
+		t.Error("format runner should not apply without Go files")
+	}
+	if ok, _ := f.Applicable(&InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"calc_test.go"}}); !ok {
+		t.Error("format runner should apply to test files")
+	}
+}
@@ -0,0 +1,36 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+)
+
+// binGit is the git binary.
+const binGit = "git"
+
+// NewInspectInputFromGit builds the code inspect input for the changes
+// between baseRef and headRef in repoDir, the repository and module root:
+// the changes headRef made since it diverged from baseRef, as in
+// git diff baseRef...headRef. Diff holds the unified diff, ModifiedFiles
+// every file the diff adds, changes, or renames (deleted files have nothing
+// to inspect), and ModifiedPackages the packages containing the modified Go
+// files. Packages are resolved in the working tree, so repoDir should have
+// headRef checked out, as a stitch worktree does. When nothing changed, the
+// input has no diff, files, or packages.
+func NewInspectInputFromGit(ctx context.Context, repoDir, baseRef, headRef string) (*InspectInput, error) {
+	diff, err := runTool(ctx, repoDir, binGit, "diff", "--no-color", "--no-ext-diff", "-M", baseRef+"..."+headRef)
+	if err != nil {
+		return nil, fmt.Errorf("diffing %s...%s: %w", baseRef, headRef, err)
+	}
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: repoDir, Diff: diff}
+	for _, file := range ParseDiff(diff) {
+		if !file.IsDeleted() {
+			input.ModifiedFiles = append(input.ModifiedFiles, file.NewPath)
+		}
+	}
+	input.ModifiedPackages, err = PackagesForFiles(ctx, repoDir, input.ModifiedFiles)
+	if err != nil {
+		return nil, err
+	}
+	return input, nil
+}
@@ -0,0 +1,87 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// gitRepo runs each git command in dir, failing the test on error.
+func gitRepo(t *testing.T, dir string, commands ...[]string) {
+	t.Helper()
+	for _, args := range commands {
+		if _, err := runTool(context.Background(), dir, binGit, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+}
+
+func TestNewInspectInputFromGit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs git and go list")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod":       goModule,
+		"calc/calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"old/old.go":   "package old\n",
+		"README.md":    "# calc\n",
+	})
+	gitRepo(t, dir,
+		[]string{"init", "-q", "-b", "main"},
+		[]string{"config", "user.email", "inspect@example.com"},
+		[]string{"config", "user.name", "Inspect Test"},
+		[]string{"add", "-A"},
+		[]string{"commit", "-q", "-m", "initial"},
+		[]string{"checkout", "-q", "-b", "crumb"},
+	)
+
+	ctx := context.Background()
+	empty, err := NewInspectInputFromGit(ctx, dir, "main", "crumb")
+	if err != nil {
+		t.Fatalf("NewInspectInputFromGit failed: %v", err)
+	}
+	if empty.Diff != "" || len(empty.ModifiedFiles) != 0 || len(empty.ModifiedPackages) != 0 {
+		t.Errorf("no changes: input = %+v, want empty", empty)
+	}
+
+	for name, content := range map[string]string{
+		"calc/calc.go":     "package calc\n\nfunc Add(a, b int) int { return a - b }\n",
+		"calc/sub/sub.go":  "package sub\n",
+		"README.md":        "# calc\n\nAdds numbers.\n",
+		"docs/overview.md": "# Overview\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitRepo(t, dir,
+		[]string{"rm", "-q", "old/old.go"},
+		[]string{"add", "-A"},
+		[]string{"commit", "-q", "-m", "change calc"},
+	)
+
+	input, err := NewInspectInputFromGit(ctx, dir, "main", "crumb")
+	if err != nil {
+		t.Fatalf("NewInspectInputFromGit failed: %v", err)
+	}
+	if input.WorkType != WorkTypeCode || input.Dir != dir {
+		t.Errorf("WorkType = %q Dir = %q, want code in %s", input.WorkType, input.Dir, dir)
+	}
+	wantFiles := []string{"README.md", "calc/calc.go", "calc/sub/sub.go", "docs/overview.md"}
+	if !slices.Equal(input.ModifiedFiles, wantFiles) {
+		t.Errorf("ModifiedFiles = %v, want %v", input.ModifiedFiles, wantFiles)
+	}
+	wantPkgs := []string{"example.com/m/calc", "example.com/m/calc/sub"}
+	if !slices.Equal(input.ModifiedPackages, wantPkgs) {
+		t.Errorf("ModifiedPackages = %v, want %v", input.ModifiedPackages, wantPkgs)
+	}
+	if got := changedLines(input.Diff)["calc/calc.go"]; !got[3] {
+		t.Errorf("diff should change calc/calc.go line 3, changed lines %v", got)
+	}
+}
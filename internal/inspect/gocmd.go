@@ -0,0 +1,145 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// binGo is the Go toolchain binary.
+const binGo = "go"
+
+// goPackage is a package resolved by go list.
+type goPackage struct {
+	ImportPath string
+	Dir        string
+	Name       string
+	// GoFiles lists the package's non-test Go files, relative to Dir.
+	GoFiles []string
+}
+
+// listPackages resolves package patterns to their directories, names, and
+// source files by running go list in dir.
+func listPackages(ctx context.Context, dir string, patterns []string) ([]goPackage, error) {
+	args := append([]string{"list", "-f", `{{.ImportPath}}	{{.Dir}}	{{.Name}}	{{join .GoFiles ","}}`}, patterns...)
+	out, err := runGo(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []goPackage
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		pkg := goPackage{ImportPath: fields[0], Dir: fields[1], Name: fields[2]}
+		if fields[3] != "" {
+			pkg.GoFiles = strings.Split(fields[3], ",")
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// PackagesForFiles returns the sorted import paths of the packages that
+// contain the Go files, resolved by go list in dir, the module root. Files
+// are relative to dir or absolute. Files outside any package of the module,
+// such as those in deleted directories, testdata, or another module, are
+// ignored, as are non-Go files.
+func PackagesForFiles(ctx context.Context, dir string, files []string) ([]string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving packages: %w", err)
+	}
+	var patterns []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+		rel := file
+		if filepath.IsAbs(file) {
+			if rel, err = filepath.Rel(root, file); err != nil {
+				continue
+			}
+		}
+		pkgDir := filepath.ToSlash(filepath.Dir(rel))
+		if strings.HasPrefix(pkgDir, "..") || slices.Contains(strings.Split(pkgDir, "/"), "testdata") {
+			continue
+		}
+		pattern := "./" + pkgDir
+		if pkgDir == "." {
+			pattern = "."
+		}
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	// With -e, directories without a package are reported instead of
+	// failing the command; only those with Go files name a package.
+	args := append([]string{"list", "-e", "-f", "{{if or .GoFiles .CgoFiles .TestGoFiles .XTestGoFiles .InvalidGoFiles}}{{.ImportPath}}{{end}}"}, patterns...)
+	out, err := runGo(ctx, dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving packages: %w", err)
+	}
+	var pkgs []string
+	for _, line := range strings.Split(out, "\n") {
+		if pkg := strings.TrimSpace(line); pkg != "" && !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs, nil
+}
+
+// TestCommand runs a project's tests in dir and returns their combined
+// output; a non-zero exit, such as a failing test, is an error. args are the
+// go test flags the technique needs, such as -count=1 or -run, followed by
+// the package patterns, so a custom command must pass them on to go test.
+// Cancelling ctx must stop the tests.
+type TestCommand func(ctx context.Context, dir string, args []string) (string, error)
+
+// GoTest is the default TestCommand: go test with args.
+func GoTest(ctx context.Context, dir string, args []string) (string, error) {
+	return runGo(ctx, dir, append([]string{"test"}, args...)...)
+}
+
+// NewTestCommand returns a TestCommand that runs argv with the technique's
+// args appended, for example go test -tags integration, or a wrapper
+// script that passes its arguments on to go test. A relative program path
+// is resolved against dir. An empty argv returns GoTest.
+func NewTestCommand(argv []string) TestCommand {
+	if len(argv) == 0 {
+		return GoTest
+	}
+	argv = slices.Clone(argv)
+	return func(ctx context.Context, dir string, args []string) (string, error) {
+		return runTool(ctx, dir, argv[0], append(slices.Clip(argv[1:]), args...)...)
+	}
+}
+
+// runGo runs the go tool in dir and returns its combined output. A non-zero
+// exit wraps the output into the error. Cancelling ctx kills the process.
+func runGo(ctx context.Context, dir string, args ...string) (string, error) {
+	return runTool(ctx, dir, binGo, args...)
+}
+
+// runTool runs name with args in dir and returns its combined output, as
+// runGo does for the go tool.
+func runTool(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
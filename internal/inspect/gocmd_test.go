@@ -0,0 +1,76 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPackagesForFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod":                       goModule,
+		"main.go":                      "package main\n\nfunc main() {}\n",
+		"calc/calc.go":                 "package calc\n",
+		"calc/calc_test.go":            "package calc\n",
+		"calc/internal/deep/deep.go":   "package deep\n",
+		"calc/internal/deep/broken.go": "packag deep\n",
+		"only/only_test.go":            "package only_test\n",
+		"calc/testdata/gen.go":         "package gen\n",
+		"docs/README.md":               "# docs\n",
+		"docs/example.go.txt":          "package docs\n",
+	})
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"none", nil, nil},
+		{"root package", []string{"main.go"}, []string{"example.com/m"}},
+		{
+			"nested files share packages",
+			[]string{"calc/calc_test.go", "calc/internal/deep/deep.go", "calc/calc.go", "calc/internal/deep/broken.go"},
+			[]string{"example.com/m/calc", "example.com/m/calc/internal/deep"},
+		},
+		{"test-only package", []string{"only/only_test.go"}, []string{"example.com/m/only"}},
+		{"absolute path", []string{filepath.Join(dir, "calc", "calc.go")}, []string{"example.com/m/calc"}},
+		{
+			"outside any package",
+			[]string{"docs/README.md", "docs/gone.go", "removed/removed.go", "calc/testdata/gen.go", "../other/other.go", "calc/calc.go"},
+			[]string{"example.com/m/calc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PackagesForFiles(context.Background(), dir, tt.files)
+			if err != nil {
+				t.Fatalf("PackagesForFiles failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PackagesForFiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTestCommand(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"test.sh": "#!/bin/sh\necho \"$@\"\n[ \"$1\" != fail ]\n",
+	})
+	if err := os.Chmod(filepath.Join(dir, "test.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The script is found relative to the directory the tests run in.
+	test := NewTestCommand([]string{"./test.sh", "-tags", "integration"})
+	out, err := test(context.Background(), dir, []string{"-count=1", "./calc"})
+	if err != nil || out != "-tags integration -count=1 ./calc\n" {
+		t.Errorf("test command = %q, %v; want the configured flags, then the technique's", out, err)
+	}
+	if _, err := NewTestCommand([]string{"./test.sh", "fail"})(context.Background(), dir, nil); err == nil {
+		t.Error("failing test command returned no error")
+	}
+}
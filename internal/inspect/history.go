@@ -0,0 +1,37 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatProgression summarizes the composite score across attempts, for
+// example "0.42 -> 0.65 -> 0.85 (+0.43 over 3 attempts)".
+func FormatProgression(history []CompositeResult) string {
+	if len(history) == 0 {
+		return "no inspect attempts"
+	}
+	scores := make([]string, len(history))
+	for i, cr := range history {
+		scores[i] = fmt.Sprintf("%.2f", cr.Score)
+	}
+	delta := history[len(history)-1].Score - history[0].Score
+	return fmt.Sprintf("%s (%+.2f over %d attempts)", strings.Join(scores, " -> "), delta, len(history))
+}
+
+// WriteHistory prints one line per inspect attempt followed by the score
+// progression summary.
+func WriteHistory(w io.Writer, history []CompositeResult) error {
+	for i, cr := range history {
+		line := fmt.Sprintf("attempt %d: %.2f %s", i+1, cr.Score, cr.Action)
+		if i > 0 {
+			line += fmt.Sprintf(" (%+.2f)", cr.Score-history[i-1].Score)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, FormatProgression(history))
+	return err
+}
@@ -0,0 +1,34 @@
+package inspect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHistory(t *testing.T) {
+	history := []CompositeResult{
+		{Score: 0.5, Action: ActionMend},
+		{Score: 0.4, Action: ActionHumanReview},
+		{Score: 0.9, Action: ActionAccept},
+	}
+	var buf bytes.Buffer
+	if err := WriteHistory(&buf, history); err != nil {
+		t.Fatalf("WriteHistory failed: %v", err)
+	}
+	want := []string{
+		"attempt 1: 0.50 mend",
+		"attempt 2: 0.40 human_review (-0.10)",
+		"attempt 3: 0.90 accept (+0.50)",
+		"0.50 -> 0.40 -> 0.90 (+0.40 over 3 attempts)",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("WriteHistory output:\n%s\nwant:\n%s", buf.String(), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatProgression_Empty(t *testing.T) {
+	if got := FormatProgression(nil); got != "no inspect attempts" {
+		t.Errorf("FormatProgression(nil) = %q", got)
+	}
+}
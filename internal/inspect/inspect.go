@@ -0,0 +1,126 @@
+// Package inspect provides the verification portfolio used by the inspect command.
+// Implements: prd008-inspect-verification R1 (Technique Interface);
+//
+//	docs/ARCHITECTURE § Inspect.
+//
+// Each verification technique implements the Technique interface and returns a
+// typed TechniqueResult. Techniques target one fault class each; callers run
+// the applicable techniques and combine their results.
+package inspect
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// Work types that a stitch task may produce.
+const (
+	WorkTypeCode = "code"
+	WorkTypeDocs = "docs"
+)
+
+// Fault classes targeted by verification techniques (eng09-verification-adequacy).
+const (
+	FaultSpecConformance = "specification conformance errors"
+	FaultTestInadequacy  = "test suite inadequacy"
+)
+
+// Verdict is the outcome of a single technique run.
+type Verdict string
+
+// Verdict values.
+const (
+	VerdictPass Verdict = "pass"
+	VerdictFail Verdict = "fail"
+	VerdictSkip Verdict = "skip"
+)
+
+// InspectInput carries everything a technique needs to evaluate stitch output.
+type InspectInput struct {
+	// WorkType is the crumb work type (WorkTypeCode or WorkTypeDocs).
+	WorkType string
+	// Dir is the module root where files resolve and go commands run.
+	// Empty means the current directory.
+	Dir string
+	// ModifiedFiles lists files changed by stitch, relative to Dir.
+	ModifiedFiles []string
+	// ModifiedPackages lists Go import paths (or ./relative patterns) changed by stitch.
+	ModifiedPackages []string
+	// Diff is the unified diff produced by stitch.
+	Diff string
+	// PRDCriteria lists the acceptance criteria that drove the task.
+	PRDCriteria []string
+	// FixtureDir points at benchmark fixtures for differential testing.
+	FixtureDir string
+}
+
+// Evidence is a single finding that supports a technique verdict.
+type Evidence struct {
+	CriterionID string `json:"criterion_id,omitempty"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Detail      string `json:"detail"`
+	// Severity is SeverityError for evidence that fails the technique on
+	// its own; empty otherwise.
+	Severity string `json:"severity,omitempty"`
+}
+
+// FileScore is one file's share of a technique result, so reports can
+// point at the weakest files.
+type FileScore struct {
+	File string `json:"file"`
+	// Passed counts the checks in the file that passed, out of Total. For
+	// mutation testing the checks are mutants and killed ones pass.
+	Passed int `json:"passed"`
+	Total  int `json:"total"`
+}
+
+// TechniqueResult is the typed result returned by every technique.
+type TechniqueResult struct {
+	Technique     string     `json:"technique"`
+	Score         float64    `json:"score"`
+	Verdict       Verdict    `json:"verdict"`
+	Evidence      []Evidence `json:"evidence,omitempty"`
+	Deterministic bool       `json:"deterministic"`
+	// Files breaks the score down by file, for techniques that score
+	// per-file checks; their totals add up to the ones Score is taken from.
+	Files []FileScore `json:"files,omitempty"`
+	// Survivors lists the mutants the tests did not kill, for mutation
+	// testing, so mend and reports need not parse Evidence.
+	Survivors []SurvivingMutant `json:"survivors,omitempty"`
+}
+
+// Technique is a single verification technique in the inspect portfolio.
+type Technique interface {
+	// Name returns the technique identifier used in weights and reports.
+	Name() string
+	// FaultClass describes the class of faults the technique targets.
+	FaultClass() string
+	// Applicable reports whether the technique can run on input and, when it
+	// cannot, a short reason.
+	Applicable(input *InspectInput) (bool, string)
+	// Run evaluates input and returns a typed result. Run stops early and
+	// returns ctx.Err() when ctx is cancelled. A technique that parses the
+	// modified sources fails, scored 0, when one does not parse, with
+	// SeverityError evidence for each such file, rather than returning an
+	// error or skipping.
+	Run(ctx context.Context, input *InspectInput) (TechniqueResult, error)
+}
+
+// path resolves a modified file path against the input directory.
+func (in *InspectInput) path(file string) string {
+	if in.Dir == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(in.Dir, file)
+}
+
+// skipResult builds a skip verdict for a technique with an explanatory note.
+func skipResult(name string, deterministic bool, reason string) TechniqueResult {
+	return TechniqueResult{
+		Technique:     name,
+		Verdict:       VerdictSkip,
+		Evidence:      []Evidence{{Detail: reason}},
+		Deterministic: deterministic,
+	}
+}
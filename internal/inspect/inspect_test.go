@@ -0,0 +1,45 @@
+package inspect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files under a temporary directory and returns the directory.
+// Keys are slash-separated paths relative to the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestInspectInputPath(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		file string
+		want string
+	}{
+		{"no dir", "", "a/b.go", "a/b.go"},
+		{"relative", "/root", "a/b.go", filepath.Join("/root", "a/b.go")},
+		{"absolute", "/root", "/abs/b.go", "/abs/b.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &InspectInput{Dir: tt.dir}
+			if got := in.path(tt.file); got != tt.want {
+				t.Errorf("path(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
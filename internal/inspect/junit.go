@@ -0,0 +1,110 @@
+package inspect
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitSuiteName names the testsuite written by WriteJUnit.
+const junitSuiteName = "cobbler-inspect"
+
+// junitSuites is the root of a JUnit XML report.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is one testsuite element.
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+// junitProperty is a name/value pair attached to a testsuite.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitCase is one testcase element.
+type junitCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Skipped   *junitSkipped  `xml:"skipped"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+// junitFailure reports one finding of a failing technique.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped marks a skipped testcase.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes cr as JUnit XML. Each technique result becomes a
+// testcase: a failing technique carries one failure per evidence entry, a
+// skipped technique is marked skipped with its reason, and a passing
+// technique's evidence goes to system-out. The composite action, score,
+// and validity are testsuite properties.
+func WriteJUnit(w io.Writer, cr CompositeResult) error {
+	suite := junitSuite{
+		Name:  junitSuiteName,
+		Tests: len(cr.Results),
+		Properties: []junitProperty{
+			{Name: "action", Value: string(cr.Action)},
+			{Name: "score", Value: fmt.Sprintf("%.4f", cr.Score)},
+			{Name: "valid", Value: fmt.Sprint(cr.Valid)},
+		},
+	}
+	if cr.Reason != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "reason", Value: cr.Reason})
+	}
+
+	for _, r := range cr.Results {
+		tc := junitCase{Name: r.Technique, ClassName: junitSuiteName}
+		switch r.Verdict {
+		case VerdictFail:
+			suite.Failures++
+			for _, ev := range r.Evidence {
+				tc.Failures = append(tc.Failures, junitFailure{Message: formatEvidence(ev), Type: r.Technique, Text: ev.Detail})
+			}
+			if len(tc.Failures) == 0 {
+				msg := fmt.Sprintf("score %.2f", r.Score)
+				tc.Failures = append(tc.Failures, junitFailure{Message: msg, Type: r.Technique, Text: msg})
+			}
+		case VerdictSkip:
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{Message: skipReason(r)}
+		default:
+			lines := make([]string, len(r.Evidence))
+			for i, ev := range r.Evidence {
+				lines[i] = formatEvidence(ev)
+			}
+			tc.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return fmt.Errorf("encoding JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
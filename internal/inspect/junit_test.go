@@ -0,0 +1,63 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	cr := CompositeResult{
+		Score:  0.62,
+		Action: ActionMend,
+		Valid:  true,
+		Results: []TechniqueResult{
+			{Technique: MutationRunnerName, Score: 0.5, Verdict: VerdictFail, Deterministic: true,
+				Evidence: []Evidence{
+					{File: "calc.go", Line: 7, Detail: "arithmetic mutant survived"},
+					{File: "calc.go", Line: 9, Detail: "literal mutant survived"},
+				}},
+			{Technique: TranslationValidatorName, Score: 1, Verdict: VerdictPass, Deterministic: true},
+			skipResult(DifferentialTestingName, true, "no fixture directory"),
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, cr); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Suites) != 1 {
+		t.Fatalf("got %d testsuites, want 1", len(doc.Suites))
+	}
+	suite := doc.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("tests/failures/skipped = %d/%d/%d, want 3/1/1", suite.Tests, suite.Failures, suite.Skipped)
+	}
+	var action string
+	for _, p := range suite.Properties {
+		if p.Name == "action" {
+			action = p.Value
+		}
+	}
+	if action != string(ActionMend) {
+		t.Errorf("action property = %q, want %q", action, ActionMend)
+	}
+
+	cases := map[string]junitCase{}
+	for _, tc := range suite.Cases {
+		cases[tc.Name] = tc
+	}
+	if got := cases[MutationRunnerName].Failures; len(got) != 2 || got[0].Message != "calc.go:7: arithmetic mutant survived" {
+		t.Errorf("mutation failures = %+v", got)
+	}
+	if tc := cases[TranslationValidatorName]; len(tc.Failures) != 0 || tc.Skipped != nil {
+		t.Errorf("passing testcase = %+v, want no failure or skip", tc)
+	}
+	if tc := cases[DifferentialTestingName]; tc.Skipped == nil || tc.Skipped.Message != "no fixture directory" {
+		t.Errorf("skipped testcase = %+v", tc)
+	}
+}
@@ -0,0 +1,232 @@
+package inspect
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// GoroutineLeakCheckerName identifies the leak checker in weights and reports.
+const GoroutineLeakCheckerName = "goroutine_leak_checking"
+
+// FaultConcurrency is the fault class for races, leaks, and other concurrency bugs.
+const FaultConcurrency = "concurrency faults"
+
+// Leak checker instrumentation constants.
+const (
+	leakMainFile       = "cobbler_leak_main_test.go"
+	leakBeginMarker    = "COBBLER_GOROUTINE_LEAK_BEGIN"
+	leakEndMarker      = "COBBLER_GOROUTINE_LEAK_END"
+	leakSepMarker      = "COBBLER_GOROUTINE_LEAK_SEP"
+	leakSettleMillis   = 500
+	leakStackLineLimit = 8
+)
+
+//go:embed leakmain.go.tmpl
+var leakMainSource string
+
+var leakMainTemplate = template.Must(template.New(leakMainFile).Parse(leakMainSource))
+
+// leakMainData fills the injected TestMain template.
+type leakMainData struct {
+	Package      string
+	Begin        string
+	End          string
+	Sep          string
+	SettleMillis int
+}
+
+// GoroutineLeakChecker runs the modified packages' tests under a temporary
+// TestMain that snapshots goroutines before the tests and reports any still
+// running after a short settle window, goleak-style. Packages that define
+// their own TestMain are reported and left uninstrumented.
+type GoroutineLeakChecker struct {
+	runTests func(ctx context.Context, dir string) (string, error)
+}
+
+// NewGoroutineLeakChecker creates a GoroutineLeakChecker that runs go test.
+func NewGoroutineLeakChecker() *GoroutineLeakChecker {
+	return &GoroutineLeakChecker{runTests: func(ctx context.Context, dir string) (string, error) {
+		return runGo(ctx, dir, "test", "-count=1", ".")
+	}}
+}
+
+// Name returns the technique identifier.
+func (g *GoroutineLeakChecker) Name() string { return GoroutineLeakCheckerName }
+
+// FaultClass returns the fault class this technique targets.
+func (g *GoroutineLeakChecker) FaultClass() string { return FaultConcurrency }
+
+// Applicable reports whether the input is code work with modified packages.
+func (g *GoroutineLeakChecker) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run instruments and tests each modified package. The score is the fraction
+// of evaluated packages that leak no goroutines.
+func (g *GoroutineLeakChecker) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	pkgs, err := listPackages(ctx, input.Dir, input.ModifiedPackages)
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("listing packages: %w", err)
+	}
+
+	var evaluated, clean int
+	var evidence []Evidence
+	for _, pkg := range pkgs {
+		ev, ok, err := g.checkPackage(ctx, pkg)
+		if err != nil {
+			return TechniqueResult{}, err
+		}
+		evidence = append(evidence, ev...)
+		if !ok {
+			continue
+		}
+		evaluated++
+		if len(ev) == 0 {
+			clean++
+		}
+	}
+
+	if evaluated == 0 {
+		result := skipResult(g.Name(), true, "no packages could be evaluated for leaks")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	verdict := VerdictPass
+	if clean < evaluated {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     g.Name(),
+		Score:         float64(clean) / float64(evaluated),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// checkPackage injects the leak-detecting TestMain into pkg, runs its tests,
+// and returns leak evidence. evaluated is false when the package could not
+// be checked; the evidence then explains why.
+func (g *GoroutineLeakChecker) checkPackage(ctx context.Context, pkg goPackage) (evidence []Evidence, evaluated bool, err error) {
+	hasTests, hasMain, err := scanTestFiles(pkg.Dir)
+	if err != nil {
+		return nil, false, err
+	}
+	if !hasTests {
+		return nil, false, nil
+	}
+	if hasMain {
+		return []Evidence{{File: pkg.ImportPath, Detail: "package defines its own TestMain; not instrumented for leaks"}}, false, nil
+	}
+
+	mainPath := filepath.Join(pkg.Dir, leakMainFile)
+	if err := writeLeakMain(mainPath, pkg.Name); err != nil {
+		return nil, false, err
+	}
+	// Best-effort removal; a leftover file only affects the next leak check.
+	defer func() { _ = os.Remove(mainPath) }()
+
+	out, testErr := g.runTests(ctx, pkg.Dir)
+	if ctx.Err() != nil {
+		return nil, false, ctx.Err()
+	}
+	leaks := parseLeaks(out)
+	if len(leaks) == 0 && testErr != nil {
+		return []Evidence{{File: pkg.ImportPath, Detail: "tests failed; leaks not evaluated"}}, false, nil
+	}
+	for _, stack := range leaks {
+		evidence = append(evidence, Evidence{
+			File:   pkg.ImportPath,
+			Detail: "goroutine still running after tests:\n" + trimStack(stack),
+		})
+	}
+	return evidence, true, nil
+}
+
+// writeLeakMain renders the instrumented TestMain for package name to path.
+func writeLeakMain(path, name string) error {
+	var src strings.Builder
+	err := leakMainTemplate.Execute(&src, leakMainData{
+		Package:      name,
+		Begin:        leakBeginMarker,
+		End:          leakEndMarker,
+		Sep:          leakSepMarker,
+		SettleMillis: leakSettleMillis,
+	})
+	if err != nil {
+		return fmt.Errorf("rendering leak TestMain: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(src.String()), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// scanTestFiles reports whether dir has test files and whether any of them
+// declares TestMain.
+func scanTestFiles(dir string) (hasTests, hasMain bool, err error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+testFileSuffix))
+	if err != nil {
+		return false, false, err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if filepath.Base(file) == leakMainFile {
+			continue
+		}
+		hasTests = true
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return false, false, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "TestMain" {
+				hasMain = true
+			}
+		}
+	}
+	return hasTests, hasMain, nil
+}
+
+// parseLeaks extracts leaked goroutine stacks from instrumented test output.
+func parseLeaks(out string) []string {
+	start := strings.Index(out, leakBeginMarker)
+	end := strings.Index(out, leakEndMarker)
+	if start < 0 || end < start {
+		return nil
+	}
+	body := strings.TrimSpace(out[start+len(leakBeginMarker) : end])
+	if body == "" {
+		return nil
+	}
+	var stacks []string
+	for _, s := range strings.Split(body, leakSepMarker) {
+		if s = strings.TrimSpace(s); s != "" {
+			stacks = append(stacks, s)
+		}
+	}
+	return stacks
+}
+
+// trimStack keeps the first lines of a goroutine stack.
+func trimStack(stack string) string {
+	lines := strings.Split(stack, "\n")
+	if len(lines) <= leakStackLineLimit {
+		return stack
+	}
+	return strings.Join(lines[:leakStackLineLimit], "\n") + "\n..."
+}
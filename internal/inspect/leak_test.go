@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// goModule is the go.mod used by temporary test modules.
+const goModule = "module example.com/m\n\ngo 1.21\n"
+
+func TestGoroutineLeakChecker(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"clean/clean.go": `package clean
+
+func Sum(a, b int) int {
+	done := make(chan int)
+	go func() { done <- a + b }()
+	return <-done
+}
+`,
+		"clean/clean_test.go": `package clean
+
+import "testing"
+
+func TestSum(t *testing.T) {
+	if Sum(1, 2) != 3 {
+		t.Fatal("Sum(1, 2) != 3")
+	}
+}
+`,
+		"leaky/leaky.go": `package leaky
+
+func Start() {
+	block := make(chan struct{})
+	go func() { <-block }()
+}
+`,
+		"leaky/leaky_test.go": `package leaky
+
+import "testing"
+
+func TestStart(t *testing.T) {
+	Start()
+}
+`,
+	})
+
+	tests := []struct {
+		name        string
+		pkg         string
+		wantVerdict Verdict
+	}{
+		{"no leak passes", "./clean", VerdictPass},
+		{"leak fails", "./leaky", VerdictFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{tt.pkg}}
+			result, err := NewGoroutineLeakChecker().Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict {
+				t.Fatalf("Verdict = %q, want %q (evidence: %+v)", result.Verdict, tt.wantVerdict, result.Evidence)
+			}
+			if tt.wantVerdict == VerdictFail {
+				if len(result.Evidence) == 0 || !strings.Contains(result.Evidence[0].Detail, "leaky.Start") {
+					t.Errorf("Evidence = %+v, want the leaked stack", result.Evidence)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, tt.pkg, leakMainFile)); !os.IsNotExist(err) {
+				t.Error("injected TestMain was not removed")
+			}
+		})
+	}
+}
+
+func TestGoroutineLeakChecker_ExistingTestMain(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main_test.go": "package p\n\nimport (\n\t\"os\"\n\t\"testing\"\n)\n\nfunc TestMain(m *testing.M) { os.Exit(m.Run()) }\n",
+	})
+	hasTests, hasMain, err := scanTestFiles(dir)
+	if err != nil {
+		t.Fatalf("scanTestFiles failed: %v", err)
+	}
+	if !hasTests || !hasMain {
+		t.Errorf("scanTestFiles = (%v, %v), want (true, true)", hasTests, hasMain)
+	}
+}
+
+func TestParseLeaks(t *testing.T) {
+	out := "ok\n" + leakBeginMarker + "\ngoroutine 7 [chan receive]:\nmain.f()\n" + leakSepMarker +
+		"\ngoroutine 8 [select]:\nmain.g()\n" + leakEndMarker + "\nFAIL\n"
+	leaks := parseLeaks(out)
+	if len(leaks) != 2 || !strings.HasPrefix(leaks[1], "goroutine 8") {
+		t.Errorf("parseLeaks = %q, want two stacks", leaks)
+	}
+	if parseLeaks("ok\n") != nil {
+		t.Error("parseLeaks without markers should return nil")
+	}
+}
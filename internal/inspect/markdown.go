@@ -0,0 +1,141 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MarkdownRunnerName identifies the markdown runner in weights and reports.
+const MarkdownRunnerName = "markdown_structure"
+
+// markdownLink matches inline links and images, capturing the target.
+var markdownLink = regexp.MustCompile(`!?\[[^\]]*\]\(([^)\s]+)[^)]*\)`)
+
+// MarkdownRunner checks the structure of every modified markdown file: it
+// is not empty, has a heading, closes every code fence it opens, and links
+// only to relative paths that exist. The score is the fraction of files
+// without problems.
+type MarkdownRunner struct{}
+
+// NewMarkdownRunner creates a MarkdownRunner.
+func NewMarkdownRunner() *MarkdownRunner {
+	return &MarkdownRunner{}
+}
+
+// Name returns the technique identifier.
+func (m *MarkdownRunner) Name() string { return MarkdownRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (m *MarkdownRunner) FaultClass() string { return FaultMaintainability }
+
+// Applicable reports whether the input is docs work with modified markdown
+// files.
+func (m *MarkdownRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeDocs {
+		return false, "not a docs task"
+	}
+	if len(markdownFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified markdown files"
+	}
+	return true, ""
+}
+
+// Run checks each modified markdown file. Evidence names each problem
+// found.
+func (m *MarkdownRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	files := markdownFiles(input.ModifiedFiles)
+	var clean int
+	var evidence []Evidence
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return TechniqueResult{}, err
+		}
+		src, err := os.ReadFile(input.path(file))
+		if err != nil {
+			evidence = append(evidence, Evidence{File: file, Detail: fmt.Sprintf("cannot be read: %v", err)})
+			continue
+		}
+		problems := markdownProblems(file, filepath.Dir(input.path(file)), src)
+		if len(problems) == 0 {
+			clean++
+		}
+		evidence = append(evidence, problems...)
+	}
+
+	verdict := VerdictPass
+	if clean < len(files) {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     m.Name(),
+		Score:         float64(clean) / float64(len(files)),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// markdownProblems returns the structural problems of file, whose content is
+// src and whose relative links resolve against dir.
+func markdownProblems(file, dir string, src []byte) []Evidence {
+	if len(bytes.TrimSpace(src)) == 0 {
+		return []Evidence{{File: file, Detail: "file is empty"}}
+	}
+	var problems []Evidence
+	var heading bool
+	fence := 0
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "```") || strings.HasPrefix(text, "~~~") {
+			if fence == 0 {
+				fence = line
+			} else {
+				fence = 0
+			}
+			continue
+		}
+		if fence != 0 {
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			heading = true
+		}
+		for _, match := range markdownLink.FindAllStringSubmatch(text, -1) {
+			target := match[1]
+			if i := strings.IndexByte(target, '#'); i >= 0 {
+				target = target[:i]
+			}
+			if target == "" || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") || filepath.IsAbs(target) {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, target)); err != nil {
+				problems = append(problems, Evidence{File: file, Line: line, Detail: fmt.Sprintf("link target %s does not exist", target)})
+			}
+		}
+	}
+	if fence != 0 {
+		problems = append(problems, Evidence{File: file, Line: fence, Detail: "code fence is never closed"})
+	}
+	if !heading {
+		problems = append(problems, Evidence{File: file, Detail: "no heading"})
+	}
+	return problems
+}
+
+// markdownFiles filters paths down to markdown files.
+func markdownFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if strings.HasSuffix(f, ".md") {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package inspect
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMarkdownRunner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"good.md":     "# Parser\n\nSee [the guide](guide.md#errors) and [Go](https://go.dev).\n\n```go\n[not](a-link.md)\n```\n",
+		"guide.md":    "# Guide\n",
+		"empty.md":    " \n",
+		"nohead.md":   "Just text.\n",
+		"fence.md":    "# Fence\n\n```\nnever closed\n",
+		"badlink.md":  "# Links\n\n![diagram](missing.png)\n",
+		"calc.go":     "package calc\n",
+		"sub/deep.md": "# Deep\n\n[up](../guide.md)\n",
+	})
+
+	tests := []struct {
+		name         string
+		files        []string
+		wantVerdict  Verdict
+		wantScore    float64
+		wantEvidence []string
+	}{
+		{"well-formed files pass", []string{"good.md", "guide.md", "sub/deep.md", "calc.go"}, VerdictPass, 1, nil},
+		{"empty file fails", []string{"good.md", "empty.md"}, VerdictFail, 0.5, []string{"empty.md"}},
+		{"file without heading fails", []string{"nohead.md"}, VerdictFail, 0, []string{"nohead.md"}},
+		{"unclosed fence fails", []string{"fence.md"}, VerdictFail, 0, []string{"fence.md"}},
+		{"missing link target fails", []string{"badlink.md", "guide.md"}, VerdictFail, 0.5, []string{"badlink.md"}},
+		{"missing file fails", []string{"gone.md"}, VerdictFail, 0, []string{"gone.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &InspectInput{WorkType: WorkTypeDocs, Dir: dir, ModifiedFiles: tt.files}
+			result, err := NewMarkdownRunner().Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore {
+				t.Fatalf("result = %+v, want verdict %s, score %v", result, tt.wantVerdict, tt.wantScore)
+			}
+			if len(result.Evidence) != len(tt.wantEvidence) {
+				t.Fatalf("Evidence = %+v, want files %v", result.Evidence, tt.wantEvidence)
+			}
+			for i, file := range tt.wantEvidence {
+				if result.Evidence[i].File != file {
+					t.Errorf("Evidence[%d].File = %q, want %q", i, result.Evidence[i].File, file)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownRunner_Applicable(t *testing.T) {
+	m := NewMarkdownRunner()
+	if ok, _ := m.Applicable(&InspectInput{WorkType: WorkTypeCode, ModifiedFiles: []string{"README.md"}}); ok {
+		t.Error("markdown runner should not apply to code work")
+	}
+	if ok, _ := m.Applicable(&InspectInput{WorkType: WorkTypeDocs, ModifiedFiles: []string{"notes.txt"}}); ok {
+		t.Error("markdown runner should not apply without markdown files")
+	}
+	if ok, _ := m.Applicable(&InspectInput{WorkType: WorkTypeDocs, ModifiedFiles: []string{"docs/guide.md"}}); !ok {
+		t.Error("markdown runner should apply to docs work with markdown files")
+	}
+}
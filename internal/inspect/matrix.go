@@ -0,0 +1,179 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// Project layout conventions used to detect project characteristics.
+const (
+	DefaultFixtureDir = "benchmarks"
+	DefaultPRDDir     = "docs/specs/product-requirements"
+)
+
+// representativeCriterion stands in for PRD criteria in representative inputs.
+const representativeCriterion = "representative acceptance criterion"
+
+// skippedDirs are never searched when detecting project characteristics.
+var skippedDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true}
+
+// WorkTypes lists every work type a technique may be evaluated against.
+var WorkTypes = []string{WorkTypeCode, WorkTypeDocs}
+
+// ProjectProfile captures the project characteristics that drive technique
+// applicability.
+type ProjectProfile struct {
+	HasCode     bool
+	HasTests    bool
+	HasFixtures bool
+	HasPRDs     bool
+}
+
+// MatrixRow is one technique/work type cell of the applicability matrix.
+type MatrixRow struct {
+	Technique  string
+	WorkType   string
+	Applicable bool
+	Reason     string
+}
+
+// DefaultTechniques returns the techniques registered by default.
+func DefaultTechniques() []Technique {
+	return []Technique{
+		NewTranslationValidator(nil),
+		NewAssertionChecker(),
+		NewMutationRunner(DefaultMutationConfig()),
+		NewDifferentialTesting(),
+		NewCoverageRunner(DefaultCoverageConfig()),
+		NewPropertyBasedRunner(DefaultPropertyConfig()),
+		NewGoroutineLeakChecker(),
+		NewRaceRunner(),
+		NewContextPropagationChecker(),
+		NewVulnRunner(),
+		NewComplexityRunner(DefaultComplexityConfig()),
+		NewDocRunner(),
+		NewFormatRunner(nil),
+		NewMarkdownRunner(),
+	}
+}
+
+// DetectProfile inspects the project rooted at dir.
+func DetectProfile(dir string) (ProjectProfile, error) {
+	var profile ProjectProfile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), testFileSuffix) {
+			profile.HasTests = true
+		} else if filepath.Ext(d.Name()) == ".go" {
+			profile.HasCode = true
+		}
+		return nil
+	})
+	if err != nil {
+		return ProjectProfile{}, fmt.Errorf("scanning %s: %w", dir, err)
+	}
+
+	if profile.HasFixtures, err = dirHasEntries(filepath.Join(dir, DefaultFixtureDir)); err != nil {
+		return ProjectProfile{}, err
+	}
+	if profile.HasPRDs, err = dirHasEntries(filepath.Join(dir, filepath.FromSlash(DefaultPRDDir))); err != nil {
+		return ProjectProfile{}, err
+	}
+	return profile, nil
+}
+
+// dirHasEntries reports whether dir exists and is non-empty.
+func dirHasEntries(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	return len(entries) > 0, nil
+}
+
+// RepresentativeInput builds the input a typical task of workType would
+// produce in a project with the given profile.
+func RepresentativeInput(profile ProjectProfile, workType string) *InspectInput {
+	input := &InspectInput{WorkType: workType}
+	switch workType {
+	case WorkTypeCode:
+		if profile.HasCode {
+			input.ModifiedFiles = append(input.ModifiedFiles, "example.go")
+			input.ModifiedPackages = []string{"./..."}
+		}
+		if profile.HasTests {
+			input.ModifiedFiles = append(input.ModifiedFiles, "example"+testFileSuffix)
+		}
+	case WorkTypeDocs:
+		input.ModifiedFiles = []string{"README.md"}
+	}
+	if profile.HasFixtures {
+		input.FixtureDir = DefaultFixtureDir
+	}
+	if profile.HasPRDs {
+		input.PRDCriteria = []string{representativeCriterion}
+	}
+	return input
+}
+
+// ApplicabilityMatrix evaluates each technique against a representative input
+// for every work type.
+func ApplicabilityMatrix(techniques []Technique, profile ProjectProfile) []MatrixRow {
+	rows := make([]MatrixRow, 0, len(techniques)*len(WorkTypes))
+	for _, tech := range techniques {
+		for _, workType := range WorkTypes {
+			ok, reason := tech.Applicable(RepresentativeInput(profile, workType))
+			rows = append(rows, MatrixRow{
+				Technique:  tech.Name(),
+				WorkType:   workType,
+				Applicable: ok,
+				Reason:     reason,
+			})
+		}
+	}
+	return rows
+}
+
+// WriteMatrix prints the matrix as a table, one row per technique with one
+// column per work type.
+func WriteMatrix(w io.Writer, rows []MatrixRow) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "TECHNIQUE\t%s\n", strings.ToUpper(strings.Join(WorkTypes, "\t")))
+
+	var current string
+	for _, row := range rows {
+		if row.Technique != current {
+			if current != "" {
+				fmt.Fprintln(tw)
+			}
+			current = row.Technique
+			fmt.Fprint(tw, current)
+		}
+		cell := "applicable"
+		if !row.Applicable {
+			cell = "no: " + row.Reason
+		}
+		fmt.Fprintf(tw, "\t%s", cell)
+	}
+	if current != "" {
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,91 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// fixtureTechnique is a fake technique that needs benchmark fixtures.
+type fixtureTechnique struct{}
+
+func (fixtureTechnique) Name() string       { return "fixture_needing" }
+func (fixtureTechnique) FaultClass() string { return "behavioral divergence from reference" }
+func (fixtureTechnique) Applicable(input *InspectInput) (bool, string) {
+	if input.FixtureDir == "" {
+		return false, "no fixtures"
+	}
+	return true, ""
+}
+func (fixtureTechnique) Run(context.Context, *InspectInput) (TechniqueResult, error) {
+	return TechniqueResult{}, nil
+}
+
+func TestDetectProfile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"calc.go":        "package calc\n",
+		"calc_test.go":   "package calc\n",
+		".git/x_test.go": "ignored",
+	})
+	profile, err := DetectProfile(dir)
+	if err != nil {
+		t.Fatalf("DetectProfile failed: %v", err)
+	}
+	want := ProjectProfile{HasCode: true, HasTests: true}
+	if profile != want {
+		t.Errorf("profile = %+v, want %+v", profile, want)
+	}
+
+	dir = writeFiles(t, map[string]string{
+		"benchmarks/hello/VISION.md":                  "x",
+		"docs/specs/product-requirements/prd001.yaml": "x",
+	})
+	profile, err = DetectProfile(dir)
+	if err != nil {
+		t.Fatalf("DetectProfile failed: %v", err)
+	}
+	want = ProjectProfile{HasFixtures: true, HasPRDs: true}
+	if profile != want {
+		t.Errorf("profile = %+v, want %+v", profile, want)
+	}
+}
+
+func TestApplicabilityMatrix_CodeWithoutFixtures(t *testing.T) {
+	profile := ProjectProfile{HasCode: true, HasTests: true}
+	rows := ApplicabilityMatrix([]Technique{NewAssertionChecker(), fixtureTechnique{}}, profile)
+
+	want := map[[2]string]bool{
+		{AssertionCheckerName, WorkTypeCode}: true,
+		{AssertionCheckerName, WorkTypeDocs}: false,
+		{"fixture_needing", WorkTypeCode}:    false,
+		{"fixture_needing", WorkTypeDocs}:    false,
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("matrix has %d rows, want %d", len(rows), len(want))
+	}
+	for _, row := range rows {
+		key := [2]string{row.Technique, row.WorkType}
+		if row.Applicable != want[key] {
+			t.Errorf("%s/%s Applicable = %v, want %v", row.Technique, row.WorkType, row.Applicable, want[key])
+		}
+		if !row.Applicable && row.Reason == "" {
+			t.Errorf("%s/%s not applicable without a reason", row.Technique, row.WorkType)
+		}
+	}
+}
+
+func TestWriteMatrix(t *testing.T) {
+	rows := ApplicabilityMatrix([]Technique{fixtureTechnique{}}, ProjectProfile{HasCode: true})
+	var buf bytes.Buffer
+	if err := WriteMatrix(&buf, rows); err != nil {
+		t.Fatalf("WriteMatrix failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("output has %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "TECHNIQUE") || !strings.Contains(lines[1], "no: no fixtures") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,1103 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default mutation settings.
+const (
+	DefaultMaxMutants      = 50
+	DefaultMutationWorkers = 4
+)
+
+// mutationWorkspacePattern names the per-worker module copies.
+const mutationWorkspacePattern = "cobbler-mutation-*"
+
+// go test output markers.
+const (
+	buildFailedMarker = "[build failed]"
+	testFailPrefix    = "--- FAIL: "
+)
+
+// testDuration matches the timings in go test output, which differ from
+// run to run.
+var testDuration = regexp.MustCompile(`[ \t]+\(?\d+(\.\d+)?s\)?`)
+
+// mutantPrinter renders mutated files with gofmt's settings.
+var mutantPrinter = printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+// MutationType classifies the fault a mutant injects.
+type MutationType string
+
+// MutationType values.
+const (
+	MutationArithmetic        MutationType = "arithmetic"
+	MutationConditional       MutationType = "conditional_boundary"
+	MutationNegateConditional MutationType = "negate_conditional"
+	MutationLogical           MutationType = "logical"
+	MutationStatementDelete   MutationType = "statement_delete"
+	MutationLiteral           MutationType = "literal"
+	MutationIncDec            MutationType = "increment_decrement"
+	MutationSwitchCase        MutationType = "switch_case"
+)
+
+// AllMutationTypes lists every mutation type, in documentation order.
+var AllMutationTypes = []MutationType{
+	MutationArithmetic,
+	MutationConditional,
+	MutationNegateConditional,
+	MutationLogical,
+	MutationStatementDelete,
+	MutationLiteral,
+	MutationIncDec,
+	MutationSwitchCase,
+}
+
+// boolFlips maps each boolean constant to its negation.
+var boolFlips = map[string]string{"true": "false", "false": "true"}
+
+// operatorMutations maps each mutated binary operator to its replacement.
+var operatorMutations = map[token.Token]struct {
+	to  token.Token
+	typ MutationType
+}{
+	token.ADD:  {token.SUB, MutationArithmetic},
+	token.SUB:  {token.ADD, MutationArithmetic},
+	token.MUL:  {token.QUO, MutationArithmetic},
+	token.QUO:  {token.MUL, MutationArithmetic},
+	token.LSS:  {token.LEQ, MutationConditional},
+	token.LEQ:  {token.LSS, MutationConditional},
+	token.GTR:  {token.GEQ, MutationConditional},
+	token.GEQ:  {token.GTR, MutationConditional},
+	token.EQL:  {token.NEQ, MutationNegateConditional},
+	token.NEQ:  {token.EQL, MutationNegateConditional},
+	token.LAND: {token.LOR, MutationLogical},
+	token.LOR:  {token.LAND, MutationLogical},
+}
+
+// incDecFlips maps each increment or decrement to its opposite.
+var incDecFlips = map[token.Token]token.Token{token.INC: token.DEC, token.DEC: token.INC}
+
+// assignMutations maps each mutated compound assignment operator to its
+// replacement. They are arithmetic operator replacements.
+var assignMutations = map[token.Token]token.Token{
+	token.ADD_ASSIGN: token.SUB_ASSIGN,
+	token.SUB_ASSIGN: token.ADD_ASSIGN,
+	token.MUL_ASSIGN: token.QUO_ASSIGN,
+	token.QUO_ASSIGN: token.MUL_ASSIGN,
+}
+
+// Mutant is a single injected fault.
+type Mutant struct {
+	// File is the mutated file, relative to InspectInput.Dir.
+	File string
+	// Line is the 1-based line of the mutation.
+	Line int
+	// Type classifies the mutation.
+	Type MutationType
+	// Original is the source text replaced by the mutation.
+	Original string
+	// Mutated is the replacement text; empty for statement deletion.
+	Mutated string
+	// Killed is true when at least one test failed against the mutant.
+	Killed bool
+	// KillingTest names the test that killed the mutant, when known.
+	KillingTest string
+	// Equivalent is true when the mutant was judged semantically equivalent
+	// to the original code. Equivalent mutants cannot be killed, so they are
+	// excluded from the score. See MutationConfig.EquivalentByOutput.
+	Equivalent bool
+
+	// pos locates the mutated node in the file as parsed by findMutationSites.
+	pos token.Pos
+	// scope identifies the mutation within its enclosing function, so the
+	// cached result stays valid while that function is byte-identical.
+	scope string
+}
+
+// SurvivingMutant is a mutant the tests did not kill, as recorded in
+// TechniqueResult.Survivors.
+type SurvivingMutant struct {
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Type     MutationType `json:"type"`
+	Original string       `json:"original"`
+	// Mutated is empty for statement deletion.
+	Mutated string `json:"mutated,omitempty"`
+}
+
+// evidence returns the evidence mutation testing records for s.
+func (s SurvivingMutant) evidence() Evidence {
+	return mutantEvidence(Mutant{File: s.File, Line: s.Line, Type: s.Type, Original: s.Original, Mutated: s.Mutated})
+}
+
+// survivor returns m as a SurvivingMutant.
+func (m Mutant) survivor() SurvivingMutant {
+	return SurvivingMutant{File: m.File, Line: m.Line, Type: m.Type, Original: m.Original, Mutated: m.Mutated}
+}
+
+// MutationConfig controls the MutationRunner.
+type MutationConfig struct {
+	// MaxMutants bounds the number of mutants tested. When there are more
+	// mutation sites, a random sample of MaxMutants chosen with SampleSeed
+	// is tested and the score estimates that of the full set. Zero means no
+	// limit.
+	MaxMutants int
+	// SampleSeed seeds the choice of sampled mutants, so re-runs on
+	// unchanged code test the same subset.
+	SampleSeed uint64
+	// Workers is the number of mutants evaluated in parallel, each in its
+	// own copy of the module. A worker's copy holds only its own mutant, so
+	// mutants of different files, and of the same file, run side by side
+	// without serializing on the file. Values below one mean one worker.
+	Workers int
+	// CacheDir holds the mutation result cache. Empty disables caching.
+	CacheDir string
+	// NoCache forces every mutant to be tested; fresh results still
+	// refresh the cache.
+	NoCache bool
+	// ChangedLinesOnly restricts mutation to lines added or changed by
+	// InspectInput.Diff, so the score reflects the tests of the new code.
+	// Without a diff, every line is mutated.
+	ChangedLinesOnly bool
+	// EnabledOperators limits mutation to the listed types. Empty means
+	// every type in AllMutationTypes.
+	EnabledOperators []MutationType
+	// EquivalentByOutput marks a surviving mutant equivalent when its
+	// verbose test output, timings removed, is byte-identical to that of an
+	// unmutated baseline run. It is off by default: the signal is only as
+	// strong as what the tests log, and in a package whose tests log
+	// nothing every surviving mutant matches the baseline.
+	//
+	// Regardless of this setting, mutants matching known-equivalent
+	// patterns (x+0 vs x-0, x*1 vs x/1, a&&a vs a||a) are marked equivalent
+	// without being tested. Both checks are heuristics, not proofs: they
+	// can miss equivalent mutants and, rarely, excuse a real one.
+	EquivalentByOutput bool
+	// TestCommand runs the tests of each mutant, of the unmutated baseline,
+	// and of the coverage that CoveredTestsOnly selects with. Nil runs go
+	// test. The cache does not record the command, so results cached under
+	// another command are reused unless NoCache is set.
+	TestCommand TestCommand
+	// CoveredTestsOnly runs, for each mutant, only the tests that cover the
+	// mutated line instead of the package's whole suite. The mapping comes
+	// from running each test once on its own with a coverage profile against
+	// the unmutated module, which costs one extra go test per test and pays
+	// off when there are many mutants. A mutant on a line no test covers is
+	// only compiled and counts as survived. Packages whose tests cannot be
+	// listed or measured fall back to running every test.
+	//
+	// Results stay deterministic as long as the tests are: the selection is
+	// fixed by the unmutated coverage, and only function bodies are mutated,
+	// so a test that never reaches the mutated line cannot observe it. Tests
+	// whose coverage depends on timing or order may select differently
+	// between runs, and KillingTest may name a different test than a full
+	// run would. Selection disables EquivalentByOutput for the mutants it
+	// applies to, since a subset of tests cannot match the full baseline.
+	CoveredTestsOnly bool
+}
+
+// DefaultMutationConfig returns the default mutation settings.
+func DefaultMutationConfig() MutationConfig {
+	return MutationConfig{MaxMutants: DefaultMaxMutants, Workers: DefaultMutationWorkers}
+}
+
+// MutationRunner injects small faults into modified source files and runs
+// the tests of the owning package against each. A mutant that no test
+// catches reveals a gap in the test suite. Mutants are evaluated in
+// throwaway copies of the module, so the working tree is never modified.
+type MutationRunner struct {
+	config MutationConfig
+	// runTests runs the test command in dir with args, flags followed by
+	// packages.
+	runTests func(ctx context.Context, dir string, args []string) (string, error)
+	// coverage maps the lines of pkg's files to the tests covering them.
+	coverage func(ctx context.Context, dir, pkg string) (lineTests, error)
+}
+
+// NewMutationRunner creates a MutationRunner that runs the configured test
+// command, go test by default.
+func NewMutationRunner(config MutationConfig) *MutationRunner {
+	m := &MutationRunner{config: config}
+	m.useTestCommand(config.TestCommand)
+	return m
+}
+
+// useTestCommand makes the runner run its tests with test; nil runs go
+// test.
+func (m *MutationRunner) useTestCommand(test TestCommand) {
+	if test == nil {
+		test = GoTest
+	}
+	m.config.TestCommand = test
+	m.runTests = func(ctx context.Context, dir string, testArgs []string) (string, error) {
+		args := []string{"-count=1"}
+		if m.config.EquivalentByOutput {
+			args = append(args, "-v")
+		}
+		return test(ctx, dir, append(args, testArgs...))
+	}
+	m.coverage = func(ctx context.Context, dir, pkg string) (lineTests, error) {
+		return coverageByTest(ctx, test, dir, pkg)
+	}
+}
+
+// cacheConfig returns the settings that change which mutants are tested
+// and how, for the result cache. The test command is keyed by the
+// portfolio that sets it.
+func (m *MutationRunner) cacheConfig() (any, bool) {
+	return struct {
+		MaxMutants         int
+		SampleSeed         uint64
+		ChangedLinesOnly   bool
+		EnabledOperators   []MutationType
+		EquivalentByOutput bool
+		CoveredTestsOnly   bool
+	}{
+		m.config.MaxMutants,
+		m.config.SampleSeed,
+		m.config.ChangedLinesOnly,
+		m.config.EnabledOperators,
+		m.config.EquivalentByOutput,
+		m.config.CoveredTestsOnly,
+	}, true
+}
+
+// Name returns the technique identifier.
+func (m *MutationRunner) Name() string { return MutationRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (m *MutationRunner) FaultClass() string { return FaultTestInadequacy }
+
+// Applicable reports whether the input is code work with modified Go sources.
+func (m *MutationRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(sourceFiles(input.ModifiedFiles)) == 0 {
+		return false, "no modified Go source files"
+	}
+	return true, ""
+}
+
+// Run tests the mutants Plan finds in the modified source files, in
+// parallel across the configured workers. Generated files are excluded. The score is the
+// fraction of applied mutants killed by the tests. Mutants that do not apply,
+// do not compile, or are judged equivalent are not counted. Results do not depend on the worker
+// count. Cancelling ctx stops the run without caching partial results.
+func (m *MutationRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	plan, err := m.Plan(input)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	if len(plan.Unparseable) > 0 {
+		return unparseableResult(m.Name(), true, plan.Unparseable, plan.Notes), nil
+	}
+	evidence := plan.Notes
+	mutants := plan.Mutants
+	population := len(mutants)
+	if plan.Tested < population {
+		evidence = append(evidence, Evidence{Detail: fmt.Sprintf("sampled %d of %d mutants (seed %d)", plan.Tested, population, m.config.SampleSeed)})
+		mutants = sampleMutants(mutants, plan.Tested, m.config.SampleSeed)
+	}
+
+	cache, err := m.loadCache(input, mutants)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	applied, notes, err := m.evaluate(ctx, input, mutants, cache)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	evidence = append(evidence, notes...)
+	if err := m.saveCache(cache, mutants, applied); err != nil {
+		return TechniqueResult{}, err
+	}
+
+	var tested, killed int
+	var files []FileScore
+	var survivors []SurvivingMutant
+	for i, mutant := range mutants {
+		if mutant.Equivalent {
+			evidence = append(evidence, mutantEvidence(mutant))
+			continue
+		}
+		if !applied[i] {
+			continue
+		}
+		j := slices.IndexFunc(files, func(f FileScore) bool { return f.File == mutant.File })
+		if j < 0 {
+			files = append(files, FileScore{File: mutant.File})
+			j = len(files) - 1
+		}
+		tested++
+		files[j].Total++
+		if mutant.Killed {
+			killed++
+			files[j].Passed++
+		} else {
+			survivors = append(survivors, mutant.survivor())
+		}
+		evidence = append(evidence, mutantEvidence(mutant))
+	}
+
+	if tested == 0 {
+		result := skipResult(m.Name(), true, "no mutants could be applied")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	score := float64(killed) / float64(tested)
+	if len(mutants) < population {
+		evidence = append(evidence, Evidence{Detail: fmt.Sprintf("score estimated from a sample: %.2f ± %.2f at 95%% confidence", score, sampleMargin(score, tested, population))})
+	}
+	verdict := VerdictPass
+	if killed < tested {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     m.Name(),
+		Score:         score,
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+		Files:         files,
+		Survivors:     survivors,
+	}, nil
+}
+
+// sampleMutants returns n of mutants chosen uniformly at random from seed,
+// in their original order. The same mutants and seed give the same sample.
+func sampleMutants(mutants []Mutant, n int, seed uint64) []Mutant {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	picked := rng.Perm(len(mutants))[:n]
+	slices.Sort(picked)
+	sample := make([]Mutant, n)
+	for i, j := range picked {
+		sample[i] = mutants[j]
+	}
+	return sample
+}
+
+// sampleMargin returns the half-width of the 95% confidence interval of a
+// score measured on n of population mutants, using the normal approximation
+// with a finite population correction. It is a rough guide: mutants of one
+// function are not independent, and scores of 0 or 1 report no margin.
+func sampleMargin(score float64, n, population int) float64 {
+	if n >= population || population < 2 {
+		return 0
+	}
+	correction := float64(population-n) / float64(population-1)
+	return 1.96 * math.Sqrt(score*(1-score)/float64(n)*correction)
+}
+
+// mutantEvidence describes a tested mutant and, when it was killed, the test
+// that caught it.
+func mutantEvidence(mutant Mutant) Evidence {
+	change := fmt.Sprintf("%q -> %q", mutant.Original, mutant.Mutated)
+	if mutant.Type == MutationStatementDelete {
+		change = fmt.Sprintf("deleted %q", mutant.Original)
+	}
+	outcome := "survived"
+	switch {
+	case mutant.Equivalent:
+		outcome = "judged equivalent, excluded from score"
+	case mutant.Killed:
+		outcome = "killed"
+		if mutant.KillingTest != "" {
+			outcome += " by " + mutant.KillingTest
+		}
+	}
+	return Evidence{
+		File:   mutant.File,
+		Line:   mutant.Line,
+		Detail: fmt.Sprintf("%s mutant %s: %s", mutant.Type, outcome, change),
+	}
+}
+
+// findMutationSites parses path and returns one mutant per mutable binary
+// or compound assignment operator, increment or decrement, literal,
+// switch case, and deletable statement inside function bodies, in source
+// order. Only types in enabled are generated; empty enables all.
+// Each mutant is keyed by the token.Pos of its node; parsing the same bytes
+// into a fresh FileSet reproduces those positions when the mutant is applied.
+func findMutationSites(path string, enabled []MutationType) ([]Mutant, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	fset, f, err := parseMutationFile(path, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutants []Mutant
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		first := len(mutants)
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if original, pos, replacements := literalMutations(n); len(replacements) > 0 {
+				for _, mutated := range replacements {
+					mutants = append(mutants, Mutant{
+						Line:     fset.Position(pos).Line,
+						Type:     MutationLiteral,
+						Original: original,
+						Mutated:  mutated,
+						pos:      pos,
+					})
+				}
+			}
+			if bin, ok := n.(*ast.BinaryExpr); ok {
+				if op, ok := operatorMutations[bin.Op]; ok {
+					mutants = append(mutants, Mutant{
+						Line:       fset.Position(bin.OpPos).Line,
+						Type:       op.typ,
+						Original:   bin.Op.String(),
+						Mutated:    op.to.String(),
+						Equivalent: equivalentOperator(bin),
+						pos:        bin.OpPos,
+					})
+				}
+			}
+			if assign, ok := n.(*ast.AssignStmt); ok {
+				if to, ok := assignMutations[assign.Tok]; ok {
+					mutants = append(mutants, Mutant{
+						Line:     fset.Position(assign.TokPos).Line,
+						Type:     MutationArithmetic,
+						Original: assign.Tok.String(),
+						Mutated:  to.String(),
+						pos:      assign.TokPos,
+					})
+				}
+			}
+			if incDec, ok := n.(*ast.IncDecStmt); ok {
+				mutants = append(mutants, Mutant{
+					Line:     fset.Position(incDec.TokPos).Line,
+					Type:     MutationIncDec,
+					Original: incDec.Tok.String(),
+					Mutated:  incDecFlips[incDec.Tok].String(),
+					pos:      incDec.TokPos,
+				})
+			}
+			mutants = append(mutants, switchMutations(fset, src, n)...)
+			list := stmtList(n)
+			if list == nil {
+				return true
+			}
+			for _, stmt := range *list {
+				if !deletable(stmt) {
+					continue
+				}
+				start, end := fset.Position(stmt.Pos()), fset.Position(stmt.End())
+				mutants = append(mutants, Mutant{
+					Line:     start.Line,
+					Type:     MutationStatementDelete,
+					Original: string(src[start.Offset:end.Offset]),
+					pos:      stmt.Pos(),
+				})
+			}
+			return true
+		})
+		for i := first; i < len(mutants); i++ {
+			mutants[i].scope = mutantScope(fset, src, fn, mutants[i])
+		}
+	}
+	if len(enabled) > 0 {
+		mutants = slices.DeleteFunc(mutants, func(m Mutant) bool { return !slices.Contains(enabled, m.Type) })
+	}
+	sort.SliceStable(mutants, func(i, j int) bool { return mutants[i].pos < mutants[j].pos })
+	return mutants, nil
+}
+
+// switchMutations returns the switch case mutants of n when it is a switch
+// or type switch: each case body swapped with the next case's, unless the
+// two bodies are identical, and, in a switch without a tag, each case
+// expression negated.
+func switchMutations(fset *token.FileSet, src []byte, n ast.Node) []Mutant {
+	var body *ast.BlockStmt
+	tagless := false
+	switch node := n.(type) {
+	case *ast.SwitchStmt:
+		body, tagless = node.Body, node.Tag == nil
+	case *ast.TypeSwitchStmt:
+		body = node.Body
+	default:
+		return nil
+	}
+
+	var mutants []Mutant
+	clauses := body.List
+	for i, stmt := range clauses {
+		clause := stmt.(*ast.CaseClause)
+		if tagless {
+			for _, expr := range clause.List {
+				mutants = append(mutants, Mutant{
+					Line:     fset.Position(expr.Pos()).Line,
+					Type:     MutationSwitchCase,
+					Original: types.ExprString(expr),
+					Mutated:  "!(" + types.ExprString(expr) + ")",
+					pos:      expr.Pos(),
+				})
+			}
+		}
+		if i+1 == len(clauses) {
+			continue
+		}
+		next := clauses[i+1].(*ast.CaseClause)
+		if stmtsText(fset, src, clause.Body) == stmtsText(fset, src, next.Body) {
+			continue
+		}
+		mutants = append(mutants, Mutant{
+			Line:     fset.Position(clause.Case).Line,
+			Type:     MutationSwitchCase,
+			Original: caseLabel(clause),
+			Mutated:  "body swapped with " + caseLabel(next),
+			pos:      clause.Case,
+		})
+	}
+	return mutants
+}
+
+// caseLabel renders a case clause's header, such as "case a, b" or "default".
+func caseLabel(clause *ast.CaseClause) string {
+	if clause.List == nil {
+		return "default"
+	}
+	exprs := make([]string, len(clause.List))
+	for i, expr := range clause.List {
+		exprs[i] = types.ExprString(expr)
+	}
+	return "case " + strings.Join(exprs, ", ")
+}
+
+// stmtsText returns the source text of stmts.
+func stmtsText(fset *token.FileSet, src []byte, stmts []ast.Stmt) string {
+	if len(stmts) == 0 {
+		return ""
+	}
+	start, end := fset.Position(stmts[0].Pos()).Offset, fset.Position(stmts[len(stmts)-1].End()).Offset
+	return string(src[start:end])
+}
+
+// equivalentOperator reports whether swapping bin's operator provably
+// leaves its value unchanged for ordinary operands: adding or subtracting a
+// zero right operand, multiplying or dividing by a right operand of one, or
+// joining identical call-free operands with && or ||.
+func equivalentOperator(bin *ast.BinaryExpr) bool {
+	switch bin.Op {
+	case token.ADD, token.SUB:
+		return isNumber(bin.Y, 0)
+	case token.MUL, token.QUO:
+		return isNumber(bin.Y, 1)
+	case token.LAND, token.LOR:
+		return !hasCall(bin.X) && types.ExprString(bin.X) == types.ExprString(bin.Y)
+	}
+	return false
+}
+
+// isNumber reports whether expr is a numeric literal equal to v.
+func isNumber(expr ast.Expr, v float64) bool {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+	if !ok || (lit.Kind != token.INT && lit.Kind != token.FLOAT) {
+		return false
+	}
+	f, err := strconv.ParseFloat(lit.Value, 64)
+	if err != nil {
+		// Integer literals in other bases.
+		i, err := strconv.ParseInt(lit.Value, 0, 64)
+		return err == nil && float64(i) == v
+	}
+	return f == v
+}
+
+// hasCall reports whether expr contains a call, whose side effects would
+// make evaluating it once differ from evaluating it twice.
+func hasCall(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.CallExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// literalMutations returns the replacements for a boolean constant or
+// integer literal: true and false flip, 0 becomes 1, and any other integer
+// is bumped by one in each direction.
+func literalMutations(n ast.Node) (original string, pos token.Pos, replacements []string) {
+	switch lit := n.(type) {
+	case *ast.Ident:
+		if flipped, ok := boolFlips[lit.Name]; ok {
+			return lit.Name, lit.NamePos, []string{flipped}
+		}
+	case *ast.BasicLit:
+		if lit.Kind != token.INT {
+			return "", token.NoPos, nil
+		}
+		v, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			return "", token.NoPos, nil
+		}
+		if v == 0 {
+			return lit.Value, lit.ValuePos, []string{"1"}
+		}
+		return lit.Value, lit.ValuePos, []string{strconv.FormatInt(v+1, 10), strconv.FormatInt(v-1, 10)}
+	}
+	return "", token.NoPos, nil
+}
+
+// mutantScope identifies mutant by the content of its enclosing function
+// and its offset within that function.
+func mutantScope(fset *token.FileSet, src []byte, fn *ast.FuncDecl, mutant Mutant) string {
+	start, end := fset.Position(fn.Pos()).Offset, fset.Position(fn.End()).Offset
+	sum := sha256.Sum256(src[start:end])
+	return fmt.Sprintf("%x:%d:%s:%s:%s", sum, mutant.pos-fn.Pos(), mutant.Type, mutant.Original, mutant.Mutated)
+}
+
+// isGeneratedFile reports whether the Go file at path carries the standard
+// "// Code generated ... DO NOT EDIT." marker before its package clause.
+func isGeneratedFile(path string) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return ast.IsGenerated(f), nil
+}
+
+// parseMutationFile parses src into a fresh FileSet.
+func parseMutationFile(path string, src []byte) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return fset, f, nil
+}
+
+// stmtList returns the statement list held by n, or nil when n holds none.
+func stmtList(n ast.Node) *[]ast.Stmt {
+	switch node := n.(type) {
+	case *ast.BlockStmt:
+		return &node.List
+	case *ast.CaseClause:
+		return &node.Body
+	case *ast.CommClause:
+		return &node.Body
+	}
+	return nil
+}
+
+// deletable reports whether stmt can be removed: a call, assignment, or
+// increment. Returns, defers, and short variable declarations are never
+// deleted because the mutant would not compile or would change nothing
+// observable.
+func deletable(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		_, ok := s.X.(*ast.CallExpr)
+		return ok
+	case *ast.AssignStmt:
+		return s.Tok != token.DEFINE
+	case *ast.IncDecStmt:
+		return true
+	}
+	return false
+}
+
+// evaluate tests every mutant, updating each in place, and reports which
+// ones were applied, along with evidence noting any test selection fallback.
+// Mutants with a cached result are not re-tested. Each
+// worker owns a private copy of the module and takes mutants from a shared
+// queue; on error, the error of the earliest failing mutant is returned.
+// Cancelling ctx stops dispatching mutants and returns ctx.Err().
+func (m *MutationRunner) evaluate(ctx context.Context, input *InspectInput, mutants []Mutant, cache *mutationCache) ([]bool, []Evidence, error) {
+	applied := make([]bool, len(mutants))
+	var pending []int
+	for i := range mutants {
+		if mutants[i].Equivalent {
+			continue
+		}
+		hit, ok := cache.lookup(&mutants[i])
+		if !ok {
+			pending = append(pending, i)
+			continue
+		}
+		applied[i] = hit
+	}
+	if len(pending) == 0 {
+		return applied, nil, nil
+	}
+
+	workers := max(1, min(m.config.Workers, len(pending)))
+	root := input.Dir
+	if root == "" {
+		root = "."
+	}
+	plans, notes, err := m.plans(ctx, root, mutants, pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	errs := make([]error, len(mutants))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var setupErr error
+	var setupOnce sync.Once
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workDir, err := newMutationWorkspace(root)
+			if err != nil {
+				setupOnce.Do(func() { setupErr = err })
+				for range jobs {
+				}
+				return
+			}
+			// Best-effort removal; a leftover copy lives in the temp directory.
+			defer func() { _ = os.RemoveAll(workDir) }()
+			for i := range jobs {
+				applied[i], errs[i] = m.applyAndTest(ctx, workDir, &mutants[i], plans[i])
+			}
+		}()
+	}
+dispatch:
+	for _, i := range pending {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	if setupErr != nil {
+		return nil, nil, setupErr
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return applied, notes, nil
+}
+
+// baselines runs the tests of each package with a pending mutant against the
+// unmutated module in root and returns their normalized output by package.
+// Returns nil unless EquivalentByOutput is set; packages whose tests fail
+// unmutated have no baseline.
+func (m *MutationRunner) baselines(ctx context.Context, root string, mutants []Mutant, pending []int) (map[string]string, error) {
+	if !m.config.EquivalentByOutput {
+		return nil, nil
+	}
+	baselines := map[string]string{}
+	tried := map[string]bool{}
+	for _, i := range pending {
+		pkg := mutantPackage(mutants[i])
+		if tried[pkg] {
+			continue
+		}
+		tried[pkg] = true
+		out, err := m.runTests(ctx, root, []string{pkg})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			baselines[pkg] = normalizeTestOutput(out)
+		}
+	}
+	return baselines, nil
+}
+
+// mutantPackage returns the ./relative package pattern of mutant's file.
+func mutantPackage(mutant Mutant) string {
+	return "./" + filepath.ToSlash(filepath.Dir(mutant.File))
+}
+
+// normalizeTestOutput removes timings from go test output so runs of the
+// same tests compare equal.
+func normalizeTestOutput(out string) string {
+	return testDuration.ReplaceAllString(out, "")
+}
+
+// newMutationWorkspace copies the module rooted at root into a new
+// temporary directory and returns its path.
+func newMutationWorkspace(root string) (string, error) {
+	dir, err := os.MkdirTemp("", mutationWorkspacePattern)
+	if err != nil {
+		return "", fmt.Errorf("creating mutation workspace: %w", err)
+	}
+	if err := copyTree(root, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("copying %s into mutation workspace: %w", root, err)
+	}
+	return dir, nil
+}
+
+// copyTree copies the regular files under src into dst, preserving
+// permissions. Only the module's own packages are copied: directories the go
+// command ignores, whose names begin with "." or "_" such as .git and the
+// .crumbs and .cobbler data and worktree directories, and nested modules are
+// skipped.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if path == src {
+				return os.MkdirAll(target, 0o755)
+			}
+			if strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// applyAndTest writes mutant into its file under workDir, runs the owning
+// package's tests there, and restores the original file so the workspace
+// can be reused. applied is false when the mutation does not match the
+// source or the mutant does not compile. Killed is set on mutant when the
+// tests selected by plan fail, along with the failing test's name. A
+// surviving mutant whose normalized output equals the plan's non-empty
+// baseline is marked Equivalent.
+func (m *MutationRunner) applyAndTest(ctx context.Context, workDir string, mutant *Mutant, plan testPlan) (applied bool, err error) {
+	path := filepath.Join(workDir, mutant.File)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	mutated, ok, err := applyMutant(path, original, *mutant)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := os.WriteFile(path, mutated, 0o644); err != nil {
+		return false, fmt.Errorf("writing mutant to %s: %w", path, err)
+	}
+	defer func() {
+		if restoreErr := os.WriteFile(path, original, 0o644); restoreErr != nil && err == nil {
+			err = fmt.Errorf("restoring %s: %w", path, restoreErr)
+		}
+	}()
+
+	out, testErr := m.runTests(ctx, workDir, plan.args(mutantPackage(*mutant)))
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if testErr == nil {
+		mutant.Equivalent = plan.baseline != "" && normalizeTestOutput(out) == plan.baseline
+		return true, nil
+	}
+	if strings.Contains(out, buildFailedMarker) {
+		return false, nil
+	}
+	mutant.Killed = true
+	mutant.KillingTest = parseKillingTest(out)
+	return true, nil
+}
+
+// parseKillingTest returns the first failing test in go test output,
+// preferring a failing subtest over its parent. Returns "" when the failure
+// was not attributed to a test (a panic in init, a timeout, ...).
+func parseKillingTest(out string) string {
+	var name string
+	for _, line := range strings.Split(out, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), testFailPrefix)
+		if !ok {
+			continue
+		}
+		failed, _, _ := strings.Cut(rest, " ")
+		switch {
+		case name == "":
+			name = failed
+		case strings.HasPrefix(failed, name+"/"):
+			name = failed
+		default:
+			return name
+		}
+	}
+	return name
+}
+
+// applyMutant returns src with mutant applied to the AST node at its
+// position, re-rendered with go/printer. ok is false when no node at that
+// position matches the mutant.
+func applyMutant(path string, src []byte, mutant Mutant) (out []byte, ok bool, err error) {
+	fset, f, err := parseMutationFile(path, src)
+	if err != nil {
+		return nil, false, err
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ok {
+			return false
+		}
+		switch mutant.Type {
+		case MutationStatementDelete:
+			ok = deleteStmt(n, mutant)
+		case MutationLiteral:
+			ok = replaceLiteral(n, mutant)
+		case MutationIncDec:
+			ok = flipIncDec(n, mutant)
+		case MutationSwitchCase:
+			ok = mutateCase(n, mutant)
+		default:
+			ok = replaceOperator(n, mutant)
+		}
+		return !ok
+	})
+	if !ok {
+		return nil, false, nil
+	}
+	var buf bytes.Buffer
+	if err := mutantPrinter.Fprint(&buf, fset, f); err != nil {
+		return nil, false, fmt.Errorf("printing mutant of %s: %w", path, err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// replaceOperator swaps the binary or compound assignment operator at the
+// mutant's position.
+func replaceOperator(n ast.Node, mutant Mutant) bool {
+	switch node := n.(type) {
+	case *ast.BinaryExpr:
+		op, mutable := operatorMutations[node.Op]
+		if node.OpPos != mutant.pos || !mutable || node.Op.String() != mutant.Original || op.to.String() != mutant.Mutated {
+			return false
+		}
+		node.Op = op.to
+		return true
+	case *ast.AssignStmt:
+		to, mutable := assignMutations[node.Tok]
+		if node.TokPos != mutant.pos || !mutable || node.Tok.String() != mutant.Original || to.String() != mutant.Mutated {
+			return false
+		}
+		node.Tok = to
+		return true
+	}
+	return false
+}
+
+// flipIncDec turns the increment or decrement statement at the mutant's
+// position into its opposite. It covers standalone statements and for loop
+// post statements alike.
+func flipIncDec(n ast.Node, mutant Mutant) bool {
+	stmt, ok := n.(*ast.IncDecStmt)
+	if !ok || stmt.TokPos != mutant.pos || stmt.Tok.String() != mutant.Original {
+		return false
+	}
+	stmt.Tok = incDecFlips[stmt.Tok]
+	return true
+}
+
+// mutateCase applies a switch case mutant to the switch n: it negates the
+// case expression at the mutant's position or swaps the body of the case
+// clause there with the next clause's.
+func mutateCase(n ast.Node, mutant Mutant) bool {
+	var body *ast.BlockStmt
+	switch node := n.(type) {
+	case *ast.SwitchStmt:
+		body = node.Body
+	case *ast.TypeSwitchStmt:
+		body = node.Body
+	default:
+		return false
+	}
+	clauses := body.List
+	for i, stmt := range clauses {
+		clause := stmt.(*ast.CaseClause)
+		for j, expr := range clause.List {
+			if expr.Pos() == mutant.pos && types.ExprString(expr) == mutant.Original {
+				clause.List[j] = &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: expr}}
+				return true
+			}
+		}
+		if clause.Case == mutant.pos && caseLabel(clause) == mutant.Original && i+1 < len(clauses) {
+			next := clauses[i+1].(*ast.CaseClause)
+			clause.Body, next.Body = next.Body, clause.Body
+			return true
+		}
+	}
+	return false
+}
+
+// replaceLiteral rewrites the literal or boolean constant at the mutant's
+// position.
+func replaceLiteral(n ast.Node, mutant Mutant) bool {
+	switch lit := n.(type) {
+	case *ast.Ident:
+		if lit.NamePos == mutant.pos && lit.Name == mutant.Original {
+			lit.Name = mutant.Mutated
+			return true
+		}
+	case *ast.BasicLit:
+		if lit.ValuePos == mutant.pos && lit.Value == mutant.Original {
+			lit.Value = mutant.Mutated
+			return true
+		}
+	}
+	return false
+}
+
+// deleteStmt removes the statement at the mutant's position from n's
+// statement list.
+func deleteStmt(n ast.Node, mutant Mutant) bool {
+	list := stmtList(n)
+	if list == nil {
+		return false
+	}
+	for i, stmt := range *list {
+		if stmt.Pos() == mutant.pos && deletable(stmt) {
+			*list = append((*list)[:i:i], (*list)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
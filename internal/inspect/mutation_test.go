@@ -0,0 +1,936 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const statementSource = `package calc
+
+import "fmt"
+
+var calls int
+
+func Sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		total += x
+	}
+	calls++
+	fmt.Sprint(total)
+	defer fmt.Sprint()
+	return total
+}
+`
+
+func TestFindMutationSites_StatementDelete(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc.go": statementSource})
+	sites, err := findMutationSites(filepath.Join(dir, "calc.go"), nil)
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var deleted []string
+	for _, s := range sites {
+		if s.Type == MutationStatementDelete {
+			deleted = append(deleted, s.Original)
+		}
+	}
+	want := []string{"total += x", "calls++", "fmt.Sprint(total)"}
+	if strings.Join(deleted, "|") != strings.Join(want, "|") {
+		t.Errorf("deletable statements = %q, want %q (no return, defer, or :=)", deleted, want)
+	}
+}
+
+func TestApplyMutant(t *testing.T) {
+	src := "package p\n\nfunc f(a, b, c, d int) (bool, string) {\n\ta++\n\treturn a < b && c < d, \"a < b\" // a < b\n}\n"
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	path := filepath.Join(dir, "p.go")
+	sites, err := findMutationSites(path, nil)
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+
+	var lessThan []Mutant
+	var deletion Mutant
+	for _, s := range sites {
+		switch {
+		case s.Original == "<":
+			lessThan = append(lessThan, s)
+		case s.Type == MutationStatementDelete:
+			deletion = s
+		}
+	}
+	if len(lessThan) != 2 {
+		t.Fatalf("found %d < sites, want 2 (literals and comments are not mutable)", len(lessThan))
+	}
+
+	tests := []struct {
+		name   string
+		mutant Mutant
+		want   string
+	}{
+		{"first operator", lessThan[0], "return a <= b && c < d, \"a < b\" // a < b"},
+		{"second operator", lessThan[1], "return a < b && c <= d, \"a < b\" // a < b"},
+		{"statement delete", deletion, "return a < b && c < d, \"a < b\" // a < b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok, err := applyMutant(path, []byte(src), tt.mutant)
+			if err != nil || !ok {
+				t.Fatalf("applyMutant = %v, %v; want applied", ok, err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("mutant source:\n%s\nwant line %q", out, tt.want)
+			}
+			if tt.mutant.Type == MutationStatementDelete && strings.Contains(string(out), "a++") {
+				t.Errorf("statement not deleted:\n%s", out)
+			}
+		})
+	}
+
+	stale := lessThan[0]
+	stale.Original, stale.Mutated = "+", "-"
+	if _, ok, err := applyMutant(path, []byte(src), stale); err != nil || ok {
+		t.Errorf("applyMutant(stale) = %v, %v; want not applied", ok, err)
+	}
+}
+
+func TestMutationRunner_StatementDeleteAccounting(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	source := `package calc
+
+var calls int
+
+func Double(n int) int {
+	n += n
+	calls++
+	return n
+}
+`
+	dir := writeFiles(t, map[string]string{
+		"go.mod":       goModule,
+		"calc/calc.go": source,
+		"calc/calc_test.go": `package calc
+
+import "testing"
+
+func TestDouble(t *testing.T) {
+	if Double(3) != 6 {
+		t.Fatal("Double(3) != 6")
+	}
+}
+`,
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+
+	for _, workers := range []int{1, 3} {
+		config := DefaultMutationConfig()
+		config.Workers = workers
+		config.EnabledOperators = []MutationType{MutationStatementDelete}
+		result, err := NewMutationRunner(config).Run(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Run with %d workers failed: %v", workers, err)
+		}
+		if result.Verdict != VerdictFail || result.Score != 0.5 {
+			t.Fatalf("%d workers: result = %s %.2f, want fail 0.50 (evidence: %+v)", workers, result.Verdict, result.Score, result.Evidence)
+		}
+		if len(result.Evidence) != 2 {
+			t.Fatalf("%d workers: Evidence = %+v, want one killed and one surviving mutant", workers, result.Evidence)
+		}
+		if ev := result.Evidence[0]; ev.Line != 6 || !strings.Contains(ev.Detail, "killed by TestDouble") {
+			t.Errorf("%d workers: Evidence[0] = %+v, want n += n deletion killed by TestDouble", workers, ev)
+		}
+		if ev := result.Evidence[1]; ev.Line != 7 || !strings.Contains(ev.Detail, `survived: deleted "calls++"`) {
+			t.Errorf("%d workers: Evidence[1] = %+v, want the surviving calls++ deletion", workers, ev)
+		}
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "calc", "calc.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != source {
+		t.Errorf("source modified:\n%s", got)
+	}
+}
+
+func TestMutationRunner_FilesInParallel(t *testing.T) {
+	sources := map[string]string{
+		"calc/add.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc/sub.go": "package calc\n\nfunc Sub(a, b int) int { return a - b }\n",
+	}
+	files := map[string]string{"go.mod": goModule}
+	maps.Copy(files, sources)
+	dir := writeFiles(t, files)
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/add.go", "calc/sub.go"}}
+
+	var results []TechniqueResult
+	for _, workers := range []int{1, 2} {
+		var mu sync.Mutex
+		var active, peak, started int
+		runner := NewMutationRunner(MutationConfig{Workers: workers, EnabledOperators: []MutationType{MutationArithmetic}})
+		runner.runTests = func(_ context.Context, workDir string, _ []string) (string, error) {
+			mu.Lock()
+			active++
+			started++
+			peak = max(peak, active)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				active--
+				mu.Unlock()
+			}()
+			// Hold each run until as many have started as there are
+			// workers, so they overlap.
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				mu.Lock()
+				all := started >= workers
+				mu.Unlock()
+				if all {
+					break
+				}
+			}
+
+			// Each workspace holds exactly one mutated file.
+			var mutated []string
+			for _, name := range slices.Sorted(maps.Keys(sources)) {
+				got, err := os.ReadFile(filepath.Join(workDir, name))
+				if err != nil {
+					return "", err
+				}
+				if string(got) != sources[name] {
+					mutated = append(mutated, name)
+				}
+			}
+			if len(mutated) != 1 {
+				t.Errorf("workspace has mutated files %v, want one", mutated)
+			}
+			if slices.Equal(mutated, []string{"calc/add.go"}) {
+				return "--- FAIL: TestAdd (0.00s)\nFAIL\n", errors.New("exit status 1")
+			}
+			return "ok\n", nil
+		}
+
+		result, err := runner.Run(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Run with %d workers failed: %v", workers, err)
+		}
+		if peak != workers {
+			t.Errorf("%d workers: at most %d mutants tested at once, want %d", workers, peak, workers)
+		}
+		results = append(results, result)
+	}
+	if results[0].Score != 0.5 || !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("results differ by worker count or miss the one killed mutant:\n1: %+v\n2: %+v", results[0], results[1])
+	}
+	if files := results[1].Files; len(files) != 2 || files[0].File != "calc/add.go" || files[1].File != "calc/sub.go" {
+		t.Errorf("Files = %+v, want add.go then sub.go", files)
+	}
+}
+
+func TestMutationRunner_LeavesWorkingTreeUntouched(t *testing.T) {
+	source := "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n"
+	dir := writeFiles(t, map[string]string{
+		"go.mod":                  goModule,
+		"calc/calc.go":            source,
+		"calc/testdata/in.txt":    "1 2\n",
+		".git/HEAD":               "ref: refs/heads/main\n",
+		".crumbs/crumbs.db":       "data",
+		".cobbler/worktrees/c1/x": "worktree",
+		"_scratch/notes.go":       "package scratch\n",
+		"tools/go.mod":            "module example.com/tools\n",
+		"tools/tools.go":          "package tools\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+
+	runner := NewMutationRunner(MutationConfig{Workers: 2})
+	runner.runTests = func(_ context.Context, workDir string, pkgs []string) (string, error) {
+		if workDir == dir {
+			t.Errorf("tests ran in the working tree")
+		}
+		for _, skipped := range []string{".git", ".crumbs", ".cobbler", "_scratch", "tools"} {
+			if _, err := os.Stat(filepath.Join(workDir, skipped)); !os.IsNotExist(err) {
+				t.Errorf("workspace copied %s, which is outside the module's packages", skipped)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(workDir, "calc", "testdata", "in.txt")); err != nil {
+			t.Errorf("workspace did not copy test data: %v", err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, "calc", "calc.go")); string(got) != source {
+			t.Errorf("working tree modified during run:\n%s", got)
+		}
+		return "--- FAIL: TestCalc (0.00s)\nFAIL\n", errors.New("exit status 1")
+	}
+
+	result, err := runner.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictPass || result.Score != 1 || len(result.Evidence) != 2 {
+		t.Errorf("result = %+v, want both mutants killed", result)
+	}
+}
+
+func TestParseKillingTest(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want string
+	}{
+		{"top-level", "--- FAIL: TestAddNegative (0.00s)\n    calc_test.go:9: got 1\nFAIL\n", "TestAddNegative"},
+		{"subtest", "--- FAIL: TestAdd (0.00s)\n    --- FAIL: TestAdd/negative (0.00s)\n    --- FAIL: TestAdd/zero (0.00s)\nFAIL\n", "TestAdd/negative"},
+		{"first of several", "--- FAIL: TestA (0.00s)\n--- FAIL: TestB (0.00s)\n", "TestA"},
+		{"unattributed", "panic: boom\nFAIL\texample.com/m/calc\t0.01s\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKillingTest(tt.out); got != tt.want {
+				t.Errorf("parseKillingTest = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMutationRunner_Cache(t *testing.T) {
+	source := "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n"
+	test := "package calc\n\nimport \"testing\"\n\nfunc TestCalc(t *testing.T) {}\n"
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "calc/calc.go": source, "calc/calc_test.go": test})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+	config := MutationConfig{Workers: 1, CacheDir: filepath.Join(t.TempDir(), MutationCacheDirName)}
+
+	run := func(t *testing.T, config MutationConfig) (TechniqueResult, int) {
+		t.Helper()
+		var calls int
+		runner := NewMutationRunner(config)
+		runner.runTests = func(context.Context, string, []string) (string, error) {
+			calls++
+			return "--- FAIL: TestCalc (0.00s)\nFAIL\n", errors.New("exit status 1")
+		}
+		result, err := runner.Run(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result, calls
+	}
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "calc", name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, calls := run(t, config)
+	if calls != 2 {
+		t.Fatalf("first run tested %d mutants, want 2", calls)
+	}
+	second, calls := run(t, config)
+	if calls != 0 {
+		t.Errorf("cached run tested %d mutants, want 0", calls)
+	}
+	if second.Score != first.Score || len(second.Evidence) != len(first.Evidence) || second.Evidence[0].Detail != first.Evidence[0].Detail {
+		t.Errorf("cached result %+v differs from %+v", second, first)
+	}
+
+	write(t, "calc.go", "package calc\n\n// Add adds.\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return b - a }\n")
+	if _, calls = run(t, config); calls != 1 {
+		t.Errorf("after editing Sub, tested %d mutants, want 1", calls)
+	}
+
+	write(t, "calc_test.go", test+"\nfunc TestMore(t *testing.T) {}\n")
+	if _, calls = run(t, config); calls != 2 {
+		t.Errorf("after editing tests, tested %d mutants, want 2", calls)
+	}
+
+	config.NoCache = true
+	if _, calls = run(t, config); calls != 2 {
+		t.Errorf("with NoCache, tested %d mutants, want 2", calls)
+	}
+}
+
+func TestMutationRunner_ChangedLinesOnly(t *testing.T) {
+	source := "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n"
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "calc/calc.go": source})
+	diff := "--- a/calc/calc.go\n+++ b/calc/calc.go\n@@ -5 +5 @@\n-func Sub(a, b int) int { return b - a }\n+func Sub(a, b int) int { return a - b }\n"
+
+	tests := []struct {
+		name     string
+		diff     string
+		wantRuns int
+	}{
+		{"changed lines", diff, 1},
+		{"no diff", "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}, Diff: tt.diff}
+			var runs int
+			runner := NewMutationRunner(MutationConfig{Workers: 1, ChangedLinesOnly: true})
+			runner.runTests = func(context.Context, string, []string) (string, error) {
+				runs++
+				return "", nil
+			}
+			result, err := runner.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("tested %d mutants, want %d", runs, tt.wantRuns)
+			}
+			for _, ev := range result.Evidence {
+				if ev.Line == 3 && tt.diff != "" {
+					t.Errorf("unchanged line mutated: %+v", ev)
+				}
+			}
+		})
+	}
+}
+
+func TestMutationRunner_SkipsGeneratedFiles(t *testing.T) {
+	generated := "// Code generated by stringer; DO NOT EDIT.\n\npackage calc\n\nfunc Add(a, b int) int { return a + b }\n"
+	dir := writeFiles(t, map[string]string{
+		"go.mod":         goModule,
+		"calc/calc.go":   "package calc\n\nfunc Sub(a, b int) int { return a - b }\n",
+		"calc/zz_gen.go": generated,
+	})
+	if ok, err := isGeneratedFile(filepath.Join(dir, "calc", "zz_gen.go")); err != nil || !ok {
+		t.Fatalf("isGeneratedFile = %v, %v; want true", ok, err)
+	}
+
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go", "calc/zz_gen.go"}}
+	var runs int
+	runner := NewMutationRunner(MutationConfig{Workers: 1})
+	runner.runTests = func(context.Context, string, []string) (string, error) {
+		runs++
+		return "--- FAIL: TestSub (0.00s)\n", errors.New("exit status 1")
+	}
+	result, err := runner.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if runs != 1 || result.Score != 1 {
+		t.Errorf("tested %d mutants with score %.2f, want only the hand-written mutant", runs, result.Score)
+	}
+	if len(result.Evidence) == 0 || result.Evidence[0].File != "calc/zz_gen.go" || !strings.Contains(result.Evidence[0].Detail, "excluded") {
+		t.Errorf("Evidence = %+v, want the generated file reported as excluded", result.Evidence)
+	}
+}
+
+func TestFindMutationSites_Literals(t *testing.T) {
+	src := "package p\n\nfunc f() (bool, int, int, string) {\n\treturn true, 0, 0x10, \"7\"\n}\n"
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	path := filepath.Join(dir, "p.go")
+	sites, err := findMutationSites(path, nil)
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var got []string
+	for _, s := range sites {
+		if s.Type == MutationLiteral {
+			got = append(got, s.Original+"->"+s.Mutated)
+		}
+	}
+	want := []string{"true->false", "0->1", "0x10->17", "0x10->15"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("literal mutants = %q, want %q", got, want)
+	}
+
+	out, ok, err := applyMutant(path, []byte(src), sites[0])
+	if err != nil || !ok {
+		t.Fatalf("applyMutant = %v, %v; want applied", ok, err)
+	}
+	if !strings.Contains(string(out), "return false, 0, 0x10") {
+		t.Errorf("mutant source:\n%s", out)
+	}
+}
+
+func TestMutationRunner_LiteralAccounting(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":       goModule,
+		"calc/calc.go": "package calc\n\nfunc Enabled() bool { return true }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+	runner := NewMutationRunner(MutationConfig{Workers: 1})
+	runner.runTests = func(context.Context, string, []string) (string, error) { return "", nil }
+	result, err := runner.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictFail || len(result.Evidence) != 1 || !strings.Contains(result.Evidence[0].Detail, `literal mutant survived: "true" -> "false"`) {
+		t.Errorf("result = %+v, want the surviving literal mutant", result)
+	}
+	want := []SurvivingMutant{{File: "calc/calc.go", Line: 3, Type: MutationLiteral, Original: "true", Mutated: "false"}}
+	if !slices.Equal(result.Survivors, want) {
+		t.Errorf("Survivors = %+v, want %+v", result.Survivors, want)
+	}
+}
+
+func TestFindMutationSites_EnabledOperators(t *testing.T) {
+	src := "package p\n\nfunc f(a, b int) bool {\n\ta++\n\treturn a+1 < b\n}\n"
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	path := filepath.Join(dir, "p.go")
+
+	tests := []struct {
+		name    string
+		enabled []MutationType
+		want    []MutationType
+	}{
+		{"empty means all", nil, []MutationType{MutationStatementDelete, MutationIncDec, MutationArithmetic, MutationLiteral, MutationLiteral, MutationConditional}},
+		{"restricted", []MutationType{MutationConditional, MutationStatementDelete}, []MutationType{MutationStatementDelete, MutationConditional}},
+		{"single", []MutationType{MutationLiteral}, []MutationType{MutationLiteral, MutationLiteral}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sites, err := findMutationSites(path, tt.enabled)
+			if err != nil {
+				t.Fatalf("findMutationSites failed: %v", err)
+			}
+			got := make([]MutationType, len(sites))
+			for i, s := range sites {
+				got[i] = s.Type
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mutation types = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMutationSites_EquivalentPatterns(t *testing.T) {
+	src := `package p
+
+func f(a, b int, ok bool) (int, int, int, bool, bool, bool) {
+	return a + 0, a * 1, a + b, ok && ok, ok || g(), a - 0x0 > b/1.0
+}
+
+func g() bool { return true }
+`
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	sites, err := findMutationSites(filepath.Join(dir, "p.go"), []MutationType{MutationArithmetic, MutationLogical})
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var got []string
+	for _, s := range sites {
+		got = append(got, fmt.Sprintf("%s->%s:%v", s.Original, s.Mutated, s.Equivalent))
+	}
+	// ok || g() involves a call, so it is not excused.
+	want := []string{"+->-:true", "*->/:true", "+->-:false", "&&->||:true", "||->&&:false", "-->+:true", "/->*:true"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mutants = %q, want %q", got, want)
+	}
+}
+
+func TestMutationRunner_Equivalent(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":       goModule,
+		"calc/calc.go": "package calc\n\nfunc Scale(a, b int) int { return a*1 + b }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+
+	tests := []struct {
+		name           string
+		byOutput       bool
+		mutantOutput   string
+		wantVerdict    Verdict
+		wantTested     int
+		wantEquivalent int
+	}{
+		{"pattern only", false, "", VerdictFail, 1, 1},
+		{"matching output", true, "=== RUN   TestScale\n--- PASS: TestScale (0.01s)\nok  \texample.com/m/calc\t0.020s\n", VerdictSkip, 0, 2},
+		{"differing output", true, "=== RUN   TestScale\n    calc_test.go:9: got 5\n--- PASS: TestScale (0.00s)\n", VerdictFail, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewMutationRunner(MutationConfig{Workers: 1, EquivalentByOutput: tt.byOutput, EnabledOperators: []MutationType{MutationArithmetic}})
+			var runs int
+			runner.runTests = func(_ context.Context, workDir string, _ []string) (string, error) {
+				runs++
+				if workDir == dir {
+					return "=== RUN   TestScale\n--- PASS: TestScale (0.00s)\nok  \texample.com/m/calc\t0.011s\n", nil
+				}
+				return tt.mutantOutput, nil
+			}
+			result, err := runner.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var equivalent, survived int
+			for _, ev := range result.Evidence {
+				switch {
+				case strings.Contains(ev.Detail, "judged equivalent"):
+					equivalent++
+				case strings.Contains(ev.Detail, "survived"):
+					survived++
+				}
+			}
+			if result.Verdict != tt.wantVerdict || survived != tt.wantTested || equivalent != tt.wantEquivalent {
+				t.Errorf("result = %+v, want verdict %s with %d survivor(s) and %d equivalent", result, tt.wantVerdict, tt.wantTested, tt.wantEquivalent)
+			}
+			// The a*1 mutant is excused by pattern and never tested.
+			if wantRuns := map[bool]int{false: 1, true: 2}[tt.byOutput]; runs != wantRuns {
+				t.Errorf("runTests called %d times, want %d", runs, wantRuns)
+			}
+		})
+	}
+}
+
+func TestFindMutationSites_AssignOperators(t *testing.T) {
+	src := "package p\n\nfunc f(xs []int) (int, int) {\n\tsum, prod := 0, 1\n\tfor _, x := range xs {\n\t\tsum += x\n\t\tsum -= 1\n\t\tprod *= x\n\t\tprod /= 2\n\t\tsum |= x\n\t}\n\treturn sum, prod\n}\n"
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	path := filepath.Join(dir, "p.go")
+	sites, err := findMutationSites(path, []MutationType{MutationArithmetic})
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var got []string
+	for _, s := range sites {
+		got = append(got, fmt.Sprintf("%d:%s->%s", s.Line, s.Original, s.Mutated))
+	}
+	// |= has no arithmetic counterpart and is left alone.
+	want := []string{"6:+=->-=", "7:-=->+=", "8:*=->/=", "9:/=->*="}
+	if !slices.Equal(got, want) {
+		t.Fatalf("assignment mutants = %q, want %q", got, want)
+	}
+
+	out, ok, err := applyMutant(path, []byte(src), sites[2])
+	if err != nil || !ok {
+		t.Fatalf("applyMutant = %v, %v; want applied", ok, err)
+	}
+	if !strings.Contains(string(out), "prod /= x") || !strings.Contains(string(out), "prod /= 2") {
+		t.Errorf("mutant source:\n%s", out)
+	}
+}
+
+func TestFindMutationSites_IncDec(t *testing.T) {
+	src := "package p\n\nfunc f(n int) int {\n\tcount := 0\n\tfor i := 0; i < n; i++ {\n\t\tcount--\n\t}\n\treturn count\n}\n"
+	dir := writeFiles(t, map[string]string{"p.go": src})
+	path := filepath.Join(dir, "p.go")
+	sites, err := findMutationSites(path, []MutationType{MutationIncDec})
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var got []string
+	for _, s := range sites {
+		got = append(got, fmt.Sprintf("%d:%s->%s", s.Line, s.Original, s.Mutated))
+	}
+	want := []string{"5:++->--", "6:--->++"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("inc/dec mutants = %q, want %q", got, want)
+	}
+
+	wantSources := []string{"for i := 0; i < n; i-- {", "\t\tcount++\n"}
+	for i, site := range sites {
+		out, ok, err := applyMutant(path, []byte(src), site)
+		if err != nil || !ok {
+			t.Fatalf("applyMutant(%s) = %v, %v; want applied", got[i], ok, err)
+		}
+		if !strings.Contains(string(out), wantSources[i]) {
+			t.Errorf("mutant %s source:\n%s", got[i], out)
+		}
+	}
+}
+
+const switchSource = `package grade
+
+func Grade(n int) string {
+	switch {
+	case n >= 90:
+		return "A"
+	case n >= 50:
+		return "B"
+	default:
+		return "C"
+	}
+}
+
+func Kind(v any) string {
+	switch v.(type) {
+	case int:
+		return "int"
+	case string:
+		return "int"
+	}
+	return "?"
+}
+`
+
+func TestFindMutationSites_SwitchCase(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"grade.go": switchSource})
+	path := filepath.Join(dir, "grade.go")
+	sites, err := findMutationSites(path, []MutationType{MutationSwitchCase})
+	if err != nil {
+		t.Fatalf("findMutationSites failed: %v", err)
+	}
+	var got []string
+	for _, s := range sites {
+		got = append(got, fmt.Sprintf("%d:%s->%s", s.Line, s.Original, s.Mutated))
+	}
+	// The type switch's identical bodies are not swapped.
+	want := []string{
+		"5:case n >= 90->body swapped with case n >= 50",
+		"5:n >= 90->!(n >= 90)",
+		"7:case n >= 50->body swapped with default",
+		"7:n >= 50->!(n >= 50)",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("switch mutants = %q, want %q", got, want)
+	}
+
+	wantSources := []string{
+		"case n >= 90:\n\t\treturn \"B\"\n\tcase n >= 50:\n\t\treturn \"A\"",
+		"case !(n >= 90):",
+		"case n >= 50:\n\t\treturn \"C\"\n\tdefault:\n\t\treturn \"B\"",
+		"case !(n >= 50):",
+	}
+	for i, site := range sites {
+		out, ok, err := applyMutant(path, []byte(switchSource), site)
+		if err != nil || !ok {
+			t.Fatalf("applyMutant(%s) = %v, %v; want applied", got[i], ok, err)
+		}
+		// Moved bodies keep their positions, so the printer may add blank lines.
+		if !strings.Contains(strings.ReplaceAll(string(out), "\n\n", "\n"), wantSources[i]) {
+			t.Errorf("mutant %s source:\n%s", got[i], out)
+		}
+	}
+}
+
+func TestMutationRunner_SwitchCaseAccounting(t *testing.T) {
+	// Only the first case is exercised, so mutants of the second survive.
+	test := "package grade\n\nimport \"testing\"\n\nfunc TestGrade(t *testing.T) {\n\tif got := Grade(95); got != \"A\" {\n\t\tt.Errorf(\"Grade(95) = %q\", got)\n\t}\n}\n"
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "grade/grade.go": switchSource, "grade/grade_test.go": test})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"grade/grade.go"}}
+
+	result, err := NewMutationRunner(MutationConfig{Workers: 2, EnabledOperators: []MutationType{MutationSwitchCase}}).Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Verdict != VerdictFail || result.Score != 0.5 || len(result.Evidence) != 4 {
+		t.Fatalf("result = %+v, want 2 of 4 switch mutants killed", result)
+	}
+	for _, ev := range result.Evidence {
+		if killed := strings.Contains(ev.Detail, "killed by TestGrade"); killed != (ev.Line == 5) {
+			t.Errorf("Evidence %+v: killed = %v, want killed only on line 5", ev, killed)
+		}
+	}
+}
+
+func TestMutationRunner_CoveredTestsOnly(t *testing.T) {
+	source := "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n"
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "calc/calc.go": source})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+
+	tests := []struct {
+		name        string
+		coverage    lineTests
+		coverageErr error
+		wantArgs    []string
+		wantScore   float64
+		wantNote    bool
+	}{
+		{"runs covering tests only", lineTests{"calc.go": {3: {"TestAdd", "TestSum"}}}, nil, []string{"-run ^(TestAdd|TestSum)$ ./calc", "-run ^$ ./calc"}, 0.5, false},
+		{"falls back to the package", nil, errors.New("no tests listed"), []string{"./calc", "./calc"}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewMutationRunner(MutationConfig{Workers: 1, CoveredTestsOnly: true, EnabledOperators: []MutationType{MutationArithmetic}})
+			runner.coverage = func(_ context.Context, root, pkg string) (lineTests, error) {
+				if root != dir || pkg != "./calc" {
+					t.Errorf("coverage(%q, %q), want the module root and ./calc", root, pkg)
+				}
+				return tt.coverage, tt.coverageErr
+			}
+			var args []string
+			runner.runTests = func(_ context.Context, _ string, testArgs []string) (string, error) {
+				args = append(args, strings.Join(testArgs, " "))
+				if len(testArgs) > 1 && testArgs[1] == noTestsPattern {
+					return "ok  \texample.com/m/calc\t0.01s [no tests to run]\n", nil
+				}
+				return "--- FAIL: TestAdd (0.00s)\nFAIL\n", errors.New("exit status 1")
+			}
+
+			result, err := runner.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("go test args = %q, want %q", args, tt.wantArgs)
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", result.Score, tt.wantScore)
+			}
+			var noted bool
+			for _, ev := range result.Evidence {
+				noted = noted || strings.Contains(ev.Detail, "test selection unavailable")
+			}
+			if noted != tt.wantNote {
+				t.Errorf("Evidence = %+v, want fallback note %v", result.Evidence, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestParseCoveredLines(t *testing.T) {
+	profile := filepath.Join(writeFiles(t, map[string]string{"cover.out": "mode: set\n" +
+		"example.com/m/calc/calc.go:3.24,3.42 1 1\n" +
+		"example.com/m/calc/calc.go:5.24,7.2 2 0\n" +
+		"example.com/m/calc/util.go:8.10,10.2 1 1\n"}), "cover.out")
+	got, err := parseCoveredLines(profile)
+	if err != nil {
+		t.Fatalf("parseCoveredLines failed: %v", err)
+	}
+	want := map[string][]int{"calc.go": {3}, "util.go": {8, 9, 10}}
+	if len(got) != len(want) {
+		t.Fatalf("covered = %v, want %v", got, want)
+	}
+	for file, lines := range want {
+		if !slices.Equal(got[file], lines) {
+			t.Errorf("covered[%s] = %v, want %v", file, got[file], lines)
+		}
+	}
+}
+
+func TestMutationRunner_Sampling(t *testing.T) {
+	var source strings.Builder
+	source.WriteString("package calc\n")
+	for i := range 12 {
+		fmt.Fprintf(&source, "\nfunc F%d(a, b int) int { return a + b }\n", i)
+	}
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "calc/calc.go": source.String()})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/calc.go"}}
+
+	run := func(seed uint64) []Evidence {
+		t.Helper()
+		runner := NewMutationRunner(MutationConfig{MaxMutants: 5, SampleSeed: seed, Workers: 2, EnabledOperators: []MutationType{MutationArithmetic}})
+		runner.runTests = func(context.Context, string, []string) (string, error) {
+			return "--- FAIL: TestCalc (0.00s)\nFAIL\n", errors.New("exit status 1")
+		}
+		result, err := runner.Run(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result.Evidence
+	}
+
+	first, second := run(7), run(7)
+	if !slices.Equal(first, second) {
+		t.Errorf("same seed sampled differently:\n%+v\n%+v", first, second)
+	}
+	if len(first) != 7 || first[0].Detail != "sampled 5 of 12 mutants (seed 7)" {
+		t.Fatalf("Evidence = %+v, want a sampling note, 5 mutants, and a confidence note", first)
+	}
+	if !strings.Contains(first[6].Detail, "score estimated from a sample: 1.00") {
+		t.Errorf("Evidence[6] = %+v, want the confidence note", first[6])
+	}
+	if slices.Equal(first[1:6], run(8)[1:6]) {
+		t.Errorf("seeds 7 and 8 sampled the same mutants")
+	}
+}
+
+func TestSampleMargin(t *testing.T) {
+	if got := sampleMargin(0.5, 10, 10); got != 0 {
+		t.Errorf("full population margin = %v, want 0", got)
+	}
+	// 1.96 * sqrt(0.25/100 * 900/999) ≈ 0.093.
+	if got := sampleMargin(0.5, 100, 1000); math.Abs(got-0.093) > 0.001 {
+		t.Errorf("sampleMargin(0.5, 100, 1000) = %v, want ≈0.093", got)
+	}
+}
+
+func TestMutationRunner_Plan(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":         goModule,
+		"calc/calc.go":   "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Less(a, b int) bool { return a < b || a == b }\n",
+		"calc/sub.go":    "package calc\n\nfunc Sub(a, b int) int { return a - b }\n",
+		"calc/zz_gen.go": "// Code generated by stringer; DO NOT EDIT.\n\npackage calc\n\nfunc Mul(a, b int) int { return a * b }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/sub.go", "calc/calc.go", "calc/zz_gen.go"}}
+	runner := NewMutationRunner(MutationConfig{MaxMutants: 3})
+	runner.runTests = func(context.Context, string, []string) (string, error) {
+		t.Fatal("Plan ran the tests")
+		return "", nil
+	}
+
+	plan, err := runner.Plan(input)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(plan.Mutants) != 5 || plan.Tested != 3 {
+		t.Errorf("plan has %d mutants, %d tested; want 5 found and a sample of 3", len(plan.Mutants), plan.Tested)
+	}
+	want := []MutantCount{
+		{"calc/calc.go", MutationArithmetic, 1},
+		{"calc/calc.go", MutationConditional, 1},
+		{"calc/calc.go", MutationNegateConditional, 1},
+		{"calc/calc.go", MutationLogical, 1},
+		{"calc/sub.go", MutationArithmetic, 1},
+	}
+	if got := plan.Counts(); !slices.Equal(got, want) {
+		t.Errorf("Counts = %+v, want %+v", got, want)
+	}
+
+	var out strings.Builder
+	if err := WriteMutationPlan(&out, plan); err != nil {
+		t.Fatalf("WriteMutationPlan failed: %v", err)
+	}
+	for _, line := range []string{"calc/sub.go   arithmetic", "note: calc/zz_gen.go: generated file excluded", "5 mutants; a run tests a sample of 3"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("plan output does not contain %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestMutationRunner_FileBreakdown(t *testing.T) {
+	strong := "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Mul(a, b int) int { return a * b }\n"
+	dir := writeFiles(t, map[string]string{
+		"go.mod":         goModule,
+		"calc/strong.go": strong,
+		"calc/weak.go":   "package calc\n\nfunc Sub(a, b int) int { return a - b }\n\nfunc Less(a, b int) bool { return a < b }\n\nfunc Div(a, b int) int { return a / b }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/strong.go", "calc/weak.go"}}
+	runner := NewMutationRunner(MutationConfig{Workers: 1, EnabledOperators: []MutationType{MutationArithmetic, MutationConditional}})
+	// Only mutants of strong.go are caught.
+	runner.runTests = func(_ context.Context, dir string, _ []string) (string, error) {
+		src, err := os.ReadFile(filepath.Join(dir, "calc", "strong.go"))
+		if err != nil || string(src) == strong {
+			return "ok\n", nil
+		}
+		return "--- FAIL: TestStrong (0.00s)\nFAIL\n", errors.New("exit status 1")
+	}
+
+	result, err := runner.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := []FileScore{{File: "calc/strong.go", Passed: 2, Total: 2}, {File: "calc/weak.go", Passed: 0, Total: 3}}
+	if !slices.Equal(result.Files, want) {
+		t.Fatalf("Files = %+v, want %+v", result.Files, want)
+	}
+	var passed, total int
+	for _, f := range result.Files {
+		passed += f.Passed
+		total += f.Total
+	}
+	if !approxEqual(result.Score, float64(passed)/float64(total)) || !approxEqual(result.Score, 0.4) {
+		t.Errorf("Score = %v, want the breakdown's %d/%d", result.Score, passed, total)
+	}
+
+	var report strings.Builder
+	if err := WriteReport(&report, []TechniqueResult{result}); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	if !strings.Contains(report.String(), "  calc/weak.go: 3/3 failed\n") {
+		t.Errorf("report does not list weak.go's surviving mutants:\n%s", report.String())
+	}
+}
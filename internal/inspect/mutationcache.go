@@ -0,0 +1,152 @@
+package inspect
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MutationCacheDirName is the directory under the crumbs data directory
+// that holds the mutation result cache.
+const MutationCacheDirName = "mutation-cache"
+
+// mutationCacheFile is the cache file inside MutationConfig.CacheDir.
+const mutationCacheFile = "results.json"
+
+// cachedMutant is the stored outcome of one mutant.
+type cachedMutant struct {
+	Applied     bool   `json:"applied"`
+	Killed      bool   `json:"killed"`
+	KillingTest string `json:"killing_test,omitempty"`
+	Equivalent  bool   `json:"equivalent,omitempty"`
+}
+
+// mutationCache maps mutant keys to outcomes. A key combines the mutant's
+// scope with a hash of its package's test files, so editing a test
+// invalidates every cached mutant of that package. The zero cache (nil)
+// never hits and is never saved.
+type mutationCache struct {
+	path    string
+	noRead  bool
+	entries map[string]cachedMutant
+	// keys maps each mutant's file and scope to its cache key.
+	keys map[string]string
+}
+
+// loadCache reads the cache and computes the key of every mutant. Returns a
+// nil cache when caching is disabled.
+func (m *MutationRunner) loadCache(input *InspectInput, mutants []Mutant) (*mutationCache, error) {
+	if m.config.CacheDir == "" {
+		return nil, nil
+	}
+	cache := &mutationCache{
+		path:    filepath.Join(m.config.CacheDir, mutationCacheFile),
+		noRead:  m.config.NoCache,
+		entries: map[string]cachedMutant{},
+		keys:    map[string]string{},
+	}
+	raw, err := os.ReadFile(cache.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("reading mutation cache: %w", err)
+	default:
+		// A corrupt cache is discarded rather than failing the run.
+		if json.Unmarshal(raw, &cache.entries) != nil {
+			cache.entries = map[string]cachedMutant{}
+		}
+	}
+
+	testHashes := map[string]string{}
+	for _, mutant := range mutants {
+		dir := filepath.Dir(input.path(mutant.File))
+		hash, ok := testHashes[dir]
+		if !ok {
+			if hash, err = hashTestFiles(dir); err != nil {
+				return nil, err
+			}
+			testHashes[dir] = hash
+		}
+		key := hash + "\x00" + mutant.scope
+		if m.config.EquivalentByOutput {
+			// Survivors are judged differently, so results are not shared.
+			key += "\x00equivalent-by-output"
+		}
+		if m.config.CoveredTestsOnly {
+			// A subset of the tests runs, so killing tests can differ.
+			key += "\x00covered-tests-only"
+		}
+		cache.keys[mutant.File+"\x00"+mutant.scope] = fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+	}
+	return cache, nil
+}
+
+// key returns the cache key of mutant.
+func (c *mutationCache) key(mutant *Mutant) string {
+	return c.keys[mutant.File+"\x00"+mutant.scope]
+}
+
+// lookup fills mutant from the cache and reports whether it was applied.
+// ok is false on a miss.
+func (c *mutationCache) lookup(mutant *Mutant) (applied, ok bool) {
+	if c == nil || c.noRead {
+		return false, false
+	}
+	entry, ok := c.entries[c.key(mutant)]
+	if !ok {
+		return false, false
+	}
+	mutant.Killed = entry.Killed
+	mutant.KillingTest = entry.KillingTest
+	mutant.Equivalent = entry.Equivalent
+	return entry.Applied, true
+}
+
+// saveCache records the outcome of every mutant and writes the cache.
+func (m *MutationRunner) saveCache(cache *mutationCache, mutants []Mutant, applied []bool) error {
+	if cache == nil {
+		return nil
+	}
+	for i, mutant := range mutants {
+		cache.entries[cache.key(&mutant)] = cachedMutant{
+			Applied:     applied[i],
+			Killed:      mutant.Killed,
+			KillingTest: mutant.KillingTest,
+			Equivalent:  mutant.Equivalent,
+		}
+	}
+	raw, err := json.Marshal(cache.entries)
+	if err != nil {
+		return fmt.Errorf("encoding mutation cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0o755); err != nil {
+		return fmt.Errorf("creating mutation cache: %w", err)
+	}
+	if err := os.WriteFile(cache.path, raw, 0o644); err != nil {
+		return fmt.Errorf("writing mutation cache: %w", err)
+	}
+	return nil
+}
+
+// hashTestFiles hashes the names and contents of the test files in dir.
+func hashTestFiles(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+testFileSuffix))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", file, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(file), len(data))
+		h.Write(data)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
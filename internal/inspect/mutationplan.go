@@ -0,0 +1,139 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// MutationPlan is what a mutation run would test, found without applying or
+// testing any mutant. It lets the operator set and scoping be tuned before
+// a long run.
+type MutationPlan struct {
+	// Mutants lists every mutation site in the modified source files, in
+	// file and source order, before sampling.
+	Mutants []Mutant
+	// Tested is the number of mutants a run tests: len(Mutants), or
+	// MaxMutants when a sample is drawn.
+	Tested int
+	// Notes holds the evidence a run reports about the plan, such as
+	// excluded generated files.
+	Notes []Evidence
+	// Unparseable holds one SeverityError entry per modified source file
+	// that does not parse. Such files have no mutants, and a run fails.
+	Unparseable []Evidence
+}
+
+// MutantCount is the number of mutants of one type in one file.
+type MutantCount struct {
+	File  string
+	Type  MutationType
+	Count int
+}
+
+// Plan finds the mutants Run would generate for input, honoring the
+// enabled operators and ChangedLinesOnly, without running any tests.
+func (m *MutationRunner) Plan(input *InspectInput) (MutationPlan, error) {
+	var plan MutationPlan
+	var changed map[string]map[int]bool
+	if m.config.ChangedLinesOnly {
+		if input.Diff == "" {
+			plan.Notes = append(plan.Notes, Evidence{Detail: "no diff provided; mutating every line"})
+		} else {
+			changed = changedLines(input.Diff)
+		}
+	}
+
+	for _, file := range sourceFiles(input.ModifiedFiles) {
+		generated, err := isGeneratedFile(input.path(file))
+		if ev, ok := syntaxError(file, err); ok {
+			plan.Unparseable = append(plan.Unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return MutationPlan{}, err
+		}
+		if generated {
+			plan.Notes = append(plan.Notes, Evidence{File: file, Detail: "generated file excluded from mutation"})
+			continue
+		}
+		sites, err := findMutationSites(input.path(file), m.config.EnabledOperators)
+		if ev, ok := syntaxError(file, err); ok {
+			plan.Unparseable = append(plan.Unparseable, ev)
+			continue
+		}
+		if err != nil {
+			return MutationPlan{}, err
+		}
+		for _, site := range sites {
+			if changed != nil && !changed[filepath.ToSlash(file)][site.Line] {
+				continue
+			}
+			site.File = file
+			plan.Mutants = append(plan.Mutants, site)
+		}
+	}
+
+	plan.Tested = len(plan.Mutants)
+	if m.config.MaxMutants > 0 && plan.Tested > m.config.MaxMutants {
+		plan.Tested = m.config.MaxMutants
+	}
+	return plan, nil
+}
+
+// Counts groups the plan's mutants by file and type, sorted by file and
+// then by type in AllMutationTypes order.
+func (p MutationPlan) Counts() []MutantCount {
+	var counts []MutantCount
+	for _, mutant := range p.Mutants {
+		i := slices.IndexFunc(counts, func(c MutantCount) bool { return c.File == mutant.File && c.Type == mutant.Type })
+		if i < 0 {
+			counts = append(counts, MutantCount{File: mutant.File, Type: mutant.Type})
+			i = len(counts) - 1
+		}
+		counts[i].Count++
+	}
+	slices.SortFunc(counts, func(a, b MutantCount) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return slices.Index(AllMutationTypes, a.Type) - slices.Index(AllMutationTypes, b.Type)
+	})
+	return counts
+}
+
+// WriteMutationPlan prints the plan as a table of mutant counts per file
+// and type, followed by its notes and totals.
+func WriteMutationPlan(w io.Writer, plan MutationPlan) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tTYPE\tMUTANTS")
+	for _, c := range plan.Counts() {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", c.File, c.Type, c.Count)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, ev := range plan.Unparseable {
+		if _, err := fmt.Fprintln(w, formatEvidence(ev)); err != nil {
+			return err
+		}
+	}
+	for _, note := range plan.Notes {
+		detail := note.Detail
+		if note.File != "" {
+			detail = note.File + ": " + detail
+		}
+		if _, err := fmt.Fprintf(w, "note: %s\n", detail); err != nil {
+			return err
+		}
+	}
+	summary := fmt.Sprintf("%d mutants", len(plan.Mutants))
+	if plan.Tested < len(plan.Mutants) {
+		summary += fmt.Sprintf("; a run tests a sample of %d", plan.Tested)
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
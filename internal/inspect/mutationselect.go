@@ -0,0 +1,197 @@
+package inspect
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// noTestsPattern is a go test -run pattern that matches no test, so the
+// package is built but nothing runs.
+const noTestsPattern = "^$"
+
+// testNamePattern matches the names go test -list prints for runnable tests.
+var testNamePattern = regexp.MustCompile(`^(Test|Example|Fuzz)\w*$`)
+
+// lineTests maps a source file's base name to the tests covering each of
+// its lines, for the files of one package.
+type lineTests map[string]map[int][]string
+
+// testPlan is how one mutant is tested.
+type testPlan struct {
+	// baseline is the normalized output of the unmutated package's tests,
+	// for EquivalentByOutput; empty means no comparison.
+	baseline string
+	// run is a go test -run pattern selecting the tests to run; empty runs
+	// the whole package.
+	run string
+}
+
+// args returns the go test arguments that test pkg under the plan.
+func (p testPlan) args(pkg string) []string {
+	if p.run == "" {
+		return []string{pkg}
+	}
+	return []string{"-run", p.run, pkg}
+}
+
+// plans decides how each pending mutant is tested: whether its output is
+// compared with a baseline and, with CoveredTestsOnly, which tests run.
+// Packages whose tests cannot be mapped to lines fall back to running every
+// test; the returned evidence notes each fallback.
+func (m *MutationRunner) plans(ctx context.Context, root string, mutants []Mutant, pending []int) ([]testPlan, []Evidence, error) {
+	plans := make([]testPlan, len(mutants))
+	baselines, err := m.baselines(ctx, root, mutants, pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, i := range pending {
+		plans[i].baseline = baselines[mutantPackage(mutants[i])]
+	}
+	if !m.config.CoveredTestsOnly {
+		return plans, nil, nil
+	}
+
+	var evidence []Evidence
+	coverage := map[string]lineTests{}
+	for _, i := range pending {
+		pkg := mutantPackage(mutants[i])
+		covered, ok := coverage[pkg]
+		if !ok {
+			covered, err = m.coverage(ctx, root, pkg)
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			if err != nil {
+				evidence = append(evidence, Evidence{File: pkg, Detail: fmt.Sprintf("test selection unavailable, running every test: %v", err)})
+			}
+			coverage[pkg] = covered
+		}
+		if covered == nil {
+			continue
+		}
+		tests := covered[filepath.Base(mutants[i].File)][mutants[i].Line]
+		// Output of a subset of tests cannot match the full baseline.
+		plans[i] = testPlan{run: runPattern(tests)}
+	}
+	return plans, evidence, nil
+}
+
+// runPattern returns a go test -run pattern matching exactly tests, or
+// noTestsPattern when there are none.
+func runPattern(tests []string) string {
+	if len(tests) == 0 {
+		return noTestsPattern
+	}
+	quoted := make([]string, len(tests))
+	for i, name := range tests {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// coverageByTest runs each test of pkg on its own with a coverage profile
+// with test and maps every covered line to the tests that reach it.
+func coverageByTest(ctx context.Context, test TestCommand, dir, pkg string) (lineTests, error) {
+	out, err := test(ctx, dir, []string{"-list", ".", pkg})
+	if err != nil {
+		return nil, fmt.Errorf("listing tests: %w", err)
+	}
+	tmp, err := os.MkdirTemp("", "cobbler-mutation-coverage-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating coverage directory: %w", err)
+	}
+	// Best-effort removal; the profiles live in the temp directory.
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	covered := lineTests{}
+	for i, name := range parseTestList(out) {
+		profile := filepath.Join(tmp, strconv.Itoa(i)+".out")
+		if _, err := test(ctx, dir, []string{"-count=1", "-run", runPattern([]string{name}), "-coverprofile=" + profile, pkg}); err != nil {
+			return nil, fmt.Errorf("measuring coverage of %s: %w", name, err)
+		}
+		lines, err := parseCoveredLines(profile)
+		if err != nil {
+			return nil, err
+		}
+		for file, nums := range lines {
+			if covered[file] == nil {
+				covered[file] = map[int][]string{}
+			}
+			for _, n := range nums {
+				covered[file][n] = append(covered[file][n], name)
+			}
+		}
+	}
+	return covered, nil
+}
+
+// parseTestList returns the test, example, and fuzz test names in go test
+// -list output.
+func parseTestList(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name := strings.TrimSpace(line); testNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseCoveredLines returns the lines of each file, by base name, that a
+// coverage profile reports as executed.
+func parseCoveredLines(profile string) (map[string][]int, error) {
+	f, err := os.Open(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := map[string][]int{}
+	seen := map[string]map[int]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, coverModePrefix) {
+			continue
+		}
+		// importpath/file.go:startLine.startCol,endLine.endCol numStmts count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed coverage line %q", line)
+		}
+		if fields[2] == "0" {
+			continue
+		}
+		file, span, ok := strings.Cut(fields[0], ":")
+		start, end, ok2 := strings.Cut(span, ",")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("malformed coverage line %q", line)
+		}
+		first, err1 := strconv.Atoi(strings.Split(start, ".")[0])
+		last, err2 := strconv.Atoi(strings.Split(end, ".")[0])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("malformed coverage line %q", line)
+		}
+		base := path.Base(file)
+		if seen[base] == nil {
+			seen[base] = map[int]bool{}
+		}
+		for n := first; n <= last; n++ {
+			if !seen[base][n] {
+				seen[base][n] = true
+				lines[base] = append(lines[base], n)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", profile, err)
+	}
+	return lines, nil
+}
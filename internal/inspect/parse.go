@@ -0,0 +1,40 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"slices"
+)
+
+// SeverityError marks evidence that fails a technique whatever its score,
+// such as a modified file that does not parse.
+const SeverityError = "error"
+
+// syntaxError returns the evidence for a file that failed to parse with
+// err. The boolean is false when err is not a syntax error, for example
+// when the file could not be read; such errors still fail the run.
+func syntaxError(file string, err error) (Evidence, bool) {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return Evidence{}, false
+	}
+	detail := "does not parse: " + list[0].Msg
+	if len(list) > 1 {
+		detail += fmt.Sprintf(" (and %d more errors)", len(list)-1)
+	}
+	return Evidence{File: file, Line: list[0].Pos.Line, Severity: SeverityError, Detail: detail}, true
+}
+
+// unparseableResult is the result of a technique that found modified files
+// that do not parse: a failure scored 0, whatever the files that parsed
+// scored, since a file that does not parse does not build. Evidence lists
+// the unparseable files first, then the technique's other evidence.
+func unparseableResult(name string, deterministic bool, unparseable, evidence []Evidence) TechniqueResult {
+	return TechniqueResult{
+		Technique:     name,
+		Verdict:       VerdictFail,
+		Evidence:      slices.Concat(unparseable, evidence),
+		Deterministic: deterministic,
+	}
+}
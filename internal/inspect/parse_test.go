@@ -0,0 +1,79 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// brokenSource fails to parse on line 6.
+const brokenSource = `package calc
+
+// Sub subtracts.
+func Sub(a, b int) int {
+	return a -
+}
+`
+
+func TestTechniques_UnparseableFile(t *testing.T) {
+	files := map[string]string{
+		"go.mod":           goModule,
+		"calc/calc.go":     "package calc\n\n// Add adds.\nfunc Add(a, b int) int { return a + b }\n",
+		"calc/sub.go":      brokenSource,
+		"calc/sub_test.go": strings.Replace(brokenSource, "Sub", "TestSub", 2),
+	}
+	source := []string{"calc/calc.go", "calc/sub.go"}
+	tests := []struct {
+		name     string
+		tech     Technique
+		modified []string
+		wantFile string
+		short    bool
+	}{
+		{"assertion", NewAssertionChecker(), []string{"calc/sub_test.go"}, "calc/sub_test.go", true},
+		{"complexity", NewComplexityRunner(ComplexityConfig{}), source, "calc/sub.go", true},
+		{"context propagation", NewContextPropagationChecker(), source, "calc/sub.go", true},
+		{"doc comments", NewDocRunner(), source, "calc/sub.go", true},
+		{"mutation", NewMutationRunner(MutationConfig{}), source, "calc/sub.go", true},
+		// Recording a baseline from a package that does not parse fails
+		// before the missing baseline directory is noticed.
+		{"api compatibility", NewAPICompatRunner(APICompatConfig{UpdateBaseline: true}), source, "calc/sub.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.short && testing.Short() {
+				t.Skip("runs go list")
+			}
+			dir := writeFiles(t, files)
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: tt.modified, ModifiedPackages: []string{"./calc"}}
+
+			result, err := tt.tech.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != VerdictFail || result.Score != 0 {
+				t.Fatalf("result = %+v, want a failure scored 0", result)
+			}
+			ev := result.Evidence[0]
+			if ev.Severity != SeverityError || ev.File != tt.wantFile || ev.Line != 6 || !strings.HasPrefix(ev.Detail, "does not parse: ") {
+				t.Errorf("evidence = %+v, want a parse error at %s:6", ev, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestWriteMutationPlan_Unparseable(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc.go": brokenSource})
+	plan, err := NewMutationRunner(MutationConfig{}).Plan(&InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc.go"}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteMutationPlan(&buf, plan); err != nil {
+		t.Fatalf("WriteMutationPlan failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "calc.go:6: error: does not parse: ") {
+		t.Errorf("plan output does not report the parse error:\n%s", buf.String())
+	}
+}
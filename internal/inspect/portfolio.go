@@ -0,0 +1,134 @@
+package inspect
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/logging"
+)
+
+// DefaultPortfolioConcurrency bounds how many techniques run at once. It is
+// small because techniques such as mutation testing run go test themselves.
+const DefaultPortfolioConcurrency = 2
+
+// PortfolioConfig controls how a Portfolio runs its techniques.
+type PortfolioConfig struct {
+	// Concurrency is the maximum number of techniques running at once.
+	// Values below 1 run one technique at a time.
+	Concurrency int
+	// Logger receives technique started/finished events; nil discards them.
+	Logger *slog.Logger
+	// CacheDir holds the technique result cache. Empty disables caching.
+	CacheDir string
+	// NoCache runs every technique, ignoring cached results; the results
+	// still refresh the cache.
+	NoCache bool
+	// TestCommand is the command, with its arguments, that runs the
+	// project's tests for the registered techniques that run them: the
+	// translation validator and the mutation runner. NewTestCommand runs
+	// it. Empty leaves each technique's own command, go test by default.
+	TestCommand []string
+	// TechniqueTimeout bounds each technique's run, as WithTimeout does, so
+	// one slow technique cannot hold up the rest. Zero leaves runs
+	// unbounded.
+	TechniqueTimeout time.Duration
+}
+
+// testCommandUser is a technique that runs the project's tests with a
+// TestCommand.
+type testCommandUser interface {
+	useTestCommand(test TestCommand)
+}
+
+// DefaultPortfolioConfig returns the default portfolio settings.
+func DefaultPortfolioConfig() PortfolioConfig {
+	return PortfolioConfig{Concurrency: DefaultPortfolioConcurrency}
+}
+
+// Portfolio runs a set of registered techniques and scores their results.
+// Its Registry reports which fault classes those techniques cover.
+type Portfolio struct {
+	Registry
+	config PortfolioConfig
+	scorer *Scorer
+}
+
+// NewPortfolio creates an empty portfolio that scores with scorer.
+func NewPortfolio(scorer *Scorer, config PortfolioConfig) *Portfolio {
+	return &Portfolio{config: config, scorer: scorer}
+}
+
+// Register adds a technique, in registration order. A technique that runs
+// the project's tests is given the configured TestCommand.
+func (p *Portfolio) Register(tech Technique) {
+	if user, ok := tech.(testCommandUser); ok && len(p.config.TestCommand) > 0 {
+		user.useTestCommand(NewTestCommand(p.config.TestCommand))
+	}
+	p.Registry.Register(tech)
+}
+
+// NewDefaultPortfolio creates a portfolio with the default scorer and
+// PortfolioTechniques registered.
+func NewDefaultPortfolio() (*Portfolio, error) {
+	scorer, err := NewScorer(DefaultScorerConfig())
+	if err != nil {
+		return nil, err
+	}
+	p := NewPortfolio(scorer, DefaultPortfolioConfig())
+	for _, tech := range PortfolioTechniques() {
+		p.Register(tech)
+	}
+	return p, nil
+}
+
+// PortfolioTechniques returns the techniques stitch inspects its output
+// with: the translation validator, the mutation runner for code, and the
+// markdown runner for docs.
+func PortfolioTechniques() []Technique {
+	return []Technique{
+		NewTranslationValidator(nil),
+		NewMutationRunner(DefaultMutationConfig()),
+		NewMarkdownRunner(),
+	}
+}
+
+// Run runs the applicable techniques concurrently, up to the configured
+// limit, and scores the results, reported in registration order.
+// Techniques that are not applicable, and techniques not finished when ctx
+// is cancelled, contribute a skip result.
+// With a CacheDir, a technique that already produced a result for the same
+// input and settings is not run again; its cached result is used.
+func (p *Portfolio) Run(ctx context.Context, input *InspectInput) (CompositeResult, error) {
+	cache, err := p.loadResultCache(input)
+	if err != nil {
+		return CompositeResult{}, err
+	}
+	// Techniques are wrapped only for this run, so Register and resultKey
+	// see the registered technique itself. A run that times out is skipped,
+	// which the cache never stores.
+	techniques := make([]Technique, len(p.techniques))
+	for i, tech := range p.techniques {
+		techniques[i] = WithTimeout(tech, p.config.TechniqueTimeout)
+		if cache == nil {
+			continue
+		}
+		key, cacheable, err := p.resultKey(tech)
+		if err != nil {
+			return CompositeResult{}, err
+		}
+		if cacheable {
+			techniques[i] = &cachedTechnique{Technique: techniques[i], cache: cache, key: key}
+		}
+	}
+	results, err := runTechniques(ctx, techniques, input, p.config.Concurrency, p.config.Logger)
+	if err != nil {
+		return CompositeResult{}, err
+	}
+	if err := cache.save(); err != nil {
+		return CompositeResult{}, err
+	}
+	cr := p.scorer.Score(results)
+	logging.OrDiscard(p.config.Logger).Info("inspect scored", logging.KeyAction, cr.Action, logging.KeyScore, cr.Score)
+	return cr, nil
+}
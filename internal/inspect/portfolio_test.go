@@ -0,0 +1,336 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTechnique is a technique with a canned applicability and result. A
+// positive delay makes Run wait that long, or until ctx is cancelled.
+type fakeTechnique struct {
+	name       string
+	applicable bool
+	result     TechniqueResult
+	err        error
+	delay      time.Duration
+	ran        atomic.Bool
+	running    *atomic.Int32
+	peak       *atomic.Int32
+}
+
+func (f *fakeTechnique) Name() string       { return f.name }
+func (f *fakeTechnique) FaultClass() string { return FaultTestInadequacy }
+
+func (f *fakeTechnique) Applicable(*InspectInput) (bool, string) {
+	if !f.applicable {
+		return false, "not applicable"
+	}
+	return true, ""
+}
+
+func (f *fakeTechnique) Run(ctx context.Context, _ *InspectInput) (TechniqueResult, error) {
+	f.ran.Store(true)
+	if f.running != nil {
+		n := f.running.Add(1)
+		defer f.running.Add(-1)
+		for {
+			peak := f.peak.Load()
+			if n <= peak || f.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+	}
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return TechniqueResult{Technique: f.name, Score: 0.1, Verdict: VerdictFail}, ctx.Err()
+		}
+	}
+	result := f.result
+	result.Technique = f.name
+	return result, f.err
+}
+
+// passing returns an applicable fake that passes after delay.
+func passing(name string, delay time.Duration) *fakeTechnique {
+	return &fakeTechnique{name: name, applicable: true, delay: delay,
+		result: TechniqueResult{Score: 1, Verdict: VerdictPass, Deterministic: true}}
+}
+
+func newTestPortfolio(t *testing.T, concurrency int, techs ...*fakeTechnique) *Portfolio {
+	t.Helper()
+	p := NewPortfolio(newScorer(t, DefaultScorerConfig()), PortfolioConfig{Concurrency: concurrency})
+	for _, tech := range techs {
+		p.Register(tech)
+	}
+	return p
+}
+
+func TestPortfolio_Run(t *testing.T) {
+	translation := passing(TranslationValidatorName, 0)
+	mutation := passing(MutationRunnerName, 0)
+	differential := &fakeTechnique{name: DifferentialTestingName}
+
+	p := newTestPortfolio(t, 2, translation, mutation, differential)
+	cr, err := p.Run(context.Background(), &InspectInput{WorkType: WorkTypeCode})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if differential.ran.Load() {
+		t.Error("Run ran an inapplicable technique")
+	}
+	if len(cr.Results) != 3 || cr.Results[2].Verdict != VerdictSkip {
+		t.Fatalf("results = %+v, want two runs and a skip", cr.Results)
+	}
+	if cr.Action != ActionAccept || !cr.Valid {
+		t.Errorf("composite = %+v, want a valid accept", cr)
+	}
+}
+
+func TestPortfolio_RunKeepsRegistrationOrder(t *testing.T) {
+	// Earlier techniques finish last, so completion order is reversed.
+	techs := []*fakeTechnique{
+		passing(TranslationValidatorName, 30*time.Millisecond),
+		passing(MutationRunnerName, 20*time.Millisecond),
+		passing(DifferentialTestingName, 10*time.Millisecond),
+		passing(PropertyBasedRunnerName, 0),
+	}
+	cr, err := newTestPortfolio(t, len(techs), techs...).Run(context.Background(), &InspectInput{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for i, tech := range techs {
+		if cr.Results[i].Technique != tech.name {
+			t.Errorf("Results[%d] = %s, want %s", i, cr.Results[i].Technique, tech.name)
+		}
+	}
+}
+
+func TestPortfolio_RunBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	var techs []*fakeTechnique
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		tech := passing(name, 10*time.Millisecond)
+		tech.running, tech.peak = &running, &peak
+		techs = append(techs, tech)
+	}
+	if _, err := newTestPortfolio(t, 2, techs...).Run(context.Background(), &InspectInput{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", got)
+	}
+}
+
+func TestPortfolio_RunCancelled(t *testing.T) {
+	fast := passing(TranslationValidatorName, 0)
+	slow := passing(MutationRunnerName, time.Minute)
+	queued := passing(DifferentialTestingName, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	cr, err := newTestPortfolio(t, 2, fast, slow, queued).Run(ctx, &InspectInput{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Run took %v after cancellation", elapsed)
+	}
+	if cr.Results[0].Verdict != VerdictPass {
+		t.Errorf("finished technique = %+v, want its pass kept", cr.Results[0])
+	}
+	for _, r := range cr.Results[1:] {
+		if r.Verdict != VerdictSkip || skipReason(r) != "cancelled: context deadline exceeded" {
+			t.Errorf("cancelled technique = %+v, want a cancellation skip", r)
+		}
+	}
+	if cr.Valid {
+		t.Errorf("composite = %+v, want invalid with one scored technique", cr)
+	}
+}
+
+func TestPortfolio_RunError(t *testing.T) {
+	boom := errors.New("boom")
+	failing := &fakeTechnique{name: MutationRunnerName, applicable: true, err: boom}
+	slow := passing(TranslationValidatorName, time.Minute)
+	p := newTestPortfolio(t, 2, slow, failing)
+	if _, err := p.Run(context.Background(), &InspectInput{}); !errors.Is(err, boom) {
+		t.Errorf("Run error = %v, want %v", err, boom)
+	}
+}
+
+func TestNewDefaultPortfolio(t *testing.T) {
+	p, err := NewDefaultPortfolio()
+	if err != nil {
+		t.Fatalf("NewDefaultPortfolio: %v", err)
+	}
+	var names []string
+	for _, tech := range p.Techniques() {
+		names = append(names, tech.Name())
+	}
+	want := []string{TranslationValidatorName, MutationRunnerName, MarkdownRunnerName}
+	if !slices.Equal(names, want) {
+		t.Errorf("default techniques = %v, want %v", names, want)
+	}
+}
+
+func TestPortfolio_ResultCache(t *testing.T) {
+	dir := t.TempDir()
+	translation := passing(TranslationValidatorName, 0)
+	mutation := passing(MutationRunnerName, 0)
+	newCached := func(noCache bool) *Portfolio {
+		p := NewPortfolio(newScorer(t, DefaultScorerConfig()), PortfolioConfig{CacheDir: dir, NoCache: noCache})
+		p.Register(translation)
+		p.Register(mutation)
+		return p
+	}
+	input := &InspectInput{WorkType: WorkTypeCode, ModifiedPackages: []string{"./a", "./b"}, Diff: "diff one"}
+
+	tests := []struct {
+		name    string
+		noCache bool
+		modify  func(*InspectInput)
+		wantRan bool
+	}{
+		{"first run misses", false, func(*InspectInput) {}, true},
+		{"unchanged input hits", false, func(*InspectInput) {}, false},
+		{"package order ignored", false, func(in *InspectInput) { in.ModifiedPackages = []string{"./b", "./a"} }, false},
+		{"other packages miss", false, func(in *InspectInput) { in.ModifiedPackages = []string{"./a"} }, true},
+		{"other criteria miss", false, func(in *InspectInput) { in.PRDCriteria = []string{"R1"} }, true},
+		{"other fixtures miss", false, func(in *InspectInput) { in.FixtureDir = "fixtures" }, true},
+		{"no cache runs", true, func(*InspectInput) {}, true},
+		{"new diff misses", false, func(in *InspectInput) { in.Diff = "diff two" }, true},
+		{"old diff invalidated", false, func(*InspectInput) {}, true},
+	}
+	for _, tt := range tests {
+		translation.ran.Store(false)
+		mutation.ran.Store(false)
+		in := *input
+		tt.modify(&in)
+		cr, err := newCached(tt.noCache).Run(context.Background(), &in)
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", tt.name, err)
+		}
+		if translation.ran.Load() != tt.wantRan || mutation.ran.Load() != tt.wantRan {
+			t.Errorf("%s: ran = %v, %v; want %v", tt.name, translation.ran.Load(), mutation.ran.Load(), tt.wantRan)
+		}
+		if cr.Action != ActionAccept || len(cr.Results) != 2 || cr.Results[0].Technique != TranslationValidatorName {
+			t.Errorf("%s: result %+v, want an accept with both results in order", tt.name, cr)
+		}
+	}
+
+	if _, err := newCached(false).Run(context.Background(), &InspectInput{WorkType: WorkTypeCode}); err != nil {
+		t.Fatal(err)
+	}
+	translation.ran.Store(false)
+	if _, err := newCached(false).Run(context.Background(), &InspectInput{WorkType: WorkTypeCode}); err != nil {
+		t.Fatal(err)
+	}
+	if !translation.ran.Load() {
+		t.Error("input without a diff was served from the cache")
+	}
+}
+
+func TestPortfolio_ResultKey(t *testing.T) {
+	key := func(config PortfolioConfig, tech Technique) (string, bool) {
+		t.Helper()
+		k, cacheable, err := NewPortfolio(newScorer(t, DefaultScorerConfig()), config).resultKey(tech)
+		if err != nil {
+			t.Fatalf("resultKey: %v", err)
+		}
+		return k, cacheable
+	}
+	mutation := func(modify func(*MutationConfig)) Technique {
+		config := DefaultMutationConfig()
+		modify(&config)
+		return NewMutationRunner(config)
+	}
+	unchanged := func(*MutationConfig) {}
+	base, _ := key(PortfolioConfig{}, mutation(unchanged))
+
+	tests := []struct {
+		name          string
+		config        PortfolioConfig
+		tech          Technique
+		wantSame      bool
+		wantCacheable bool
+	}{
+		{"same settings", PortfolioConfig{}, mutation(unchanged), true, true},
+		{"cache settings ignored", PortfolioConfig{}, mutation(func(c *MutationConfig) { c.CacheDir, c.NoCache = "elsewhere", true }), true, true},
+		{"operators", PortfolioConfig{}, mutation(func(c *MutationConfig) { c.EnabledOperators = []MutationType{MutationArithmetic} }), false, true},
+		{"changed lines only", PortfolioConfig{}, mutation(func(c *MutationConfig) { c.ChangedLinesOnly = true }), false, true},
+		{"test command", PortfolioConfig{TestCommand: []string{"./test.sh"}}, mutation(unchanged), false, true},
+		{"bench", PortfolioConfig{}, NewBenchRunner(DefaultBenchConfig()), false, true},
+		{"bench updating baseline", PortfolioConfig{}, NewBenchRunner(BenchConfig{UpdateBaseline: true}), false, false},
+		{"api updating baseline", PortfolioConfig{}, NewAPICompatRunner(APICompatConfig{UpdateBaseline: true}), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cacheable := key(tt.config, tt.tech)
+			if cacheable != tt.wantCacheable {
+				t.Fatalf("cacheable = %v, want %v", cacheable, tt.wantCacheable)
+			}
+			if cacheable && (got == base) != tt.wantSame {
+				t.Errorf("key equal to the default mutation key = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestPortfolio_TestCommand(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":      goModule,
+		"calc/add.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedFiles: []string{"calc/add.go"}, ModifiedPackages: []string{"./calc"}}
+
+	// The script logs its arguments; the tests pass on the unmutated module
+	// and fail on every mutant.
+	scripts := t.TempDir()
+	log := filepath.Join(scripts, "calls.log")
+	script := filepath.Join(scripts, "test.sh")
+	body := fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\n[ \"$(pwd)\" = %s ] && exit 0\necho '--- FAIL: TestAdd (0.00s)'\necho FAIL\nexit 1\n", log, dir)
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	validator := NewTranslationValidator(nil)
+	validator.buildCheck = func(context.Context, []string) error { return nil }
+	validator.vetCheck = func(context.Context, []string) error { return nil }
+
+	// A technique timeout must not hide the techniques that run tests.
+	p := NewPortfolio(newScorer(t, DefaultScorerConfig()), PortfolioConfig{TestCommand: []string{script}, TechniqueTimeout: time.Minute})
+	p.Register(validator)
+	p.Register(NewMutationRunner(MutationConfig{Workers: 1, EnabledOperators: []MutationType{MutationArithmetic}}))
+	cr, err := p.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range cr.Results {
+		if r.Verdict != VerdictPass || r.Score != 1 {
+			t.Errorf("%s = %+v, want a pass from the script's results", r.Technique, r)
+		}
+	}
+	raw, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(calls) != 2 {
+		t.Fatalf("test command called with %q, want once for the tests_pass check and once for the mutant", calls)
+	}
+	for _, args := range calls {
+		if args != "-count=1 ./calc" {
+			t.Errorf("test command args = %q, want -count=1 and the package", args)
+		}
+	}
+}
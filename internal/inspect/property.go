@@ -0,0 +1,278 @@
+package inspect
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PropertyBasedRunnerName identifies the property-based runner in weights and reports.
+const PropertyBasedRunnerName = "property_based_testing"
+
+// FaultInvariantViolation is the fault class targeted by property-based testing.
+const FaultInvariantViolation = "invariant violations and edge case failures"
+
+// Corpus layout constants, compatible with Go's native fuzz corpus
+// (testdata/fuzz/<FuzzName>/<hash>, "go test fuzz v1" encoding).
+const (
+	corpusRoot      = "testdata/fuzz"
+	corpusHeader    = "go test fuzz v1"
+	corpusBytesOpen = "[]byte("
+)
+
+// Default generator settings.
+const (
+	DefaultPropertyIterations  = 100
+	DefaultPropertyMaxInputLen = 64
+)
+
+// Property is a testable invariant traced to a PRD requirement.
+type Property struct {
+	// Name identifies the property and names its corpus directory.
+	Name string
+	// RequirementID traces the property to its source PRD requirement.
+	RequirementID string
+	// Package is the package directory, relative to InspectInput.Dir, that
+	// owns the property's corpus.
+	Package string
+	// Check returns an error when input violates the property.
+	Check func(input []byte) error
+}
+
+// PropertyConfig controls input generation for the PropertyBasedRunner.
+type PropertyConfig struct {
+	// Iterations is the number of random inputs tried per property after the
+	// corpus is replayed. Zero disables randomization.
+	Iterations int
+	// Seed makes random generation reproducible.
+	Seed uint64
+	// MaxInputLen bounds the length of generated inputs.
+	MaxInputLen int
+}
+
+// DefaultPropertyConfig returns the default generator settings.
+func DefaultPropertyConfig() PropertyConfig {
+	return PropertyConfig{
+		Iterations:  DefaultPropertyIterations,
+		MaxInputLen: DefaultPropertyMaxInputLen,
+	}
+}
+
+// PropertyBasedRunner checks properties against recorded and random inputs.
+// Every run replays the per-package seed corpus first, so known failures are
+// caught deterministically, then generates random inputs. A newly found
+// failing input is persisted into the corpus as a permanent regression case.
+type PropertyBasedRunner struct {
+	config     PropertyConfig
+	properties []Property
+}
+
+// NewPropertyBasedRunner creates a runner for the given properties.
+func NewPropertyBasedRunner(config PropertyConfig, properties ...Property) *PropertyBasedRunner {
+	return &PropertyBasedRunner{config: config, properties: properties}
+}
+
+// cacheConfig returns the runner's settings and property names for the
+// result cache.
+func (p *PropertyBasedRunner) cacheConfig() (any, bool) {
+	names := make([]string, len(p.properties))
+	for i, prop := range p.properties {
+		names[i] = prop.Name
+	}
+	return struct {
+		Config     PropertyConfig
+		Properties []string
+	}{p.config, names}, true
+}
+
+// Name returns the technique identifier.
+func (p *PropertyBasedRunner) Name() string { return PropertyBasedRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (p *PropertyBasedRunner) FaultClass() string { return FaultInvariantViolation }
+
+// Applicable reports whether the input is code work with properties to check.
+func (p *PropertyBasedRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(p.properties) == 0 {
+		return false, "no properties derived"
+	}
+	return true, ""
+}
+
+// Run replays each property's corpus, then tries random inputs. The score is
+// the fraction of properties that held for every input.
+func (p *PropertyBasedRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if len(p.properties) == 0 {
+		return skipResult(p.Name(), true, "no properties derived"), nil
+	}
+
+	rng := rand.New(rand.NewPCG(p.config.Seed, p.config.Seed))
+	var passing int
+	var evidence []Evidence
+	for _, prop := range p.properties {
+		if ctx.Err() != nil {
+			return TechniqueResult{}, ctx.Err()
+		}
+		ev, err := p.checkProperty(input, prop, rng)
+		if err != nil {
+			return TechniqueResult{}, err
+		}
+		if ev == nil {
+			passing++
+			continue
+		}
+		evidence = append(evidence, *ev)
+	}
+
+	verdict := VerdictPass
+	if len(evidence) > 0 {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     p.Name(),
+		Score:         float64(passing) / float64(len(p.properties)),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// checkProperty returns evidence for the first input that violates prop, or
+// nil when the property holds.
+func (p *PropertyBasedRunner) checkProperty(input *InspectInput, prop Property, rng *rand.Rand) (*Evidence, error) {
+	dir := input.path(corpusDir(prop))
+
+	entries, err := readCorpus(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if checkErr := prop.Check(entry.data); checkErr != nil {
+			return propertyEvidence(prop, entry.path, entry.data, checkErr, "replayed from corpus"), nil
+		}
+	}
+
+	for i := 0; i < p.config.Iterations; i++ {
+		data := randomInput(rng, p.config.MaxInputLen)
+		checkErr := prop.Check(data)
+		if checkErr == nil {
+			continue
+		}
+		path, err := writeCorpusEntry(dir, data)
+		if err != nil {
+			return nil, err
+		}
+		return propertyEvidence(prop, path, data, checkErr, "recorded to corpus"), nil
+	}
+	return nil, nil
+}
+
+// propertyEvidence describes a property violation with its counterexample.
+func propertyEvidence(prop Property, path string, data []byte, checkErr error, origin string) *Evidence {
+	return &Evidence{
+		CriterionID: prop.RequirementID,
+		File:        path,
+		Detail:      fmt.Sprintf("property %s violated by input %q (%s): %v", prop.Name, data, origin, checkErr),
+	}
+}
+
+// corpusDir returns the corpus directory of prop relative to the input directory.
+func corpusDir(prop Property) string {
+	return filepath.Join(prop.Package, filepath.FromSlash(corpusRoot), prop.Name)
+}
+
+// randomInput generates a random byte slice of up to maxLen bytes.
+func randomInput(rng *rand.Rand, maxLen int) []byte {
+	data := make([]byte, rng.IntN(maxLen+1))
+	for i := range data {
+		data[i] = byte(rng.UintN(256))
+	}
+	return data
+}
+
+// corpusEntry is one recorded input.
+type corpusEntry struct {
+	path string
+	data []byte
+}
+
+// readCorpus loads every entry in dir in file name order. A missing
+// directory is an empty corpus.
+func readCorpus(dir string) ([]corpusEntry, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading corpus %s: %w", dir, err)
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	entries := make([]corpusEntry, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading corpus entry %s: %w", path, err)
+		}
+		data, err := decodeCorpusEntry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decoding corpus entry %s: %w", path, err)
+		}
+		entries = append(entries, corpusEntry{path: path, data: data})
+	}
+	return entries, nil
+}
+
+// writeCorpusEntry persists data into dir, named by the content hash as Go's
+// fuzzer does, and returns the entry path.
+func writeCorpusEntry(dir string, data []byte) (string, error) {
+	raw := encodeCorpusEntry(data)
+	name := fmt.Sprintf("%x", sha256.Sum256(raw))[:16]
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating corpus %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return "", fmt.Errorf("writing corpus entry %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// encodeCorpusEntry renders data in the go test fuzz v1 format.
+func encodeCorpusEntry(data []byte) []byte {
+	return []byte(corpusHeader + "\n" + corpusBytesOpen + strconv.Quote(string(data)) + ")\n")
+}
+
+// decodeCorpusEntry parses a go test fuzz v1 file holding a single []byte value.
+func decodeCorpusEntry(raw []byte) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 || strings.TrimSpace(lines[0]) != corpusHeader {
+		return nil, fmt.Errorf("want %q header and one []byte value", corpusHeader)
+	}
+	value := strings.TrimSpace(lines[1])
+	if !strings.HasPrefix(value, corpusBytesOpen) || !strings.HasSuffix(value, ")") {
+		return nil, fmt.Errorf("unsupported corpus value %q", value)
+	}
+	s, err := strconv.Unquote(strings.TrimSuffix(strings.TrimPrefix(value, corpusBytesOpen), ")"))
+	if err != nil {
+		return nil, fmt.Errorf("unquoting corpus value: %w", err)
+	}
+	return []byte(s), nil
+}
@@ -0,0 +1,127 @@
+package inspect
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RaceRunnerName identifies the race runner in weights and reports.
+const RaceRunnerName = "race_detection"
+
+// Race detector report markers.
+const (
+	raceWarning   = "WARNING: DATA RACE"
+	raceSeparator = "=================="
+)
+
+// RaceRunner runs the modified packages' tests under the race detector. The
+// score is 1 when no data race is reported and 0 otherwise; each report
+// becomes evidence at the first stack frame it names.
+//
+// The race detector has no false positives, but it only sees the
+// interleavings that happen to occur while the tests run, so a clean result
+// may not reproduce. Results are therefore marked non-deterministic.
+type RaceRunner struct {
+	runTests func(ctx context.Context, dir string, pkgs []string) (string, error)
+}
+
+// NewRaceRunner creates a RaceRunner that runs go test -race.
+func NewRaceRunner() *RaceRunner {
+	return &RaceRunner{runTests: func(ctx context.Context, dir string, pkgs []string) (string, error) {
+		return runGo(ctx, dir, append([]string{"test", "-race", "-count=1"}, pkgs...)...)
+	}}
+}
+
+// Name returns the technique identifier.
+func (r *RaceRunner) Name() string { return RaceRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (r *RaceRunner) FaultClass() string { return FaultConcurrency }
+
+// Applicable reports whether the input is code work with modified packages.
+func (r *RaceRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run tests the modified packages with -race. When the tests fail without a
+// race report, races were not evaluated and the result is skipped.
+func (r *RaceRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	dir := input.Dir
+	if dir == "" {
+		dir = "."
+	}
+	out, testErr := r.runTests(ctx, dir, input.ModifiedPackages)
+	if ctx.Err() != nil {
+		return TechniqueResult{}, ctx.Err()
+	}
+	races := parseRaces(out)
+	if len(races) == 0 && testErr != nil {
+		result := skipResult(r.Name(), false, "tests failed; races not evaluated")
+		result.Evidence = append(result.Evidence, Evidence{Detail: checkDetail(testErr)})
+		return result, nil
+	}
+
+	evidence := make([]Evidence, 0, len(races))
+	for _, report := range races {
+		file, line := raceLocation(report)
+		evidence = append(evidence, Evidence{
+			File:   relativeTo(input.Dir, file),
+			Line:   line,
+			Detail: "data race:\n" + trimStack(report),
+		})
+	}
+	score, verdict := 1.0, VerdictPass
+	if len(evidence) > 0 {
+		score, verdict = 0, VerdictFail
+	}
+	return TechniqueResult{
+		Technique: r.Name(),
+		Score:     score,
+		Verdict:   verdict,
+		Evidence:  evidence,
+	}, nil
+}
+
+// parseRaces returns the body of each data race report in go test output,
+// without the warning line and separators.
+func parseRaces(out string) []string {
+	var races []string
+	var current []string
+	inRace := false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.TrimSpace(line) == raceWarning:
+			inRace, current = true, nil
+		case inRace && strings.TrimSpace(line) == raceSeparator:
+			races = append(races, strings.TrimSpace(strings.Join(current, "\n")))
+			inRace = false
+		case inRace:
+			current = append(current, line)
+		}
+	}
+	return races
+}
+
+// raceLocation returns the file and line of the first stack frame in a race
+// report, or "" and 0 when it names none.
+func raceLocation(report string) (string, int) {
+	for _, line := range strings.Split(report, "\n") {
+		loc, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+		file, num, ok := strings.Cut(loc, ".go:")
+		if !ok || !filepath.IsAbs(file) {
+			continue
+		}
+		if n, err := strconv.Atoi(num); err == nil {
+			return file + ".go", n
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,81 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// raceOutput is go test -race output reporting one data race.
+const raceOutput = `==================
+WARNING: DATA RACE
+Write at 0x00c00001c0f8 by goroutine 8:
+  example.com/m/calc.(*Counter).Inc()
+      /work/calc/calc.go:9 +0x44
+  example.com/m/calc.TestCounter.func1()
+      /work/calc/calc_test.go:12 +0x30
+
+Previous write at 0x00c00001c0f8 by goroutine 7:
+  example.com/m/calc.(*Counter).Inc()
+      /work/calc/calc.go:9 +0x44
+==================
+--- FAIL: TestCounter (0.00s)
+    testing.go:1490: race detected during execution of test
+FAIL
+FAIL	example.com/m/calc	0.012s
+`
+
+func TestRaceRunner(t *testing.T) {
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: "/work", ModifiedPackages: []string{"./calc"}}
+
+	tests := []struct {
+		name        string
+		out         string
+		err         error
+		wantVerdict Verdict
+		wantScore   float64
+	}{
+		{"clean", "ok  \texample.com/m/calc\t1.02s\n", nil, VerdictPass, 1},
+		{"race detected", raceOutput, errors.New("exit status 1"), VerdictFail, 0},
+		{"tests fail without race", "--- FAIL: TestAdd (0.00s)\nFAIL\n", errors.New("exit status 1"), VerdictSkip, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRaceRunner()
+			runner.runTests = func(_ context.Context, dir string, pkgs []string) (string, error) {
+				if dir != "/work" || len(pkgs) != 1 || pkgs[0] != "./calc" {
+					t.Errorf("runTests(%q, %q), want /work and ./calc", dir, pkgs)
+				}
+				return tt.out, tt.err
+			}
+			result, err := runner.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore || result.Deterministic {
+				t.Fatalf("result = %+v, want verdict %s, score %v, non-deterministic", result, tt.wantVerdict, tt.wantScore)
+			}
+			if tt.wantVerdict != VerdictFail {
+				return
+			}
+			if len(result.Evidence) != 1 {
+				t.Fatalf("Evidence = %+v, want one race", result.Evidence)
+			}
+			ev := result.Evidence[0]
+			if ev.File != "calc/calc.go" || ev.Line != 9 || !strings.Contains(ev.Detail, "Write at 0x00c00001c0f8 by goroutine 8") {
+				t.Errorf("Evidence = %+v, want the race at calc/calc.go:9", ev)
+			}
+		})
+	}
+}
+
+func TestRaceRunner_Applicable(t *testing.T) {
+	r := NewRaceRunner()
+	if ok, _ := r.Applicable(&InspectInput{WorkType: WorkTypeDocs, ModifiedPackages: []string{"./calc"}}); ok {
+		t.Error("race runner should not apply to docs tasks")
+	}
+	if ok, _ := r.Applicable(&InspectInput{WorkType: WorkTypeCode}); ok {
+		t.Error("race runner should not apply without modified packages")
+	}
+}
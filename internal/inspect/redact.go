@@ -0,0 +1,106 @@
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// redactionMask replaces every redacted substring.
+const redactionMask = "[REDACTED]"
+
+// High-entropy detection settings. Candidates shorter than the minimum or
+// below the entropy threshold (bits per character) are left alone, which
+// keeps hex digests (at most 4 bits per character) readable.
+const (
+	highEntropyMinLen    = 32
+	highEntropyThreshold = 4.5
+)
+
+// DefaultRedactionPatterns match common secret shapes.
+var DefaultRedactionPatterns = []string{
+	`AKIA[0-9A-Z]{16}`,                   // AWS access key ID
+	`gh[pousr]_[A-Za-z0-9]{36,}`,         // GitHub token
+	`xox[abprs]-[A-Za-z0-9-]{10,}`,       // Slack token
+	`\bsk-[A-Za-z0-9_-]{20,}`,            // API secret key
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`, // PEM private key header
+	`(?i)(?:api[_-]?key|secret|token|password)\s*[:=]\s*["']?[^\s"']{8,}`,
+}
+
+// highEntropyCandidate matches long runs of base64/token characters.
+var highEntropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+// Redactor masks secrets in evidence before results are serialized. It never
+// modifies the results it is given; the raw evidence stays in memory.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor compiles the given patterns. High-entropy strings are always
+// masked in addition to the patterns.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("compiling redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact masks every pattern match and high-entropy substring in s.
+func (r *Redactor) Redact(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, redactionMask)
+	}
+	return highEntropyCandidate.ReplaceAllStringFunc(s, func(m string) string {
+		if len(m) >= highEntropyMinLen && shannonEntropy(m) >= highEntropyThreshold {
+			return redactionMask
+		}
+		return m
+	})
+}
+
+// RedactResults returns a copy of results with every evidence detail redacted.
+func (r *Redactor) RedactResults(results []TechniqueResult) []TechniqueResult {
+	if results == nil {
+		return nil
+	}
+	out := make([]TechniqueResult, len(results))
+	for i, res := range results {
+		out[i] = res
+		if res.Evidence == nil {
+			continue
+		}
+		out[i].Evidence = make([]Evidence, len(res.Evidence))
+		for j, ev := range res.Evidence {
+			ev.Detail = r.Redact(ev.Detail)
+			out[i].Evidence[j] = ev
+		}
+	}
+	return out
+}
+
+// RedactComposite returns a copy of cr with every evidence detail redacted.
+func (r *Redactor) RedactComposite(cr CompositeResult) CompositeResult {
+	cr.Results = r.RedactResults(cr.Results)
+	return cr
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	var n int
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
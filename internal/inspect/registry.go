@@ -0,0 +1,116 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// KnownFaultClasses lists every fault class a built-in technique targets, in
+// reporting order.
+var KnownFaultClasses = []string{
+	FaultSpecConformance,
+	FaultTestInadequacy,
+	FaultRegression,
+	FaultInvariantViolation,
+	FaultConcurrency,
+	FaultCancellation,
+	FaultSecurity,
+	FaultVulnerableDependency,
+	FaultPerformance,
+	FaultMaintainability,
+	FaultStyleConformance,
+	FaultAPICompatibility,
+}
+
+// ErrUnknownTechnique reports a technique name that no built-in technique
+// has.
+var ErrUnknownTechnique = fmt.Errorf("inspect: unknown technique")
+
+// TechniqueNames lists the name of every built-in technique: those of
+// DefaultTechniques, in order, then the opt-in security, benchmark, and API
+// compatibility runners.
+func TechniqueNames() []string {
+	var names []string
+	for _, tech := range DefaultTechniques() {
+		names = append(names, tech.Name())
+	}
+	return append(names, SecurityRunnerName, BenchRunnerName, APICompatRunnerName)
+}
+
+// CheckTechniqueNames returns ErrUnknownTechnique for the first name that
+// is not in TechniqueNames.
+func CheckTechniqueNames(names []string) error {
+	known := TechniqueNames()
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("%w %q (valid: %s)", ErrUnknownTechnique, name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// EnableTechniques returns the techniques named in enabled, keeping their
+// order. An empty enabled keeps every technique.
+func EnableTechniques(techniques []Technique, enabled []string) []Technique {
+	if len(enabled) == 0 {
+		return techniques
+	}
+	return slices.DeleteFunc(slices.Clone(techniques), func(tech Technique) bool {
+		return !slices.Contains(enabled, tech.Name())
+	})
+}
+
+// Registry tracks a set of techniques and the fault classes they cover.
+type Registry struct {
+	techniques []Technique
+}
+
+// NewRegistry creates a registry holding techniques.
+func NewRegistry(techniques ...Technique) *Registry {
+	return &Registry{techniques: slices.Clone(techniques)}
+}
+
+// Register adds a technique. Techniques are kept in registration order.
+func (r *Registry) Register(tech Technique) {
+	r.techniques = append(r.techniques, tech)
+}
+
+// Techniques returns the registered techniques.
+func (r *Registry) Techniques() []Technique {
+	return r.techniques
+}
+
+// FaultClassCoverage maps each fault class targeted by a registered
+// technique to the names of those techniques, in registration order.
+func (r *Registry) FaultClassCoverage() map[string][]string {
+	coverage := map[string][]string{}
+	for _, tech := range r.techniques {
+		coverage[tech.FaultClass()] = append(coverage[tech.FaultClass()], tech.Name())
+	}
+	return coverage
+}
+
+// UncoveredFaultClasses returns the known fault classes that no registered
+// technique targets, in KnownFaultClasses order.
+func (r *Registry) UncoveredFaultClasses() []string {
+	coverage := r.FaultClassCoverage()
+	var uncovered []string
+	for _, class := range KnownFaultClasses {
+		if len(coverage[class]) == 0 {
+			uncovered = append(uncovered, class)
+		}
+	}
+	return uncovered
+}
+
+// WriteFaultCoverage prints a warning line for each uncovered fault class.
+func WriteFaultCoverage(w io.Writer, uncovered []string) error {
+	for _, class := range uncovered {
+		if _, err := fmt.Fprintf(w, "warning: no technique covers %s\n", class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
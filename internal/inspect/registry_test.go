@@ -0,0 +1,50 @@
+package inspect
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestRegistry_FaultClassCoverage(t *testing.T) {
+	r := NewRegistry(NewGoroutineLeakChecker(), NewAssertionChecker())
+	r.Register(NewRaceRunner())
+
+	coverage := r.FaultClassCoverage()
+	if got := coverage[FaultConcurrency]; !slices.Equal(got, []string{GoroutineLeakCheckerName, RaceRunnerName}) {
+		t.Errorf("concurrency coverage = %q, want the leak checker and race runner", got)
+	}
+	if got := coverage[FaultTestInadequacy]; !slices.Equal(got, []string{AssertionCheckerName}) {
+		t.Errorf("test inadequacy coverage = %q, want the assertion checker", got)
+	}
+
+	uncovered := r.UncoveredFaultClasses()
+	if slices.Contains(uncovered, FaultConcurrency) || !slices.Contains(uncovered, FaultSecurity) {
+		t.Errorf("uncovered = %q, want security but not concurrency", uncovered)
+	}
+	if len(uncovered) != len(KnownFaultClasses)-2 {
+		t.Errorf("uncovered = %q, want every known class but two", uncovered)
+	}
+}
+
+func TestRegistry_DefaultTechniquesUseKnownClasses(t *testing.T) {
+	techniques := append(DefaultTechniques(), NewSecurityRunner(), NewBenchRunner(DefaultBenchConfig()), NewAPICompatRunner(APICompatConfig{}))
+	for _, tech := range techniques {
+		if !slices.Contains(KnownFaultClasses, tech.FaultClass()) {
+			t.Errorf("%s targets %q, which is not in KnownFaultClasses", tech.Name(), tech.FaultClass())
+		}
+	}
+	if uncovered := NewRegistry(techniques...).UncoveredFaultClasses(); len(uncovered) != 0 {
+		t.Errorf("uncovered = %q, want every known class covered", uncovered)
+	}
+}
+
+func TestWriteFaultCoverage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFaultCoverage(&buf, []string{FaultConcurrency}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "warning: no technique covers concurrency faults\n"; got != want {
+		t.Errorf("WriteFaultCoverage = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package inspect
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ReportSchemaVersion versions the JSON report document. Bump it when a
+// field is removed or changes meaning; adding fields keeps the version.
+const ReportSchemaVersion = "1"
+
+// Report is the stable JSON document describing one inspect outcome.
+type Report struct {
+	SchemaVersion       string            `json:"schema_version"`
+	Score               float64           `json:"score"`
+	Action              Action            `json:"action"`
+	Valid               bool              `json:"valid"`
+	Reason              string            `json:"reason,omitempty"`
+	VetoedBy            string            `json:"vetoed_by,omitempty"`
+	DeterministicWeight float64           `json:"deterministic_weight"`
+	MinDeterministic    float64           `json:"min_deterministic"`
+	Confidence          float64           `json:"confidence"`
+	Techniques          []TechniqueResult `json:"techniques"`
+}
+
+// Report converts cr into the versioned report document.
+func (cr CompositeResult) Report() Report {
+	techniques := cr.Results
+	if techniques == nil {
+		techniques = []TechniqueResult{}
+	}
+	return Report{
+		SchemaVersion:       ReportSchemaVersion,
+		Score:               cr.Score,
+		Action:              cr.Action,
+		Valid:               cr.Valid,
+		Reason:              cr.Reason,
+		VetoedBy:            cr.VetoedBy,
+		DeterministicWeight: cr.DeterministicWeight,
+		MinDeterministic:    cr.MinDeterministic,
+		Confidence:          cr.Confidence,
+		Techniques:          techniques,
+	}
+}
+
+// EvidenceGroup is the evidence reported at one location, across
+// techniques.
+type EvidenceGroup struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Techniques names the techniques that contributed evidence, in result
+	// order.
+	Techniques []string `json:"techniques"`
+	// Evidence holds the distinct evidence entries, in result order.
+	Evidence []Evidence `json:"evidence"`
+}
+
+// GroupedEvidence groups the evidence of every technique that was not
+// skipped by file and line, so one location flagged by several techniques
+// appears once. Identical entries are kept once. Groups are ordered by file
+// and line; evidence without a file has no location to share and forms one
+// group per technique, after the located groups.
+func (cr CompositeResult) GroupedEvidence() []EvidenceGroup {
+	type location struct {
+		file      string
+		line      int
+		technique string
+	}
+	var groups []EvidenceGroup
+	index := map[location]int{}
+	for _, r := range cr.Results {
+		if r.Verdict == VerdictSkip {
+			continue
+		}
+		for _, ev := range r.Evidence {
+			loc := location{file: ev.File, line: ev.Line}
+			if ev.File == "" {
+				loc = location{technique: r.Technique}
+			}
+			i, ok := index[loc]
+			if !ok {
+				i = len(groups)
+				index[loc] = i
+				groups = append(groups, EvidenceGroup{File: ev.File, Line: ev.Line})
+			}
+			g := &groups[i]
+			if !slices.Contains(g.Techniques, r.Technique) {
+				g.Techniques = append(g.Techniques, r.Technique)
+			}
+			if !slices.Contains(g.Evidence, ev) {
+				g.Evidence = append(g.Evidence, ev)
+			}
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b EvidenceGroup) int {
+		if (a.File == "") != (b.File == "") {
+			if a.File == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return groups
+}
+
+// WriteJSON writes the report document for cr as indented JSON.
+func WriteJSON(w io.Writer, cr CompositeResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cr.Report()); err != nil {
+		return fmt.Errorf("encoding report: %w", err)
+	}
+	return nil
+}
+
+// WriteReport prints a human-readable summary of technique results. A
+// result broken down by file ends with one line per file counting its
+// failed checks.
+func WriteReport(w io.Writer, results []TechniqueResult) error {
+	for _, r := range results {
+		if _, err := fmt.Fprintf(w, "%s: %s (score %.2f)\n", r.Technique, r.Verdict, r.Score); err != nil {
+			return err
+		}
+		for _, ev := range r.Evidence {
+			if _, err := fmt.Fprintf(w, "  %s\n", formatEvidence(ev)); err != nil {
+				return err
+			}
+		}
+		for _, f := range r.Files {
+			if _, err := fmt.Fprintf(w, "  %s: %d/%d failed\n", f.File, f.Total-f.Passed, f.Total); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteSummary prints the composite score and action, the share of scored
+// weight that came from deterministic techniques against the minimum the
+// scorer required, and the scored techniques that are not deterministic,
+// such as an LLM judge.
+func WriteSummary(w io.Writer, cr CompositeResult) error {
+	if _, err := fmt.Fprintf(w, "composite: %s (score %.2f, confidence %.2f)\n", cr.Action, cr.Score, cr.Confidence); err != nil {
+		return err
+	}
+	if cr.Reason != "" {
+		if _, err := fmt.Fprintf(w, "  %s\n", cr.Reason); err != nil {
+			return err
+		}
+	}
+	met := "met"
+	if cr.DeterministicWeight < cr.MinDeterministic {
+		met = "not met"
+	}
+	if _, err := fmt.Fprintf(w, "deterministic weight: %.2f (minimum %.2f, %s)\n", cr.DeterministicWeight, cr.MinDeterministic, met); err != nil {
+		return err
+	}
+	var judged []string
+	for _, r := range cr.Results {
+		if r.Verdict != VerdictSkip && !r.Deterministic {
+			judged = append(judged, r.Technique)
+		}
+	}
+	if len(judged) > 0 {
+		if _, err := fmt.Fprintf(w, "  non-deterministic: %s\n", strings.Join(judged, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatEvidence renders one evidence entry with its location and, when
+// set, its severity.
+func formatEvidence(ev Evidence) string {
+	detail := ev.Detail
+	if ev.Severity != "" {
+		detail = ev.Severity + ": " + detail
+	}
+	loc := ev.File
+	if loc != "" && ev.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", ev.File, ev.Line)
+	}
+	if ev.CriterionID != "" {
+		loc = joinNonEmpty(ev.CriterionID, loc)
+	}
+	if loc == "" {
+		return detail
+	}
+	return loc + ": " + detail
+}
+
+// joinNonEmpty joins a and b with a space, omitting empty parts.
+func joinNonEmpty(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + " " + b
+}
@@ -0,0 +1,133 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	cr := CompositeResult{
+		Score:               0.72,
+		Action:              ActionMend,
+		Valid:               true,
+		DeterministicWeight: 1,
+		Results: []TechniqueResult{
+			{Technique: MutationRunnerName, Score: 0.5, Verdict: VerdictFail, Deterministic: true,
+				Evidence: []Evidence{{File: "calc.go", Line: 7, Detail: "mutant survived"}}},
+			skipResult(DifferentialTestingName, true, "no fixture directory"),
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, cr); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	for _, key := range []string{"schema_version", "score", "action", "valid", "deterministic_weight", "confidence", "techniques"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("missing field %q in %s", key, buf.String())
+		}
+	}
+	if doc["schema_version"] != ReportSchemaVersion || doc["action"] != string(ActionMend) {
+		t.Errorf("schema_version = %v, action = %v", doc["schema_version"], doc["action"])
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("decoding Report: %v", err)
+	}
+	if len(report.Techniques) != 2 || report.Techniques[0].Evidence[0].Line != 7 || report.Techniques[1].Verdict != VerdictSkip {
+		t.Errorf("techniques round-trip = %+v", report.Techniques)
+	}
+}
+
+func TestReport_EmptyTechniques(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, CompositeResult{Action: ActionHumanReview}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"techniques": []`)) {
+		t.Errorf("empty techniques should encode as [], got:\n%s", buf.String())
+	}
+}
+
+func TestCompositeResult_GroupedEvidence(t *testing.T) {
+	survivor := Evidence{File: "calc/calc.go", Line: 12, Detail: "surviving mutant: + -> -"}
+	cr := CompositeResult{Results: []TechniqueResult{
+		{Technique: MutationRunnerName, Verdict: VerdictFail, Evidence: []Evidence{
+			survivor,
+			survivor,
+			{File: "calc/calc.go", Line: 3, Detail: "surviving mutant: == -> !="},
+			{Detail: "sampled 20 of 40 mutants"},
+		}},
+		{Technique: CoverageRunnerName, Verdict: VerdictFail, Evidence: []Evidence{
+			{File: "calc/calc.go", Line: 12, Detail: "line not covered"},
+			{File: "calc/div.go", Line: 1, Detail: "line not covered"},
+			{Detail: "coverage 60%"},
+		}},
+		{Technique: PropertyBasedRunnerName, Verdict: VerdictSkip, Evidence: []Evidence{
+			{File: "calc/calc.go", Line: 12, Detail: "skipped: no properties"},
+		}},
+	}}
+
+	want := []EvidenceGroup{
+		{File: "calc/calc.go", Line: 3, Techniques: []string{MutationRunnerName}, Evidence: []Evidence{
+			{File: "calc/calc.go", Line: 3, Detail: "surviving mutant: == -> !="},
+		}},
+		{File: "calc/calc.go", Line: 12, Techniques: []string{MutationRunnerName, CoverageRunnerName}, Evidence: []Evidence{
+			survivor,
+			{File: "calc/calc.go", Line: 12, Detail: "line not covered"},
+		}},
+		{File: "calc/div.go", Line: 1, Techniques: []string{CoverageRunnerName}, Evidence: []Evidence{
+			{File: "calc/div.go", Line: 1, Detail: "line not covered"},
+		}},
+		{Techniques: []string{MutationRunnerName}, Evidence: []Evidence{{Detail: "sampled 20 of 40 mutants"}}},
+		{Techniques: []string{CoverageRunnerName}, Evidence: []Evidence{{Detail: "coverage 60%"}}},
+	}
+	if got := cr.GroupedEvidence(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupedEvidence =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	results := []TechniqueResult{
+		{Technique: MutationRunnerName, Score: 0.9, Verdict: VerdictPass, Deterministic: true},
+		{Technique: TranslationValidatorName, Score: 0.8, Verdict: VerdictPass},
+		skipResult(DifferentialTestingName, true, "no fixture directory"),
+	}
+	tests := []struct {
+		name string
+		cr   CompositeResult
+		want string
+	}{
+		{"minimum met", CompositeResult{Score: 0.86, Action: ActionAccept, Valid: true, DeterministicWeight: 0.6, MinDeterministic: 0.5, Confidence: 0.7, Results: results},
+			"composite: accept (score 0.86, confidence 0.70)\n" +
+				"deterministic weight: 0.60 (minimum 0.50, met)\n" +
+				"  non-deterministic: " + TranslationValidatorName + "\n"},
+		{"minimum not met", CompositeResult{Score: 0.86, Action: ActionHumanReview, DeterministicWeight: 0.4, MinDeterministic: 0.5, Confidence: 0.7, Results: results,
+			Reason: "deterministic weight 0.40 below minimum 0.50"},
+			"composite: human_review (score 0.86, confidence 0.70)\n" +
+				"  deterministic weight 0.40 below minimum 0.50\n" +
+				"deterministic weight: 0.40 (minimum 0.50, not met)\n" +
+				"  non-deterministic: " + TranslationValidatorName + "\n"},
+		{"all deterministic", CompositeResult{Score: 1, Action: ActionAccept, Valid: true, DeterministicWeight: 1, MinDeterministic: 0.5, Confidence: 1, Results: results[:1]},
+			"composite: accept (score 1.00, confidence 1.00)\n" +
+				"deterministic weight: 1.00 (minimum 0.50, met)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSummary(&buf, tt.cr); err != nil {
+				t.Fatalf("WriteSummary failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteSummary =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
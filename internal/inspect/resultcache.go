@@ -0,0 +1,169 @@
+package inspect
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ResultCacheDirName is the directory under the crumbs data directory that
+// holds the technique result cache.
+const ResultCacheDirName = "result-cache"
+
+// resultCacheFile is the cache file inside PortfolioConfig.CacheDir.
+const resultCacheFile = "results.json"
+
+// storedResults is the on-disk form of the result cache.
+type storedResults struct {
+	// InputHash is the hash of the input the results were produced for.
+	InputHash string `json:"input_hash"`
+	// Results maps a technique and its settings to its result.
+	Results map[string]TechniqueResult `json:"results"`
+}
+
+// resultCache holds technique results for one input. Results are keyed by
+// technique name and settings; a cache written for a different input is
+// discarded on load. The zero cache (nil) never hits and is never saved.
+type resultCache struct {
+	path   string
+	noRead bool
+	mu     sync.Mutex
+	stored storedResults
+}
+
+// cacheConfigurer is a technique whose result depends on its settings as
+// well as on the input. cacheConfig returns the settings, which the result
+// cache key includes, and false when the technique's results must not be
+// cached, for example because it records a baseline.
+type cacheConfigurer interface {
+	cacheConfig() (any, bool)
+}
+
+// loadResultCache reads the cache for input. Returns a nil cache when
+// caching is disabled or input has no diff: without one, the files could
+// change while the input stays the same.
+func (p *Portfolio) loadResultCache(input *InspectInput) (*resultCache, error) {
+	if p.config.CacheDir == "" || input.Diff == "" {
+		return nil, nil
+	}
+	hash, err := inputHash(input)
+	if err != nil {
+		return nil, err
+	}
+	cache := &resultCache{
+		path:   filepath.Join(p.config.CacheDir, resultCacheFile),
+		noRead: p.config.NoCache,
+		stored: storedResults{InputHash: hash, Results: map[string]TechniqueResult{}},
+	}
+	raw, err := os.ReadFile(cache.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("reading result cache: %w", err)
+	default:
+		// A corrupt cache, or one for another input, is discarded.
+		var stored storedResults
+		if json.Unmarshal(raw, &stored) == nil && stored.InputHash == cache.stored.InputHash && stored.Results != nil {
+			cache.stored.Results = stored.Results
+		}
+	}
+	return cache, nil
+}
+
+// inputHash hashes every field of input. The order of the modified files
+// and packages does not change the hash.
+func inputHash(input *InspectInput) (string, error) {
+	normalized := *input
+	normalized.ModifiedFiles = slices.Sorted(slices.Values(input.ModifiedFiles))
+	normalized.ModifiedPackages = slices.Sorted(slices.Values(input.ModifiedPackages))
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("hashing inspect input: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(raw)), nil
+}
+
+// resultKey identifies the result of tech run with the portfolio's test
+// command. The boolean is false when tech's results must not be cached.
+func (p *Portfolio) resultKey(tech Technique) (string, bool, error) {
+	settings := map[string]any{}
+	if c, ok := tech.(cacheConfigurer); ok {
+		config, cacheable := c.cacheConfig()
+		if !cacheable {
+			return "", false, nil
+		}
+		settings["config"] = config
+	}
+	if _, ok := tech.(testCommandUser); ok && len(p.config.TestCommand) > 0 {
+		settings["test_command"] = p.config.TestCommand
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return "", false, fmt.Errorf("hashing %s settings: %w", tech.Name(), err)
+	}
+	return fmt.Sprintf("%s\x00%x", tech.Name(), sha256.Sum256(raw)), true, nil
+}
+
+// lookup returns the cached result stored under key.
+func (c *resultCache) lookup(key string) (TechniqueResult, bool) {
+	if c.noRead {
+		return TechniqueResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.stored.Results[key]
+	return result, ok
+}
+
+// store records a result under key.
+func (c *resultCache) store(key string, result TechniqueResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stored.Results[key] = result
+}
+
+// save writes the cache.
+func (c *resultCache) save() error {
+	if c == nil {
+		return nil
+	}
+	raw, err := json.Marshal(c.stored)
+	if err != nil {
+		return fmt.Errorf("encoding result cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating result cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, raw, 0o644); err != nil {
+		return fmt.Errorf("writing result cache: %w", err)
+	}
+	return nil
+}
+
+// cachedTechnique wraps a technique, returning its cached result when there
+// is one and caching the result of each completed run. Skip results are
+// not cached: what made the technique skip, such as a missing tool, may be
+// fixed without changing the input.
+type cachedTechnique struct {
+	Technique
+	cache *resultCache
+	key   string
+}
+
+// Run returns the cached result or runs the wrapped technique.
+func (c *cachedTechnique) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if result, ok := c.cache.lookup(c.key); ok {
+		return result, nil
+	}
+	result, err := c.Technique.Run(ctx, input)
+	if err == nil && ctx.Err() == nil && result.Verdict != VerdictSkip {
+		c.cache.store(c.key, result)
+	}
+	return result, err
+}
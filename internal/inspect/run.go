@@ -0,0 +1,127 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/petar-djukic/cobbler/internal/logging"
+)
+
+// ErrExpectedSkipped reports that a technique the user expected to run was skipped.
+var ErrExpectedSkipped = fmt.Errorf("inspect: expected technique skipped")
+
+// RunAll runs every applicable technique in order, one at a time.
+// Techniques that are not applicable produce a skip result carrying the
+// reason; techniques cut short by cancelling ctx produce a skip result
+// naming the cancellation.
+func RunAll(ctx context.Context, techniques []Technique, input *InspectInput) ([]TechniqueResult, error) {
+	return runTechniques(ctx, techniques, input, 1, nil)
+}
+
+// runTechniques runs the applicable techniques with at most limit running
+// at once and returns their results in technique order. The first technique
+// error, in technique order, cancels the remaining techniques and is
+// returned. Each technique run is logged to logger, which may be nil.
+func runTechniques(ctx context.Context, techniques []Technique, input *InspectInput, limit int, logger *slog.Logger) ([]TechniqueResult, error) {
+	logger = logging.OrDiscard(logger)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]TechniqueResult, len(techniques))
+	errs := make([]error, len(techniques))
+	sem := make(chan struct{}, max(1, limit))
+	var wg sync.WaitGroup
+	for i, tech := range techniques {
+		if ok, reason := tech.Applicable(input); !ok {
+			results[i] = skipResult(tech.Name(), false, reason)
+			logger.Debug("technique not applicable", logging.KeyTechnique, tech.Name(), "reason", reason)
+			continue
+		}
+		// Acquire here rather than in the goroutine so techniques start in order.
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = cancelledResult(tech.Name(), ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			logger.Info("technique started", logging.KeyTechnique, tech.Name())
+			start := time.Now()
+			result, err := tech.Run(ctx, input)
+			switch {
+			case ctx.Err() != nil:
+				// Results of an interrupted run are incomplete; discard them.
+				results[i] = cancelledResult(tech.Name(), ctx.Err())
+			case err != nil:
+				errs[i] = fmt.Errorf("running %s: %w", tech.Name(), err)
+				cancel()
+			default:
+				results[i] = result
+			}
+			if errs[i] != nil {
+				logger.Error("technique failed", logging.KeyTechnique, tech.Name(), logging.KeyDuration, time.Since(start), "error", errs[i])
+				return
+			}
+			logger.Info("technique finished", logging.KeyTechnique, tech.Name(),
+				logging.KeyVerdict, results[i].Verdict, logging.KeyDuration, time.Since(start))
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// cancelledResult is the skip result of a technique stopped by cancellation.
+func cancelledResult(name string, err error) TechniqueResult {
+	return skipResult(name, false, fmt.Sprintf("cancelled: %v", err))
+}
+
+// CheckExpected returns ErrExpectedSkipped, listing each technique and reason,
+// when any expected technique was skipped or produced no result at all.
+func CheckExpected(expected []string, results []TechniqueResult) error {
+	byName := make(map[string]TechniqueResult, len(results))
+	for _, r := range results {
+		byName[r.Technique] = r
+	}
+
+	var problems []string
+	for _, name := range expected {
+		r, ok := byName[name]
+		switch {
+		case !ok:
+			problems = append(problems, name+" (did not run)")
+		case r.Verdict == VerdictSkip:
+			problems = append(problems, fmt.Sprintf("%s (%s)", name, skipReason(r)))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrExpectedSkipped, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// skipReason joins the evidence details of a skip result.
+func skipReason(r TechniqueResult) string {
+	details := make([]string, 0, len(r.Evidence))
+	for _, ev := range r.Evidence {
+		if ev.Detail != "" {
+			details = append(details, ev.Detail)
+		}
+	}
+	if len(details) == 0 {
+		return "no reason given"
+	}
+	return strings.Join(details, ", ")
+}
@@ -0,0 +1,55 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunAll_SkipsInapplicable(t *testing.T) {
+	results, err := RunAll(context.Background(), []Technique{NewAssertionChecker()}, &InspectInput{WorkType: WorkTypeDocs})
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Verdict != VerdictSkip {
+		t.Fatalf("results = %+v, want one skip", results)
+	}
+	if got := skipReason(results[0]); got != "not a code task" {
+		t.Errorf("skip reason = %q, want %q", got, "not a code task")
+	}
+}
+
+func TestCheckExpected(t *testing.T) {
+	results := []TechniqueResult{
+		{Technique: "ran", Verdict: VerdictFail},
+		skipResult("skipped", true, "no fixtures"),
+	}
+	tests := []struct {
+		name     string
+		expected []string
+		wantErr  bool
+		contains []string
+	}{
+		{"none expected", nil, false, nil},
+		{"expected ran", []string{"ran"}, false, nil},
+		{"expected skipped", []string{"ran", "skipped"}, true, []string{"skipped (no fixtures)"}},
+		{"expected missing", []string{"absent"}, true, []string{"absent (did not run)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckExpected(tt.expected, results)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckExpected error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrExpectedSkipped) {
+				t.Errorf("error = %v, want ErrExpectedSkipped", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("error %q missing %q", err, s)
+				}
+			}
+		})
+	}
+}
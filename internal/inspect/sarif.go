@@ -0,0 +1,180 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+)
+
+// SARIF document constants.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF result levels.
+const (
+	sarifLevelError   = "error"
+	sarifLevelWarning = "warning"
+)
+
+// sarifTechniques are the analysis-style techniques whose findings point at
+// lines of code, and so are written as SARIF results.
+var sarifTechniques = []string{
+	SecurityRunnerName,
+	ComplexityRunnerName,
+	FormatRunnerName,
+	MutationRunnerName,
+	ContextPropagationCheckerName,
+	DocRunnerName,
+}
+
+// sarifLog is the root of a SARIF document.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is the output of one tool invocation.
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool that produced a run.
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver names the tool and the rules its results refer to.
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+// sarifRule is a rule results refer to by ID.
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+// sarifResult is one finding.
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// sarifMessage is plain message text.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifLocation places a finding in a file.
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// sarifPhysicalLocation is a file and, when known, a line in it.
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+// sarifArtifactLocation is a file URI relative to the module root.
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion is the line a finding starts on.
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the findings of cr as a SARIF 2.1.0 log for code
+// scanning. The findings are the evidence with a file of the failing
+// analysis-style techniques (security, complexity, formatting, context
+// propagation, doc comments, and the surviving mutants of mutation
+// testing), reported as warnings, plus SeverityError evidence with a file
+// from any technique, reported as errors. The line, when known, is the
+// region. Evidence without a file, and the results of other techniques,
+// are omitted, so a log may have no results.
+//
+// Rule IDs are the technique name, followed by /error for SeverityError
+// evidence, and otherwise by the gosec rule or the mutation type when there
+// is one. The same finding reported by several
+// techniques, such as a file that does not parse, is written once.
+func WriteSARIF(w io.Writer, cr CompositeResult) error {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: junitSuiteName}}, Results: []sarifResult{}}
+	seen := map[string]bool{}
+	add := func(technique string, ev Evidence, result sarifResult) {
+		key := fmt.Sprintf("%s\x00%s:%d\x00%s", result.Level, ev.File, ev.Line, ev.Detail)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		run.Results = append(run.Results, result)
+		if !slices.ContainsFunc(run.Tool.Driver.Rules, func(rule sarifRule) bool { return rule.ID == result.RuleID }) {
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: result.RuleID, ShortDescription: sarifMessage{Text: technique}})
+		}
+	}
+	for _, r := range cr.Results {
+		analysis := r.Verdict == VerdictFail && slices.Contains(sarifTechniques, r.Technique)
+		for _, ev := range r.Evidence {
+			if ev.File == "" {
+				continue
+			}
+			if result, ok := sarifFinding(r.Technique, ev, analysis); ok {
+				add(r.Technique, ev, result)
+			}
+		}
+		if !analysis {
+			continue
+		}
+		for _, survivor := range r.Survivors {
+			ev := survivor.evidence()
+			result := sarifResult{RuleID: r.Technique + "/" + string(survivor.Type), Level: sarifLevelWarning, Message: sarifMessage{Text: ev.Detail}}
+			result.Locations = sarifLocations(ev)
+			add(r.Technique, ev, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encoding SARIF report: %w", err)
+	}
+	return nil
+}
+
+// sarifFinding converts evidence of technique to a SARIF result. The
+// boolean is false when the evidence is not a finding: evidence that is not
+// SeverityError outside a failing analysis technique, and mutation testing
+// evidence, whose findings are its Survivors.
+func sarifFinding(technique string, ev Evidence, analysis bool) (sarifResult, bool) {
+	result := sarifResult{RuleID: technique, Level: sarifLevelWarning, Message: sarifMessage{Text: ev.Detail}}
+	switch {
+	case ev.Severity == SeverityError:
+		result.RuleID += "/" + SeverityError
+		result.Level = sarifLevelError
+	case !analysis, technique == MutationRunnerName:
+		return sarifResult{}, false
+	case ev.CriterionID != "":
+		result.RuleID += "/" + ev.CriterionID
+	}
+	result.Locations = sarifLocations(ev)
+	return result, true
+}
+
+// sarifLocations places ev at its file and, when known, its line.
+func sarifLocations(ev Evidence) []sarifLocation {
+	location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(ev.File)}}
+	if ev.Line > 0 {
+		location.Region = &sarifRegion{StartLine: ev.Line}
+	}
+	return []sarifLocation{{PhysicalLocation: location}}
+}
@@ -0,0 +1,135 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	parseError := Evidence{File: "calc/sub.go", Line: 6, Severity: SeverityError, Detail: "does not parse: expected operand"}
+	cr := CompositeResult{
+		Action: ActionMend,
+		Results: []TechniqueResult{
+			{Technique: SecurityRunnerName, Verdict: VerdictFail, Evidence: []Evidence{
+				{CriterionID: "G101", File: "calc/creds.go", Line: 3, Detail: "Potential hardcoded credentials"},
+			}},
+			{Technique: MutationRunnerName, Verdict: VerdictFail, Evidence: []Evidence{
+				{Detail: "sampled 2 of 4 mutants (seed 0)"},
+				{File: "calc/add.go", Line: 4, Detail: `arithmetic mutant survived: "+" -> "-"`},
+				{File: "calc/add.go", Line: 5, Detail: `literal mutant killed by TestAdd: "1" -> "0"`},
+			}, Survivors: []SurvivingMutant{{File: "calc/add.go", Line: 4, Type: MutationArithmetic, Original: "+", Mutated: "-"}}},
+			{Technique: FormatRunnerName, Verdict: VerdictFail, Evidence: []Evidence{{File: "calc/add.go", Detail: "not gofmt-formatted"}}},
+			// A passing analysis technique has no findings.
+			{Technique: ComplexityRunnerName, Verdict: VerdictPass, Evidence: []Evidence{{File: "calc/add.go", Line: 9, Detail: "Add has cyclomatic complexity 3 (threshold 10)"}}},
+			// Nor does a technique that is not analysis-style, unless its
+			// evidence is error-severity; the parse error is reported once.
+			{Technique: AssertionCheckerName, Verdict: VerdictFail, Evidence: []Evidence{{File: "calc/add_test.go", Line: 7, Detail: "no assertions"}, parseError}},
+			{Technique: DocRunnerName, Verdict: VerdictFail, Evidence: []Evidence{parseError}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, cr); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+
+	// The fields the SARIF 2.1.0 schema requires, checked on the decoded
+	// document rather than the writer's own types.
+	var doc struct {
+		Version *string `json:"version"`
+		Runs    []struct {
+			Tool *struct {
+				Driver *struct {
+					Name  *string `json:"name"`
+					Rules []struct {
+						ID *string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message *struct {
+					Text *string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Version == nil || *doc.Version != "2.1.0" || len(doc.Runs) != 1 {
+		t.Fatalf("version %v with %d runs, want 2.1.0 with one run:\n%s", doc.Version, len(doc.Runs), buf.String())
+	}
+	run := doc.Runs[0]
+	if run.Tool == nil || run.Tool.Driver == nil || run.Tool.Driver.Name == nil || *run.Tool.Driver.Name == "" {
+		t.Fatalf("run has no tool driver name:\n%s", buf.String())
+	}
+	rules := map[string]bool{}
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ID == nil {
+			t.Fatalf("rule without an id:\n%s", buf.String())
+		}
+		rules[*rule.ID] = true
+	}
+
+	type location struct {
+		uri  string
+		line int
+	}
+	want := []struct {
+		ruleID, level string
+		location
+	}{
+		{SecurityRunnerName + "/G101", "warning", location{"calc/creds.go", 3}},
+		{MutationRunnerName + "/arithmetic", "warning", location{"calc/add.go", 4}},
+		{FormatRunnerName, "warning", location{"calc/add.go", 0}},
+		{AssertionCheckerName + "/error", "error", location{"calc/sub.go", 6}},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("got %d results, want %d:\n%s", len(run.Results), len(want), buf.String())
+	}
+	for i, w := range want {
+		r := run.Results[i]
+		if r.Message == nil || r.Message.Text == nil || *r.Message.Text == "" {
+			t.Errorf("result %d has no message text", i)
+		}
+		if r.RuleID != w.ruleID || r.Level != w.level || !rules[r.RuleID] {
+			t.Errorf("result %d rule %q level %q (declared %v), want %q %q", i, r.RuleID, r.Level, rules[r.RuleID], w.ruleID, w.level)
+		}
+		if len(r.Locations) != 1 {
+			t.Errorf("result %d has %d locations, want 1", i, len(r.Locations))
+			continue
+		}
+		loc := r.Locations[0].PhysicalLocation
+		got := location{uri: loc.ArtifactLocation.URI}
+		if loc.Region != nil {
+			got.line = loc.Region.StartLine
+		}
+		if got != w.location {
+			t.Errorf("result %d location = %+v, want %+v", i, got, w.location)
+		}
+	}
+}
+
+func TestWriteSARIF_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, CompositeResult{Results: []TechniqueResult{result(TranslationValidatorName, 1)}}); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	// Code scanning treats a missing results array as an incomplete run.
+	if !strings.Contains(buf.String(), `"results": []`) {
+		t.Errorf("log without findings has no empty results array:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,423 @@
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Technique names referenced by the default weights (prd008 R7.2).
+const (
+	TranslationValidatorName = "translation_validation"
+	MutationRunnerName       = "mutation_testing"
+	DifferentialTestingName  = "differential_testing"
+	ContractInjectionName    = "contract_injection"
+)
+
+// Default thresholds (prd008 R7.1, R7.3, R7.4).
+const (
+	DefaultAcceptThreshold  = 0.80
+	DefaultMendThreshold    = 0.50
+	DefaultMinDeterministic = 0.50
+	MinScoredTechniques     = 2
+)
+
+// Confidence settings.
+const (
+	// ConfidenceSaturation is the number of scored techniques at which the
+	// technique count no longer limits confidence.
+	ConfidenceSaturation = 5
+	// LowConfidence is the confidence below which an accept is flagged.
+	LowConfidence = 0.5
+)
+
+// weightEpsilon absorbs floating-point error when comparing weight sums.
+const weightEpsilon = 1e-9
+
+// Scorer configuration errors.
+var (
+	ErrInvalidWeight      = fmt.Errorf("inspect: invalid technique weight")
+	ErrInvalidThreshold   = fmt.Errorf("inspect: invalid scorer threshold")
+	ErrInvalidAggregation = fmt.Errorf("inspect: invalid score aggregation")
+)
+
+// DefaultWeights assigns each technique its share of the composite score.
+var DefaultWeights = map[string]float64{
+	TranslationValidatorName: 0.30,
+	MutationRunnerName:       0.25,
+	DifferentialTestingName:  0.20,
+	PropertyBasedRunnerName:  0.15,
+	ContractInjectionName:    0.10,
+	// The security runner is opt-in; its weight applies only when it runs.
+	SecurityRunnerName: 0.10,
+	// Formatting is cheap to fix, so it only nudges the composite.
+	FormatRunnerName: 0.05,
+	// Markdown structure is the only check docs work gets besides
+	// translation validation.
+	MarkdownRunnerName: 0.20,
+	// Test adequacy signals that back up mutation testing.
+	AssertionCheckerName: 0.10,
+	CoverageRunnerName:   0.10,
+	// Static checks on code shape weigh no more than formatting.
+	ContextPropagationCheckerName: 0.05,
+	ComplexityRunnerName:          0.05,
+	DocRunnerName:                 0.05,
+}
+
+// DefaultVetoTechniques are the techniques whose failure blocks an accept
+// whatever the composite: a race, a leaked goroutine, or a known
+// vulnerability is a defect, not a lower score.
+var DefaultVetoTechniques = []string{
+	RaceRunnerName,
+	GoroutineLeakCheckerName,
+	VulnRunnerName,
+}
+
+// Action is the decision derived from the composite score.
+type Action string
+
+// Action values.
+const (
+	ActionAccept      Action = "accept"
+	ActionMend        Action = "mend"
+	ActionHumanReview Action = "human_review"
+)
+
+// Accepting reports whether a releases the change: accept itself and tier
+// actions that refine it, such as accept_with_warning.
+func (a Action) Accepting() bool {
+	return a == ActionAccept || strings.HasPrefix(string(a), string(ActionAccept)+"_")
+}
+
+// Tier assigns Action to composite scores at or above Threshold.
+type Tier struct {
+	Threshold float64
+	Action    Action
+}
+
+// Aggregation selects how technique scores combine into the composite.
+type Aggregation string
+
+// Aggregation values. The zero value behaves as AggregationWeightedMean.
+const (
+	AggregationWeightedMean   Aggregation = "weighted_mean"
+	AggregationWeightedMedian Aggregation = "weighted_median"
+)
+
+// ScorerConfig holds weights and thresholds for composite scoring.
+type ScorerConfig struct {
+	// Weights maps technique names to weights. Techniques without a weight
+	// do not contribute to the composite.
+	Weights map[string]float64
+	// AcceptThreshold is the minimum composite score to accept.
+	AcceptThreshold float64
+	// MendThreshold is the minimum composite score to send to mend; lower
+	// scores go to human review.
+	MendThreshold float64
+	// Tiers, when set, replaces the accept and mend thresholds with an
+	// ordered action ladder, highest threshold first: a score takes the
+	// action of the first tier it reaches, and scores below every tier go to
+	// human review. Actions other than accept and mend, such as an
+	// accept-with-warning tier, are released for a person to act on.
+	Tiers []Tier
+	// MinDeterministic is the minimum share of active weight that must come
+	// from deterministic techniques.
+	MinDeterministic float64
+	// Aggregation selects weighted mean (default) or weighted median.
+	// Weighted median is less sensitive to a single outlier technique.
+	Aggregation Aggregation
+	// VetoTechniques lists techniques whose failure can never be averaged
+	// into an accept: when one fails, an accepting action is capped at the
+	// first non-accepting tier.
+	VetoTechniques []string
+}
+
+// DefaultScorerConfig returns the PRD default weights, thresholds, and veto
+// techniques.
+func DefaultScorerConfig() ScorerConfig {
+	weights := make(map[string]float64, len(DefaultWeights))
+	for name, w := range DefaultWeights {
+		weights[name] = w
+	}
+	return ScorerConfig{
+		Weights:          weights,
+		AcceptThreshold:  DefaultAcceptThreshold,
+		MendThreshold:    DefaultMendThreshold,
+		MinDeterministic: DefaultMinDeterministic,
+		Aggregation:      AggregationWeightedMean,
+		VetoTechniques:   slices.Clone(DefaultVetoTechniques),
+	}
+}
+
+// CompositeResult is the aggregated outcome of a set of technique results.
+type CompositeResult struct {
+	// Score is the composite adequacy score in [0, 1].
+	Score float64 `json:"score"`
+	// Action is the decision derived from Score.
+	Action Action `json:"action"`
+	// Valid is false when too few techniques produced results or the
+	// deterministic weight requirement is not met.
+	Valid bool `json:"valid"`
+	// Reason explains why the result is not valid or was vetoed.
+	Reason string `json:"reason,omitempty"`
+	// VetoedBy names the veto technique that failed, if any.
+	VetoedBy string `json:"vetoed_by,omitempty"`
+	// DeterministicWeight is the share of active weight from deterministic techniques.
+	DeterministicWeight float64 `json:"deterministic_weight"`
+	// MinDeterministic is the DeterministicWeight the scorer required for
+	// a valid result.
+	MinDeterministic float64 `json:"min_deterministic"`
+	// Confidence in [0, 1] reflects how much evidence backs Score. See
+	// Confidence for the formula.
+	Confidence float64 `json:"confidence"`
+	// Results holds every technique result, including skips.
+	Results []TechniqueResult `json:"results"`
+	// Timestamp is when the result was recorded in the crumb's history;
+	// zero until then.
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	// Regressed is set when recording: Score fell below the score of the
+	// crumb's previous recorded result.
+	Regressed bool `json:"regressed,omitempty"`
+}
+
+// WeightedScore pairs a technique score with its weight.
+type WeightedScore struct {
+	Score  float64
+	Weight float64
+}
+
+// Scorer aggregates technique results into a composite result.
+type Scorer struct {
+	config ScorerConfig
+}
+
+// NewScorer creates a Scorer with the given configuration, rejecting
+// configurations that fail Validate.
+func NewScorer(config ScorerConfig) (*Scorer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Scorer{config: config}, nil
+}
+
+// Validate checks that:
+//   - every weight is in [0, 1] and the weights sum above zero;
+//   - 0 <= MendThreshold <= AcceptThreshold <= 1;
+//   - every tier names an action, with thresholds in [0, 1], decreasing;
+//   - MinDeterministic is in [0, 1];
+//   - Aggregation is empty or known.
+func (c ScorerConfig) Validate() error {
+	var total float64
+	for name, w := range c.Weights {
+		if w < 0 || w > 1 || math.IsNaN(w) {
+			return fmt.Errorf("%w: %s has weight %v, want [0, 1]", ErrInvalidWeight, name, w)
+		}
+		total += w
+	}
+	if total <= 0 {
+		return fmt.Errorf("%w: weights sum to zero", ErrInvalidWeight)
+	}
+	if !(0 <= c.MendThreshold && c.MendThreshold <= c.AcceptThreshold && c.AcceptThreshold <= 1) {
+		return fmt.Errorf("%w: want 0 <= mend (%v) <= accept (%v) <= 1", ErrInvalidThreshold, c.MendThreshold, c.AcceptThreshold)
+	}
+	for i, tier := range c.Tiers {
+		switch {
+		case tier.Action == "":
+			return fmt.Errorf("%w: tier %d has no action", ErrInvalidThreshold, i)
+		case !(0 <= tier.Threshold && tier.Threshold <= 1):
+			return fmt.Errorf("%w: %s tier threshold %v, want [0, 1]", ErrInvalidThreshold, tier.Action, tier.Threshold)
+		case i > 0 && tier.Threshold >= c.Tiers[i-1].Threshold:
+			return fmt.Errorf("%w: %s tier threshold %v not below %s tier threshold %v", ErrInvalidThreshold, tier.Action, tier.Threshold, c.Tiers[i-1].Action, c.Tiers[i-1].Threshold)
+		}
+	}
+	if c.MinDeterministic < 0 || c.MinDeterministic > 1 || math.IsNaN(c.MinDeterministic) {
+		return fmt.Errorf("%w: min deterministic %v, want [0, 1]", ErrInvalidThreshold, c.MinDeterministic)
+	}
+	switch c.Aggregation {
+	case "", AggregationWeightedMean, AggregationWeightedMedian:
+	default:
+		return fmt.Errorf("%w: %q, want %s or %s", ErrInvalidAggregation, c.Aggregation, AggregationWeightedMean, AggregationWeightedMedian)
+	}
+	return nil
+}
+
+// Score computes the composite result. Skipped techniques and techniques
+// without weight are excluded from the denominator. Invalid results are
+// routed to human review.
+func (s *Scorer) Score(results []TechniqueResult) CompositeResult {
+	cr := CompositeResult{Results: results, MinDeterministic: s.config.MinDeterministic}
+
+	var active []WeightedScore
+	var total, deterministic float64
+	for _, r := range results {
+		w := s.config.Weights[r.Technique]
+		if r.Verdict == VerdictSkip || w <= 0 {
+			continue
+		}
+		active = append(active, WeightedScore{Score: r.Score, Weight: w})
+		total += w
+		if r.Deterministic {
+			deterministic += w
+		}
+	}
+
+	cr.Confidence = Confidence(active)
+	if total > 0 {
+		cr.DeterministicWeight = deterministic / total
+	}
+	if len(active) < MinScoredTechniques {
+		cr.Action = ActionHumanReview
+		cr.Reason = fmt.Sprintf("%d scored techniques, need at least %d", len(active), MinScoredTechniques)
+		return cr
+	}
+
+	cr.Score = s.aggregate(active)
+	if cr.DeterministicWeight < s.config.MinDeterministic {
+		cr.Action = ActionHumanReview
+		cr.Reason = fmt.Sprintf("deterministic weight %.2f below minimum %.2f", cr.DeterministicWeight, s.config.MinDeterministic)
+		return cr
+	}
+
+	cr.Valid = true
+	cr.Action = s.actionFor(cr.Score)
+	if veto := s.veto(results); veto != "" {
+		cr.VetoedBy = veto
+		cr.Reason = fmt.Sprintf("vetoed by failing %s", veto)
+		if cr.Action.Accepting() {
+			cr.Action = s.config.vetoAction()
+		}
+	}
+	return cr
+}
+
+// LowConfidenceAccept reports whether cr has an accepting action on a score
+// that Confidence says little backs.
+func (cr CompositeResult) LowConfidenceAccept() bool {
+	return cr.Action.Accepting() && cr.Confidence < LowConfidence
+}
+
+// Confidence rates how much a composite of scores can be trusted, in
+// [0, 1], as the product of a count factor and an agreement factor:
+//
+//	min(1, n / ConfidenceSaturation) * (1 - 2σ)
+//
+// where n is the number of scored techniques and σ is the weighted standard
+// deviation of their scores. Scores lie in [0, 1], so σ is at most 0.5: full
+// agreement gives an agreement factor of 1, and an even split between 0 and
+// 1 gives 0. Returns 0 for no scores.
+func Confidence(scores []WeightedScore) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	mean := WeightedMean(scores)
+	var variance, total float64
+	for _, ws := range scores {
+		d := ws.Score - mean
+		variance += ws.Weight * d * d
+		total += ws.Weight
+	}
+	if total > 0 {
+		variance /= total
+	}
+	count := math.Min(1, float64(len(scores))/ConfidenceSaturation)
+	agreement := math.Max(0, 1-2*math.Sqrt(variance))
+	return count * agreement
+}
+
+// veto returns the first veto technique that failed, or "".
+func (s *Scorer) veto(results []TechniqueResult) string {
+	for _, r := range results {
+		if r.Verdict == VerdictFail && slices.Contains(s.config.VetoTechniques, r.Technique) {
+			return r.Technique
+		}
+	}
+	return ""
+}
+
+// aggregate combines active scores using the configured aggregation.
+func (s *Scorer) aggregate(active []WeightedScore) float64 {
+	if s.config.Aggregation == AggregationWeightedMedian {
+		return WeightedMedian(active)
+	}
+	return WeightedMean(active)
+}
+
+// actionFor maps a composite score to the action of the first tier it
+// reaches, or to human review.
+func (s *Scorer) actionFor(score float64) Action {
+	for _, tier := range s.config.actionTiers() {
+		if score >= tier.Threshold {
+			return tier.Action
+		}
+	}
+	return ActionHumanReview
+}
+
+// vetoAction returns the action of the first non-accepting tier, which a
+// veto caps the action at, or human review when every tier accepts.
+func (c ScorerConfig) vetoAction() Action {
+	for _, tier := range c.actionTiers() {
+		if !tier.Action.Accepting() {
+			return tier.Action
+		}
+	}
+	return ActionHumanReview
+}
+
+// actionTiers returns Tiers, or when it is empty the accept and mend tiers
+// of AcceptThreshold and MendThreshold.
+func (c ScorerConfig) actionTiers() []Tier {
+	if len(c.Tiers) > 0 {
+		return c.Tiers
+	}
+	return []Tier{{Threshold: c.AcceptThreshold, Action: ActionAccept}, {Threshold: c.MendThreshold, Action: ActionMend}}
+}
+
+// WeightedMean returns the weighted average of the scores, or 0 when the
+// total weight is zero.
+func WeightedMean(scores []WeightedScore) float64 {
+	var sum, total float64
+	for _, ws := range scores {
+		sum += ws.Score * ws.Weight
+		total += ws.Weight
+	}
+	if total <= 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// WeightedMedian returns the score at which cumulative weight first reaches
+// half the total weight. When the cumulative weight lands exactly on half,
+// the midpoint of that score and the next is returned. Returns 0 when the
+// total weight is zero.
+func WeightedMedian(scores []WeightedScore) float64 {
+	sorted := make([]WeightedScore, 0, len(scores))
+	var total float64
+	for _, ws := range scores {
+		if ws.Weight > 0 {
+			sorted = append(sorted, ws)
+			total += ws.Weight
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score < sorted[j].Score })
+
+	half := total / 2
+	var cumulative float64
+	for i, ws := range sorted {
+		cumulative += ws.Weight
+		if math.Abs(cumulative-half) < weightEpsilon && i+1 < len(sorted) {
+			return (ws.Score + sorted[i+1].Score) / 2
+		}
+		if cumulative >= half-weightEpsilon {
+			return ws.Score
+		}
+	}
+	return sorted[len(sorted)-1].Score
+}
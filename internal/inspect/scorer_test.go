@@ -0,0 +1,357 @@
+package inspect
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// approxEqual compares floats within a small tolerance.
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// newScorer creates a Scorer, failing the test on an invalid config.
+func newScorer(t *testing.T, config ScorerConfig) *Scorer {
+	t.Helper()
+	scorer, err := NewScorer(config)
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	return scorer
+}
+
+// result builds a deterministic technique result with a pass/fail verdict.
+func result(name string, score float64) TechniqueResult {
+	verdict := VerdictPass
+	if score < 1 {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{Technique: name, Score: score, Verdict: verdict, Deterministic: true}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []WeightedScore
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single", []WeightedScore{{0.4, 1}}, 0.4},
+		{"heavy middle", []WeightedScore{{0.1, 0.2}, {0.5, 0.6}, {0.9, 0.2}}, 0.5},
+		{"exact half averages", []WeightedScore{{0.2, 0.5}, {0.8, 0.5}}, 0.5},
+		{"unsorted input", []WeightedScore{{0.9, 0.1}, {0.3, 0.3}, {0.6, 0.3}}, 0.6},
+		{"zero weights ignored", []WeightedScore{{0.0, 0}, {0.7, 1}}, 0.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeightedMedian(tt.scores); !approxEqual(got, tt.want) {
+				t.Errorf("WeightedMedian = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorer_MedianResistsOutlier(t *testing.T) {
+	results := []TechniqueResult{
+		result(TranslationValidatorName, 0.9),
+		result(MutationRunnerName, 0.85),
+		result(DifferentialTestingName, 0.9),
+		result(PropertyBasedRunnerName, 0.0), // outlier
+	}
+	unanimous := []TechniqueResult{
+		result(TranslationValidatorName, 0.9),
+		result(MutationRunnerName, 0.85),
+		result(DifferentialTestingName, 0.9),
+	}
+
+	meanConfig := DefaultScorerConfig()
+	medianConfig := DefaultScorerConfig()
+	medianConfig.Aggregation = AggregationWeightedMedian
+
+	meanShift := newScorer(t, meanConfig).Score(unanimous).Score - newScorer(t, meanConfig).Score(results).Score
+	medianShift := newScorer(t, medianConfig).Score(unanimous).Score - newScorer(t, medianConfig).Score(results).Score
+	if medianShift >= meanShift {
+		t.Errorf("median shift %v should be smaller than mean shift %v", medianShift, meanShift)
+	}
+
+	mean := newScorer(t, meanConfig).Score(results)
+	median := newScorer(t, medianConfig).Score(results)
+	if mean.Action == ActionAccept {
+		t.Errorf("mean Action = %q, outlier should pull it below accept", mean.Action)
+	}
+	if median.Action != ActionAccept {
+		t.Errorf("median Action = %q, want %q", median.Action, ActionAccept)
+	}
+}
+
+func TestScorer_Actions(t *testing.T) {
+	scorer := newScorer(t, DefaultScorerConfig())
+	tests := []struct {
+		name  string
+		score float64
+		want  Action
+	}{
+		{"accept", 0.9, ActionAccept},
+		{"accept boundary", 0.8, ActionAccept},
+		{"mend", 0.6, ActionMend},
+		{"human review", 0.2, ActionHumanReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := scorer.Score([]TechniqueResult{
+				result(MutationRunnerName, tt.score),
+				result(DifferentialTestingName, tt.score),
+			})
+			if !cr.Valid {
+				t.Fatalf("result invalid: %s", cr.Reason)
+			}
+			if cr.Action != tt.want {
+				t.Errorf("Action = %q, want %q", cr.Action, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorer_Tiers(t *testing.T) {
+	const acceptWithWarning Action = "accept_with_warning"
+	config := DefaultScorerConfig()
+	config.Tiers = []Tier{{0.9, ActionAccept}, {0.8, acceptWithWarning}, {0.5, ActionMend}}
+	scorer := newScorer(t, config)
+	tests := []struct {
+		name  string
+		score float64
+		want  Action
+	}{
+		{"accept", 0.95, ActionAccept},
+		{"accept boundary", 0.9, ActionAccept},
+		{"accept with warning", 0.85, acceptWithWarning},
+		{"warning boundary", 0.8, acceptWithWarning},
+		{"mend", 0.6, ActionMend},
+		{"below every tier", 0.3, ActionHumanReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := scorer.Score([]TechniqueResult{
+				result(MutationRunnerName, tt.score),
+				result(DifferentialTestingName, tt.score),
+			})
+			if cr.Action != tt.want {
+				t.Errorf("Action = %q, want %q", cr.Action, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorer_ExcludesSkipsAndRequiresTwo(t *testing.T) {
+	scorer := newScorer(t, DefaultScorerConfig())
+	cr := scorer.Score([]TechniqueResult{
+		result(MutationRunnerName, 1),
+		skipResult(DifferentialTestingName, true, "no fixtures"),
+	})
+	if cr.Valid {
+		t.Error("Valid = true with one scored technique")
+	}
+	if cr.Action != ActionHumanReview {
+		t.Errorf("Action = %q, want %q", cr.Action, ActionHumanReview)
+	}
+	if len(cr.Results) != 2 {
+		t.Errorf("Results has %d entries, want 2", len(cr.Results))
+	}
+}
+
+func TestScorer_DeterministicWeight(t *testing.T) {
+	scorer := newScorer(t, DefaultScorerConfig())
+	llm := result(TranslationValidatorName, 1)
+	llm.Deterministic = false
+
+	cr := scorer.Score([]TechniqueResult{llm, result(ContractInjectionName, 1)})
+	if cr.Valid {
+		t.Error("Valid = true with deterministic weight below minimum")
+	}
+	if !approxEqual(cr.DeterministicWeight, 0.25) {
+		t.Errorf("DeterministicWeight = %v, want 0.25", cr.DeterministicWeight)
+	}
+	if cr.MinDeterministic != DefaultMinDeterministic {
+		t.Errorf("MinDeterministic = %v, want %v", cr.MinDeterministic, DefaultMinDeterministic)
+	}
+}
+
+func TestNewScorer_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*ScorerConfig)
+		wantErr error
+	}{
+		{"default is valid", func(*ScorerConfig) {}, nil},
+		{"negative weight", func(c *ScorerConfig) { c.Weights[MutationRunnerName] = -0.1 }, ErrInvalidWeight},
+		{"weight above one", func(c *ScorerConfig) { c.Weights[MutationRunnerName] = 1.5 }, ErrInvalidWeight},
+		{"zero total weight", func(c *ScorerConfig) { c.Weights = map[string]float64{MutationRunnerName: 0} }, ErrInvalidWeight},
+		{"mend above accept", func(c *ScorerConfig) { c.MendThreshold, c.AcceptThreshold = 0.9, 0.8 }, ErrInvalidThreshold},
+		{"negative mend", func(c *ScorerConfig) { c.MendThreshold = -0.1 }, ErrInvalidThreshold},
+		{"accept above one", func(c *ScorerConfig) { c.AcceptThreshold = 1.1 }, ErrInvalidThreshold},
+		{"min deterministic above one", func(c *ScorerConfig) { c.MinDeterministic = 1.2 }, ErrInvalidThreshold},
+		{"negative min deterministic", func(c *ScorerConfig) { c.MinDeterministic = -0.5 }, ErrInvalidThreshold},
+		{"weighted median", func(c *ScorerConfig) { c.Aggregation = AggregationWeightedMedian }, nil},
+		{"unknown aggregation", func(c *ScorerConfig) { c.Aggregation = "median" }, ErrInvalidAggregation},
+		{"tiers", func(c *ScorerConfig) { c.Tiers = []Tier{{0.9, ActionAccept}, {0.5, ActionMend}} }, nil},
+		{"tiers out of order", func(c *ScorerConfig) { c.Tiers = []Tier{{0.5, ActionMend}, {0.9, ActionAccept}} }, ErrInvalidThreshold},
+		{"tiers with equal thresholds", func(c *ScorerConfig) { c.Tiers = []Tier{{0.9, ActionAccept}, {0.9, ActionMend}} }, ErrInvalidThreshold},
+		{"tier above one", func(c *ScorerConfig) { c.Tiers = []Tier{{1.5, ActionAccept}} }, ErrInvalidThreshold},
+		{"tier without action", func(c *ScorerConfig) { c.Tiers = []Tier{{0.9, ""}} }, ErrInvalidThreshold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultScorerConfig()
+			tt.modify(&config)
+			scorer, err := NewScorer(config)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (scorer != nil) {
+				t.Errorf("NewScorer = %v, %v; want error %v", scorer, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScorer_Veto(t *testing.T) {
+	config := DefaultScorerConfig()
+	config.VetoTechniques = []string{TranslationValidatorName}
+	scorer := newScorer(t, config)
+
+	// A failing compile check outweighed by perfect scores elsewhere.
+	results := []TechniqueResult{
+		result(TranslationValidatorName, 0.75),
+		result(MutationRunnerName, 1),
+		result(DifferentialTestingName, 1),
+		result(PropertyBasedRunnerName, 1),
+	}
+	if unvetoed := newScorer(t, DefaultScorerConfig()).Score(results); unvetoed.Action != ActionAccept {
+		t.Fatalf("without veto Action = %q, want %q", unvetoed.Action, ActionAccept)
+	}
+
+	cr := scorer.Score(results)
+	if cr.Action != ActionMend || cr.VetoedBy != TranslationValidatorName {
+		t.Errorf("Action = %q VetoedBy = %q, want mend vetoed by %s", cr.Action, cr.VetoedBy, TranslationValidatorName)
+	}
+
+	results[0] = result(TranslationValidatorName, 1)
+	if cr := scorer.Score(results); cr.Action != ActionAccept || cr.VetoedBy != "" {
+		t.Errorf("passing veto technique: Action = %q VetoedBy = %q, want accept", cr.Action, cr.VetoedBy)
+	}
+}
+
+func TestDefaultScorerConfig_CoversDefaultTechniques(t *testing.T) {
+	config := DefaultScorerConfig()
+	for _, tech := range DefaultTechniques() {
+		if config.Weights[tech.Name()] <= 0 && !slices.Contains(config.VetoTechniques, tech.Name()) {
+			t.Errorf("%s runs by default but has neither a weight nor a veto", tech.Name())
+		}
+	}
+	config.VetoTechniques[0] = "changed"
+	if DefaultVetoTechniques[0] == "changed" {
+		t.Error("DefaultScorerConfig shares DefaultVetoTechniques")
+	}
+}
+
+func TestScorer_VetoCapsTiers(t *testing.T) {
+	const acceptWithWarning Action = "accept_with_warning"
+	tests := []struct {
+		name  string
+		tiers []Tier
+		score float64
+		want  Action
+	}{
+		{"accept capped at mend", []Tier{{0.9, ActionAccept}, {0.8, acceptWithWarning}, {0.5, ActionMend}}, 0.95, ActionMend},
+		{"accept with warning capped at mend", []Tier{{0.9, ActionAccept}, {0.8, acceptWithWarning}, {0.5, ActionMend}}, 0.85, ActionMend},
+		{"mend unchanged", []Tier{{0.9, ActionAccept}, {0.8, acceptWithWarning}, {0.5, ActionMend}}, 0.6, ActionMend},
+		{"every tier accepting", []Tier{{0.9, ActionAccept}, {0.5, acceptWithWarning}}, 0.95, ActionHumanReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultScorerConfig()
+			config.Tiers = tt.tiers
+			config.VetoTechniques = []string{TranslationValidatorName}
+			scorer := newScorer(t, config)
+			fail := result(TranslationValidatorName, 1)
+			fail.Verdict = VerdictFail
+			results := []TechniqueResult{
+				fail,
+				result(MutationRunnerName, tt.score),
+				result(DifferentialTestingName, tt.score),
+				result(PropertyBasedRunnerName, tt.score),
+			}
+			if cr := scorer.Score(results); cr.Action != tt.want {
+				t.Errorf("Action = %q, want %q", cr.Action, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	agreeing := func(n int, score float64) []WeightedScore {
+		scores := make([]WeightedScore, n)
+		for i := range scores {
+			scores[i] = WeightedScore{Score: score, Weight: 0.2}
+		}
+		return scores
+	}
+	tests := []struct {
+		name   string
+		scores []WeightedScore
+		want   float64
+	}{
+		{"no scores", nil, 0},
+		{"two agreeing", agreeing(2, 0.81), 0.4},
+		{"five agreeing", agreeing(5, 0.81), 1},
+		{"more than saturation", agreeing(7, 0.81), 1},
+		{"even split", []WeightedScore{{1, 0.5}, {0, 0.5}}, 0},
+		// Weighted mean 0.75, σ = sqrt(0.75·0.25) ≈ 0.433.
+		{"weighted split", []WeightedScore{{1, 0.3}, {1, 0.3}, {1, 0.3}, {0, 0.3}, {1, 0}}, 1 - 2*math.Sqrt(0.75*0.25)},
+		{"close scores", []WeightedScore{{0.9, 0.25}, {0.7, 0.25}, {0.9, 0.25}, {0.7, 0.25}, {0.8, 0}}, 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Confidence(tt.scores); !approxEqual(got, tt.want) {
+				t.Errorf("Confidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorer_Confidence(t *testing.T) {
+	scorer := newScorer(t, DefaultScorerConfig())
+	few := scorer.Score([]TechniqueResult{result(MutationRunnerName, 0.85), result(DifferentialTestingName, 0.85)})
+	if few.Action != ActionAccept || !approxEqual(few.Confidence, 0.4) || !few.LowConfidenceAccept() {
+		t.Errorf("two agreeing techniques: %+v, want a low-confidence accept at 0.4", few)
+	}
+
+	split := scorer.Score([]TechniqueResult{
+		result(TranslationValidatorName, 1),
+		result(MutationRunnerName, 1),
+		result(DifferentialTestingName, 1),
+		result(PropertyBasedRunnerName, 0),
+		result(ContractInjectionName, 1),
+	})
+	// The failing technique carries 0.15 of the weight: mean 0.85.
+	if want := 1 - 2*math.Sqrt(0.85*0.15); !approxEqual(split.Confidence, want) {
+		t.Errorf("split verdicts: confidence %v, want %v", split.Confidence, want)
+	}
+
+	agreed := scorer.Score([]TechniqueResult{
+		result(TranslationValidatorName, 0.9),
+		result(MutationRunnerName, 0.9),
+		result(DifferentialTestingName, 0.9),
+		result(PropertyBasedRunnerName, 0.9),
+		result(ContractInjectionName, 0.9),
+	})
+	if !approxEqual(agreed.Confidence, 1) || agreed.LowConfidenceAccept() {
+		t.Errorf("five agreeing techniques: %+v, want confidence 1", agreed)
+	}
+
+	warned := DefaultScorerConfig()
+	warned.Tiers = []Tier{{Threshold: 0.9, Action: ActionAccept}, {Threshold: 0.8, Action: "accept_with_warning"}, {Threshold: 0.5, Action: ActionMend}}
+	few = newScorer(t, warned).Score([]TechniqueResult{result(MutationRunnerName, 0.85), result(DifferentialTestingName, 0.85)})
+	if few.Action != "accept_with_warning" || !few.LowConfidenceAccept() {
+		t.Errorf("two agreeing techniques in the warning tier: %+v, want a low-confidence accept", few)
+	}
+}
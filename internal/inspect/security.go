@@ -0,0 +1,167 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SecurityRunnerName identifies the security runner in weights and reports.
+const SecurityRunnerName = "security_analysis"
+
+// FaultSecurity is the fault class for security weaknesses in the code.
+const FaultSecurity = "security weaknesses"
+
+// binGosec is the gosec binary.
+const binGosec = "gosec"
+
+// severityWeights weights gosec findings by severity. Unknown severities
+// count as low.
+var severityWeights = map[string]float64{"HIGH": 3, "MEDIUM": 2, "LOW": 1}
+
+// gosecReport is the subset of gosec's JSON report the runner reads.
+type gosecReport struct {
+	Issues []gosecIssue `json:"Issues"`
+}
+
+// gosecIssue is one gosec finding. Line is a string because gosec reports
+// multi-line findings as a range such as "12-14".
+type gosecIssue struct {
+	Severity   string `json:"severity"`
+	Confidence string `json:"confidence"`
+	RuleID     string `json:"rule_id"`
+	Details    string `json:"details"`
+	File       string `json:"file"`
+	Line       string `json:"line"`
+}
+
+// SecurityRunner runs gosec on the modified packages and scores inversely to
+// the severity-weighted number of findings: 1 / (1 + weighted count), where
+// high, medium, and low findings weigh 3, 2, and 1. It skips when gosec is
+// not installed.
+type SecurityRunner struct {
+	lookPath func(file string) (string, error)
+	// runGosec runs gosec on dirs from dir and returns its JSON report.
+	runGosec func(ctx context.Context, dir string, dirs []string) ([]byte, error)
+}
+
+// NewSecurityRunner creates a SecurityRunner that runs gosec from PATH.
+func NewSecurityRunner() *SecurityRunner {
+	return &SecurityRunner{lookPath: exec.LookPath, runGosec: runGosec}
+}
+
+// Name returns the technique identifier.
+func (s *SecurityRunner) Name() string { return SecurityRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (s *SecurityRunner) FaultClass() string { return FaultSecurity }
+
+// Applicable reports whether the input is code work with modified packages.
+func (s *SecurityRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	if len(input.ModifiedPackages) == 0 {
+		return false, "no modified packages"
+	}
+	return true, ""
+}
+
+// Run analyzes the modified packages with gosec. Each finding becomes
+// evidence naming its file, line, rule, and severity.
+func (s *SecurityRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if _, err := s.lookPath(binGosec); err != nil {
+		return skipResult(s.Name(), true, "gosec is not installed"), nil
+	}
+	pkgs, err := listPackages(ctx, input.Dir, input.ModifiedPackages)
+	if err != nil {
+		return TechniqueResult{}, fmt.Errorf("listing packages: %w", err)
+	}
+	dirs := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		dirs = append(dirs, pkg.Dir)
+	}
+	out, err := s.runGosec(ctx, input.Dir, dirs)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	var report gosecReport
+	if err := json.Unmarshal(out, &report); err != nil {
+		return TechniqueResult{}, fmt.Errorf("parsing gosec report: %w", err)
+	}
+
+	var weighted float64
+	evidence := make([]Evidence, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		w, ok := severityWeights[issue.Severity]
+		if !ok {
+			w = severityWeights["LOW"]
+		}
+		weighted += w
+		evidence = append(evidence, Evidence{
+			CriterionID: issue.RuleID,
+			File:        relativeTo(input.Dir, issue.File),
+			Line:        firstLine(issue.Line),
+			Detail:      fmt.Sprintf("%s (severity %s, confidence %s)", issue.Details, issue.Severity, issue.Confidence),
+		})
+	}
+	verdict := VerdictPass
+	if len(evidence) > 0 {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     s.Name(),
+		Score:         1 / (1 + weighted),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: true,
+	}, nil
+}
+
+// runGosec runs gosec with a JSON report on dirs. gosec exits 1 when it
+// finds issues, so that exit status is not an error.
+func runGosec(ctx context.Context, dir string, dirs []string) ([]byte, error) {
+	args := append([]string{"-fmt=json", "-quiet"}, dirs...)
+	cmd := exec.CommandContext(ctx, binGosec, args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) > 0) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("gosec: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// firstLine returns the first line of a gosec line or line range, or 0.
+func firstLine(s string) int {
+	first, _, _ := strings.Cut(s, "-")
+	n, _ := strconv.Atoi(first)
+	return n
+}
+
+// relativeTo returns path relative to dir when it lies under dir, and path
+// unchanged otherwise.
+func relativeTo(dir, path string) string {
+	if dir == "" {
+		dir = "."
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,84 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecurityRunner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":      goModule,
+		"app/app.go":  "package app\n",
+		"app/util.go": "package app\n",
+	})
+	abs, err := filepath.Abs(filepath.Join(dir, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := func(string) (string, error) { return binGosec, nil }
+	report := `{"Issues": [
+  {"severity": "HIGH", "confidence": "HIGH", "rule_id": "G204", "details": "Subprocess launched with variable", "file": "` + filepath.Join(abs, "app.go") + `", "line": "12"},
+  {"severity": "LOW", "confidence": "MEDIUM", "rule_id": "G104", "details": "Errors unhandled", "file": "` + filepath.Join(abs, "util.go") + `", "line": "7-9"}
+]}`
+
+	tests := []struct {
+		name         string
+		lookPath     func(string) (string, error)
+		report       string
+		wantVerdict  Verdict
+		wantScore    float64
+		wantEvidence int
+	}{
+		{"not installed skips", func(string) (string, error) { return "", exec.ErrNotFound }, "", VerdictSkip, 0, 1},
+		{"no findings pass", found, `{"Issues": []}`, VerdictPass, 1, 0},
+		{"findings fail, weighted by severity", found, report, VerdictFail, 1.0 / 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotDirs []string
+			s := &SecurityRunner{lookPath: tt.lookPath, runGosec: func(_ context.Context, _ string, dirs []string) ([]byte, error) {
+				gotDirs = dirs
+				return []byte(tt.report), nil
+			}}
+			input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./app"}}
+			result, err := s.Run(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore || len(result.Evidence) != tt.wantEvidence {
+				t.Fatalf("result = %+v, want verdict %s, score %v, %d evidence", result, tt.wantVerdict, tt.wantScore, tt.wantEvidence)
+			}
+			if !result.Deterministic {
+				t.Error("security runner should be deterministic")
+			}
+			if tt.wantVerdict == VerdictSkip {
+				return
+			}
+			if len(gotDirs) != 1 || gotDirs[0] != abs {
+				t.Errorf("gosec ran on %v, want [%s]", gotDirs, abs)
+			}
+			if tt.wantEvidence > 0 {
+				want := Evidence{CriterionID: "G104", File: filepath.Join("app", "util.go"), Line: 7}
+				got := result.Evidence[1]
+				if got.CriterionID != want.CriterionID || got.File != want.File || got.Line != want.Line {
+					t.Errorf("Evidence[1] = %+v, want rule, file, and line %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityRunner_GosecError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"go.mod": goModule, "app/app.go": "package app\n"})
+	s := &SecurityRunner{
+		lookPath: func(string) (string, error) { return binGosec, nil },
+		runGosec: func(context.Context, string, []string) ([]byte, error) { return nil, errors.New("boom") },
+	}
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./app"}}
+	if _, err := s.Run(context.Background(), input); err == nil {
+		t.Error("Run should report a gosec failure")
+	}
+}
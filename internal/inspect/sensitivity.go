@@ -0,0 +1,118 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+)
+
+// SensitivityStep is the relative change SensitivityReport applies to each
+// weight, in both directions.
+const SensitivityStep = 0.10
+
+// WeightSensitivity is how scaling one technique's weight by
+// 1 ± SensitivityStep moves the composite.
+type WeightSensitivity struct {
+	Technique string
+	// Down and Up are the composites with the weight lowered and raised.
+	Down CompositeResult
+	Up   CompositeResult
+	// Flips is true when Down or Up takes a different action than the
+	// unperturbed composite.
+	Flips bool
+	// Influence is the larger of the two changes in the composite score.
+	Influence float64
+}
+
+// SensitivityReport describes how fragile a decision is to the weights.
+type SensitivityReport struct {
+	// Composite is the decision under the configured weights.
+	Composite CompositeResult
+	// Weights holds one entry per scored technique, in result order.
+	Weights []WeightSensitivity
+	// Fragile is true when some entry of Weights flips the action.
+	Fragile bool
+	// MostInfluential names the technique whose weight moves the decision
+	// most: the one with the highest Influence among those that flip the
+	// action, or among all when none does. Empty when nothing was scored.
+	MostInfluential string
+	// Margin is the distance from the composite score to the nearest
+	// action threshold.
+	Margin float64
+}
+
+// SensitivityReport scores results again with each scored technique's
+// weight lowered and raised by SensitivityStep, the other weights and
+// settings unchanged, and reports which perturbations change the action.
+// A fragile accept is one reviewers should not trust on the weights alone:
+// trusting a technique a little less would send it to mend.
+func (s *Scorer) SensitivityReport(results []TechniqueResult) SensitivityReport {
+	report := SensitivityReport{Composite: s.Score(results), Margin: math.Inf(1)}
+	for _, tier := range s.config.actionTiers() {
+		report.Margin = math.Min(report.Margin, math.Abs(report.Composite.Score-tier.Threshold))
+	}
+
+	for _, r := range results {
+		w := s.config.Weights[r.Technique]
+		if r.Verdict == VerdictSkip || w <= 0 {
+			continue
+		}
+		ws := WeightSensitivity{
+			Technique: r.Technique,
+			Down:      s.withWeight(r.Technique, w*(1-SensitivityStep)).Score(results),
+			Up:        s.withWeight(r.Technique, w*(1+SensitivityStep)).Score(results),
+		}
+		ws.Flips = ws.Down.Action != report.Composite.Action || ws.Up.Action != report.Composite.Action
+		ws.Influence = math.Max(math.Abs(ws.Down.Score-report.Composite.Score), math.Abs(ws.Up.Score-report.Composite.Score))
+		report.Fragile = report.Fragile || ws.Flips
+		report.Weights = append(report.Weights, ws)
+	}
+	var best *WeightSensitivity
+	for i := range report.Weights {
+		ws := &report.Weights[i]
+		if best == nil || ws.Flips && !best.Flips || ws.Flips == best.Flips && ws.Influence > best.Influence {
+			best = ws
+		}
+	}
+	if best != nil {
+		report.MostInfluential = best.Technique
+	}
+	return report
+}
+
+// withWeight returns a scorer with the named technique's weight replaced.
+// The weight is not validated: a raised weight may exceed 1.
+func (s *Scorer) withWeight(name string, weight float64) *Scorer {
+	config := s.config
+	config.Weights = maps.Clone(s.config.Weights)
+	config.Weights[name] = weight
+	return &Scorer{config: config}
+}
+
+// WriteSensitivity prints how each scored technique's weight moves the
+// decision, then whether the decision is fragile and which technique
+// influences it most.
+func WriteSensitivity(w io.Writer, report SensitivityReport) error {
+	pct := SensitivityStep * 100
+	for _, ws := range report.Weights {
+		line := fmt.Sprintf("%s: -%.0f%% weight %.2f %s, +%.0f%% weight %.2f %s",
+			ws.Technique, pct, ws.Down.Score, ws.Down.Action, pct, ws.Up.Score, ws.Up.Action)
+		if ws.Flips {
+			line += " (flips)"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	verdict := "robust"
+	if report.Fragile {
+		verdict = "fragile"
+	}
+	line := fmt.Sprintf("decision %s is %s to ±%.0f%% weights (%.2f from the nearest threshold)", report.Composite.Action, verdict, pct, report.Margin)
+	if report.MostInfluential != "" {
+		line += "; most influential: " + report.MostInfluential
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
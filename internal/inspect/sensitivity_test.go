@@ -0,0 +1,64 @@
+package inspect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScorer_SensitivityReport(t *testing.T) {
+	scorer := newScorer(t, DefaultScorerConfig())
+	skipped := skipResult(DifferentialTestingName, true, "no fixture directory")
+
+	t.Run("fragile", func(t *testing.T) {
+		// 0.30*1 + 0.25*0.58 over 0.55 is 0.809, just above the accept
+		// threshold: trusting translation less or mutation more drops it
+		// below.
+		report := scorer.SensitivityReport([]TechniqueResult{result(TranslationValidatorName, 1), result(MutationRunnerName, 0.58), skipped})
+		if report.Composite.Action != ActionAccept || !report.Fragile {
+			t.Fatalf("report = %+v, want a fragile accept", report)
+		}
+		if len(report.Weights) != 2 {
+			t.Fatalf("Weights = %+v, want the two scored techniques", report.Weights)
+		}
+		translation, mutation := report.Weights[0], report.Weights[1]
+		if translation.Down.Action != ActionMend || translation.Up.Action != ActionAccept || !translation.Flips {
+			t.Errorf("translation: down %s, up %s; want a lower weight to flip to mend", translation.Down.Action, translation.Up.Action)
+		}
+		if mutation.Down.Action != ActionAccept || mutation.Up.Action != ActionMend || !mutation.Flips {
+			t.Errorf("mutation: down %s, up %s; want a higher weight to flip to mend", mutation.Down.Action, mutation.Up.Action)
+		}
+		if report.MostInfluential != TranslationValidatorName {
+			t.Errorf("MostInfluential = %q, want %q (influence %v vs %v)", report.MostInfluential, TranslationValidatorName, translation.Influence, mutation.Influence)
+		}
+		if !approxEqual(report.Margin, report.Composite.Score-DefaultAcceptThreshold) {
+			t.Errorf("Margin = %v, want the distance to the accept threshold", report.Margin)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteSensitivity(&buf, report); err != nil {
+			t.Fatalf("WriteSensitivity failed: %v", err)
+		}
+		for _, want := range []string{"translation_validation: -10% weight 0.80 mend, +10% weight 0.82 accept (flips)", "decision accept is fragile", "most influential: translation_validation"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("output missing %q:\n%s", want, buf.String())
+			}
+		}
+	})
+
+	t.Run("robust", func(t *testing.T) {
+		// A contract failure weighs little against a strong composite.
+		report := scorer.SensitivityReport([]TechniqueResult{result(TranslationValidatorName, 1), result(MutationRunnerName, 0.95), result(ContractInjectionName, 0.5), skipped})
+		if report.Composite.Action != ActionAccept || report.Fragile {
+			t.Fatalf("report = %+v, want a robust accept", report)
+		}
+		for _, ws := range report.Weights {
+			if ws.Flips || ws.Down.Action != ActionAccept || ws.Up.Action != ActionAccept {
+				t.Errorf("%s flips the decision: down %s, up %s", ws.Technique, ws.Down.Action, ws.Up.Action)
+			}
+		}
+		if report.MostInfluential != ContractInjectionName {
+			t.Errorf("MostInfluential = %q, want the outlying %q", report.MostInfluential, ContractInjectionName)
+		}
+	})
+}
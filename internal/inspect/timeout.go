@@ -0,0 +1,54 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// timeoutTechnique bounds the runtime of the technique it wraps.
+type timeoutTechnique struct {
+	Technique
+	timeout time.Duration
+}
+
+// WithTimeout wraps tech so that Run gives up after d, returning a skip
+// result that notes the timeout instead of an error. The wrapped technique's
+// context is cancelled at the deadline; a technique that ignores its context
+// keeps running in the background until it returns, but no longer holds up
+// the caller. A non-positive d returns tech unchanged.
+func WithTimeout(tech Technique, d time.Duration) Technique {
+	if d <= 0 {
+		return tech
+	}
+	return &timeoutTechnique{Technique: tech, timeout: d}
+}
+
+// Run runs the wrapped technique with a deadline. Cancellation of ctx itself
+// is returned as ctx.Err().
+func (t *timeoutTechnique) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	type outcome struct {
+		result TechniqueResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := t.Technique.Run(runCtx, input)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		if runCtx.Err() == nil || ctx.Err() != nil {
+			return o.result, o.err
+		}
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			return TechniqueResult{}, ctx.Err()
+		}
+	}
+	return skipResult(t.Name(), false, fmt.Sprintf("timed out after %v", t.timeout)), nil
+}
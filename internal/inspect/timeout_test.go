@@ -0,0 +1,74 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubbornTechnique is a fake that ignores cancellation while it waits.
+type stubbornTechnique struct{ *fakeTechnique }
+
+func (s stubbornTechnique) Run(_ context.Context, input *InspectInput) (TechniqueResult, error) {
+	time.Sleep(s.delay)
+	return s.fakeTechnique.Run(context.Background(), input)
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		tech        Technique
+		timeout     time.Duration
+		wantVerdict Verdict
+	}{
+		{"finishes in time", passing("slow", time.Millisecond), time.Minute, VerdictPass},
+		{"times out", passing("slow", time.Minute), 20 * time.Millisecond, VerdictSkip},
+		{"times out ignoring cancellation", stubbornTechnique{passing("slow", time.Second)}, 20 * time.Millisecond, VerdictSkip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tech := WithTimeout(tt.tech, tt.timeout)
+			if tech.Name() != "slow" || tech.FaultClass() != FaultTestInadequacy {
+				t.Errorf("wrapped technique = %s/%s, want the inner name and fault class", tech.Name(), tech.FaultClass())
+			}
+			start := time.Now()
+			result, err := tech.Run(context.Background(), &InspectInput{})
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Run took %v", elapsed)
+			}
+			if result.Verdict != tt.wantVerdict {
+				t.Fatalf("result = %+v, want verdict %s", result, tt.wantVerdict)
+			}
+			if tt.wantVerdict == VerdictSkip && (len(result.Evidence) != 1 || !strings.Contains(result.Evidence[0].Detail, "timed out after 20ms")) {
+				t.Errorf("Evidence = %+v, want a timeout note", result.Evidence)
+			}
+		})
+	}
+}
+
+func TestWithTimeout_ParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := WithTimeout(passing("slow", time.Minute), time.Minute).Run(ctx, &InspectInput{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run error = %v, want context.Canceled", err)
+	}
+}
+
+func TestPortfolio_TechniqueTimeout(t *testing.T) {
+	p := NewPortfolio(newScorer(t, DefaultScorerConfig()), PortfolioConfig{TechniqueTimeout: 20 * time.Millisecond})
+	p.Register(passing("slow", time.Minute))
+	p.Register(passing("fast", 0))
+	cr, err := p.Run(context.Background(), &InspectInput{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if cr.Results[0].Verdict != VerdictSkip || cr.Results[1].Verdict != VerdictPass {
+		t.Errorf("results = %+v, want the slow technique skipped and the fast one passing", cr.Results)
+	}
+}
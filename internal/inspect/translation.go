@@ -0,0 +1,235 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Mechanical check names, used as criterion IDs in evidence.
+const (
+	CheckFilesExist = "files_exist"
+	CheckCompiles   = "compiles"
+	CheckTestsPass  = "tests_pass"
+	CheckVetPasses  = "vet_passes"
+)
+
+// maxCheckDetailLines bounds the lines of check output kept in evidence.
+const maxCheckDetailLines = 8
+
+// MechanicalCheck is one criterion verified without judgment.
+type MechanicalCheck struct {
+	// Name identifies the check and is reported as the criterion ID.
+	Name string
+	// Run returns an error describing why the criterion is not met.
+	Run func() error
+}
+
+// SemanticJudge decides prose criteria that no mechanical check covers,
+// typically by asking an LLM.
+type SemanticJudge interface {
+	// Judge reports whether input satisfies criterion, with a short
+	// explanation.
+	Judge(criterion string, input *InspectInput) (pass bool, detail string, err error)
+}
+
+// TranslationValidator verifies that stitch output meets its criteria with
+// mechanical checks: modified files exist, and for code, the modified
+// packages compile, pass their tests, and pass go vet. PRD criteria of the
+// forms "must export <Symbol>", "package <x> must compile", and "function
+// <F> must return <type>" become targeted checks. When a SemanticJudge is
+// configured, other criteria are routed to it and the result is marked
+// non-deterministic; without a judge they are reported as skipped.
+//
+// The build, test, and vet checks are pluggable; nil uses the go tool, and
+// for tests the validator's TestCommand. Each receives the context passed to
+// Run and must stop when it is cancelled.
+type TranslationValidator struct {
+	judge      SemanticJudge
+	test       TestCommand
+	buildCheck goCheckFunc
+	testCheck  goCheckFunc
+	vetCheck   goCheckFunc
+}
+
+// goCheckFunc checks packages, returning an error describing why they fail.
+type goCheckFunc func(ctx context.Context, pkgs []string) error
+
+// NewTranslationValidator creates a TranslationValidator that runs the go
+// tool. judge may be nil to keep validation fully deterministic.
+func NewTranslationValidator(judge SemanticJudge) *TranslationValidator {
+	return &TranslationValidator{judge: judge, test: GoTest}
+}
+
+// useTestCommand makes the tests_pass check run test; nil runs go test.
+func (v *TranslationValidator) useTestCommand(test TestCommand) {
+	if test == nil {
+		test = GoTest
+	}
+	v.test = test
+}
+
+// cacheConfig reports whether a semantic judge runs, for the result cache.
+func (v *TranslationValidator) cacheConfig() (any, bool) {
+	return struct{ Judge bool }{v.judge != nil}, true
+}
+
+// Name returns the technique identifier.
+func (v *TranslationValidator) Name() string { return TranslationValidatorName }
+
+// FaultClass returns the fault class this technique targets.
+func (v *TranslationValidator) FaultClass() string { return FaultSpecConformance }
+
+// Applicable reports whether the input has modified files to validate.
+func (v *TranslationValidator) Applicable(input *InspectInput) (bool, string) {
+	if len(input.ModifiedFiles) == 0 && len(input.ModifiedPackages) == 0 {
+		return false, "no modified files or packages"
+	}
+	return true, ""
+}
+
+// Run executes every mechanical check, then judges the remaining criteria
+// when a judge is configured. The score is the fraction of evaluated
+// criteria that pass; each failure is reported against its criterion. A
+// judge error leaves its criterion unevaluated.
+func (v *TranslationValidator) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	checks, unmatched := v.buildChecks(ctx, input)
+	var judged []string
+	var evidence []Evidence
+	if v.judge != nil {
+		judged = unmatched
+	} else {
+		for _, criterion := range unmatched {
+			evidence = append(evidence, Evidence{CriterionID: criterion, Detail: "skipped: no mechanical check matches this criterion"})
+		}
+	}
+	if len(checks) == 0 && len(judged) == 0 {
+		result := skipResult(v.Name(), true, "no mechanical checks apply")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+
+	var evaluated, passed int
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			return TechniqueResult{}, ctx.Err()
+		}
+		evaluated++
+		if err := check.Run(); err != nil {
+			evidence = append(evidence, Evidence{CriterionID: check.Name, Detail: checkDetail(err)})
+			continue
+		}
+		passed++
+	}
+	for _, criterion := range judged {
+		if ctx.Err() != nil {
+			return TechniqueResult{}, ctx.Err()
+		}
+		ok, detail, err := v.judge.Judge(criterion, input)
+		if err != nil {
+			evidence = append(evidence, Evidence{CriterionID: criterion, Detail: fmt.Sprintf("not judged: %v", err)})
+			continue
+		}
+		evaluated++
+		if ok {
+			passed++
+			continue
+		}
+		evidence = append(evidence, Evidence{CriterionID: criterion, Detail: detail})
+	}
+
+	deterministic := len(judged) == 0
+	if evaluated == 0 {
+		result := skipResult(v.Name(), deterministic, "no criteria could be evaluated")
+		result.Evidence = append(result.Evidence, evidence...)
+		return result, nil
+	}
+	verdict := VerdictPass
+	if passed < evaluated {
+		verdict = VerdictFail
+	}
+	return TechniqueResult{
+		Technique:     v.Name(),
+		Score:         float64(passed) / float64(evaluated),
+		Verdict:       verdict,
+		Evidence:      evidence,
+		Deterministic: deterministic,
+	}, nil
+}
+
+// buildChecks returns the mechanical checks that apply to input and the PRD
+// criteria no check covers.
+func (v *TranslationValidator) buildChecks(ctx context.Context, input *InspectInput) ([]MechanicalCheck, []string) {
+	checks, unmatched := v.criterionChecks(ctx, input)
+	if len(input.ModifiedFiles) > 0 {
+		checks = append(checks, MechanicalCheck{Name: CheckFilesExist, Run: func() error {
+			return filesExist(input)
+		}})
+	}
+	if input.WorkType != WorkTypeCode || len(input.ModifiedPackages) == 0 {
+		return checks, unmatched
+	}
+	pkgs := input.ModifiedPackages
+	test := v.test
+	if test == nil {
+		test = GoTest
+	}
+	for _, c := range []struct {
+		name     string
+		check    goCheckFunc
+		fallback goCheckFunc
+	}{
+		{CheckCompiles, v.buildCheck, goCheck(input.Dir, "build")},
+		{CheckTestsPass, v.testCheck, testCheck(input.Dir, test)},
+		{CheckVetPasses, v.vetCheck, goCheck(input.Dir, "vet")},
+	} {
+		check := c.check
+		if check == nil {
+			check = c.fallback
+		}
+		checks = append(checks, MechanicalCheck{Name: c.name, Run: func() error { return check(ctx, pkgs) }})
+	}
+	return checks, unmatched
+}
+
+// goCheck returns a check that runs the go tool with args and the packages
+// in dir, failing on a non-zero exit. Cancelling ctx kills the go tool.
+func goCheck(dir string, args ...string) goCheckFunc {
+	return func(ctx context.Context, pkgs []string) error {
+		_, err := runGo(ctx, dir, append(append([]string{}, args...), pkgs...)...)
+		return err
+	}
+}
+
+// testCheck returns a check that runs test with -count=1 and the packages
+// in dir, failing when the tests do.
+func testCheck(dir string, test TestCommand) goCheckFunc {
+	return func(ctx context.Context, pkgs []string) error {
+		_, err := test(ctx, dir, append([]string{"-count=1"}, pkgs...))
+		return err
+	}
+}
+
+// checkDetail returns the message of a failed check, such as compiler or
+// test output, clipped to its first maxCheckDetailLines lines.
+func checkDetail(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	if len(lines) <= maxCheckDetailLines {
+		return strings.Join(lines, "\n")
+	}
+	omitted := len(lines) - maxCheckDetailLines
+	return fmt.Sprintf("%s\n... (%d more lines)", strings.Join(lines[:maxCheckDetailLines], "\n"), omitted)
+}
+
+// filesExist reports the modified files that are missing.
+func filesExist(input *InspectInput) error {
+	var errs []error
+	for _, file := range input.ModifiedFiles {
+		if _, err := os.Stat(input.path(file)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,262 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubValidator returns a validator whose go checks report the given errors.
+func stubValidator(buildErr, testErr, vetErr error) *TranslationValidator {
+	return &TranslationValidator{
+		buildCheck: func(context.Context, []string) error { return buildErr },
+		testCheck:  func(context.Context, []string) error { return testErr },
+		vetCheck:   func(context.Context, []string) error { return vetErr },
+	}
+}
+
+func TestTranslationValidator(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc/calc.go": "package calc\n"})
+	input := &InspectInput{
+		WorkType:         WorkTypeCode,
+		Dir:              dir,
+		ModifiedFiles:    []string{"calc/calc.go"},
+		ModifiedPackages: []string{"./calc"},
+	}
+
+	tests := []struct {
+		name          string
+		validator     *TranslationValidator
+		files         []string
+		wantScore     float64
+		wantCriterion string
+	}{
+		{"all pass", stubValidator(nil, nil, nil), nil, 1, ""},
+		{"vet fails", stubValidator(nil, nil, errors.New("calc.go:3: unreachable code")), nil, 0.75, CheckVetPasses},
+		{"test fails", stubValidator(nil, errors.New("FAIL"), nil), nil, 0.75, CheckTestsPass},
+		{"missing file", stubValidator(nil, nil, nil), []string{"calc/calc.go", "calc/gone.go"}, 0.75, CheckFilesExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := *input
+			if tt.files != nil {
+				in.ModifiedFiles = tt.files
+			}
+			result, err := tt.validator.Run(context.Background(), &in)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %.2f, want %.2f", result.Score, tt.wantScore)
+			}
+			if tt.wantCriterion == "" {
+				if result.Verdict != VerdictPass || len(result.Evidence) != 0 {
+					t.Errorf("result = %+v, want pass without evidence", result)
+				}
+				return
+			}
+			if result.Verdict != VerdictFail || len(result.Evidence) != 1 || result.Evidence[0].CriterionID != tt.wantCriterion {
+				t.Errorf("result = %+v, want %s to fail", result, tt.wantCriterion)
+			}
+		})
+	}
+}
+
+func TestTranslationValidator_Vet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go vet")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"calc/calc.go": `package calc
+
+import "fmt"
+
+func Describe(n int) string {
+	return fmt.Sprintf("%s", n)
+}
+`,
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}}
+	validator := &TranslationValidator{testCheck: func(context.Context, []string) error { return nil }}
+
+	result, err := validator.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Evidence) != 1 || result.Evidence[0].CriterionID != CheckVetPasses || !strings.Contains(result.Evidence[0].Detail, "Sprintf") {
+		t.Errorf("Evidence = %+v, want go vet's Sprintf finding", result.Evidence)
+	}
+}
+
+// fakeJudge passes criteria containing "pass" and fails on "error".
+type fakeJudge struct{ calls []string }
+
+func (j *fakeJudge) Judge(criterion string, _ *InspectInput) (bool, string, error) {
+	j.calls = append(j.calls, criterion)
+	if strings.Contains(criterion, "error") {
+		return false, "", errors.New("judge unavailable")
+	}
+	return strings.Contains(criterion, "pass"), "criterion not met", nil
+}
+
+func TestTranslationValidator_SemanticJudge(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"README.md": "# Calc\n"})
+	criteria := []string{"README explains usage (pass)", "README lists every flag", "judge error"}
+	input := &InspectInput{WorkType: WorkTypeDocs, Dir: dir, ModifiedFiles: []string{"README.md"}, PRDCriteria: criteria}
+
+	judge := &fakeJudge{}
+	validator := NewTranslationValidator(judge)
+	result, err := validator.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(judge.calls) != len(criteria) {
+		t.Errorf("judge called for %q, want every criterion", judge.calls)
+	}
+	// files_exist and one judged criterion pass; the judge error is not counted.
+	if result.Deterministic || result.Score != 2.0/3 || result.Verdict != VerdictFail {
+		t.Errorf("result = %+v, want non-deterministic fail scoring 2/3", result)
+	}
+
+	result, err = NewTranslationValidator(nil).Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Deterministic || result.Score != 1 {
+		t.Errorf("without judge: result = %+v, want deterministic pass on mechanical checks only", result)
+	}
+}
+
+func TestTranslationValidator_Criteria(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list and type-checks from source")
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod": goModule,
+		"calc/calc.go": `package calc
+
+import "errors"
+
+type Calc struct{}
+
+func (c *Calc) Reset() {}
+
+func Add(a, b int) (int, error) {
+	if a < 0 {
+		return 0, errors.New("negative")
+	}
+	return helper(a, b), nil
+}
+
+func helper(a, b int) int { return a + b }
+`,
+	})
+	criteria := []string{
+		"calc must export Add",
+		"The package must export calc.Calc.",
+		"must export Calc.Reset",
+		"must export helper",
+		"must export Missing",
+		"function Add must return (int, error)",
+		"function Add must return int",
+		"package ./calc must compile",
+		"Errors are handled gracefully",
+	}
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}, PRDCriteria: criteria}
+	var built []string
+	validator := stubValidator(nil, nil, nil)
+	validator.buildCheck = func(_ context.Context, pkgs []string) error {
+		built = append(built, pkgs...)
+		return nil
+	}
+
+	result, err := validator.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	failed := map[string]string{}
+	for _, ev := range result.Evidence {
+		failed[ev.CriterionID] = ev.Detail
+	}
+	want := map[string]string{
+		"must export helper":            "not exported",
+		"must export Missing":           "not found",
+		"function Add must return int":  "returns (int, error)",
+		"Errors are handled gracefully": "skipped",
+	}
+	if len(failed) != len(want) {
+		t.Errorf("evidence for %v, want %v", failed, want)
+	}
+	for criterion, detail := range want {
+		if !strings.Contains(failed[criterion], detail) {
+			t.Errorf("evidence for %q = %q, want %q", criterion, failed[criterion], detail)
+		}
+	}
+	// 8 matched criteria and 3 package checks, 3 failing; the prose criterion is not counted.
+	if result.Score != 8.0/11 || !result.Deterministic {
+		t.Errorf("Score = %.3f deterministic=%v, want 8/11 and deterministic", result.Score, result.Deterministic)
+	}
+	if !slices.Contains(built, "./calc") {
+		t.Errorf("compile criterion built %v, want ./calc", built)
+	}
+}
+
+func TestTranslationValidator_CancelsCheck(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"calc/calc.go": "package calc\n"})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}}
+	validator := stubValidator(nil, nil, nil)
+	validator.buildCheck = func(ctx context.Context, _ []string) error {
+		// A hung build: only cancellation ends it.
+		return exec.CommandContext(ctx, "sleep", "60").Run()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := validator.Run(ctx, input)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Run took %v after cancellation", elapsed)
+	}
+}
+
+func TestTranslationValidator_CheckOutput(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":       goModule,
+		"calc/calc.go": "package calc\n\nfunc Add(a, b int) int { return a + c }\n",
+	})
+	input := &InspectInput{WorkType: WorkTypeCode, Dir: dir, ModifiedPackages: []string{"./calc"}}
+	validator := stubValidator(nil, nil, nil)
+	validator.buildCheck = nil
+
+	result, err := validator.Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Evidence) != 1 || !strings.Contains(result.Evidence[0].Detail, "undefined: c") {
+		t.Errorf("Evidence = %+v, want the compiler error", result.Evidence)
+	}
+}
+
+func TestCheckDetail(t *testing.T) {
+	var long []string
+	for i := range 20 {
+		long = append(long, fmt.Sprintf("line %d", i))
+	}
+	got := checkDetail(errors.New(strings.Join(long, "\n") + "\n"))
+	want := strings.Join(long[:maxCheckDetailLines], "\n") + "\n... (12 more lines)"
+	if got != want {
+		t.Errorf("checkDetail = %q, want %q", got, want)
+	}
+	if got := checkDetail(errors.New("short\n")); got != "short" {
+		t.Errorf("checkDetail = %q, want %q", got, "short")
+	}
+}
@@ -0,0 +1,184 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// VulnRunnerName identifies the vulnerability runner in weights and reports.
+const VulnRunnerName = "vulnerability_check"
+
+// FaultVulnerableDependency is the fault class for calls into dependencies
+// with known vulnerabilities.
+const FaultVulnerableDependency = "vulnerable dependencies"
+
+// binGovulncheck is the govulncheck binary.
+const binGovulncheck = "govulncheck"
+
+// vulnMessage is one message of govulncheck's JSON stream. Only findings
+// are read.
+type vulnMessage struct {
+	Finding *vulnFinding `json:"finding"`
+}
+
+// vulnFinding reports that a vulnerability affects the module. Trace[0] is
+// the vulnerable symbol; it names a function only when the vulnerable code
+// is reachable from the module, and the last frame is then the module's call
+// site.
+type vulnFinding struct {
+	OSV          string      `json:"osv"`
+	FixedVersion string      `json:"fixed_version"`
+	Trace        []vulnFrame `json:"trace"`
+}
+
+// vulnFrame is one frame of a finding trace.
+type vulnFrame struct {
+	Module   string        `json:"module"`
+	Package  string        `json:"package"`
+	Function string        `json:"function"`
+	Receiver string        `json:"receiver"`
+	Position *vulnPosition `json:"position"`
+}
+
+// vulnPosition is a source position in a finding trace.
+type vulnPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// VulnRunner runs govulncheck on the module and fails when the module calls
+// a symbol with a known vulnerability. Vulnerabilities in packages that are
+// imported but whose vulnerable symbols are never called do not count. The
+// score is 1 with no called vulnerabilities and 0 otherwise. It skips when
+// govulncheck is not installed.
+type VulnRunner struct {
+	lookPath func(file string) (string, error)
+	// runVulncheck runs govulncheck in dir and returns its JSON stream.
+	runVulncheck func(ctx context.Context, dir string) ([]byte, error)
+}
+
+// NewVulnRunner creates a VulnRunner that runs govulncheck from PATH.
+func NewVulnRunner() *VulnRunner {
+	return &VulnRunner{lookPath: exec.LookPath, runVulncheck: runGovulncheck}
+}
+
+// Name returns the technique identifier.
+func (v *VulnRunner) Name() string { return VulnRunnerName }
+
+// FaultClass returns the fault class this technique targets.
+func (v *VulnRunner) FaultClass() string { return FaultVulnerableDependency }
+
+// Applicable reports whether the input is code work.
+func (v *VulnRunner) Applicable(input *InspectInput) (bool, string) {
+	if input.WorkType != WorkTypeCode {
+		return false, "not a code task"
+	}
+	return true, ""
+}
+
+// Run checks the module in input.Dir. Each called vulnerable symbol becomes
+// evidence naming the vulnerability and, when known, the module's call site.
+func (v *VulnRunner) Run(ctx context.Context, input *InspectInput) (TechniqueResult, error) {
+	if _, err := v.lookPath(binGovulncheck); err != nil {
+		return skipResult(v.Name(), true, "govulncheck is not installed"), nil
+	}
+	out, err := v.runVulncheck(ctx, input.Dir)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+	findings, err := parseVulnFindings(out)
+	if err != nil {
+		return TechniqueResult{}, err
+	}
+
+	var evidence []Evidence
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if len(f.Trace) == 0 || f.Trace[0].Function == "" {
+			continue // imported or required, but not called
+		}
+		symbol := f.Trace[0].symbol()
+		if key := f.OSV + " " + symbol; !seen[key] {
+			seen[key] = true
+			evidence = append(evidence, f.evidence(input.Dir, symbol))
+		}
+	}
+	result := TechniqueResult{
+		Technique:     v.Name(),
+		Score:         1,
+		Verdict:       VerdictPass,
+		Evidence:      evidence,
+		Deterministic: true,
+	}
+	if len(evidence) > 0 {
+		result.Score = 0
+		result.Verdict = VerdictFail
+	}
+	return result, nil
+}
+
+// parseVulnFindings decodes the findings from govulncheck's stream of JSON
+// messages.
+func parseVulnFindings(out []byte) ([]vulnFinding, error) {
+	var findings []vulnFinding
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var msg vulnMessage
+		err := dec.Decode(&msg)
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing govulncheck output: %w", err)
+		}
+		if msg.Finding != nil {
+			findings = append(findings, *msg.Finding)
+		}
+	}
+}
+
+// symbol names the frame's function as package.Function or
+// package.Receiver.Method.
+func (f vulnFrame) symbol() string {
+	name := f.Function
+	if f.Receiver != "" {
+		name = strings.TrimPrefix(f.Receiver, "*") + "." + name
+	}
+	return f.Package + "." + name
+}
+
+// evidence describes a called vulnerability at the module's call site.
+func (f vulnFinding) evidence(dir, symbol string) Evidence {
+	detail := fmt.Sprintf("%s: calls vulnerable %s", f.OSV, symbol)
+	if f.FixedVersion != "" {
+		detail += fmt.Sprintf(" (fixed in %s %s)", f.Trace[0].Module, f.FixedVersion)
+	}
+	ev := Evidence{CriterionID: f.OSV, Detail: detail}
+	if caller := f.Trace[len(f.Trace)-1]; len(f.Trace) > 1 && caller.Position != nil {
+		ev.File = relativeTo(dir, caller.Position.Filename)
+		ev.Line = caller.Position.Line
+	}
+	return ev
+}
+
+// runGovulncheck runs govulncheck -json on every package of the module in dir.
+func runGovulncheck(ctx context.Context, dir string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, binGovulncheck, "-json", "./...")
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("govulncheck: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,91 @@
+package inspect
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vulnStream is govulncheck -json output with one called and one merely
+// imported vulnerability, plus the non-finding messages it always emits.
+const vulnStream = `{"config": {"protocol_version": "v1.0.0", "scanner_name": "govulncheck"}}
+{"progress": {"message": "Scanning your code..."}}
+{"osv": {"id": "GO-2024-0001", "summary": "Called vulnerability"}}
+{
+  "finding": {
+    "osv": "GO-2024-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [
+      {"module": "example.com/dep", "version": "v1.2.0", "package": "example.com/dep/parse", "function": "Decode", "receiver": "*Decoder"},
+      {"module": "example.com/m", "package": "example.com/m/app", "function": "Load", "position": {"filename": "/mod/app/app.go", "line": 14}}
+    ]
+  }
+}
+{"finding": {"osv": "GO-2024-0001", "fixed_version": "v1.2.3", "trace": [{"module": "example.com/dep", "version": "v1.2.0", "package": "example.com/dep/parse"}]}}
+{"finding": {"osv": "GO-2024-0002", "trace": [{"module": "example.com/other", "version": "v0.1.0", "package": "example.com/other/net"}]}}
+`
+
+func TestVulnRunner(t *testing.T) {
+	found := func(string) (string, error) { return binGovulncheck, nil }
+	tests := []struct {
+		name        string
+		lookPath    func(string) (string, error)
+		output      string
+		wantVerdict Verdict
+		wantScore   float64
+	}{
+		{"not installed skips", func(string) (string, error) { return "", exec.ErrNotFound }, "", VerdictSkip, 0},
+		{"no findings pass", found, `{"config": {"protocol_version": "v1.0.0"}}`, VerdictPass, 1},
+		{"called vulnerability fails", found, vulnStream, VerdictFail, 0},
+		{"imported-only vulnerability passes", found, `{"finding": {"osv": "GO-2024-0002", "trace": [{"module": "example.com/other", "package": "example.com/other/net"}]}}`, VerdictPass, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &VulnRunner{lookPath: tt.lookPath, runVulncheck: func(context.Context, string) ([]byte, error) {
+				return []byte(tt.output), nil
+			}}
+			result, err := v.Run(context.Background(), &InspectInput{WorkType: WorkTypeCode, Dir: "/mod"})
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.Score != tt.wantScore {
+				t.Fatalf("result = %+v, want verdict %s, score %v", result, tt.wantVerdict, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestVulnRunner_Evidence(t *testing.T) {
+	v := &VulnRunner{
+		lookPath:     func(string) (string, error) { return binGovulncheck, nil },
+		runVulncheck: func(context.Context, string) ([]byte, error) { return []byte(vulnStream), nil },
+	}
+	result, err := v.Run(context.Background(), &InspectInput{WorkType: WorkTypeCode, Dir: "/mod"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Evidence) != 1 {
+		t.Fatalf("Evidence = %+v, want only the called vulnerability", result.Evidence)
+	}
+	ev := result.Evidence[0]
+	if ev.CriterionID != "GO-2024-0001" || ev.File != filepath.Join("app", "app.go") || ev.Line != 14 {
+		t.Errorf("Evidence = %+v, want GO-2024-0001 at app/app.go:14", ev)
+	}
+	for _, want := range []string{"example.com/dep/parse.Decoder.Decode", "v1.2.3"} {
+		if !strings.Contains(ev.Detail, want) {
+			t.Errorf("Detail = %q, want it to mention %q", ev.Detail, want)
+		}
+	}
+}
+
+func TestVulnRunner_MalformedOutput(t *testing.T) {
+	v := &VulnRunner{
+		lookPath:     func(string) (string, error) { return binGovulncheck, nil },
+		runVulncheck: func(context.Context, string) ([]byte, error) { return []byte("{not json"), nil },
+	}
+	if _, err := v.Run(context.Background(), &InspectInput{WorkType: WorkTypeCode}); err == nil {
+		t.Error("Run should reject malformed govulncheck output")
+	}
+}
@@ -0,0 +1,87 @@
+// Package logging builds the structured logger cobbler commands emit
+// events with. It is a thin layer over log/slog.
+// Implements: docs/ARCHITECTURE § project structure (internal/logging).
+//
+// Packages that log take a *slog.Logger in their configuration and treat nil
+// as "do not log" (see OrDiscard). Events share the attribute keys below so
+// logs from different commands can be grepped and joined.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// DefaultLevel is the level logged when none is configured.
+const DefaultLevel = "info"
+
+// Attribute keys shared by cobbler events.
+const (
+	KeyCrumb      = "crumb"
+	KeyTechnique  = "technique"
+	KeyVerdict    = "verdict"
+	KeyAction     = "action"
+	KeyScore      = "score"
+	KeyAttempt    = "attempt"
+	KeyDuration   = "duration"
+	KeyFaultClass = "fault_class"
+)
+
+// Options configures New.
+type Options struct {
+	// Level is debug, info, warn, or error. Empty means DefaultLevel.
+	Level string
+	// Format is FormatText or FormatJSON. Empty means FormatText.
+	Format string
+	// Quiet logs errors only, overriding Level.
+	Quiet bool
+}
+
+// New returns a logger writing to w as opts configures.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Quiet {
+		level = slog.LevelError
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch opts.Format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q (valid: %s, %s)", opts.Format, FormatText, FormatJSON)
+	}
+}
+
+// ParseLevel parses a level name, case-insensitively. Empty means
+// DefaultLevel.
+func ParseLevel(s string) (slog.Level, error) {
+	if s == "" {
+		s = DefaultLevel
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return 0, fmt.Errorf("logging: unknown level %q (valid: debug, info, warn, error)", s)
+	}
+	return level, nil
+}
+
+// OrDiscard returns l, or a logger that discards everything when l is nil.
+func OrDiscard(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return l
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		wantInfo  bool
+		wantDebug bool
+		wantErr   bool
+	}{
+		{name: "defaults log info", opts: Options{}, wantInfo: true},
+		{name: "debug level", opts: Options{Level: "DEBUG"}, wantInfo: true, wantDebug: true},
+		{name: "warn level hides info", opts: Options{Level: "warn"}},
+		{name: "quiet overrides level", opts: Options{Level: "debug", Quiet: true}},
+		{name: "unknown level", opts: Options{Level: "loud"}, wantErr: true},
+		{name: "unknown format", opts: Options{Format: "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			logger.Debug("debug event")
+			logger.Info("info event")
+			logger.Error("error event")
+			out := buf.String()
+			if got := strings.Contains(out, "info event"); got != tt.wantInfo {
+				t.Errorf("info logged = %v, want %v:\n%s", got, tt.wantInfo, out)
+			}
+			if got := strings.Contains(out, "debug event"); got != tt.wantDebug {
+				t.Errorf("debug logged = %v, want %v:\n%s", got, tt.wantDebug, out)
+			}
+			if !strings.Contains(out, "error event") {
+				t.Errorf("errors must always be logged:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("crumb claimed", KeyCrumb, "c1")
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if record["msg"] != "crumb claimed" || record[KeyCrumb] != "c1" {
+		t.Errorf("record = %v", record)
+	}
+}
+
+func TestOrDiscard(t *testing.T) {
+	OrDiscard(nil).Info("dropped")
+	var buf bytes.Buffer
+	logger, _ := New(&buf, Options{})
+	if OrDiscard(logger) != logger {
+		t.Error("OrDiscard replaced a non-nil logger")
+	}
+}
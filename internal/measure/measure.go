@@ -0,0 +1,72 @@
+// Package measure proposes new work by asking a planning agent to assess
+// project state.
+// Implements: prd003-measure R1 (project state reader), R3 (agent dispatch),
+// R4 (proposal format), R6 (work type), R8 (review mode), R9 (issue limit).
+//
+// Measure reads the project documents and cupboard into a ProjectState,
+// renders the planning prompt, runs the agent, and parses its proposals.
+// Proposals are written to a review file rather than the cupboard; once
+// reviewed, ImportProposals creates them as pending crumbs.
+package measure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/prompt"
+)
+
+// DefaultLimit is the maximum number of proposals per run (prd003 R9.1).
+const DefaultLimit = 10
+
+// Config controls a measure run.
+type Config struct {
+	// Limit bounds the number of proposals kept from the agent's output.
+	Limit int
+	// Templates renders the planning prompt; nil uses the embedded default.
+	Templates *prompt.Templates
+}
+
+// BuildPrompt renders the planning prompt for state with templates (nil
+// uses the embedded default), asking for at most limit proposals.
+func BuildPrompt(templates *prompt.Templates, state ProjectState, limit int) (string, error) {
+	if templates == nil {
+		templates = prompt.Default()
+	}
+	return templates.Render(prompt.Measure, prompt.Data{
+		Vision:          state.Vision,
+		Architecture:    state.Architecture,
+		Roadmap:         state.Roadmap,
+		Crumbs:          state.Crumbs,
+		Limit:           limit,
+		WorkTypes:       strings.Join(WorkTypes, ", "),
+		MinPriority:     crumbs.MinPriority,
+		DefaultPriority: crumbs.DefaultPriority,
+		MaxPriority:     crumbs.MaxPriority,
+	})
+}
+
+// Measure asks a for proposals based on state. Proposals beyond
+// config.Limit are dropped; since dependencies only point backwards,
+// trimming from the end never leaves a dangling dependency.
+func Measure(ctx context.Context, a agent.Agent, state ProjectState, config Config) ([]Proposal, error) {
+	text, err := BuildPrompt(config.Templates, state, config.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.Run(ctx, agent.Request{Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("running planning agent: %w", err)
+	}
+	proposals, err := ParseProposals(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	if config.Limit > 0 && len(proposals) > config.Limit {
+		proposals = proposals[:config.Limit]
+	}
+	return proposals, nil
+}
@@ -0,0 +1,150 @@
+package measure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func newCupboard(t *testing.T) *crumbs.Cupboard {
+	t.Helper()
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	t.Cleanup(func() { cupboard.Close() })
+	return cupboard
+}
+
+const agentOutput = "Summary of the project.\n\n```json\n" + `[
+  {"index": 0, "title": "Document the parser", "work_type": "docs", "dependency": -1, "description": "Write docs.", "target_file": "docs/parser.md"},
+  {"index": 1, "title": "Implement the parser", "work_type": "code", "dependency": 0, "description": "Write code.", "priority": 5},
+  {"index": 2, "title": "Release the parser", "work_type": "operations", "dependency": 1, "description": "Ship it."}
+]` + "\n```\n"
+
+func TestParseProposals(t *testing.T) {
+	got, err := ParseProposals(agentOutput)
+	if err != nil {
+		t.Fatalf("ParseProposals failed: %v", err)
+	}
+	if len(got) != 3 || got[1].Title != "Implement the parser" || got[1].Dependency != 0 || got[1].Priority != crumbs.MaxPriority {
+		t.Errorf("ParseProposals = %+v", got)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"not JSON", "I could not decide."},
+		{"unterminated fence", "```json\n[]"},
+		{"missing title", `[{"index": 0, "work_type": "code", "dependency": -1}]`},
+		{"unknown work type", `[{"index": 0, "title": "t", "work_type": "testing", "dependency": -1}]`},
+		{"docs without target file", `[{"index": 0, "title": "t", "work_type": "docs", "dependency": -1}]`},
+		{"forward dependency", `[{"index": 0, "title": "t", "work_type": "code", "dependency": 0}]`},
+		{"index out of order", `[{"index": 1, "title": "t", "work_type": "code", "dependency": -1}]`},
+		{"priority out of range", `[{"index": 0, "title": "t", "work_type": "code", "dependency": -1, "priority": 6}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseProposals(tt.output); !errors.Is(err, ErrInvalidProposal) {
+				t.Errorf("ParseProposals error = %v, want ErrInvalidProposal", err)
+			}
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, VisionFile), []byte("vision: parse everything\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cupboard := newCupboard(t)
+	existing, err := cupboard.SetCrumb("", &types.Crumb{Name: "Set up the repository", State: types.StateDone})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+
+	state, err := ReadProjectState(root, cupboard)
+	if err != nil {
+		t.Fatalf("ReadProjectState failed: %v", err)
+	}
+	if state.Vision == "" || state.Roadmap != "" || len(state.Crumbs) != 1 {
+		t.Fatalf("state = %+v, want vision, no roadmap, one crumb", state)
+	}
+
+	a := agent.NewMockAgent(agent.Response{Content: agentOutput})
+	proposals, err := Measure(context.Background(), a, state, Config{Limit: 2})
+	if err != nil {
+		t.Fatalf("Measure failed: %v", err)
+	}
+	if len(proposals) != 2 {
+		t.Errorf("len(proposals) = %d, want trimmed to 2", len(proposals))
+	}
+	for _, want := range []string{"parse everything", existing, "Set up the repository", "at most 2", `"priority": urgency from 1`} {
+		if !strings.Contains(a.Requests()[0].Prompt, want) {
+			t.Errorf("prompt does not contain %q", want)
+		}
+	}
+}
+
+func TestImportProposals(t *testing.T) {
+	proposals, err := ParseProposals(agentOutput)
+	if err != nil {
+		t.Fatalf("ParseProposals failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteProposals(&buf, proposals); err != nil {
+		t.Fatalf("WriteProposals failed: %v", err)
+	}
+	reviewed, err := ReadProposals(&buf)
+	if err != nil {
+		t.Fatalf("ReadProposals failed: %v", err)
+	}
+	if !reflect.DeepEqual(reviewed, proposals) {
+		t.Fatalf("review file round trip = %+v, want %+v", reviewed, proposals)
+	}
+
+	cupboard := newCupboard(t)
+	ids, err := ImportProposals(cupboard, reviewed)
+	if err != nil {
+		t.Fatalf("ImportProposals failed: %v", err)
+	}
+	for i, id := range ids {
+		crumb, err := cupboard.GetCrumb(id)
+		if err != nil {
+			t.Fatalf("GetCrumb failed: %v", err)
+		}
+		if crumb.State != types.StatePending || crumb.Properties[crumbs.PropWorkType] != proposals[i].WorkType {
+			t.Errorf("crumb %d = %+v, want pending %s", i, crumb, proposals[i].WorkType)
+		}
+		if target, _ := crumb.Properties[crumbs.PropTargetFile].(string); target != proposals[i].TargetFile {
+			t.Errorf("crumb %d target file = %q, want %q", i, target, proposals[i].TargetFile)
+		}
+		wantPriority := crumbs.DefaultPriority
+		if i == 1 {
+			wantPriority = crumbs.MaxPriority
+		}
+		if crumb.Properties[crumbs.PropPriority] != wantPriority {
+			t.Errorf("crumb %d priority = %v, want %d", i, crumb.Properties[crumbs.PropPriority], wantPriority)
+		}
+		blockers, _ := crumb.Properties[crumbs.PropBlockedBy].([]any)
+		if i == 0 && blockers != nil {
+			t.Errorf("crumb 0 blocked_by = %v, want none", blockers)
+		}
+		if i > 0 && (len(blockers) != 1 || blockers[0] != ids[i-1]) {
+			t.Errorf("crumb %d blocked_by = %v, want [%s]", i, blockers, ids[i-1])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package measure
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// WorkTypes are the valid proposal work types (prd003 R6.1). Docs and code
+// use the inspect work types, which stitch claims crumbs by.
+var WorkTypes = []string{"planning", inspect.WorkTypeDocs, inspect.WorkTypeCode, "operations"}
+
+// ErrInvalidProposal reports agent output that is not a valid proposal list.
+var ErrInvalidProposal = fmt.Errorf("measure: invalid proposal")
+
+// Proposal is one work item proposed by the planning agent.
+type Proposal struct {
+	Index       int    `json:"index"`
+	Title       string `json:"title"`
+	WorkType    string `json:"work_type"`
+	Dependency  int    `json:"dependency"`
+	Description string `json:"description"`
+	// TargetFile is the file a docs proposal writes, relative to the
+	// repository root. Only docs proposals have one.
+	TargetFile string `json:"target_file,omitempty"`
+	// Priority orders the crumb's claim among ready crumbs, from
+	// crumbs.MinPriority to crumbs.MaxPriority, highest first. Zero means
+	// crumbs.DefaultPriority.
+	Priority int `json:"priority,omitempty"`
+}
+
+// jsonFence opens the fenced block the planning prompt asks for.
+const jsonFence = "```json"
+
+// ParseProposals extracts the proposals from the agent's output. The output
+// is expected to hold a ```json fenced block; output without one is parsed
+// as bare JSON. Every proposal must have a title, a known work type, a
+// target file when it is docs work, a dependency that is -1 or the index of
+// an earlier proposal, and no priority or one in the crumb priority range.
+func ParseProposals(output string) ([]Proposal, error) {
+	body := output
+	if start := strings.Index(output, jsonFence); start >= 0 {
+		body = output[start+len(jsonFence):]
+		end := strings.Index(body, "```")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated %s block", ErrInvalidProposal, jsonFence)
+		}
+		body = body[:end]
+	}
+
+	var proposals []Proposal
+	if err := json.Unmarshal([]byte(strings.TrimSpace(body)), &proposals); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProposal, err)
+	}
+	if err := validateProposals(proposals); err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// validateProposals checks proposals in order. Indexes must be sequential
+// so dependencies can refer to them by position.
+func validateProposals(proposals []Proposal) error {
+	for i, p := range proposals {
+		switch {
+		case p.Index != i:
+			return fmt.Errorf("%w: proposal %d has index %d", ErrInvalidProposal, i, p.Index)
+		case strings.TrimSpace(p.Title) == "":
+			return fmt.Errorf("%w: proposal %d has no title", ErrInvalidProposal, i)
+		case !slices.Contains(WorkTypes, p.WorkType):
+			return fmt.Errorf("%w: proposal %d (%s): work type %q is not one of %s",
+				ErrInvalidProposal, i, p.Title, p.WorkType, strings.Join(WorkTypes, ", "))
+		case p.WorkType == inspect.WorkTypeDocs && strings.TrimSpace(p.TargetFile) == "":
+			return fmt.Errorf("%w: proposal %d (%s): a %s proposal needs a target file",
+				ErrInvalidProposal, i, p.Title, inspect.WorkTypeDocs)
+		case p.Dependency < -1 || p.Dependency >= i:
+			return fmt.Errorf("%w: proposal %d (%s): dependency %d is not an earlier proposal",
+				ErrInvalidProposal, i, p.Title, p.Dependency)
+		case p.Priority != 0 && (p.Priority < crumbs.MinPriority || p.Priority > crumbs.MaxPriority):
+			return fmt.Errorf("%w: proposal %d (%s): priority %d is not between %d and %d",
+				ErrInvalidProposal, i, p.Title, p.Priority, crumbs.MinPriority, crumbs.MaxPriority)
+		}
+	}
+	return nil
+}
+
+// WriteProposals writes proposals to w as an indented JSON array, the
+// review file format read back by ReadProposals.
+func WriteProposals(w io.Writer, proposals []Proposal) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(proposals); err != nil {
+		return fmt.Errorf("writing proposals: %w", err)
+	}
+	return nil
+}
+
+// ReadProposals reads and validates a review file written by
+// WriteProposals, possibly edited by hand.
+func ReadProposals(r io.Reader) ([]Proposal, error) {
+	var proposals []Proposal
+	if err := json.NewDecoder(r).Decode(&proposals); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProposal, err)
+	}
+	if err := validateProposals(proposals); err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// ImportProposals creates one pending crumb per proposal with a single
+// SetCrumbs batch, with the proposal's priority or crumbs.DefaultPriority
+// and each dependency as a blocked_by edge to the crumb created for the
+// earlier proposal. The IDs are generated up front so the edges are part of
+// the batch: the import creates every crumb with its edges or nothing. It
+// returns the new crumb IDs in proposal order.
+func ImportProposals(cupboard *crumbs.Cupboard, proposals []Proposal) ([]string, error) {
+	if err := validateProposals(proposals); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(proposals))
+	for i := range proposals {
+		id, err := crumbs.NewCrumbID()
+		if err != nil {
+			return nil, fmt.Errorf("importing proposals: %w", err)
+		}
+		ids[i] = id
+	}
+	batch := make([]*types.Crumb, len(proposals))
+	for i, p := range proposals {
+		props := map[string]any{
+			crumbs.PropDescription: p.Description,
+			crumbs.PropWorkType:    p.WorkType,
+			crumbs.PropPriority:    cmp.Or(p.Priority, crumbs.DefaultPriority),
+		}
+		if p.TargetFile != "" {
+			props[crumbs.PropTargetFile] = p.TargetFile
+		}
+		if p.Dependency >= 0 {
+			props[crumbs.PropBlockedBy] = []string{ids[p.Dependency]}
+		}
+		batch[i] = &types.Crumb{CrumbID: ids[i], Name: p.Title, State: types.StatePending, Properties: props}
+	}
+	if _, err := cupboard.SetCrumbs(batch); err != nil {
+		return nil, fmt.Errorf("importing proposals: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,61 @@
+package measure
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// Project documents, relative to the project root.
+const (
+	VisionFile       = "docs/VISION.yaml"
+	ArchitectureFile = "docs/ARCHITECTURE.yaml"
+	RoadmapFile      = "docs/road-map.yaml"
+)
+
+// ProjectState is what measure knows about a project before invoking the
+// agent: its planning documents and the crumbs already in the cupboard.
+type ProjectState struct {
+	Vision       string
+	Architecture string
+	Roadmap      string
+	// Crumbs holds every crumb in the cupboard, so the agent does not
+	// propose work that already exists.
+	Crumbs []*types.Crumb
+}
+
+// ReadProjectState reads the project documents under root and the crumbs in
+// cupboard. A missing document is left empty rather than treated as an
+// error, since early projects may not have written it yet.
+func ReadProjectState(root string, cupboard *crumbs.Cupboard) (ProjectState, error) {
+	var state ProjectState
+	for _, doc := range []struct {
+		path string
+		dst  *string
+	}{
+		{VisionFile, &state.Vision},
+		{ArchitectureFile, &state.Architecture},
+		{RoadmapFile, &state.Roadmap},
+	} {
+		data, err := os.ReadFile(filepath.Join(root, doc.path))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return ProjectState{}, fmt.Errorf("reading %s: %w", doc.path, err)
+		}
+		*doc.dst = string(data)
+	}
+
+	existing, err := cupboard.FetchCrumbs(nil)
+	if err != nil {
+		return ProjectState{}, fmt.Errorf("reading cupboard: %w", err)
+	}
+	state.Crumbs = existing
+	return state, nil
+}
@@ -0,0 +1,72 @@
+package mend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// ErrNothingToFix reports that the last inspect result of a crumb in mend
+// has no failing technique to turn into instructions.
+var ErrNothingToFix = fmt.Errorf("mend: no failing technique to fix")
+
+// Workspace holds the work of a crumb for an AgentFixer.
+type Workspace interface {
+	// Request returns the agent request that mends the crumb's work by
+	// following prompt.
+	Request(crumb *types.Crumb, prompt string) (agent.Request, error)
+	// Input applies the agent's response to the crumb's work, where the work
+	// is taken from the response, and returns the input that re-inspects it.
+	Input(ctx context.Context, crumb *types.Crumb, resp agent.Response) (*inspect.InspectInput, error)
+	// Accept finishes the crumb's work once a mend attempt was accepted,
+	// before the crumb is closed.
+	Accept(ctx context.Context, crumb *types.Crumb) error
+}
+
+// AgentFixer mends a crumb by sending an agent the BuildMendPrompt of the
+// crumb's last inspect result, through the crumb's workspace, then
+// re-inspecting the work with a portfolio.
+type AgentFixer struct {
+	agent     agent.Agent
+	portfolio *inspect.Portfolio
+	workspace Workspace
+}
+
+// NewAgentFixer creates an AgentFixer.
+func NewAgentFixer(a agent.Agent, portfolio *inspect.Portfolio, workspace Workspace) *AgentFixer {
+	return &AgentFixer{agent: a, portfolio: portfolio, workspace: workspace}
+}
+
+// Fix runs one mend attempt on crumb. Returns ErrNothingToFix when last has
+// no failing technique.
+func (f *AgentFixer) Fix(ctx context.Context, crumb *types.Crumb, last inspect.CompositeResult) (inspect.CompositeResult, error) {
+	prompt := BuildMendPrompt(last)
+	if prompt == "" {
+		return inspect.CompositeResult{}, ErrNothingToFix
+	}
+	req, err := f.workspace.Request(crumb, prompt)
+	if err != nil {
+		return inspect.CompositeResult{}, err
+	}
+	resp, err := f.agent.Run(ctx, req)
+	if err != nil {
+		return inspect.CompositeResult{}, fmt.Errorf("running agent: %w", err)
+	}
+	input, err := f.workspace.Input(ctx, crumb, resp)
+	if err != nil {
+		return inspect.CompositeResult{}, err
+	}
+	cr, err := f.portfolio.Run(ctx, input)
+	if err != nil {
+		return inspect.CompositeResult{}, fmt.Errorf("re-inspecting: %w", err)
+	}
+	return cr, nil
+}
+
+// Accept finishes the accepted work of crumb in its workspace.
+func (f *AgentFixer) Accept(ctx context.Context, crumb *types.Crumb) error {
+	return f.workspace.Accept(ctx, crumb)
+}
@@ -0,0 +1,111 @@
+package mend
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// passTechnique passes every input with a perfect score.
+type passTechnique string
+
+func (p passTechnique) Name() string                                  { return string(p) }
+func (passTechnique) FaultClass() string                              { return "test" }
+func (passTechnique) Applicable(*inspect.InspectInput) (bool, string) { return true, "" }
+func (p passTechnique) Run(context.Context, *inspect.InspectInput) (inspect.TechniqueResult, error) {
+	return inspect.TechniqueResult{Technique: string(p), Score: 1, Verdict: inspect.VerdictPass, Deterministic: true}, nil
+}
+
+// dirWorkspace mends every crumb in one directory and records the inputs it
+// builds and the crumbs it accepts.
+type dirWorkspace struct {
+	dir      string
+	inputs   []string
+	accepted []string
+}
+
+func (w *dirWorkspace) Request(_ *types.Crumb, prompt string) (agent.Request, error) {
+	return agent.Request{Prompt: prompt, Dir: w.dir}, nil
+}
+
+func (w *dirWorkspace) Input(_ context.Context, crumb *types.Crumb, _ agent.Response) (*inspect.InspectInput, error) {
+	w.inputs = append(w.inputs, crumb.CrumbID)
+	return &inspect.InspectInput{WorkType: inspect.WorkTypeDocs, Dir: w.dir, ModifiedFiles: []string{"README.md"}}, nil
+}
+
+func (w *dirWorkspace) Accept(_ context.Context, crumb *types.Crumb) error {
+	w.accepted = append(w.accepted, crumb.CrumbID)
+	return nil
+}
+
+func TestAgentFixer_Fix(t *testing.T) {
+	config := inspect.DefaultScorerConfig()
+	config.Weights = map[string]float64{"first": 0.5, "second": 0.5}
+	scorer, err := inspect.NewScorer(config)
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	portfolio := inspect.NewPortfolio(scorer, inspect.DefaultPortfolioConfig())
+	portfolio.Register(passTechnique("first"))
+	portfolio.Register(passTechnique("second"))
+
+	failing := inspect.CompositeResult{Score: 0.6, Results: []inspect.TechniqueResult{{
+		Technique: "criteria",
+		Verdict:   inspect.VerdictFail,
+		Evidence:  []inspect.Evidence{{Detail: "criterion 2 is not met"}},
+	}}}
+	crumb := &types.Crumb{CrumbID: "c1", Name: "Document the parser"}
+
+	tests := []struct {
+		name       string
+		last       inspect.CompositeResult
+		agentErr   error
+		wantErr    error
+		wantRuns   int
+		wantAction inspect.Action
+	}{
+		{"fixes and re-inspects", failing, nil, nil, 1, inspect.ActionAccept},
+		{"nothing to fix", inspect.CompositeResult{Score: 0.6}, nil, ErrNothingToFix, 0, ""},
+		{"agent fails", failing, errors.New("agent down"), nil, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := agent.NewMockAgent()
+			a.Err = tt.agentErr
+			workspace := &dirWorkspace{dir: t.TempDir()}
+			fixer := NewAgentFixer(a, portfolio, workspace)
+
+			cr, err := fixer.Fix(context.Background(), crumb, tt.last)
+			if runs := len(a.Requests()); runs != tt.wantRuns {
+				t.Errorf("agent runs = %d, want %d", runs, tt.wantRuns)
+			}
+			if tt.wantErr != nil || tt.agentErr != nil {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Fix err = %v, want %v", err, tt.wantErr)
+				}
+				if len(workspace.inputs) != 0 {
+					t.Error("re-inspected after a failed fix")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fix failed: %v", err)
+			}
+			req := a.Requests()[0]
+			if req.Dir != workspace.dir {
+				t.Errorf("agent Dir = %q, want %q", req.Dir, workspace.dir)
+			}
+			if !strings.Contains(req.Prompt, "criterion 2 is not met") {
+				t.Errorf("prompt does not carry the failing evidence:\n%s", req.Prompt)
+			}
+			if cr.Action != tt.wantAction {
+				t.Errorf("Action = %s, want %s", cr.Action, tt.wantAction)
+			}
+		})
+	}
+}
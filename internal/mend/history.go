@@ -0,0 +1,88 @@
+package mend
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+// RecordResult records cr in the crumb's inspect history and returns it
+// with the Timestamp and Regressed flag the cupboard set.
+func RecordResult(cupboard *crumbs.Cupboard, id string, cr inspect.CompositeResult) (inspect.CompositeResult, error) {
+	encoded, err := json.Marshal(cr)
+	if err != nil {
+		return cr, fmt.Errorf("encoding inspect result: %w", err)
+	}
+	rec, err := cupboard.RecordInspectResult(id, crumbs.InspectRecord{
+		Timestamp: cr.Timestamp,
+		Score:     cr.Score,
+		Action:    string(cr.Action),
+		Result:    encoded,
+	})
+	cr.Timestamp, cr.Regressed = rec.Timestamp, rec.Regressed
+	return cr, err
+}
+
+// LatestResult returns the crumb's most recent inspect result. The boolean
+// is false when the crumb has never been inspected.
+func LatestResult(cupboard *crumbs.Cupboard, id string) (inspect.CompositeResult, bool, error) {
+	rec, ok, err := cupboard.LatestInspectResult(id)
+	if err != nil || !ok {
+		return inspect.CompositeResult{}, ok, err
+	}
+	cr, err := compositeResult(rec)
+	if err != nil {
+		return inspect.CompositeResult{}, false, err
+	}
+	return cr, true, nil
+}
+
+// LatestResults returns the latest inspect result of every crumb that has
+// been inspected, in crumb fetch order.
+func LatestResults(cupboard *crumbs.Cupboard) ([]inspect.CompositeResult, error) {
+	records, err := cupboard.LatestInspectResults()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]inspect.CompositeResult, len(records))
+	for i, rec := range records {
+		if results[i], err = compositeResult(rec); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// History returns the crumb's recorded inspect results, oldest first.
+func History(cupboard *crumbs.Cupboard, id string) ([]inspect.CompositeResult, error) {
+	records, err := cupboard.CrumbInspectHistory(id)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]inspect.CompositeResult, len(records))
+	for i, rec := range records {
+		if results[i], err = compositeResult(rec); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// compositeResult decodes the result a record holds. The record's own
+// fields win over those encoded in the result, since the cupboard sets the
+// timestamp and regression flag when recording.
+func compositeResult(rec crumbs.InspectRecord) (inspect.CompositeResult, error) {
+	var cr inspect.CompositeResult
+	if len(rec.Result) > 0 {
+		if err := json.Unmarshal(rec.Result, &cr); err != nil {
+			return inspect.CompositeResult{}, fmt.Errorf("decoding inspect result: %w", err)
+		}
+	}
+	cr.Timestamp = rec.Timestamp
+	cr.Score = rec.Score
+	cr.Action = inspect.Action(rec.Action)
+	cr.Regressed = rec.Regressed
+	return cr, nil
+}
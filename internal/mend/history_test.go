@@ -0,0 +1,56 @@
+package mend
+
+import (
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestRecordResult_RoundTrip(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Mended crumb", State: types.StateTaken})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	attempts := []inspect.CompositeResult{
+		{Score: 0.42, Action: inspect.ActionHumanReview},
+		{Score: 0.65, Action: inspect.ActionMend},
+		{Score: 0.85, Action: inspect.ActionAccept, Valid: true, Results: []inspect.TechniqueResult{
+			{Technique: inspect.MutationRunnerName, Score: 0.85, Verdict: inspect.VerdictFail, Survivors: []inspect.SurvivingMutant{
+				{File: "util.go", Line: 42, Type: inspect.MutationConditional, Original: "<", Mutated: "<="},
+			}},
+		}},
+	}
+	for _, cr := range attempts {
+		if _, err := RecordResult(cupboard, id, cr); err != nil {
+			t.Fatalf("RecordResult failed: %v", err)
+		}
+	}
+
+	history, err := History(cupboard, id)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	want := "0.42 -> 0.65 -> 0.85 (+0.43 over 3 attempts)"
+	if got := inspect.FormatProgression(history); got != want {
+		t.Errorf("FormatProgression = %q, want %q", got, want)
+	}
+
+	latest, ok, err := LatestResult(cupboard, id)
+	if err != nil || !ok {
+		t.Fatalf("LatestResult = %v, %v; want the recorded result", ok, err)
+	}
+	if !latest.Valid || latest.Action != inspect.ActionAccept || len(latest.Results) != 1 || len(latest.Results[0].Survivors) != 1 {
+		t.Errorf("LatestResult = %+v, want the full accepted result with its survivor", latest)
+	}
+	if latest.Timestamp.IsZero() {
+		t.Error("LatestResult has no timestamp, want the one the cupboard set")
+	}
+}
@@ -0,0 +1,169 @@
+// Package mend re-runs fixes for crumbs that inspect sent to mend.
+// Implements: prd008-inspect-verification R7.3 (mend action);
+//
+//	docs/ARCHITECTURE § Mend.
+//
+// A Fixer performs one mend attempt (an agent fix followed by re-inspection);
+// AgentFixer is the Fixer that runs a configured agent in a Workspace.
+// MendAll drives every crumb whose latest inspect action is mend through up
+// to MaxAttempts attempts and summarizes the outcome. BuildMendPrompt turns
+// the findings of an inspect result into instructions for the fixing agent.
+package mend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/logging"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// DefaultMaxAttempts is the number of mend attempts per crumb.
+const DefaultMaxAttempts = 3
+
+// Fixer performs one mend attempt on a crumb and returns the result of
+// re-inspecting the fixed output.
+type Fixer interface {
+	Fix(ctx context.Context, crumb *types.Crumb, last inspect.CompositeResult) (inspect.CompositeResult, error)
+}
+
+// accepter is implemented by a Fixer whose accepted work needs finishing,
+// such as merging a branch, before the crumb is closed.
+type accepter interface {
+	Accept(ctx context.Context, crumb *types.Crumb) error
+}
+
+// Config controls a mend run.
+type Config struct {
+	// MaxAttempts bounds the mend attempts per crumb.
+	MaxAttempts int
+	// Logger receives attempt and action events; nil discards them.
+	Logger *slog.Logger
+}
+
+// Summary counts the outcome of a mend run.
+type Summary struct {
+	// Accepted crumbs reached an accepting action.
+	Accepted int
+	// StillMend crumbs remained in mend after MaxAttempts or after an
+	// attempt lowered the score.
+	StillMend int
+	// Escalated crumbs were routed to human review.
+	Escalated int
+	// Failed crumbs could not be mended because the fixer returned an error.
+	Failed int
+	// Errors holds one message per failed crumb.
+	Errors []string
+}
+
+// String renders the summary on one line.
+func (s Summary) String() string {
+	return fmt.Sprintf("accepted %d, still mend %d, escalated %d, failed %d",
+		s.Accepted, s.StillMend, s.Escalated, s.Failed)
+}
+
+// MendAll processes every crumb whose latest inspect action is mend, one at a
+// time. Each attempt's result is recorded in the crumb's inspect history.
+// A crumb is claimed before its first attempt, so stitch and other mend
+// runs leave it alone, and a crumb that cannot be claimed fails. When an
+// attempt is accepted the crumb is closed, once a Fixer that finishes its
+// work has done so; otherwise, or on an error, it is released back to ready
+// with a note.
+func MendAll(ctx context.Context, cupboard *crumbs.Cupboard, fixer Fixer, config Config) (Summary, error) {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	logger := logging.OrDiscard(config.Logger)
+	pending, err := cupboard.CrumbsByAction(string(inspect.ActionMend))
+	if err != nil {
+		return Summary{}, fmt.Errorf("finding mend crumbs: %w", err)
+	}
+
+	var summary Summary
+	for _, crumb := range pending {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		action, err := mendClaimed(ctx, cupboard, fixer, crumb, config.MaxAttempts, logger)
+		if err != nil {
+			logger.Error("mend failed", logging.KeyCrumb, crumb.CrumbID, "error", err)
+			summary.Failed++
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", crumb.CrumbID, err))
+			continue
+		}
+		logger.Info("action taken", logging.KeyCrumb, crumb.CrumbID, logging.KeyAction, action)
+		switch {
+		case action.Accepting():
+			summary.Accepted++
+		case action == inspect.ActionHumanReview:
+			summary.Escalated++
+		default:
+			summary.StillMend++
+		}
+	}
+	return summary, nil
+}
+
+// mendClaimed claims crumb, mends it, and closes or releases it according
+// to the final action, which it returns.
+func mendClaimed(ctx context.Context, cupboard *crumbs.Cupboard, fixer Fixer, crumb *types.Crumb, maxAttempts int, logger *slog.Logger) (inspect.Action, error) {
+	claimed, err := cupboard.ClaimCrumbByID(crumb.CrumbID)
+	if err != nil {
+		return "", fmt.Errorf("claiming: %w", err)
+	}
+	cr, err := mendCrumb(ctx, cupboard, fixer, claimed, maxAttempts, logger)
+	if err == nil && cr.Action.Accepting() {
+		if a, ok := fixer.(accepter); ok {
+			if err = a.Accept(ctx, claimed); err != nil {
+				err = fmt.Errorf("finishing accepted work: %w", err)
+			}
+		}
+		if err == nil {
+			return cr.Action, cupboard.SetCrumbState(claimed.CrumbID, types.StateDone)
+		}
+	}
+	note := fmt.Sprintf("mend: %s (score %.2f)", cr.Action, cr.Score)
+	if err != nil {
+		note = err.Error()
+	}
+	if releaseErr := cupboard.ReleaseCrumb(claimed.CrumbID, note); releaseErr != nil {
+		return cr.Action, errors.Join(err, fmt.Errorf("releasing: %w", releaseErr))
+	}
+	return cr.Action, err
+}
+
+// mendCrumb runs up to maxAttempts fixes on crumb and returns the final
+// result. It stops early, still in mend, when an attempt lowers the score.
+func mendCrumb(ctx context.Context, cupboard *crumbs.Cupboard, fixer Fixer, crumb *types.Crumb, maxAttempts int, logger *slog.Logger) (inspect.CompositeResult, error) {
+	last, _, err := LatestResult(cupboard, crumb.CrumbID)
+	if err != nil {
+		return last, err
+	}
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		cr, err := fixer.Fix(ctx, crumb, last)
+		if err != nil {
+			return last, fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+		logger.Info("mend attempt", logging.KeyCrumb, crumb.CrumbID, logging.KeyAttempt, attempt,
+			logging.KeyAction, cr.Action, logging.KeyScore, cr.Score)
+		cr, err = RecordResult(cupboard, crumb.CrumbID, cr)
+		if err != nil {
+			return last, err
+		}
+		if cr.Action != inspect.ActionMend {
+			return cr, nil
+		}
+		if cr.Regressed {
+			// The fix made things worse; further attempts build on it.
+			logger.Warn("mend regressed", logging.KeyCrumb, crumb.CrumbID, logging.KeyAttempt, attempt,
+				logging.KeyScore, cr.Score, "previous_score", last.Score)
+			return cr, nil
+		}
+		last = cr
+	}
+	return last, nil
+}
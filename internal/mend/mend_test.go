@@ -0,0 +1,176 @@
+package mend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// fakeFixer returns scripted actions per crumb name, one per attempt, and
+// records the crumbs it accepts.
+type fakeFixer struct {
+	script   map[string][]inspect.Action
+	calls    map[string]int
+	accepted []string
+}
+
+func (f *fakeFixer) Accept(_ context.Context, crumb *types.Crumb) error {
+	if crumb.State != types.StateTaken {
+		return errors.New("accepting an unclaimed crumb")
+	}
+	f.accepted = append(f.accepted, crumb.Name)
+	return nil
+}
+
+func (f *fakeFixer) Fix(_ context.Context, crumb *types.Crumb, _ inspect.CompositeResult) (inspect.CompositeResult, error) {
+	actions, ok := f.script[crumb.Name]
+	if !ok {
+		return inspect.CompositeResult{}, errors.New("agent unavailable")
+	}
+	n := f.calls[crumb.Name]
+	f.calls[crumb.Name]++
+	if n >= len(actions) {
+		n = len(actions) - 1
+	}
+	return inspect.CompositeResult{Action: actions[n]}, nil
+}
+
+func TestMendAll_Summary(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	seed := map[string]inspect.Action{
+		"fixed first try":    inspect.ActionMend,
+		"fixed second try":   inspect.ActionMend,
+		"fixed with warning": inspect.ActionMend,
+		"never fixed":        inspect.ActionMend,
+		"escalates":          inspect.ActionMend,
+		"agent down":         inspect.ActionMend,
+		"claimed elsewhere":  inspect.ActionMend,
+		"already accepted":   inspect.ActionAccept,
+	}
+	ids := map[string]string{}
+	for name, action := range seed {
+		state := types.StateReady
+		if name == "claimed elsewhere" {
+			state = types.StateTaken
+		}
+		id, err := cupboard.SetCrumb("", &types.Crumb{Name: name, State: state})
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		if _, err := RecordResult(cupboard, id, inspect.CompositeResult{Action: action}); err != nil {
+			t.Fatalf("RecordResult failed: %v", err)
+		}
+		ids[name] = id
+	}
+
+	fixer := &fakeFixer{
+		script: map[string][]inspect.Action{
+			"fixed first try":    {inspect.ActionAccept},
+			"fixed second try":   {inspect.ActionMend, inspect.ActionAccept},
+			"fixed with warning": {"accept_with_warning"},
+			"never fixed":        {inspect.ActionMend},
+			"escalates":          {inspect.ActionHumanReview},
+			"claimed elsewhere":  {inspect.ActionAccept},
+			"already accepted":   {inspect.ActionMend},
+		},
+		calls: map[string]int{},
+	}
+
+	summary, err := MendAll(context.Background(), cupboard, fixer, Config{MaxAttempts: 2})
+	if err != nil {
+		t.Fatalf("MendAll failed: %v", err)
+	}
+	want := Summary{Accepted: 3, StillMend: 1, Escalated: 1, Failed: 2}
+	if summary.Accepted != want.Accepted || summary.StillMend != want.StillMend ||
+		summary.Escalated != want.Escalated || summary.Failed != want.Failed {
+		t.Errorf("summary = %s, want %s", summary, want)
+	}
+	if fixer.calls["never fixed"] != 2 {
+		t.Errorf("never fixed attempted %d times, want MaxAttempts 2", fixer.calls["never fixed"])
+	}
+	if fixer.calls["already accepted"] != 0 {
+		t.Error("accepted crumb should not be mended")
+	}
+	if fixer.calls["claimed elsewhere"] != 0 {
+		t.Error("crumb claimed elsewhere should not be mended")
+	}
+	if len(fixer.accepted) != 3 {
+		t.Errorf("accepted work of %v, want the 3 accepted crumbs", fixer.accepted)
+	}
+
+	// Accepted crumbs are closed; the rest are released back to ready.
+	wantState := map[string]types.State{
+		"fixed first try":    types.StateDone,
+		"fixed second try":   types.StateDone,
+		"fixed with warning": types.StateDone,
+		"never fixed":        types.StateReady,
+		"escalates":          types.StateReady,
+		"agent down":         types.StateReady,
+		"claimed elsewhere":  types.StateTaken,
+	}
+	for name, state := range wantState {
+		crumb, err := cupboard.GetCrumb(ids[name])
+		if err != nil {
+			t.Fatalf("GetCrumb failed: %v", err)
+		}
+		if crumb.State != state {
+			t.Errorf("%s state = %s, want %s", name, crumb.State, state)
+		}
+	}
+}
+
+// scoreFixer returns scripted mend results with the given scores, one per attempt.
+type scoreFixer struct {
+	scores []float64
+	calls  int
+}
+
+func (f *scoreFixer) Fix(context.Context, *types.Crumb, inspect.CompositeResult) (inspect.CompositeResult, error) {
+	score := f.scores[min(f.calls, len(f.scores)-1)]
+	f.calls++
+	return inspect.CompositeResult{Score: score, Action: inspect.ActionMend}, nil
+}
+
+func TestMendAll_StopsOnRegression(t *testing.T) {
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	defer cupboard.Close()
+
+	id, err := cupboard.SetCrumb("", &types.Crumb{Name: "worsens", State: types.StateReady})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	if _, err := RecordResult(cupboard, id, inspect.CompositeResult{Score: 0.6, Action: inspect.ActionMend}); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+
+	fixer := &scoreFixer{scores: []float64{0.65, 0.55, 0.7}}
+	summary, err := MendAll(context.Background(), cupboard, fixer, Config{MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("MendAll failed: %v", err)
+	}
+	if fixer.calls != 2 {
+		t.Errorf("fixer called %d times, want 2: the second attempt lowered the score", fixer.calls)
+	}
+	if summary.StillMend != 1 {
+		t.Errorf("summary = %s, want still mend 1", summary)
+	}
+	latest, _, err := LatestResult(cupboard, id)
+	if err != nil {
+		t.Fatalf("LatestResult failed: %v", err)
+	}
+	if !latest.Regressed || latest.Score != 0.55 {
+		t.Errorf("latest result = %+v, want the regressed 0.55 attempt", latest)
+	}
+}
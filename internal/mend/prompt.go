@@ -0,0 +1,84 @@
+package mend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+// BuildMendPrompt turns the failures in cr into focused instructions for
+// the mend agent: a test to add for each surviving mutant, then the
+// evidence of every other failing technique, such as unmet criteria.
+// Returns "" when no technique failed.
+func BuildMendPrompt(cr inspect.CompositeResult) string {
+	var mutants, checks []string
+	for _, r := range cr.Results {
+		if r.Verdict != inspect.VerdictFail {
+			continue
+		}
+		if r.Technique == inspect.MutationRunnerName {
+			for _, m := range r.Survivors {
+				mutants = append(mutants, mutantInstruction(m))
+			}
+			continue
+		}
+		for _, ev := range r.Evidence {
+			checks = append(checks, fmt.Sprintf("%s: %s", r.Technique, evidenceText(ev)))
+		}
+	}
+	if len(mutants) == 0 && len(checks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Inspect sent this work to mend with score %.2f. Fix only the problems below.\n", cr.Score)
+	if len(mutants) > 0 {
+		b.WriteString("\n## Surviving mutants\nThe tests did not notice these changes to the code.\n")
+		for _, line := range mutants {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	if len(checks) > 0 {
+		b.WriteString("\n## Failed checks\n")
+		for _, line := range checks {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// mutantInstruction asks for a test that kills m.
+func mutantInstruction(m inspect.SurvivingMutant) string {
+	at := location(m.File, m.Line)
+	if m.Type == inspect.MutationStatementDelete {
+		return fmt.Sprintf("Add a test that fails when `%s` at %s is deleted.", m.Original, at)
+	}
+	if other, ok := strings.CutPrefix(m.Mutated, "body swapped with "); ok {
+		return fmt.Sprintf("Add a test that fails when the bodies of `%s` and `%s` at %s are swapped.", m.Original, other, at)
+	}
+	return fmt.Sprintf("Add a test that distinguishes `%s` from `%s` at %s.", m.Original, m.Mutated, at)
+}
+
+// evidenceText renders ev with its criterion and location when known.
+func evidenceText(ev inspect.Evidence) string {
+	var prefix []string
+	if ev.CriterionID != "" {
+		prefix = append(prefix, ev.CriterionID)
+	}
+	if ev.File != "" {
+		prefix = append(prefix, location(ev.File, ev.Line))
+	}
+	if len(prefix) == 0 {
+		return ev.Detail
+	}
+	return strings.Join(prefix, " ") + ": " + ev.Detail
+}
+
+// location renders file:line, or file alone when the line is unknown.
+func location(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return file
+}
@@ -0,0 +1,63 @@
+package mend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+func TestBuildMendPrompt(t *testing.T) {
+	cr := inspect.CompositeResult{
+		Score:  0.62,
+		Action: inspect.ActionMend,
+		Results: []inspect.TechniqueResult{
+			{
+				Technique: inspect.MutationRunnerName,
+				Verdict:   inspect.VerdictFail,
+				Evidence: []inspect.Evidence{
+					{File: "util.go", Line: 70, Detail: `arithmetic mutant killed by TestSum: "+" -> "-"`},
+					{File: "util.go", Line: 75, Detail: `arithmetic mutant judged equivalent, excluded from score: "*" -> "/"`},
+				},
+				Survivors: []inspect.SurvivingMutant{
+					{File: "util.go", Line: 42, Type: inspect.MutationConditional, Original: "<", Mutated: "<="},
+					{File: "util.go", Line: 50, Type: inspect.MutationStatementDelete, Original: "count++"},
+					{File: "util.go", Line: 61, Type: inspect.MutationSwitchCase, Original: "case n > 9", Mutated: "body swapped with default"},
+				},
+			},
+			{
+				Technique: inspect.TranslationValidatorName,
+				Verdict:   inspect.VerdictFail,
+				Evidence:  []inspect.Evidence{{CriterionID: "R2.1", Detail: "Parse does not reject empty input"}},
+			},
+			{
+				Technique: inspect.CoverageRunnerName,
+				Verdict:   inspect.VerdictPass,
+				Evidence:  []inspect.Evidence{{Detail: "85% of statements covered"}},
+			},
+		},
+	}
+
+	got := BuildMendPrompt(cr)
+	for _, want := range []string{
+		"score 0.62",
+		"- Add a test that distinguishes `<` from `<=` at util.go:42.\n",
+		"- Add a test that fails when `count++` at util.go:50 is deleted.\n",
+		"- Add a test that fails when the bodies of `case n > 9` and `default` at util.go:61 are swapped.\n",
+		"- translation_validation: R2.1: Parse does not reject empty input\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"util.go:70", "util.go:75", "statements covered"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("prompt contains %q from killed, equivalent, or passing results:\n%s", unwanted, got)
+		}
+	}
+
+	cr.Results = cr.Results[2:]
+	if got := BuildMendPrompt(cr); got != "" {
+		t.Errorf("BuildMendPrompt with no failures = %q, want empty", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package prompt renders the prompts cobbler sends to agents from named
+// text/template templates.
+// Implements: prd002-stitch R4 (prompt builder), R10 (template system);
+// prd003-measure R10 (planning prompt template).
+//
+// Default templates are embedded from templates/. A project overrides one by
+// placing <name>.tmpl under the prompts directory of its data dir; Load
+// prefers those files over the embedded defaults.
+package prompt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// Template names.
+const (
+	Measure    = "measure"
+	StitchDocs = "stitch-docs"
+	StitchCode = "stitch-code"
+)
+
+// Names lists every template, in a stable order.
+var Names = []string{Measure, StitchDocs, StitchCode}
+
+// OverrideDir is the directory under the data dir holding template overrides.
+const OverrideDir = "prompts"
+
+// templateExt is the file extension of template files.
+const templateExt = ".tmpl"
+
+// ErrUnknownTemplate reports a template name that is not in Names.
+var ErrUnknownTemplate = fmt.Errorf("prompt: unknown template")
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+// Data is what templates are rendered against. Each template uses the
+// fields relevant to it; the rest are left zero.
+type Data struct {
+	// Project documents.
+	Vision       string
+	Architecture string
+	Roadmap      string
+
+	// Crumbs lists the crumbs already in the cupboard (measure).
+	Crumbs []*types.Crumb
+	// Limit is the maximum number of proposals (measure).
+	Limit int
+	// WorkTypes lists the valid proposal work types (measure).
+	WorkTypes string
+	// MinPriority, DefaultPriority, and MaxPriority bound the proposal
+	// priorities (measure).
+	MinPriority, DefaultPriority, MaxPriority int
+
+	// Crumb is the task being stitched; TaskID, TaskTitle, and
+	// TaskDescription are taken from it.
+	Crumb           *types.Crumb
+	TaskID          string
+	TaskTitle       string
+	TaskDescription string
+	// TargetFile and Existing are the docs target file and its current
+	// contents (stitch docs).
+	TargetFile string
+	Existing   string
+	// Context is the assembled repository context for the task.
+	Context string
+	// OmittedContext lists the repository files left out of Context to fit
+	// its budget, least relevant last.
+	OmittedContext []string
+	// Findings holds the failing technique results from the crumb's latest
+	// inspect, so a retry can address them.
+	Findings []inspect.TechniqueResult
+}
+
+// TaskData returns Data for stitching crumb.
+func TaskData(crumb *types.Crumb) Data {
+	description, _ := crumb.Properties[crumbs.PropDescription].(string)
+	return Data{
+		Crumb:           crumb,
+		TaskID:          crumb.CrumbID,
+		TaskTitle:       crumb.Name,
+		TaskDescription: description,
+	}
+}
+
+// Templates holds one parsed template per name.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// Default returns the embedded templates.
+func Default() *Templates {
+	t, err := Load("")
+	if err != nil {
+		// The embedded templates are parsed by the package tests.
+		panic(err)
+	}
+	return t
+}
+
+// Load parses the templates, preferring <dataDir>/prompts/<name>.tmpl over
+// the embedded default for each name. An empty dataDir loads the defaults.
+func Load(dataDir string) (*Templates, error) {
+	t := &Templates{templates: make(map[string]*template.Template, len(Names))}
+	for _, name := range Names {
+		text, err := fs.ReadFile(defaults, "templates/"+name+templateExt)
+		if err != nil {
+			return nil, fmt.Errorf("reading default %s template: %w", name, err)
+		}
+		source := "default"
+		if dataDir != "" {
+			path := filepath.Join(dataDir, OverrideDir, name+templateExt)
+			override, err := os.ReadFile(path)
+			switch {
+			case err == nil:
+				text, source = override, path
+			case !errors.Is(err, fs.ErrNotExist):
+				return nil, fmt.Errorf("reading %s: %w", path, err)
+			}
+		}
+		parsed, err := template.New(name).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s template (%s): %w", name, source, err)
+		}
+		t.templates[name] = parsed
+	}
+	return t, nil
+}
+
+// Render executes the named template with data.
+func (t *Templates) Render(name string, data Data) (string, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (known: %v)", ErrUnknownTemplate, name, Names)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering %s prompt: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Findings returns the failing technique results of cr, for Data.Findings.
+func Findings(cr inspect.CompositeResult) []inspect.TechniqueResult {
+	var out []inspect.TechniqueResult
+	for _, r := range cr.Results {
+		if r.Verdict == inspect.VerdictFail {
+			out = append(out, r)
+		}
+	}
+	return out
+}
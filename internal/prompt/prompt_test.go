@@ -0,0 +1,108 @@
+package prompt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func sampleCrumb() *types.Crumb {
+	return &types.Crumb{
+		CrumbID:    "crumb-42",
+		Name:       "Document the parser",
+		State:      types.StateTaken,
+		Properties: map[string]any{crumbs.PropDescription: "Explain how parse errors are reported."},
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := TaskData(sampleCrumb())
+	data.TargetFile = "docs/parser.md"
+	data.Findings = Findings(inspect.CompositeResult{Results: []inspect.TechniqueResult{
+		{Technique: inspect.TranslationValidatorName, Verdict: inspect.VerdictFail, Evidence: []inspect.Evidence{
+			{File: "docs/parser.md", Line: 3, Detail: "criterion AC1 not covered"},
+		}},
+		{Technique: inspect.MutationRunnerName, Verdict: inspect.VerdictPass, Evidence: []inspect.Evidence{{Detail: "all mutants killed"}}},
+	}})
+
+	tests := []struct {
+		name     string
+		template string
+		data     Data
+		want     []string
+		notWant  []string
+	}{
+		{"stitch docs", StitchDocs, data, []string{
+			"## Task crumb-42: Document the parser",
+			"Explain how parse errors are reported.",
+			"docs/parser.md does not exist yet",
+			"- translation_validation: docs/parser.md:3: criterion AC1 not covered",
+		}, []string{"all mutants killed"}},
+		{"stitch code without findings", StitchCode, TaskData(sampleCrumb()), []string{"## Task crumb-42: Document the parser"}, []string{"Findings from the previous attempt"}},
+		{"measure", Measure, Data{Vision: "parse everything", Crumbs: []*types.Crumb{sampleCrumb()}, Limit: 3, WorkTypes: "code"}, []string{
+			"propose at most 3", "parse everything", "- crumb-42 [taken] Document the parser", "one of code", `"target_file": for docs tasks`,
+		}, nil},
+	}
+	templates := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := templates.Render(tt.template, tt.data)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("prompt does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("prompt contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+
+	if _, err := templates.Render("pattern", Data{}); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("Render(pattern) error = %v, want ErrUnknownTemplate", err)
+	}
+}
+
+func TestLoad_Override(t *testing.T) {
+	dataDir := t.TempDir()
+	dir := filepath.Join(dataDir, OverrideDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, StitchCode+templateExt), []byte("Do {{.TaskTitle}}."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := Load(dataDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got, err := templates.Render(StitchCode, TaskData(sampleCrumb()))
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got != "Do Document the parser." {
+		t.Errorf("overridden prompt = %q", got)
+	}
+	if got, _ := templates.Render(StitchDocs, TaskData(sampleCrumb())); !strings.Contains(got, "project documentation") {
+		t.Error("templates without an override should keep the embedded default")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, Measure+templateExt), []byte("{{.Limit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dataDir); err == nil || !strings.Contains(err.Error(), Measure) {
+		t.Errorf("Load with a broken override error = %v, want a parse error naming the template", err)
+	}
+}
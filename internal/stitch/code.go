@@ -0,0 +1,196 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	cobble "github.com/petar-djukic/cobbler/internal/context"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/mend"
+	"github.com/petar-djukic/cobbler/internal/prompt"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// ErrNoChanges reports that the agent left the worktree unchanged.
+var ErrNoChanges = fmt.Errorf("stitch: agent made no changes")
+
+// ErrMergeConflict reports that an accepted branch conflicts with the base
+// branch and was not merged.
+var ErrMergeConflict = fmt.Errorf("stitch: merge conflict")
+
+// branchPrefix prefixes the branch each code task works on.
+const branchPrefix = "stitch/"
+
+// StitchCode claims a code crumb and implements it in an isolated git
+// worktree on branch stitch/<crumb ID>, created from config.BaseBranch. The
+// agent works in the worktree; stitch then builds, tests, commits, and runs
+// the inspect portfolio against the branch. On an accepting action the
+// branch is merged into the base branch, which must be checked out in
+// config.Dir, and the worktree is removed. Otherwise the worktree is kept for inspection and
+// the crumb is released back to ready with a note naming it; the next
+// attempt, by stitch or mend, continues in it on the same branch. As with
+// StitchDocs, the agent's token usage is added to the crumb's totals.
+func StitchCode(ctx context.Context, cupboard *crumbs.Cupboard, a agent.Agent, portfolio *inspect.Portfolio, config Config) (Result, error) {
+	return stitchClaimed(cupboard, a, config, inspect.WorkTypeCode, func(a agent.Agent, crumb *types.Crumb) (Result, error) {
+		return stitchCode(ctx, cupboard, a, portfolio, config, crumb)
+	})
+}
+
+// stitchCode runs the worktree lifecycle for a claimed crumb.
+func stitchCode(ctx context.Context, cupboard *crumbs.Cupboard, a agent.Agent, portfolio *inspect.Portfolio, config Config, crumb *types.Crumb) (Result, error) {
+	result := Result{CrumbID: crumb.CrumbID}
+	branch := branchPrefix + crumb.CrumbID
+	root, err := worktreeRoot(config)
+	if err != nil {
+		return result, err
+	}
+	worktree := filepath.Join(root, crumb.CrumbID)
+	result.Worktree = worktree
+	reused, err := addWorktree(ctx, config.Dir, worktree, branch, config.BaseBranch)
+	if err != nil {
+		return result, err
+	}
+
+	data, err := taskData(ctx, cupboard, crumb, worktree, cobble.Config{Budget: config.ContextBudget})
+	if err != nil {
+		return result, err
+	}
+	text, err := templates(config).Render(prompt.StitchCode, data)
+	if err != nil {
+		return result, err
+	}
+	if _, err := runAgent(ctx, a, agent.Request{Prompt: text, Dir: worktree}, config.AgentOutput); err != nil {
+		return result, fmt.Errorf("running agent: %w", err)
+	}
+
+	if _, err := run(ctx, worktree, "go", "build", "./..."); err != nil {
+		return result, err
+	}
+	if _, err := run(ctx, worktree, "go", "test", "./..."); err != nil {
+		return result, err
+	}
+	err = commitAll(ctx, worktree, fmt.Sprintf("[%s] %s", crumb.CrumbID, crumb.Name))
+	// A retry that changes nothing re-inspects the earlier attempt's commits.
+	if err != nil && !(reused && errors.Is(err, ErrNoChanges)) {
+		return result, err
+	}
+
+	input, err := inspect.NewInspectInputFromGit(ctx, worktree, config.BaseBranch, branch)
+	if err != nil {
+		return result, err
+	}
+	cr, err := portfolio.Run(ctx, input)
+	if err != nil {
+		return result, fmt.Errorf("inspecting %s: %w", branch, err)
+	}
+	cr, err = mend.RecordResult(cupboard, crumb.CrumbID, cr)
+	result.Composite = cr
+	if err != nil {
+		return result, err
+	}
+
+	if !cr.Action.Accepting() {
+		note := fmt.Sprintf("%s; worktree kept at %s", inspectNote(cr), worktree)
+		return result, cupboard.ReleaseCrumb(crumb.CrumbID, note)
+	}
+	if err := merge(ctx, config.Dir, config.BaseBranch, branch); err != nil {
+		return result, err
+	}
+	if err := cupboard.SetCrumbState(crumb.CrumbID, types.StateDone); err != nil {
+		return result, err
+	}
+	result.Done = true
+	if removeWorktree(ctx, config.Dir, worktree, branch) {
+		result.Worktree = ""
+	}
+	return result, nil
+}
+
+// removeWorktree removes the worktree and branch of merged work and reports
+// whether the worktree is gone. The work is merged, so a failed cleanup
+// leaves a stale worktree but does not undo the task.
+func removeWorktree(ctx context.Context, dir, worktree, branch string) bool {
+	if _, err := git(ctx, dir, "worktree", "remove", worktree); err != nil {
+		return false
+	}
+	_, _ = git(ctx, dir, "branch", "-d", branch)
+	return true
+}
+
+// addWorktree checks out branch in worktree. A worktree an earlier attempt
+// kept there is reused as is, and a branch it left is checked out again;
+// otherwise branch is created from base. Reports whether earlier work was
+// reused.
+func addWorktree(ctx context.Context, dir, worktree, branch, base string) (bool, error) {
+	// Forget a kept worktree whose directory was deleted by hand, so it can
+	// be added again.
+	if _, err := git(ctx, dir, "worktree", "prune"); err != nil {
+		return false, err
+	}
+	paths, err := listWorktrees(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	resolved := worktree
+	if r, err := filepath.EvalSymlinks(worktree); err == nil {
+		resolved = r
+	}
+	if slices.Contains(paths, resolved) {
+		return true, nil
+	}
+	if _, err := git(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		_, err := git(ctx, dir, "worktree", "add", worktree, branch)
+		return true, err
+	}
+	_, err = git(ctx, dir, "worktree", "add", "-b", branch, worktree, base)
+	return false, err
+}
+
+// commitAll stages and commits every change in dir. Returns ErrNoChanges
+// when there is nothing to commit.
+func commitAll(ctx context.Context, dir, message string) error {
+	if _, err := git(ctx, dir, "add", "-A"); err != nil {
+		return err
+	}
+	status, err := git(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		return ErrNoChanges
+	}
+	_, err = git(ctx, dir, "commit", "-m", message)
+	return err
+}
+
+// merge merges branch into base, which must be checked out in dir. On a
+// conflict the merge is aborted, leaving dir as it was, and
+// ErrMergeConflict names the conflicting files.
+func merge(ctx context.Context, dir, base, branch string) error {
+	current, err := currentBranch(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if current != base {
+		return fmt.Errorf("stitch: cannot merge %s: %s is on %s, not %s", branch, dir, current, base)
+	}
+	_, err = git(ctx, dir, "merge", "--no-ff", "-m", "Merge "+branch, branch)
+	if err == nil {
+		return nil
+	}
+	conflicts, diffErr := git(ctx, dir, "diff", "--name-only", "--diff-filter=U")
+	if diffErr != nil || strings.TrimSpace(conflicts) == "" {
+		// Not a conflict: the merge did not start, or failed for
+		// another reason.
+		return err
+	}
+	conflictErr := fmt.Errorf("%w: merging %s into %s: %s", ErrMergeConflict, branch, base, strings.Join(strings.Fields(conflicts), ", "))
+	_, abortErr := git(ctx, dir, "merge", "--abort")
+	return errors.Join(conflictErr, abortErr)
+}
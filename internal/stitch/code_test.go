@@ -0,0 +1,263 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// newRepo creates a git repository on main holding a small Go module.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/calc\n\ngo 1.21\n",
+		"calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "stitch@example.com"},
+		{"config", "user.name", "Stitch Test"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		if _, err := git(context.Background(), dir, args...); err != nil {
+			t.Fatalf("setting up repo: %v", err)
+		}
+	}
+	return dir
+}
+
+// addSub writes a Sub function and its test into the request directory.
+func addSub(req agent.Request) error {
+	dir := req.Dir
+	if err := os.WriteFile(filepath.Join(dir, "sub.go"), []byte("package calc\n\nfunc Sub(a, b int) int { return a - b }\n"), 0o644); err != nil {
+		return err
+	}
+	test := "package calc\n\nimport \"testing\"\n\nfunc TestSub(t *testing.T) {\n\tif Sub(3, 1) != 2 {\n\t\tt.Fatal(\"Sub(3, 1) != 2\")\n\t}\n}\n"
+	return os.WriteFile(filepath.Join(dir, "sub_test.go"), []byte(test), 0o644)
+}
+
+func codeCrumb(t *testing.T, cupboard *crumbs.Cupboard) string {
+	t.Helper()
+	id, err := cupboard.SetCrumb("", &types.Crumb{
+		Name:       "Add Sub",
+		State:      types.StateReady,
+		Properties: map[string]any{crumbs.PropWorkType: inspect.WorkTypeCode, crumbs.PropDescription: "Add a Sub function to package calc."},
+	})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	return id
+}
+
+func codeConfig(repo string) Config {
+	config := DefaultConfig()
+	config.Dir = repo
+	return config
+}
+
+func TestStitchCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		score     float64
+		wantState types.State
+	}{
+		{"accept merges", 1, types.StateDone},
+		{"mend keeps the worktree", 0.6, types.StateReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			cupboard := newCupboard(t)
+			id := codeCrumb(t, cupboard)
+			a := &agent.MockAgent{OnRun: addSub}
+
+			result, err := StitchCode(context.Background(), cupboard, a, newPortfolio(t, tt.score), codeConfig(repo))
+			if err != nil {
+				t.Fatalf("StitchCode failed: %v", err)
+			}
+			if prompt := a.Requests()[0].Prompt; !strings.Contains(prompt, "Add a Sub function") {
+				t.Errorf("prompt does not carry the crumb description:\n%s", prompt)
+			}
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			if crumb.State != tt.wantState {
+				t.Errorf("State = %s, want %s", crumb.State, tt.wantState)
+			}
+
+			_, statErr := os.Stat(filepath.Join(repo, "sub.go"))
+			if tt.wantState == types.StateDone {
+				if statErr != nil {
+					t.Errorf("sub.go not merged into main: %v", statErr)
+				}
+				if result.Worktree != "" {
+					t.Errorf("Worktree = %q, want it removed after merge", result.Worktree)
+				}
+				return
+			}
+			if statErr == nil {
+				t.Error("sub.go merged into main without an accept")
+			}
+			if _, err := os.Stat(filepath.Join(result.Worktree, "sub.go")); err != nil {
+				t.Errorf("worktree not kept with the change: %v", err)
+			}
+			if note, _ := crumb.Properties[crumbs.PropReleaseNote].(string); !strings.Contains(note, result.Worktree) {
+				t.Errorf("release note = %q, want it to name the worktree", note)
+			}
+		})
+	}
+}
+
+func TestStitchCode_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(agent.Request) error
+		wantErr error
+		wantMsg string
+	}{
+		{"no changes", func(agent.Request) error { return nil }, ErrNoChanges, ""},
+		{"build fails", func(req agent.Request) error {
+			return os.WriteFile(filepath.Join(req.Dir, "broken.go"), []byte("package calc\n\nfunc Broken() int { return \"x\" }\n"), 0o644)
+		}, nil, "go build"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			cupboard := newCupboard(t)
+			id := codeCrumb(t, cupboard)
+
+			_, err := StitchCode(context.Background(), cupboard, &agent.MockAgent{OnRun: tt.edit}, newPortfolio(t, 1), codeConfig(repo))
+			if err == nil {
+				t.Fatal("StitchCode succeeded, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantMsg)
+			}
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			if crumb.State != types.StateReady {
+				t.Errorf("State = %s, want released to ready", crumb.State)
+			}
+		})
+	}
+}
+
+func TestStitchCode_Retry(t *testing.T) {
+	tests := []struct {
+		name           string
+		removeWorktree bool
+	}{
+		{"reuses the kept worktree", false},
+		{"checks out the kept branch again", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo(t)
+			cupboard := newCupboard(t)
+			id := codeCrumb(t, cupboard)
+
+			first, err := StitchCode(ctx, cupboard, &agent.MockAgent{OnRun: addSub}, newPortfolio(t, 0.6), codeConfig(repo))
+			if err != nil {
+				t.Fatalf("first StitchCode failed: %v", err)
+			}
+			if tt.removeWorktree {
+				if err := os.RemoveAll(first.Worktree); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The retry's agent changes nothing: the earlier commit is what
+			// gets inspected and merged.
+			second, err := StitchCode(ctx, cupboard, &agent.MockAgent{OnRun: func(agent.Request) error { return nil }}, newPortfolio(t, 1), codeConfig(repo))
+			if err != nil {
+				t.Fatalf("retried StitchCode failed: %v", err)
+			}
+			if !second.Done {
+				t.Errorf("retry Action = %s, want the crumb done", second.Composite.Action)
+			}
+			if _, err := os.Stat(filepath.Join(repo, "sub.go")); err != nil {
+				t.Errorf("sub.go from the first attempt not merged: %v", err)
+			}
+			if crumb, err := cupboard.GetCrumb(id); err != nil || crumb.State != types.StateDone {
+				t.Errorf("crumb = %+v, %v; want done", crumb, err)
+			}
+		})
+	}
+}
+
+func TestStitchCode_MergeConflict(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	cupboard := newCupboard(t)
+	id := codeCrumb(t, cupboard)
+
+	// main gains its own sub.go while the agent writes one on the branch.
+	edit := func(req agent.Request) error {
+		if err := os.WriteFile(filepath.Join(repo, "sub.go"), []byte("package calc\n\nfunc Sub(a, b int) int { return b - a }\n"), 0o644); err != nil {
+			return err
+		}
+		for _, args := range [][]string{{"add", "sub.go"}, {"commit", "-q", "-m", "conflicting sub"}} {
+			if _, err := git(ctx, repo, args...); err != nil {
+				return err
+			}
+		}
+		return addSub(req)
+	}
+	_, err := StitchCode(ctx, cupboard, &agent.MockAgent{OnRun: edit}, newPortfolio(t, 1), codeConfig(repo))
+	if !errors.Is(err, ErrMergeConflict) || !strings.Contains(err.Error(), "sub.go") {
+		t.Fatalf("StitchCode error = %v, want ErrMergeConflict naming sub.go", err)
+	}
+	if status, err := git(ctx, repo, "status", "--porcelain", "--untracked-files=no"); err != nil || status != "" {
+		t.Errorf("base checkout status = %q, %v; want the merge aborted", status, err)
+	}
+	if _, err := git(ctx, repo, "rev-parse", "--verify", "--quiet", "MERGE_HEAD"); err == nil {
+		t.Error("MERGE_HEAD exists, want the merge aborted")
+	}
+	if crumb, err := cupboard.GetCrumb(id); err != nil || crumb.State != types.StateReady {
+		t.Errorf("crumb = %+v, %v; want released to ready", crumb, err)
+	}
+}
+
+func TestRecentFiles(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+	if err := os.WriteFile(filepath.Join(repo, "sub.go"), []byte("package calc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitAll(ctx, repo, "add sub"); err != nil {
+		t.Fatalf("commitAll failed: %v", err)
+	}
+
+	got, err := recentFiles(ctx, repo)
+	if err != nil {
+		t.Fatalf("recentFiles failed: %v", err)
+	}
+	if want := "sub.go,calc.go,go.mod"; strings.Join(got, ",") != want {
+		t.Errorf("recentFiles = %v, want %s", got, want)
+	}
+	if _, err := recentFiles(ctx, t.TempDir()); err == nil {
+		t.Error("recentFiles outside a repository: want an error")
+	}
+}
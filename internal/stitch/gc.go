@@ -0,0 +1,127 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// Worktree is a code task worktree found by CollectWorktrees.
+type Worktree struct {
+	// Path is the worktree directory.
+	Path string
+	// CrumbID is the crumb the worktree was created for.
+	CrumbID string
+	// State is the crumb's state; empty when the crumb is no longer in the
+	// cupboard.
+	State types.State
+	// Stale is true when the crumb is done or no longer in the cupboard, so
+	// nothing will work in the worktree again.
+	Stale bool
+	// Removed is true when the worktree was removed.
+	Removed bool
+}
+
+// CollectWorktrees finds the code task worktrees under config.WorktreeRoot
+// and removes the stale ones: those whose crumb is done or no longer in the
+// cupboard. A worktree of a crumb in any other state, in particular a taken
+// one that stitch is working in, is kept. The worktree's stitch/<crumb ID>
+// branch is deleted only when it is merged, so unmerged work stays
+// reachable. With dryRun nothing is removed. Worktrees are returned in git
+// order; a failure to remove one does not stop the others, and the failures
+// are returned together.
+func CollectWorktrees(ctx context.Context, cupboard *crumbs.Cupboard, config Config, dryRun bool) ([]Worktree, error) {
+	root, err := worktreeRoot(config)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		// Forget worktrees whose directories were deleted by hand.
+		if _, err := git(ctx, config.Dir, "worktree", "prune"); err != nil {
+			return nil, err
+		}
+	}
+	paths, err := listWorktrees(ctx, config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []Worktree
+	var errs []error
+	for _, path := range paths {
+		id, ok := crumbWorktree(root, path)
+		if !ok {
+			continue
+		}
+		wt := Worktree{Path: path, CrumbID: id}
+		crumb, err := cupboard.GetCrumb(id)
+		var notFound *crumbs.CrumbNotFoundError
+		switch {
+		case errors.As(err, &notFound):
+			wt.Stale = true
+		case err != nil:
+			return worktrees, err
+		default:
+			wt.State = crumb.State
+			wt.Stale = crumb.State == types.StateDone
+		}
+		if wt.Stale && !dryRun {
+			if _, err := git(ctx, config.Dir, "worktree", "remove", "--force", path); err != nil {
+				errs = append(errs, err)
+			} else {
+				wt.Removed = true
+				_, _ = git(ctx, config.Dir, "branch", "-d", branchPrefix+id)
+			}
+		}
+		worktrees = append(worktrees, wt)
+	}
+	return worktrees, errors.Join(errs...)
+}
+
+// worktreeRoot returns the absolute directory code task worktrees are
+// created under.
+func worktreeRoot(config Config) (string, error) {
+	root := config.WorktreeRoot
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(config.Dir, root)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolving worktree root: %w", err)
+	}
+	return root, nil
+}
+
+// listWorktrees returns the paths of the worktrees of the repository in dir.
+func listWorktrees(ctx context.Context, dir string) ([]string, error) {
+	out, err := git(ctx, dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// crumbWorktree returns the crumb ID of a worktree directly under root.
+// git reports paths with symbolic links resolved, so root is resolved too
+// when it exists.
+func crumbWorktree(root, path string) (string, bool) {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel != filepath.Base(rel) || rel == ".." {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,83 @@
+package stitch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+func TestCollectWorktrees(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	cupboard := newCupboard(t)
+	config := codeConfig(repo)
+	root := filepath.Join(repo, config.WorktreeRoot)
+
+	ids := map[types.State]string{}
+	for _, state := range []types.State{types.StateTaken, types.StateReady, types.StateDone} {
+		id, err := cupboard.SetCrumb("", &types.Crumb{Name: "Worktree crumb", State: state})
+		if err != nil {
+			t.Fatalf("SetCrumb failed: %v", err)
+		}
+		ids[state] = id
+	}
+	const missing = "deleted-crumb"
+	worktrees := []string{ids[types.StateTaken], ids[types.StateReady], ids[types.StateDone], missing}
+	for _, id := range worktrees {
+		if _, err := git(ctx, repo, "worktree", "add", "-q", "-b", branchPrefix+id, filepath.Join(root, id), "main"); err != nil {
+			t.Fatalf("adding worktree: %v", err)
+		}
+	}
+	// A worktree outside the root is not stitch's.
+	other := filepath.Join(t.TempDir(), "other")
+	if _, err := git(ctx, repo, "worktree", "add", "-q", "-b", "other", other, "main"); err != nil {
+		t.Fatalf("adding worktree: %v", err)
+	}
+	// Uncommitted work in a stale worktree does not block its removal.
+	if err := os.WriteFile(filepath.Join(root, missing, "scratch.go"), []byte("package calc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := map[string]bool{ids[types.StateDone]: true, missing: true}
+	for _, dryRun := range []bool{true, false} {
+		got, err := CollectWorktrees(ctx, cupboard, config, dryRun)
+		if err != nil {
+			t.Fatalf("CollectWorktrees(dryRun %v) failed: %v", dryRun, err)
+		}
+		if len(got) != len(worktrees) {
+			t.Fatalf("CollectWorktrees(dryRun %v) = %+v, want the %d worktrees under the root", dryRun, got, len(worktrees))
+		}
+		for _, wt := range got {
+			if wt.Stale != stale[wt.CrumbID] || wt.Removed != (stale[wt.CrumbID] && !dryRun) {
+				t.Errorf("dryRun %v: %s (state %q) Stale = %v, Removed = %v", dryRun, wt.CrumbID, wt.State, wt.Stale, wt.Removed)
+			}
+			if wt.CrumbID == missing && wt.State != "" {
+				t.Errorf("State of a worktree without a crumb = %q, want empty", wt.State)
+			}
+			_, statErr := os.Stat(filepath.Join(root, wt.CrumbID))
+			if exists := statErr == nil; exists == wt.Removed {
+				t.Errorf("dryRun %v: %s exists = %v, Removed = %v", dryRun, wt.CrumbID, exists, wt.Removed)
+			}
+		}
+	}
+
+	branches, err := git(ctx, repo, "branch", "--list", branchPrefix+"*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range worktrees {
+		if kept := strings.Contains(branches, branchPrefix+id); kept == stale[id] {
+			t.Errorf("branch of %s kept = %v, want %v", id, kept, !stale[id])
+		}
+	}
+	if got, err := CollectWorktrees(ctx, cupboard, config, false); err != nil || len(got) != 2 {
+		t.Errorf("second CollectWorktrees = %+v, %v; want the taken and ready worktrees", got, err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("worktree outside the root removed: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package stitch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// run executes name with args in dir and returns its combined output.
+// A failure includes the output in the error.
+func run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
+
+// git runs a git command in dir.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	return run(ctx, dir, "git", args...)
+}
+
+// currentBranch returns the branch checked out in dir.
+func currentBranch(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// recentCommits is how many commits recentFiles looks back.
+const recentCommits = 20
+
+// recentFiles lists the files, relative to dir, that the last recentCommits
+// commits touched, most recently touched first. Context assembly ranks them
+// ahead of other neighboring files.
+func recentFiles(ctx context.Context, dir string) ([]string, error) {
+	out, err := git(ctx, dir, "log", "-n", strconv.Itoa(recentCommits), "--format=", "--name-only", "--no-renames", "--relative")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !slices.Contains(files, line) {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,125 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// MendWorkspace is the mend.Workspace of stitched crumbs. A docs crumb's
+// target is rewritten under Config.Dir from the agent's response, as
+// StitchDocs writes it; a code crumb is mended in the worktree StitchCode
+// kept when inspect did not accept, and its branch is merged on accept.
+type MendWorkspace struct {
+	config Config
+}
+
+// NewMendWorkspace creates a MendWorkspace for crumbs stitched with config.
+func NewMendWorkspace(config Config) *MendWorkspace {
+	return &MendWorkspace{config: config}
+}
+
+// Request returns the agent request that follows prompt. A docs request
+// carries the target's current contents and asks for its complete new
+// contents; a code request runs in the crumb's worktree. Returns
+// ErrNoWorktree when a code crumb's worktree no longer exists.
+func (w *MendWorkspace) Request(crumb *types.Crumb, prompt string) (agent.Request, error) {
+	target, ok, err := docsTarget(crumb)
+	if err != nil {
+		return agent.Request{}, err
+	}
+	if ok {
+		existing, err := os.ReadFile(filepath.Join(w.config.Dir, target))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return agent.Request{}, fmt.Errorf("reading %s: %w", target, err)
+		}
+		return agent.Request{Prompt: fmt.Sprintf("%s\n## Current contents of %s\n%s\n\n"+
+			"## Output format\nReturn only the full markdown for %s. Do not add commentary\n"+
+			"before or after it. Do not use any tools.\n", prompt, target, existing, target)}, nil
+	}
+	worktree, err := w.worktree(crumb)
+	if err != nil {
+		return agent.Request{}, err
+	}
+	return agent.Request{Prompt: prompt, Dir: worktree}, nil
+}
+
+// Input applies resp and returns the input that re-inspects the mended
+// work. A docs response is written to the target; for a code crumb the
+// agent's changes in the worktree are committed on the crumb's branch.
+func (w *MendWorkspace) Input(ctx context.Context, crumb *types.Crumb, resp agent.Response) (*inspect.InspectInput, error) {
+	target, ok, err := docsTarget(crumb)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if _, err := writeResponse(w.config.Dir, target, resp); err != nil {
+			return nil, err
+		}
+		return &inspect.InspectInput{WorkType: inspect.WorkTypeDocs, Dir: w.config.Dir, ModifiedFiles: []string{target}}, nil
+	}
+	worktree, err := w.worktree(crumb)
+	if err != nil {
+		return nil, err
+	}
+	err = commitAll(ctx, worktree, fmt.Sprintf("[%s] mend %s", crumb.CrumbID, crumb.Name))
+	if err != nil && !errors.Is(err, ErrNoChanges) {
+		return nil, err
+	}
+	return inspect.NewInspectInputFromGit(ctx, worktree, w.config.BaseBranch, branchPrefix+crumb.CrumbID)
+}
+
+// Accept merges an accepted code crumb's branch into the base branch and
+// removes its worktree, as StitchCode does on accept. A docs target is
+// already in place.
+func (w *MendWorkspace) Accept(ctx context.Context, crumb *types.Crumb) error {
+	if _, ok, err := docsTarget(crumb); ok || err != nil {
+		return err
+	}
+	worktree, err := w.worktree(crumb)
+	if err != nil {
+		return err
+	}
+	branch := branchPrefix + crumb.CrumbID
+	if err := merge(ctx, w.config.Dir, w.config.BaseBranch, branch); err != nil {
+		return err
+	}
+	removeWorktree(ctx, w.config.Dir, worktree, branch)
+	return nil
+}
+
+// worktree returns the worktree kept for a code crumb. Returns
+// ErrNoWorktree when it no longer exists.
+func (w *MendWorkspace) worktree(crumb *types.Crumb) (string, error) {
+	root, err := worktreeRoot(w.config)
+	if err != nil {
+		return "", err
+	}
+	worktree := filepath.Join(root, crumb.CrumbID)
+	if _, err := os.Stat(worktree); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNoWorktree, worktree)
+	}
+	return worktree, nil
+}
+
+// docsTarget returns the target file of a docs crumb. The boolean is false
+// for a crumb without a target_file property, which is a code crumb.
+func docsTarget(crumb *types.Crumb) (string, bool, error) {
+	value, ok := crumb.Properties[crumbs.PropTargetFile]
+	if !ok {
+		return "", false, nil
+	}
+	target, _ := value.(string)
+	if !filepath.IsLocal(target) {
+		return "", false, fmt.Errorf("%w: %q", ErrNoTargetFile, target)
+	}
+	return target, true, nil
+}
@@ -0,0 +1,138 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+)
+
+func TestMendWorkspace_Code(t *testing.T) {
+	repo := newRepo(t)
+	cupboard := newCupboard(t)
+	id := codeCrumb(t, cupboard)
+	config := codeConfig(repo)
+	ctx := context.Background()
+
+	// A mend action keeps the worktree for mend to work in.
+	result, err := StitchCode(ctx, cupboard, &agent.MockAgent{OnRun: addSub}, newPortfolio(t, 0.6), config)
+	if err != nil {
+		t.Fatalf("StitchCode failed: %v", err)
+	}
+	crumb, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+
+	workspace := NewMendWorkspace(config)
+	req, err := workspace.Request(crumb, "Add a test for Add.")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if req.Dir != result.Worktree {
+		t.Errorf("Request Dir = %q, want the kept worktree %q", req.Dir, result.Worktree)
+	}
+	test := "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"Add(1, 2) != 3\")\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(req.Dir, "calc_test.go"), []byte(test), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := workspace.Input(ctx, crumb, agent.Response{})
+	if err != nil {
+		t.Fatalf("Input failed: %v", err)
+	}
+	if input.WorkType != inspect.WorkTypeCode {
+		t.Errorf("WorkType = %s, want %s", input.WorkType, inspect.WorkTypeCode)
+	}
+	for _, file := range []string{"sub.go", "calc_test.go"} {
+		if !slices.Contains(input.ModifiedFiles, file) {
+			t.Errorf("ModifiedFiles = %v, want %s against main", input.ModifiedFiles, file)
+		}
+	}
+	status, err := git(ctx, req.Dir, "status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "" {
+		t.Errorf("mend changes left uncommitted:\n%s", status)
+	}
+
+	// Accepting merges the branch, as StitchCode does, and drops the worktree.
+	if err := workspace.Accept(ctx, crumb); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	for _, file := range []string{"sub.go", "calc_test.go"} {
+		if _, err := os.Stat(filepath.Join(repo, file)); err != nil {
+			t.Errorf("%s not merged into main: %v", file, err)
+		}
+	}
+	if _, err := workspace.Request(crumb, "Add a test for Add."); !errors.Is(err, ErrNoWorktree) {
+		t.Errorf("Request without a worktree err = %v, want ErrNoWorktree", err)
+	}
+}
+
+func TestMendWorkspace_Docs(t *testing.T) {
+	cupboard := newCupboard(t)
+	id := docsCrumb(t, cupboard, "docs/parser.md")
+	crumb, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs/parser.md"), []byte("# Parser\n\nSee [missing](gone.md).\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	workspace := NewMendWorkspace(Config{Dir: dir})
+	ctx := context.Background()
+
+	req, err := workspace.Request(crumb, "Fix the broken link.")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if req.Dir != "" {
+		t.Errorf("Request Dir = %q, want none: the agent only answers", req.Dir)
+	}
+	for _, want := range []string{"Fix the broken link.", "See [missing](gone.md).", "full markdown for docs/parser.md"} {
+		if !strings.Contains(req.Prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, req.Prompt)
+		}
+	}
+
+	// The response is the target's new contents, written as StitchDocs does.
+	input, err := workspace.Input(ctx, crumb, agent.Response{Content: "```markdown\n# Parser\n\nHandles errors.\n```"})
+	if err != nil {
+		t.Fatalf("Input failed: %v", err)
+	}
+	want := &inspect.InspectInput{WorkType: inspect.WorkTypeDocs, Dir: dir, ModifiedFiles: []string{"docs/parser.md"}}
+	if input.WorkType != want.WorkType || input.Dir != want.Dir || !slices.Equal(input.ModifiedFiles, want.ModifiedFiles) {
+		t.Errorf("Input = %+v, want %+v", input, want)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "docs/parser.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "# Parser\n\nHandles errors.\n" {
+		t.Errorf("target = %q, want the unfenced response", got)
+	}
+	if _, err := workspace.Input(ctx, crumb, agent.Response{Content: "  "}); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("Input with an empty response err = %v, want ErrEmptyResponse", err)
+	}
+	if err := workspace.Accept(ctx, crumb); err != nil {
+		t.Errorf("Accept failed: %v", err)
+	}
+
+	crumb.Properties[crumbs.PropTargetFile] = "../outside.md"
+	if _, err := workspace.Request(crumb, "Fix the broken link."); !errors.Is(err, ErrNoTargetFile) {
+		t.Errorf("Request with a non-local target err = %v, want ErrNoTargetFile", err)
+	}
+}
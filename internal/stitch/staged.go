@@ -0,0 +1,68 @@
+package stitch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stagedFile is a file written by stitch together with what the write
+// replaced, so that a stitch failing after the write can put the tree back.
+type stagedFile struct {
+	path string
+	// existed, original, and mode describe the file before the write.
+	existed  bool
+	original []byte
+	mode     fs.FileMode
+	// created lists the directories made for the file, deepest first.
+	created []string
+}
+
+// stageFile writes content to path, creating missing parent directories,
+// and records the previous state of path for restore.
+func stageFile(path string, content []byte) (*stagedFile, error) {
+	s := &stagedFile{path: path, mode: 0o644}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if s.original, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+		s.existed, s.mode = true, info.Mode().Perm()
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || !errors.Is(err, fs.ErrNotExist) || dir == filepath.Dir(dir) {
+			break
+		}
+		s.created = append(s.created, dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, content, s.mode); err != nil {
+		_ = s.restore()
+		return nil, err
+	}
+	return s, nil
+}
+
+// restore puts back the file's previous contents, or removes the file and
+// the directories made for it when it did not exist.
+func (s *stagedFile) restore() error {
+	if s.existed {
+		return os.WriteFile(s.path, s.original, s.mode)
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, dir := range s.created {
+		if err := os.Remove(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+// Package stitch executes ready crumbs with an agent and closes them when
+// inspect accepts the result.
+// Implements: prd002-stitch R2 (claim and release), R4 (prompt builder),
+// R5 (agent dispatch), R6 (git worktree management), R7 (quality gates),
+// R8 (crumb state transitions).
+//
+// StitchDocs handles documentation crumbs: it claims a crumb, asks the agent
+// for the contents of the crumb's target file, writes the file, and runs the
+// inspect portfolio. An accepted crumb moves to done; any other action
+// releases it back to ready with a note. StitchCode handles code crumbs in an
+// isolated git worktree (R6) and merges the branch only when inspect accepts.
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	cobble "github.com/petar-djukic/cobbler/internal/context"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/logging"
+	"github.com/petar-djukic/cobbler/internal/mend"
+	"github.com/petar-djukic/cobbler/internal/prompt"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+var (
+	// ErrNoTargetFile reports a docs crumb without a usable target_file property.
+	ErrNoTargetFile = fmt.Errorf("stitch: crumb has no valid target_file")
+	// ErrEmptyResponse reports an agent response with no document content.
+	ErrEmptyResponse = fmt.Errorf("stitch: agent returned no content")
+	// ErrNoWorktree reports a code crumb whose stitch worktree is gone.
+	ErrNoWorktree = fmt.Errorf("stitch: crumb has no worktree")
+)
+
+// Config controls a stitch run.
+type Config struct {
+	// Dir is the project root that target files resolve against.
+	// Empty means the current directory.
+	Dir string
+	// CrumbID selects the crumb to stitch; empty claims the next ready crumb,
+	// by priority and then age.
+	CrumbID string
+	// BaseBranch is the branch code tasks start from and merge into.
+	BaseBranch string
+	// WorktreeRoot is the directory code task worktrees are created under,
+	// relative to Dir unless absolute.
+	WorktreeRoot string
+	// Templates renders the task prompts; nil uses the embedded defaults.
+	Templates *prompt.Templates
+	// ContextBudget bounds the repository context in each prompt, in bytes.
+	// Values below 1 use the context package default.
+	ContextBudget int
+	// Logger receives claim, release, and action events; nil discards them.
+	// Set the portfolio's logger separately.
+	Logger *slog.Logger
+	// AgentOutput receives the agent's output as it streams, so long runs
+	// show progress; nil discards it.
+	AgentOutput io.Writer
+}
+
+// Defaults for code tasks.
+const (
+	DefaultBaseBranch   = "main"
+	DefaultWorktreeRoot = ".cobbler/worktrees"
+)
+
+// DefaultConfig returns a Config with the default base branch and worktree
+// root.
+func DefaultConfig() Config {
+	return Config{BaseBranch: DefaultBaseBranch, WorktreeRoot: DefaultWorktreeRoot}
+}
+
+// Result describes one stitched crumb.
+type Result struct {
+	CrumbID string
+	// File is the docs target file, relative to Config.Dir.
+	File string
+	// Composite is the inspect result for the written file.
+	Composite inspect.CompositeResult
+	// Done is true when inspect accepted the work and the crumb was closed.
+	Done bool
+	// Worktree is the code task worktree, when one was kept for inspection.
+	Worktree string
+	// Usage totals the agent tokens spent on the crumb across all stitch
+	// attempts, including this one.
+	Usage agent.Usage
+}
+
+// StitchDocs claims a documentation crumb and runs it through the agent and
+// the inspect portfolio. If any step fails after the claim, the crumb is
+// released back to ready with the error as its note, and a target file
+// already written is restored. The agent's token usage
+// is added to the crumb's usage totals.
+func StitchDocs(ctx context.Context, cupboard *crumbs.Cupboard, a agent.Agent, portfolio *inspect.Portfolio, config Config) (Result, error) {
+	return stitchClaimed(cupboard, a, config, inspect.WorkTypeDocs, func(a agent.Agent, crumb *types.Crumb) (Result, error) {
+		return stitchDocs(ctx, cupboard, a, portfolio, config, crumb)
+	})
+}
+
+// stitchClaimed claims a crumb of workType (config.CrumbID, or the next
+// ready one) and runs fn on it with a metered agent. If fn fails, the crumb is released with
+// the error as its note. Either way, the tokens fn spent are added to the
+// crumb's usage totals.
+func stitchClaimed(cupboard *crumbs.Cupboard, a agent.Agent, config Config, workType string, fn func(agent.Agent, *types.Crumb) (Result, error)) (Result, error) {
+	logger := logging.OrDiscard(config.Logger)
+	crumb, err := claim(cupboard, config.CrumbID, workType)
+	if err != nil {
+		return Result{}, err
+	}
+	logger.Info("crumb claimed", logging.KeyCrumb, crumb.CrumbID, "name", crumb.Name)
+	metered := &meteredAgent{Agent: a}
+	result, err := fn(metered, crumb)
+	if err != nil {
+		logger.Warn("crumb released", logging.KeyCrumb, crumb.CrumbID, "error", err)
+	} else {
+		logger.Info("action taken", logging.KeyCrumb, crumb.CrumbID, logging.KeyAction, result.Composite.Action,
+			logging.KeyScore, result.Composite.Score, "done", result.Done)
+	}
+	err = releaseOnError(cupboard, crumb.CrumbID, err)
+	usage, usageErr := recordUsage(cupboard, crumb.CrumbID, metered.total())
+	if usageErr != nil {
+		usageErr = fmt.Errorf("recording usage for %s: %w", crumb.CrumbID, usageErr)
+	}
+	result.Usage = usage
+	return result, errors.Join(err, usageErr)
+}
+
+// releaseOnError releases the crumb with err as its note when err is not
+// nil, and returns err.
+func releaseOnError(cupboard *crumbs.Cupboard, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if releaseErr := cupboard.ReleaseCrumb(id, err.Error()); releaseErr != nil {
+		return errors.Join(err, fmt.Errorf("releasing %s: %w", id, releaseErr))
+	}
+	return err
+}
+
+// runAgent streams req from a, copying content to w as it arrives when w is
+// not nil, and returns the assembled response.
+func runAgent(ctx context.Context, a agent.Agent, req agent.Request, w io.Writer) (agent.Response, error) {
+	chunks, err := agent.Streaming(a).RunStream(ctx, req)
+	if err != nil {
+		return agent.Response{}, err
+	}
+	var progress func(agent.Chunk)
+	if w != nil {
+		progress = func(c agent.Chunk) { _, _ = io.WriteString(w, c.Content) }
+	}
+	return agent.Collect(chunks, progress)
+}
+
+// templates returns the configured templates or the embedded defaults.
+func templates(config Config) *prompt.Templates {
+	if config.Templates == nil {
+		return prompt.Default()
+	}
+	return config.Templates
+}
+
+// taskData builds the prompt data for crumb: its repository context
+// assembled from root, ranking recently committed files ahead of other
+// neighbors, and the findings of its latest inspect when it is being
+// retried. A root outside a git work tree has no recent files.
+func taskData(ctx context.Context, cupboard *crumbs.Cupboard, crumb *types.Crumb, root string, config cobble.Config) (prompt.Data, error) {
+	data := prompt.TaskData(crumb)
+	if recent, err := recentFiles(ctx, root); err == nil {
+		config.Recent = recent
+	}
+	assembled, err := cobble.Assemble(root, crumb, config)
+	if err != nil {
+		return data, fmt.Errorf("assembling context: %w", err)
+	}
+	data.Context = assembled.String()
+	data.OmittedContext = assembled.Omitted
+	last, ok, err := mend.LatestResult(cupboard, crumb.CrumbID)
+	if err != nil {
+		return data, err
+	}
+	if ok {
+		data.Findings = prompt.Findings(last)
+	}
+	return data, nil
+}
+
+// claim takes the crumb of workType with the given ID, or the next ready
+// crumb of workType.
+func claim(cupboard *crumbs.Cupboard, id, workType string) (*types.Crumb, error) {
+	if id == "" {
+		return cupboard.ClaimCrumb(workType)
+	}
+	return cupboard.ClaimCrumbByID(id, workType)
+}
+
+// stitchDocs writes and inspects the claimed crumb's target file, then
+// closes or releases the crumb according to the inspect action. When
+// inspecting or recording fails outright, the target is restored to its
+// state before the write.
+func stitchDocs(ctx context.Context, cupboard *crumbs.Cupboard, a agent.Agent, portfolio *inspect.Portfolio, config Config, crumb *types.Crumb) (Result, error) {
+	dir := config.Dir
+	result := Result{CrumbID: crumb.CrumbID}
+	target, _ := crumb.Properties[crumbs.PropTargetFile].(string)
+	if !filepath.IsLocal(target) {
+		return result, fmt.Errorf("%w: %q", ErrNoTargetFile, target)
+	}
+	result.File = target
+	path := filepath.Join(dir, target)
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return result, fmt.Errorf("reading %s: %w", target, err)
+	}
+	// The target's current contents are shown separately.
+	data, err := taskData(ctx, cupboard, crumb, dir, cobble.Config{Budget: config.ContextBudget, Exclude: []string{target}})
+	if err != nil {
+		return result, err
+	}
+	data.TargetFile = target
+	data.Existing = string(existing)
+	text, err := templates(config).Render(prompt.StitchDocs, data)
+	if err != nil {
+		return result, err
+	}
+
+	resp, err := runAgent(ctx, a, agent.Request{Prompt: text}, config.AgentOutput)
+	if err != nil {
+		return result, fmt.Errorf("running agent: %w", err)
+	}
+	staged, err := writeResponse(dir, target, resp)
+	if err != nil {
+		return result, err
+	}
+
+	result.Composite, result.Done, err = inspectDocs(ctx, cupboard, portfolio, dir, target, crumb.CrumbID)
+	if err != nil {
+		// The crumb is released on error, so the write must not outlive it.
+		if restoreErr := staged.restore(); restoreErr != nil {
+			return result, errors.Join(err, fmt.Errorf("restoring %s: %w", target, restoreErr))
+		}
+		return result, fmt.Errorf("%w (restored %s)", err, target)
+	}
+	return result, nil
+}
+
+// inspectDocs runs the portfolio on the written target, records the result,
+// and closes the crumb on accept or releases it with a note otherwise. It
+// reports whether the crumb was closed.
+func inspectDocs(ctx context.Context, cupboard *crumbs.Cupboard, portfolio *inspect.Portfolio, dir, target, id string) (inspect.CompositeResult, bool, error) {
+	input := &inspect.InspectInput{WorkType: inspect.WorkTypeDocs, Dir: dir, ModifiedFiles: []string{target}}
+	cr, err := portfolio.Run(ctx, input)
+	if err != nil {
+		return cr, false, fmt.Errorf("inspecting %s: %w", target, err)
+	}
+	cr, err = mend.RecordResult(cupboard, id, cr)
+	if err != nil {
+		return cr, false, err
+	}
+
+	if cr.Action.Accepting() {
+		if err := cupboard.SetCrumbState(id, types.StateDone); err != nil {
+			return cr, false, err
+		}
+		return cr, true, nil
+	}
+	if err := cupboard.ReleaseCrumb(id, inspectNote(cr)); err != nil {
+		return cr, false, err
+	}
+	return cr, false, nil
+}
+
+// inspectNote explains a composite result that did not accept.
+func inspectNote(cr inspect.CompositeResult) string {
+	note := fmt.Sprintf("inspect: %s (score %.2f)", cr.Action, cr.Score)
+	if cr.Reason != "" {
+		note += ": " + cr.Reason
+	}
+	return note
+}
+
+// writeResponse writes the agent's response, without a surrounding fence,
+// to target under dir as its complete contents. Returns ErrEmptyResponse
+// when nothing is left to write.
+func writeResponse(dir, target string, resp agent.Response) (*stagedFile, error) {
+	content := unfence(resp.Content)
+	if content == "" {
+		return nil, ErrEmptyResponse
+	}
+	staged, err := stageFile(filepath.Join(dir, target), []byte(content+"\n"))
+	if err != nil {
+		return nil, fmt.Errorf("writing %s: %w", target, err)
+	}
+	return staged, nil
+}
+
+// unfence strips surrounding whitespace and, when the whole response is a
+// single fenced code block, the fence itself.
+func unfence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") {
+		return s
+	}
+	_, body, ok := strings.Cut(strings.TrimSuffix(s, "```"), "\n")
+	if !ok {
+		return s
+	}
+	return strings.TrimSpace(body)
+}
@@ -0,0 +1,422 @@
+package stitch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+	"github.com/petar-djukic/cobbler/internal/inspect"
+	"github.com/petar-djukic/cobbler/internal/logging"
+	"github.com/petar-djukic/cobbler/internal/mend"
+	"github.com/petar-djukic/crumbs/pkg/types"
+)
+
+// fixedTechnique passes every input with a fixed score.
+type fixedTechnique struct {
+	name  string
+	score float64
+}
+
+func (f fixedTechnique) Name() string                                    { return f.name }
+func (f fixedTechnique) FaultClass() string                              { return "test" }
+func (f fixedTechnique) Applicable(*inspect.InspectInput) (bool, string) { return true, "" }
+func (f fixedTechnique) Run(context.Context, *inspect.InspectInput) (inspect.TechniqueResult, error) {
+	return inspect.TechniqueResult{Technique: f.name, Score: f.score, Verdict: inspect.VerdictPass, Deterministic: true}, nil
+}
+
+// brokenTechnique errors on every input, as a technique whose tool crashes.
+type brokenTechnique struct{ fixedTechnique }
+
+func (brokenTechnique) Run(context.Context, *inspect.InspectInput) (inspect.TechniqueResult, error) {
+	return inspect.TechniqueResult{}, errors.New("tool crashed")
+}
+
+// newPortfolio scores every docs task at score.
+func newPortfolio(t *testing.T, score float64) *inspect.Portfolio {
+	t.Helper()
+	config := inspect.DefaultScorerConfig()
+	config.Weights = map[string]float64{"first": 0.5, "second": 0.5}
+	scorer, err := inspect.NewScorer(config)
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	p := inspect.NewPortfolio(scorer, inspect.DefaultPortfolioConfig())
+	p.Register(fixedTechnique{"first", score})
+	p.Register(fixedTechnique{"second", score})
+	return p
+}
+
+// newTieredPortfolio scores every task at score with accept,
+// accept_with_warning, and mend tiers.
+func newTieredPortfolio(t *testing.T, score float64) *inspect.Portfolio {
+	t.Helper()
+	config := inspect.DefaultScorerConfig()
+	config.Weights = map[string]float64{"first": 0.5, "second": 0.5}
+	config.Tiers = []inspect.Tier{
+		{Threshold: 0.9, Action: inspect.ActionAccept},
+		{Threshold: 0.7, Action: "accept_with_warning"},
+		{Threshold: 0.5, Action: inspect.ActionMend},
+	}
+	scorer, err := inspect.NewScorer(config)
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	p := inspect.NewPortfolio(scorer, inspect.DefaultPortfolioConfig())
+	p.Register(fixedTechnique{"first", score})
+	p.Register(fixedTechnique{"second", score})
+	return p
+}
+
+func newCupboard(t *testing.T) *crumbs.Cupboard {
+	t.Helper()
+	cupboard, err := crumbs.NewCupboard(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCupboard failed: %v", err)
+	}
+	t.Cleanup(func() { cupboard.Close() })
+	return cupboard
+}
+
+func docsCrumb(t *testing.T, cupboard *crumbs.Cupboard, target string) string {
+	t.Helper()
+	id, err := cupboard.SetCrumb("", &types.Crumb{
+		Name:  "Document the parser",
+		State: types.StateReady,
+		Properties: map[string]any{
+			crumbs.PropWorkType:    inspect.WorkTypeDocs,
+			crumbs.PropTargetFile:  target,
+			crumbs.PropDescription: "Explain how the parser handles errors.",
+		},
+	})
+	if err != nil {
+		t.Fatalf("SetCrumb failed: %v", err)
+	}
+	return id
+}
+
+func TestStitchDocs(t *testing.T) {
+	tests := []struct {
+		name      string
+		score     float64
+		wantState types.State
+	}{
+		{"accept closes the crumb", 1, types.StateDone},
+		{"mend releases the crumb", 0.6, types.StateReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cupboard := newCupboard(t)
+			id := docsCrumb(t, cupboard, "docs/parser.md")
+			a := agent.NewMockAgent(agent.Response{Content: "```markdown\n# Parser\n\nErrors are reported with positions.\n```"})
+
+			result, err := StitchDocs(context.Background(), cupboard, a, newPortfolio(t, tt.score), Config{Dir: dir})
+			if err != nil {
+				t.Fatalf("StitchDocs failed: %v", err)
+			}
+			prompt := a.Requests()[0].Prompt
+			if !strings.Contains(prompt, "Explain how the parser handles errors.") || !strings.Contains(prompt, "docs/parser.md") {
+				t.Errorf("prompt does not carry the crumb's description and target:\n%s", prompt)
+			}
+			written, err := os.ReadFile(filepath.Join(dir, "docs", "parser.md"))
+			if err != nil {
+				t.Fatalf("target file not written: %v", err)
+			}
+			if string(written) != "# Parser\n\nErrors are reported with positions.\n" {
+				t.Errorf("written = %q, want the unfenced markdown", written)
+			}
+
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			if crumb.State != tt.wantState || result.Done != (tt.wantState == types.StateDone) {
+				t.Errorf("State = %s (Done %v), want %s", crumb.State, result.Done, tt.wantState)
+			}
+			if tt.wantState == types.StateReady && !strings.Contains(crumb.Properties[crumbs.PropReleaseNote].(string), "mend") {
+				t.Errorf("release note = %v, want the inspect action", crumb.Properties[crumbs.PropReleaseNote])
+			}
+			if _, ok, _ := mend.LatestResult(cupboard, id); !ok {
+				t.Error("inspect result was not recorded")
+			}
+		})
+	}
+}
+
+func TestStitchDocs_DefaultPortfolio(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantState types.State
+	}{
+		{"well-formed docs close the crumb", "# Parser\n\nErrors are reported with positions.\n", types.StateDone},
+		{"docs without a heading are released", "Errors are reported with positions.\n", types.StateReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portfolio, err := inspect.NewDefaultPortfolio()
+			if err != nil {
+				t.Fatalf("NewDefaultPortfolio failed: %v", err)
+			}
+			cupboard := newCupboard(t)
+			id := docsCrumb(t, cupboard, "docs/parser.md")
+			a := agent.NewMockAgent(agent.Response{Content: tt.content})
+
+			result, err := StitchDocs(context.Background(), cupboard, a, portfolio, Config{Dir: t.TempDir()})
+			if err != nil {
+				t.Fatalf("StitchDocs failed: %v", err)
+			}
+			if !result.Composite.Valid {
+				t.Fatalf("composite is not valid: %s", result.Composite.Reason)
+			}
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			if crumb.State != tt.wantState {
+				t.Errorf("State = %s (action %s), want %s", crumb.State, result.Composite.Action, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestStitchDocs_TargetCrumb(t *testing.T) {
+	dir := t.TempDir()
+	cupboard := newCupboard(t)
+	docsCrumb(t, cupboard, "older.md")
+	target := docsCrumb(t, cupboard, "target.md")
+
+	result, err := StitchDocs(context.Background(), cupboard, agent.NewMockAgent(agent.Response{Content: "# Target"}), newPortfolio(t, 1), Config{Dir: dir, CrumbID: target})
+	if err != nil {
+		t.Fatalf("StitchDocs failed: %v", err)
+	}
+	if result.CrumbID != target || result.File != "target.md" {
+		t.Errorf("result = %+v, want the targeted crumb", result)
+	}
+}
+
+func TestStitchDocs_SkipsCodeCrumbs(t *testing.T) {
+	cupboard := newCupboard(t)
+	code := codeCrumb(t, cupboard)
+	a := agent.NewMockAgent(agent.Response{Content: "# Doc"})
+
+	if _, err := StitchDocs(context.Background(), cupboard, a, newPortfolio(t, 1), Config{Dir: t.TempDir()}); !errors.Is(err, crumbs.ErrNoReadyCrumb) {
+		t.Errorf("StitchDocs error = %v, want ErrNoReadyCrumb", err)
+	}
+	if _, err := StitchDocs(context.Background(), cupboard, a, newPortfolio(t, 1), Config{Dir: t.TempDir(), CrumbID: code}); !errors.Is(err, crumbs.ErrNoReadyCrumb) {
+		t.Errorf("StitchDocs of the code crumb error = %v, want ErrNoReadyCrumb", err)
+	}
+	if crumb, _ := cupboard.GetCrumb(code); crumb.State != types.StateReady {
+		t.Errorf("code crumb State = %s, want it left ready", crumb.State)
+	}
+}
+
+func TestStitchDocs_AgentOutput(t *testing.T) {
+	dir := t.TempDir()
+	cupboard := newCupboard(t)
+	docsCrumb(t, cupboard, "streamed.md")
+
+	a := &agent.CommandAgent{Args: []string{"sh", "-c", "printf '# Streamed\\n'; sleep 0.1; printf 'More text.\\n'"}}
+	var output bytes.Buffer
+	if _, err := StitchDocs(context.Background(), cupboard, a, newPortfolio(t, 1), Config{Dir: dir, AgentOutput: &output}); err != nil {
+		t.Fatalf("StitchDocs failed: %v", err)
+	}
+	if want := "# Streamed\nMore text.\n"; output.String() != want {
+		t.Errorf("agent output = %q, want %q", output.String(), want)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "streamed.md")); err != nil || !strings.HasPrefix(string(got), "# Streamed") {
+		t.Errorf("streamed.md = %q, %v; want the streamed document", got, err)
+	}
+}
+
+func TestStitchDocs_ReleasesOnError(t *testing.T) {
+	cupboard := newCupboard(t)
+	id := docsCrumb(t, cupboard, "../outside.md")
+
+	_, err := StitchDocs(context.Background(), cupboard, agent.NewMockAgent(agent.Response{Content: "# Doc"}), newPortfolio(t, 1), Config{Dir: t.TempDir()})
+	if !errors.Is(err, ErrNoTargetFile) {
+		t.Fatalf("StitchDocs error = %v, want ErrNoTargetFile", err)
+	}
+	crumb, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	if crumb.State != types.StateReady || crumb.Properties[crumbs.PropReleaseNote] == nil {
+		t.Errorf("crumb = %s %v, want released with a note", crumb.State, crumb.Properties)
+	}
+}
+
+func TestStitchDocs_RestoresOnInspectError(t *testing.T) {
+	scorer, err := inspect.NewScorer(inspect.DefaultScorerConfig())
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	portfolio := inspect.NewPortfolio(scorer, inspect.DefaultPortfolioConfig())
+	portfolio.Register(brokenTechnique{fixedTechnique{"broken", 1}})
+
+	tests := []struct {
+		name     string
+		target   string
+		existing string
+	}{
+		{"existing file", "README.md", "# Original\n"},
+		{"new file", "docs/guide/parser.md", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, filepath.FromSlash(tt.target))
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cupboard := newCupboard(t)
+			id := docsCrumb(t, cupboard, tt.target)
+
+			_, err := StitchDocs(context.Background(), cupboard, agent.NewMockAgent(agent.Response{Content: "# Rewritten"}), portfolio, Config{Dir: dir})
+			if err == nil || !strings.Contains(err.Error(), "tool crashed") {
+				t.Fatalf("StitchDocs error = %v, want the technique error", err)
+			}
+
+			got, readErr := os.ReadFile(path)
+			if tt.existing != "" {
+				info, statErr := os.Stat(path)
+				if readErr != nil || string(got) != tt.existing || statErr != nil || info.Mode().Perm() != 0o600 {
+					t.Errorf("%s = %q, %v; want the original contents and mode restored", tt.target, got, readErr)
+				}
+			} else {
+				if !errors.Is(readErr, fs.ErrNotExist) {
+					t.Errorf("%s = %q, %v; want it removed", tt.target, got, readErr)
+				}
+				if _, err := os.Stat(filepath.Join(dir, "docs")); !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("docs directory left behind: %v", err)
+				}
+			}
+
+			crumb, err := cupboard.GetCrumb(id)
+			if err != nil {
+				t.Fatalf("GetCrumb failed: %v", err)
+			}
+			note, _ := crumb.Properties[crumbs.PropReleaseNote].(string)
+			if crumb.State != types.StateReady || !strings.Contains(note, "tool crashed") || !strings.Contains(note, "restored") {
+				t.Errorf("crumb = %s with note %q, want released with the error and restore noted", crumb.State, note)
+			}
+		})
+	}
+}
+
+func TestStitchDocs_Usage(t *testing.T) {
+	dir := t.TempDir()
+	cupboard := newCupboard(t)
+	id := docsCrumb(t, cupboard, "usage.md")
+	a := agent.NewMockAgent(agent.Response{
+		Content: "# Usage",
+		Usage:   agent.Usage{InputTokens: 1200, OutputTokens: 300, CacheReadTokens: 5000},
+	})
+
+	// The first attempt is sent to mend and released; the retry is accepted.
+	// The crumb's totals cover both.
+	var last Result
+	for _, score := range []float64{0.6, 1} {
+		var err error
+		last, err = StitchDocs(context.Background(), cupboard, a, newPortfolio(t, score), Config{Dir: dir, CrumbID: id})
+		if err != nil {
+			t.Fatalf("StitchDocs failed: %v", err)
+		}
+	}
+	result, err := cupboard.GetCrumb(id)
+	if err != nil {
+		t.Fatalf("GetCrumb failed: %v", err)
+	}
+	want := map[string]int{crumbs.PropInputTokens: 2400, crumbs.PropOutputTokens: 600, crumbs.PropCacheReadTokens: 10000}
+	for prop, n := range want {
+		if result.Properties[prop] != n {
+			t.Errorf("%s = %v, want %d", prop, result.Properties[prop], n)
+		}
+	}
+
+	total := agent.Usage{InputTokens: 2400, OutputTokens: 600, CacheReadTokens: 10000}
+	if last.Usage != total {
+		t.Errorf("Result.Usage = %+v, want crumb totals %+v", last.Usage, total)
+	}
+	// 2400*3 + 600*15 + 10000*0.3 = 19200 dollars per million.
+	if got := agent.DefaultCostModel().Cost(total); math.Abs(got-0.0192) > 1e-12 {
+		t.Errorf("Cost = %v, want 0.0192", got)
+	}
+}
+
+func TestStitchDocs_Logs(t *testing.T) {
+	cupboard := newCupboard(t)
+	id := docsCrumb(t, cupboard, "logged.md")
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Format: logging.FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{Dir: t.TempDir(), Logger: logger}
+	if _, err := StitchDocs(context.Background(), cupboard, agent.NewMockAgent(agent.Response{Content: "# Doc"}), newPortfolio(t, 1), config); err != nil {
+		t.Fatalf("StitchDocs failed: %v", err)
+	}
+	var events []string
+	for line := range strings.Lines(buf.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %v: %s", err, line)
+		}
+		if record[logging.KeyCrumb] != id {
+			t.Errorf("event %v does not name crumb %s", record, id)
+		}
+		events = append(events, record["msg"].(string))
+	}
+	if want := []string{"crumb claimed", "action taken"}; !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestStitch_AcceptWithWarning(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("docs", func(t *testing.T) {
+		cupboard := newCupboard(t)
+		id := docsCrumb(t, cupboard, "docs/parser.md")
+		a := agent.NewMockAgent(agent.Response{Content: "# Parser"})
+		result, err := StitchDocs(ctx, cupboard, a, newTieredPortfolio(t, 0.8), Config{Dir: t.TempDir()})
+		if err != nil {
+			t.Fatalf("StitchDocs failed: %v", err)
+		}
+		if result.Composite.Action != "accept_with_warning" || !result.Done {
+			t.Errorf("Action = %s (Done %v), want accept_with_warning and done", result.Composite.Action, result.Done)
+		}
+		if crumb, err := cupboard.GetCrumb(id); err != nil || crumb.State != types.StateDone {
+			t.Errorf("crumb = %+v, %v; want done", crumb, err)
+		}
+	})
+
+	t.Run("code", func(t *testing.T) {
+		repo := newRepo(t)
+		cupboard := newCupboard(t)
+		codeCrumb(t, cupboard)
+		result, err := StitchCode(ctx, cupboard, &agent.MockAgent{OnRun: addSub}, newTieredPortfolio(t, 0.8), codeConfig(repo))
+		if err != nil {
+			t.Fatalf("StitchCode failed: %v", err)
+		}
+		if !result.Done {
+			t.Errorf("Action = %s, want the branch merged and the crumb done", result.Composite.Action)
+		}
+		if _, err := os.Stat(filepath.Join(repo, "sub.go")); err != nil {
+			t.Errorf("sub.go not merged into main: %v", err)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package stitch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/petar-djukic/cobbler/internal/agent"
+	"github.com/petar-djukic/cobbler/internal/crumbs"
+)
+
+// meteredAgent sums the usage of every call made through it.
+type meteredAgent struct {
+	agent.Agent
+
+	mu    sync.Mutex
+	usage agent.Usage
+}
+
+// Run forwards to the wrapped agent and adds the response usage to the
+// total.
+func (m *meteredAgent) Run(ctx context.Context, req agent.Request) (agent.Response, error) {
+	resp, err := m.Agent.Run(ctx, req)
+	m.mu.Lock()
+	m.usage = m.usage.Add(resp.Usage)
+	m.mu.Unlock()
+	return resp, err
+}
+
+// RunStream streams from the wrapped agent and adds the usage of the final
+// chunk to the total.
+func (m *meteredAgent) RunStream(ctx context.Context, req agent.Request) (<-chan agent.Chunk, error) {
+	chunks, err := agent.Streaming(m.Agent).RunStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan agent.Chunk)
+	go func() {
+		defer close(out)
+		for c := range chunks {
+			if c.Final {
+				m.mu.Lock()
+				m.usage = m.usage.Add(c.Usage)
+				m.mu.Unlock()
+			}
+			out <- c
+		}
+	}()
+	return out, nil
+}
+
+// total returns the usage summed so far.
+func (m *meteredAgent) total() agent.Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// recordUsage adds usage to the token totals stored on the crumb, so the
+// totals cover every stitch attempt, and returns the new totals. Only the
+// token properties are written, in one transaction.
+func recordUsage(cupboard *crumbs.Cupboard, id string, usage agent.Usage) (agent.Usage, error) {
+	var total agent.Usage
+	err := cupboard.UpdateProperties(id, func(props map[string]any) (map[string]any, error) {
+		stored := func(name string) int {
+			n, _ := props[name].(int)
+			return n
+		}
+		total = usage.Add(agent.Usage{
+			InputTokens:         stored(crumbs.PropInputTokens),
+			OutputTokens:        stored(crumbs.PropOutputTokens),
+			CacheCreationTokens: stored(crumbs.PropCacheCreationTokens),
+			CacheReadTokens:     stored(crumbs.PropCacheReadTokens),
+		})
+		return map[string]any{
+			crumbs.PropInputTokens:         total.InputTokens,
+			crumbs.PropOutputTokens:        total.OutputTokens,
+			crumbs.PropCacheCreationTokens: total.CacheCreationTokens,
+			crumbs.PropCacheReadTokens:     total.CacheReadTokens,
+		}, nil
+	})
+	if err != nil {
+		return agent.Usage{}, err
+	}
+	return total, nil
+}